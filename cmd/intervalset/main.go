@@ -0,0 +1,269 @@
+// Command intervalset performs set operations on lists of int64 intervals, so
+// they can be used in shell pipelines. Lists are read from files, or from the
+// standard input when a file is "-" or none is given, and written in the same
+// compact text format: intervals separated by commas, spaces or newlines, each
+// written as "x-y", or as "x" if it holds a single value. Output lists hold one
+// interval per line, in ascending order, with overlapping and adjacent
+// intervals joined.
+//
+// Usage:
+//
+//	intervalset merge [file...]             union of the lists
+//	intervalset diff file [file...]         first list minus the others
+//	intervalset complement [-min x] [-max y] [file]
+//	                                        values in [x, y] not in the list
+//	intervalset contains file value...      whether each value is in the list
+//	intervalset next file value...          least value not in the list >= each,
+//	                                        or none
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "intervalset:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command in args.
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("Missing command: merge, diff, complement, contains or next")
+	}
+
+	w := bufio.NewWriter(stdout)
+	var err error
+	switch cmd, args := args[0], args[1:]; cmd {
+	case "merge":
+		err = merge(args, stdin, w)
+	case "diff":
+		err = diff(args, stdin, w)
+	case "complement":
+		err = complement(args, stdin, w)
+	case "contains", "next":
+		err = lookup(cmd, args, stdin, w)
+	default:
+		err = fmt.Errorf("Unknown command %q", cmd)
+	}
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func merge(files []string, stdin io.Reader, w io.Writer) error {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	var intervals []intervaltree.Interval[int64]
+	for _, f := range files {
+		l, err := readFile(f, stdin)
+		if err != nil {
+			return err
+		}
+		intervals = append(intervals, l...)
+	}
+
+	t, err := intervaltree.BuildTree(intervals, 1)
+	if err != nil {
+		return err
+	}
+	write(w, t)
+	return nil
+}
+
+func diff(files []string, stdin io.Reader, w io.Writer) error {
+	if len(files) == 0 {
+		return fmt.Errorf("diff needs at least one list")
+	}
+
+	l, err := readFile(files[0], stdin)
+	if err != nil {
+		return err
+	}
+	t, err := intervaltree.BuildTree(l, 1)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files[1:] {
+		l, err := readFile(f, stdin)
+		if err != nil {
+			return err
+		}
+		for _, i := range l {
+			subtract(t, i.I, i.J)
+		}
+	}
+	write(w, t)
+	return nil
+}
+
+// subtract removes the values in [x, y] contained in t from it.
+func subtract(t *intervaltree.Tree[int64], x, y int64) {
+	next := x // Least value of [x, y] yet to be removed
+	for _, g := range t.Gaps(x, y) {
+		if g.I > next {
+			t.Remove(next, g.I-1)
+		}
+		if g.J == y {
+			return
+		}
+		next = g.J + 1
+	}
+	t.Remove(next, y)
+}
+
+func complement(args []string, stdin io.Reader, w io.Writer) error {
+	fs := flag.NewFlagSet("complement", flag.ContinueOnError)
+	lo := fs.Int64("min", math.MinInt64, "least value of the complement")
+	hi := fs.Int64("max", math.MaxInt64, "greatest value of the complement")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	f := "-"
+	if fs.NArg() > 1 {
+		return fmt.Errorf("complement takes a single list")
+	} else if fs.NArg() == 1 {
+		f = fs.Arg(0)
+	}
+	l, err := readFile(f, stdin)
+	if err != nil {
+		return err
+	}
+	t, err := intervaltree.BuildTree(l, 1)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range t.Gaps(*lo, *hi) {
+		writeInterval(w, g.I, g.J)
+	}
+	return nil
+}
+
+func lookup(cmd string, args []string, stdin io.Reader, w io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s needs a list", cmd)
+	}
+
+	l, err := readFile(args[0], stdin)
+	if err != nil {
+		return err
+	}
+	t, err := intervaltree.BuildTree(l, 1)
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range args[1:] {
+		x, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return err
+		}
+		if cmd == "contains" {
+			fmt.Fprintf(w, "%d %t\n", x, t.Contains(x))
+		} else {
+			if n, ok := t.Next(x); ok {
+				fmt.Fprintf(w, "%d %d\n", x, n)
+			} else {
+				fmt.Fprintf(w, "%d none\n", x)
+			}
+		}
+	}
+	return nil
+}
+
+// readFile reads the list in the file named f, or in stdin if f is "-".
+func readFile(f string, stdin io.Reader) ([]intervaltree.Interval[int64], error) {
+	if f == "-" {
+		return read(stdin)
+	}
+
+	r, err := os.Open(f)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	l, err := read(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f, err)
+	}
+	return l, nil
+}
+
+// read reads a list of intervals from r.
+func read(r io.Reader) ([]intervaltree.Interval[int64], error) {
+	s := bufio.NewScanner(r)
+	s.Split(bufio.ScanWords)
+
+	var l []intervaltree.Interval[int64]
+	for s.Scan() {
+		for _, field := range strings.Split(s.Text(), ",") {
+			if field == "" {
+				continue
+			}
+			i, err := parseInterval(field)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, i)
+		}
+	}
+	return l, s.Err()
+}
+
+// parseInterval parses an interval written as "x-y" or "x", where both bounds
+// may be negative.
+func parseInterval(s string) (intervaltree.Interval[int64], error) {
+	sep := strings.IndexByte(s[1:], '-') + 1 // A leading sign is not a separator
+	if sep == 0 {
+		x, err := strconv.ParseInt(s, 10, 64)
+		return intervaltree.Interval[int64]{I: x, J: x}, err
+	}
+
+	x, err := strconv.ParseInt(s[:sep], 10, 64)
+	if err != nil {
+		return intervaltree.Interval[int64]{}, err
+	}
+	y, err := strconv.ParseInt(s[sep+1:], 10, 64)
+	if err != nil {
+		return intervaltree.Interval[int64]{}, err
+	}
+	if x > y {
+		return intervaltree.Interval[int64]{}, intervaltree.InvalidIntervalError[int64]{X: x, Y: y}
+	}
+	return intervaltree.Interval[int64]{I: x, J: y}, nil
+}
+
+// write writes the intervals in t, one per line.
+func write(w io.Writer, t *intervaltree.Tree[int64]) {
+	t.Walk(func(x, y int64) bool {
+		writeInterval(w, x, y)
+		return true
+	})
+}
+
+// writeInterval writes [x, y] in the compact text format, followed by a
+// newline.
+func writeInterval(w io.Writer, x, y int64) {
+	if x == y {
+		fmt.Fprintf(w, "%d\n", x)
+	} else {
+		fmt.Fprintf(w, "%d-%d\n", x, y)
+	}
+}
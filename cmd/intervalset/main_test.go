@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a")
+	b := filepath.Join(dir, "b")
+	os.WriteFile(a, []byte("1-10, 20-30\n-5--3,40\n"), 0o644)
+	os.WriteFile(b, []byte("5-8 25-45"), 0o644)
+
+	for _, c := range []struct {
+		args     []string
+		stdin    string
+		expected string
+	}{
+		{[]string{"merge", a, b}, "", "-5--3\n1-10\n20-45\n"},
+		{[]string{"merge"}, "3,1-2 7", "1-3\n7\n"},
+		{[]string{"merge", a, "-"}, "-2,11", "-5--2\n1-11\n20-30\n40\n"},
+		{[]string{"diff", a, b}, "", "-5--3\n1-4\n9-10\n20-24\n"},
+		{[]string{"diff", b, a}, "", "31-39\n41-45\n"},
+		{[]string{"complement", "-min", "-10", "-max", "25", a}, "", "-10--6\n-2-0\n11-19\n"},
+		{[]string{"contains", a, "-4", "0", "40"}, "", "-4 true\n0 false\n40 true\n"},
+		{[]string{"next", a, "-4", "0", "25"}, "", "-4 -2\n0 0\n25 31\n"},
+		{[]string{"next", "-", "5"}, "0-9223372036854775807", "5 none\n"},
+	} {
+		var out strings.Builder
+		if err := run(c.args, strings.NewReader(c.stdin), &out); err != nil {
+			t.Fatalf("%v: %v", c.args, err)
+		}
+		if out.String() != c.expected {
+			t.Fatalf("%v: unexpected output %q, expected %q", c.args, out.String(), c.expected)
+		}
+	}
+
+	for _, args := range [][]string{
+		{},
+		{"unknown"},
+		{"merge", filepath.Join(dir, "missing")},
+		{"diff"},
+		{"contains", a, "x"},
+	} {
+		if err := run(args, strings.NewReader(""), &strings.Builder{}); err == nil {
+			t.Fatalf("%v: expected an error", args)
+		}
+	}
+	if err := run([]string{"merge"}, strings.NewReader("5-1"), &strings.Builder{}); err == nil {
+		t.Fatal("Expected an error for an invalid interval")
+	}
+}
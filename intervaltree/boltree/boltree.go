@@ -0,0 +1,292 @@
+// Package boltree persists an intervaltree.Tree in a bucket of a bbolt
+// database, for applications that already embed bbolt. Each interval is a
+// record keyed by its first value, holding its last one, both encoded in
+// 8 bytes so that keys sort as the values do. Only the records of the ranges
+// changed since the last save are rewritten.
+//
+// The package does not depend on bbolt: it works on the Bucket and Cursor
+// interfaces, which *bbolt.Bucket satisfies through a two-line wrapper:
+//
+//	type bucket struct{ *bbolt.Bucket }
+//
+//	func (b bucket) Cursor() boltree.Cursor { return b.Bucket.Cursor() }
+package boltree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+// Cursor iterates over the records of a Bucket in the order of their keys, as
+// *bbolt.Cursor does. Every method returns a nil key past the last record.
+type Cursor interface {
+	First() (key, value []byte)
+	Last() (key, value []byte)
+	Seek(seek []byte) (key, value []byte)
+	Next() (key, value []byte)
+	Prev() (key, value []byte)
+}
+
+// Bucket is the subset of the methods of *bbolt.Bucket used by a Store.
+type Bucket interface {
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	Cursor() Cursor
+}
+
+// Store is a tree mirrored into a Bucket. Mutations must go through the Store,
+// which records the ranges they change, so Save only rewrites those. The tree
+// can be read directly.
+type Store[T intervaltree.Integer] struct {
+	tree    *intervaltree.Tree[T]
+	dirty   *intervaltree.Tree[T] // Values whose records may have changed
+	pending *intervaltree.Tree[T] // Values written by the last Save
+	mu      sync.Mutex
+}
+
+// New returns a pointer to a Store of an empty tree created with opts.
+func New[T intervaltree.Integer](opts ...intervaltree.Option) *Store[T] {
+	return newStore(intervaltree.NewTree[T](opts...))
+}
+
+// newStore returns a pointer to a Store of t, with nothing to save.
+func newStore[T intervaltree.Integer](t *intervaltree.Tree[T]) *Store[T] {
+	union := intervaltree.WithOverlapMode(intervaltree.OverlapUnion)
+	return &Store[T]{tree: t, dirty: intervaltree.NewTree[T](union), pending: intervaltree.NewTree[T](union)}
+}
+
+// Load returns a pointer to a Store of a tree created with opts holding the
+// records of b. It fails if they are malformed, out of order or adjacent, as
+// a bucket written by a Store never is.
+func Load[T intervaltree.Integer](b Bucket, opts ...intervaltree.Option) (*Store[T], error) {
+	var intervals []intervaltree.Interval[T]
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		i, err := decode[T](k, v)
+		if err != nil {
+			return nil, err
+		}
+		intervals = append(intervals, i)
+	}
+
+	t, err := intervaltree.NewFromSorted(intervals, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot load bucket: %w", err)
+	}
+	return newStore(t), nil
+}
+
+// Tree returns the tree of the store, for reading it. It must not be mutated
+// other than through the store.
+func (s *Store[T]) Tree() *intervaltree.Tree[T] {
+	return s.tree
+}
+
+// Insert adds [x, y] to the tree, as Tree.Insert does, and marks the range as
+// changed.
+func (s *Store[T]) Insert(x, y T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.tree.Insert(x, y); err != nil {
+		return err
+	}
+	s.touch(x, y)
+	return nil
+}
+
+// Remove deletes [x, y] from the tree, as Tree.Remove does, and marks the range
+// as changed.
+func (s *Store[T]) Remove(x, y T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.tree.Remove(x, y); err != nil {
+		return err
+	}
+	s.touch(x, y)
+	return nil
+}
+
+// touch marks [x, y] as changed, along with the values around it, as the
+// intervals holding them may have been joined with or split from it.
+func (s *Store[T]) touch(x, y T) {
+	if x-1 < x {
+		x--
+	}
+	if y+1 > y {
+		y++
+	}
+	s.dirty.Insert(x, y)
+}
+
+// Save rewrites the records of b in the ranges changed since the last Save,
+// so b mirrors the tree, and should be called within a bbolt transaction. If
+// the transaction is not committed, Rollback must be called before the next
+// Save so the ranges are written again.
+func (s *Store[T]) Save(b Bucket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = s.dirty
+	s.dirty = intervaltree.NewTree[T](intervaltree.WithOverlapMode(intervaltree.OverlapUnion))
+	var err error
+	s.pending.Walk(func(lo, hi T) bool {
+		err = s.saveRange(b, lo, hi)
+		return err == nil
+	})
+	if err != nil {
+		s.rollback()
+	}
+	return err
+}
+
+// Rollback marks the ranges written by the last Save as changed again, after
+// the transaction it wrote in was rolled back.
+func (s *Store[T]) Rollback() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rollback()
+}
+
+// rollback marks the pending ranges as changed. The caller must hold the lock.
+func (s *Store[T]) rollback() {
+	s.pending.Walk(func(x, y T) bool {
+		s.dirty.Insert(x, y)
+		return true
+	})
+	s.pending = intervaltree.NewTree[T](intervaltree.WithOverlapMode(intervaltree.OverlapUnion))
+}
+
+// saveRange replaces the records of b holding values in [lo, hi] with the
+// intervals of the tree holding them.
+func (s *Store[T]) saveRange(b Bucket, lo, hi T) error {
+	old, err := overlapping[T](b.Cursor(), lo, hi)
+	if err != nil {
+		return err
+	}
+	current := s.tree.Overlapping(lo, hi, -1)
+
+	keep := make(map[intervaltree.Interval[T]]bool, len(current))
+	for _, i := range current {
+		keep[i] = true
+	}
+	for _, i := range old {
+		if keep[i] {
+			delete(keep, i) // Already saved
+			continue
+		}
+		if err := b.Delete(encode(i.I)); err != nil {
+			return err
+		}
+	}
+	for _, i := range current {
+		if keep[i] {
+			if err := b.Put(encode(i.I), encode(i.J)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// overlapping returns the intervals of the records read through c holding
+// values in [lo, hi], in ascending order.
+func overlapping[T intervaltree.Integer](c Cursor, lo, hi T) ([]intervaltree.Interval[T], error) {
+	var ret []intervaltree.Interval[T]
+	// The record before the first at or after lo may hold lo
+	k, v := c.Seek(encode(lo))
+	var pk, pv []byte
+	if k == nil {
+		pk, pv = c.Last()
+	} else if !bytes.Equal(k, encode(lo)) {
+		pk, pv = c.Prev()
+	}
+	if pk != nil {
+		i, err := decode[T](pk, pv)
+		if err != nil {
+			return nil, err
+		}
+		if i.J >= lo {
+			ret = append(ret, i)
+		}
+		k, v = c.Seek(encode(lo))
+	}
+
+	for ; k != nil; k, v = c.Next() {
+		i, err := decode[T](k, v)
+		if err != nil {
+			return nil, err
+		}
+		if i.I > hi {
+			break
+		}
+		ret = append(ret, i)
+	}
+	return ret, nil
+}
+
+// Check verifies that the records of b mirror the tree, which holds as long
+// as every change was saved, returning an error describing the first
+// difference found.
+func (s *Store[T]) Check(b Bucket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := b.Cursor()
+	k, v := c.First()
+	var err error
+	s.tree.Walk(func(x, y T) bool {
+		if k == nil {
+			err = fmt.Errorf("Interval [%d, %d] missing from bucket", x, y)
+			return false
+		}
+		var i intervaltree.Interval[T]
+		if i, err = decode[T](k, v); err != nil {
+			return false
+		}
+		if i != (intervaltree.Interval[T]{I: x, J: y}) {
+			err = fmt.Errorf("Bucket holds [%d, %d] instead of [%d, %d]", i.I, i.J, x, y)
+			return false
+		}
+		k, v = c.Next()
+		return true
+	})
+	if err == nil && k != nil {
+		i, _ := decode[T](k, v)
+		err = fmt.Errorf("Bucket holds [%d, %d] not in the tree", i.I, i.J)
+	}
+	return err
+}
+
+// signBit flips the order of negative values of T, so keys sort as the values.
+func signBit[T intervaltree.Integer]() uint64 {
+	var zero T
+	if zero-1 < zero {
+		return 1 << 63
+	}
+	return 0
+}
+
+// encode returns the key encoding x.
+func encode[T intervaltree.Integer](x T) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(x)^signBit[T]())
+}
+
+// decode returns the interval of the record with key k and value v.
+func decode[T intervaltree.Integer](k, v []byte) (intervaltree.Interval[T], error) {
+	if len(k) != 8 || len(v) != 8 {
+		return intervaltree.Interval[T]{}, fmt.Errorf("Malformed record %x: %x", k, v)
+	}
+
+	i := intervaltree.Interval[T]{
+		I: T(binary.BigEndian.Uint64(k) ^ signBit[T]()),
+		J: T(binary.BigEndian.Uint64(v) ^ signBit[T]()),
+	}
+	if uint64(i.I)^signBit[T]() != binary.BigEndian.Uint64(k) || uint64(i.J)^signBit[T]() != binary.BigEndian.Uint64(v) || i.I > i.J {
+		return i, fmt.Errorf("Malformed record %x: %x", k, v)
+	}
+	return i, nil
+}
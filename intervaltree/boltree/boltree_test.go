@@ -0,0 +1,164 @@
+package boltree
+
+import (
+	"bytes"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+// memBucket is an in-memory Bucket holding records sorted by key, as a bbolt
+// bucket does.
+type memBucket struct {
+	keys, values [][]byte
+	writes       int
+}
+
+func (b *memBucket) search(key []byte) int {
+	return sort.Search(len(b.keys), func(k int) bool { return bytes.Compare(b.keys[k], key) >= 0 })
+}
+
+func (b *memBucket) Put(key, value []byte) error {
+	b.writes++
+	k := b.search(key)
+	if k < len(b.keys) && bytes.Equal(b.keys[k], key) {
+		b.values[k] = slices.Clone(value)
+		return nil
+	}
+	b.keys = slices.Insert(b.keys, k, slices.Clone(key))
+	b.values = slices.Insert(b.values, k, slices.Clone(value))
+	return nil
+}
+
+func (b *memBucket) Delete(key []byte) error {
+	b.writes++
+	if k := b.search(key); k < len(b.keys) && bytes.Equal(b.keys[k], key) {
+		b.keys = slices.Delete(b.keys, k, k+1)
+		b.values = slices.Delete(b.values, k, k+1)
+	}
+	return nil
+}
+
+func (b *memBucket) Cursor() Cursor {
+	return &memCursor{b: b}
+}
+
+// memCursor is a Cursor over a memBucket.
+type memCursor struct {
+	b *memBucket
+	k int
+}
+
+func (c *memCursor) at(k int) ([]byte, []byte) {
+	c.k = min(max(k, -1), len(c.b.keys))
+	if c.k < 0 || c.k == len(c.b.keys) {
+		return nil, nil
+	}
+	return c.b.keys[c.k], c.b.values[c.k]
+}
+
+func (c *memCursor) First() ([]byte, []byte)          { return c.at(0) }
+func (c *memCursor) Last() ([]byte, []byte)           { return c.at(len(c.b.keys) - 1) }
+func (c *memCursor) Seek(key []byte) ([]byte, []byte) { return c.at(c.b.search(key)) }
+func (c *memCursor) Next() ([]byte, []byte)           { return c.at(c.k + 1) }
+func (c *memCursor) Prev() ([]byte, []byte)           { return c.at(c.k - 1) }
+
+func TestStore(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var b memBucket
+	s := New[int16]()
+	for i := 0; i < 5000; i++ {
+		x := int16(r.Intn(4000) - 2000)
+		y := x + int16(r.Intn(10))
+		if r.Intn(3) == 0 && s.Tree().Contains(x) {
+			if n, ok := s.Tree().Next(x); ok {
+				y = min(y, n-1)
+			}
+			if err := s.Remove(x, y); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			s.Insert(x, y)
+		}
+
+		if i%100 == 0 {
+			if err := s.Save(&b); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Check(&b); err != nil {
+				t.Fatalf("Operation %d: %v", i, err)
+			}
+		}
+	}
+	s.Save(&b)
+
+	loaded, err := Load[int16](&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got, expected []intervaltree.Interval[int16]
+	loaded.Tree().Walk(func(x, y int16) bool {
+		got = append(got, intervaltree.Interval[int16]{I: x, J: y})
+		return true
+	})
+	s.Tree().Walk(func(x, y int16) bool {
+		expected = append(expected, intervaltree.Interval[int16]{I: x, J: y})
+		return true
+	})
+	if !slices.Equal(got, expected) {
+		t.Fatal("Loaded tree differs from the saved one")
+	}
+
+	// Saving a single change only writes the records around it
+	b.writes = 0
+	s.Insert(30000, 30000)
+	s.Save(&b)
+	if b.writes != 1 {
+		t.Fatalf("Saving a single insertion took %d writes", b.writes)
+	}
+}
+
+func TestStoreRollback(t *testing.T) {
+	s := New[uint8]()
+	s.Insert(10, 20)
+	var aborted memBucket
+	if err := s.Save(&aborted); err != nil {
+		t.Fatal(err)
+	}
+
+	// The transaction is rolled back, so the changes must be written again
+	s.Rollback()
+	var b memBucket
+	s.Insert(0, 0)
+	s.Save(&b)
+	if err := s.Check(&b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStoreConsistency(t *testing.T) {
+	s := New[uint64]()
+	s.Insert(10, 20)
+	s.Insert(30, 40)
+	var b memBucket
+	s.Save(&b)
+
+	b.Put(encode[uint64](50), encode[uint64](60))
+	if err := s.Check(&b); err == nil {
+		t.Fatal("Record not in the tree went unnoticed")
+	}
+	b.Put(encode[uint64](41), encode[uint64](45))
+	if _, err := Load[uint64](&b); err == nil {
+		t.Fatal("Loaded adjacent records")
+	}
+	b.Put(encode[uint64](41), []byte{1})
+	if _, err := Load[uint64](&b); err == nil {
+		t.Fatal("Loaded malformed record")
+	}
+	if _, err := Load[uint8](&memBucket{keys: [][]byte{encode[uint64](256)}, values: [][]byte{encode[uint64](300)}}); err == nil {
+		t.Fatal("Loaded record out of the range of the type")
+	}
+}
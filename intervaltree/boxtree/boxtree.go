@@ -0,0 +1,112 @@
+// Package boxtree provides a two-dimensional interval tree of axis-aligned
+// boxes with uint64 coordinates, for tile and region coverage tracking. Boxes
+// may overlap and are indexed by their horizontal extent in an
+// intervaltree.OverlapTree, and those sharing a horizontal extent by their
+// vertical extent in another one.
+package boxtree
+
+import (
+	"sync"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+// Box represents the closed axis-aligned box holding the points (x, y) with x
+// in X and y in Y.
+type Box struct {
+	X, Y intervaltree.Interval[uint64]
+}
+
+// Tree represents a set of boxes. It is safe for concurrent use.
+type Tree struct {
+	xs    *intervaltree.OverlapTree[uint64]
+	boxes map[intervaltree.Interval[uint64]]*intervaltree.OverlapTree[uint64] // Vertical extents by horizontal extent
+	sync.RWMutex
+}
+
+// New returns a pointer to an empty Tree.
+func New() *Tree {
+	return &Tree{
+		xs:    intervaltree.NewOverlap[uint64](),
+		boxes: make(map[intervaltree.Interval[uint64]]*intervaltree.OverlapTree[uint64]),
+	}
+}
+
+// valid checks if the bounds of b are in order.
+func (b Box) valid() error {
+	if b.X.I > b.X.J {
+		return intervaltree.InvalidIntervalError[uint64]{X: b.X.I, Y: b.X.J}
+	}
+	if b.Y.I > b.Y.J {
+		return intervaltree.InvalidIntervalError[uint64]{X: b.Y.I, Y: b.Y.J}
+	}
+	return nil
+}
+
+// Intersects checks if b and o have at least one point in common.
+func (b Box) Intersects(o Box) bool {
+	return b.X.I <= o.X.J && o.X.I <= b.X.J && b.Y.I <= o.Y.J && o.Y.I <= b.Y.J
+}
+
+// Insert adds b to the tree. It may overlap or be equal to boxes already in
+// the tree.
+func (t *Tree) Insert(b Box) error {
+	if err := b.valid(); err != nil {
+		return err
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	ys, ok := t.boxes[b.X]
+	if !ok {
+		ys = intervaltree.NewOverlap[uint64]()
+		t.boxes[b.X] = ys
+		t.xs.Insert(b.X.I, b.X.J)
+	}
+	return ys.Insert(b.Y.I, b.Y.J)
+}
+
+// Remove deletes one occurrence of b from the tree, which must hold it.
+func (t *Tree) Remove(b Box) error {
+	if err := b.valid(); err != nil {
+		return err
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	ys, ok := t.boxes[b.X]
+	if !ok || ys.Remove(b.Y.I, b.Y.J) != nil {
+		return intervaltree.NotContainedError[Box]{Value: b}
+	}
+	if ys.Len() == 0 {
+		delete(t.boxes, b.X)
+		t.xs.Remove(b.X.I, b.X.J)
+	}
+	return nil
+}
+
+// Contains checks if the point (x, y) is contained in some box of the tree.
+func (t *Tree) Contains(x, y uint64) bool {
+	return len(t.Stab(x, y)) > 0
+}
+
+// Stab returns the boxes in the tree containing the point (x, y).
+func (t *Tree) Stab(x, y uint64) []Box {
+	return t.Intersecting(Box{intervaltree.Interval[uint64]{I: x, J: x}, intervaltree.Interval[uint64]{I: y, J: y}})
+}
+
+// Intersecting returns the boxes in the tree that have at least one point in
+// common with b, ordered by their horizontal extent and then by their vertical
+// one.
+func (t *Tree) Intersecting(b Box) []Box {
+	t.RLock()
+	defer t.RUnlock()
+
+	var ret []Box
+	for _, x := range t.xs.QueryRange(b.X.I, b.X.J) {
+		for _, y := range t.boxes[x].QueryRange(b.Y.I, b.Y.J) {
+			ret = append(ret, Box{x, y})
+		}
+	}
+	return ret
+}
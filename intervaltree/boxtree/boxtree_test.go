@@ -0,0 +1,98 @@
+package boxtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+func box(x0, x1, y0, y1 uint64) Box {
+	return Box{intervaltree.Interval[uint64]{I: x0, J: x1}, intervaltree.Interval[uint64]{I: y0, J: y1}}
+}
+
+func TestBoxes(t *testing.T) {
+	bt := New()
+	for _, b := range []Box{box(0, 9, 0, 9), box(0, 9, 20, 29), box(5, 14, 5, 14), box(5, 14, 5, 14)} {
+		if err := bt.Insert(b); err != nil {
+			t.Fatalf("Failed to insert %v: %v", b, err)
+		}
+	}
+	if err := bt.Insert(box(3, 2, 0, 0)); err == nil {
+		t.Fatal("Inserted invalid box")
+	}
+
+	if !bt.Contains(0, 25) || bt.Contains(12, 25) || bt.Contains(15, 15) {
+		t.Fatal("Unexpected point membership")
+	}
+	if s := bt.Stab(7, 7); len(s) != 3 {
+		t.Fatalf("Unexpected boxes containing (7, 7): %v", s)
+	}
+	if s := bt.Intersecting(box(10, 30, 0, 4)); len(s) != 0 {
+		t.Fatalf("Unexpected boxes intersecting: %v", s)
+	}
+
+	if err := bt.Remove(box(5, 14, 5, 14)); err != nil {
+		t.Fatalf("Failed to remove box: %v", err)
+	}
+	if err := bt.Remove(box(0, 9, 0, 9)); err != nil {
+		t.Fatalf("Failed to remove box: %v", err)
+	}
+	if err := bt.Remove(box(0, 9, 0, 9)); err == nil {
+		t.Fatal("Removed box not in the tree")
+	}
+	if s := bt.Stab(7, 7); len(s) != 1 || s[0] != box(5, 14, 5, 14) {
+		t.Fatalf("Unexpected boxes containing (7, 7) after removal: %v", s)
+	}
+	if !bt.Contains(9, 29) {
+		t.Fatal("Box sharing horizontal extent was removed")
+	}
+}
+
+func TestRandomBoxes(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	bt := New()
+	var ref []Box
+	for i := 0; i < 500; i++ {
+		x, y := uint64(r.Intn(100)), uint64(r.Intn(100))
+		b := box(x, x+uint64(r.Intn(10)), y, y+uint64(r.Intn(10)))
+		bt.Insert(b)
+		ref = append(ref, b)
+	}
+
+	for i := 0; i < 200; i++ {
+		x, y := uint64(r.Intn(110)), uint64(r.Intn(110))
+		q := box(x, x+uint64(r.Intn(5)), y, y+uint64(r.Intn(5)))
+		found := 0
+		for _, b := range ref {
+			if b.Intersects(q) {
+				found++
+			}
+		}
+		if got := len(bt.Intersecting(q)); got != found {
+			t.Fatalf("Intersecting(%v) found %d boxes, expected %d", q, got, found)
+		}
+	}
+}
+
+func TestSharedHorizontalExtent(t *testing.T) {
+	bt := New()
+	for y := uint64(0); y < 1000; y++ {
+		bt.Insert(box(0, 9, 10*y, 10*y+14))
+	}
+	if s := bt.Stab(5, 5007); len(s) != 1 || s[0] != box(0, 9, 5000, 5014) {
+		t.Fatalf("Unexpected boxes containing (5, 5007): %v", s)
+	}
+	if s := bt.Intersecting(box(0, 0, 4994, 5010)); len(s) != 4 || s[0] != box(0, 9, 4980, 4994) || s[3] != box(0, 9, 5010, 5024) {
+		t.Fatalf("Unexpected boxes intersecting: %v", s)
+	}
+
+	for y := uint64(0); y < 1000; y++ {
+		if err := bt.Remove(box(0, 9, 10*y, 10*y+14)); err != nil {
+			t.Fatalf("Failed to remove box: %v", err)
+		}
+	}
+	if len(bt.boxes) != 0 || bt.xs.Len() != 0 {
+		t.Fatal("Emptied horizontal extent was kept")
+	}
+}
@@ -0,0 +1,85 @@
+// Package extentalloc provides an allocator of extents, runs of bytes given by
+// an offset and a length, in a byte address space such as a file or an object
+// store, built on an intervaltree.Allocator.
+package extentalloc
+
+import (
+	"fmt"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+// Extent represents the bytes in [Offset, Offset+Length).
+type Extent struct {
+	Offset, Length uint64
+}
+
+func (e Extent) String() string {
+	return fmt.Sprintf("%d+%d", e.Offset, e.Length)
+}
+
+// last returns the offset of the last byte of e, and whether it is a valid
+// non-empty extent.
+func (e Extent) last() (uint64, bool) {
+	last := e.Offset + e.Length - 1
+	return last, e.Length > 0 && last >= e.Offset
+}
+
+// Allocator hands out extents of an address space. It is safe for concurrent
+// use.
+type Allocator struct {
+	a *intervaltree.Allocator[uint64]
+}
+
+// New returns a pointer to an Allocator of the address space [0, size). The
+// options set the strategy used to place extents, as in
+// intervaltree.NewAllocator.
+func New(size uint64, opts ...intervaltree.Option) (*Allocator, error) {
+	if size == 0 {
+		return nil, fmt.Errorf("Cannot allocate from an empty address space")
+	}
+
+	a, err := intervaltree.NewAllocator(0, size-1, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Allocator{a}, nil
+}
+
+// Alloc allocates an extent of length bytes.
+func (a *Allocator) Alloc(length uint64) (Extent, error) {
+	return a.AllocAligned(length, 1)
+}
+
+// AllocAligned allocates an extent of length bytes starting at a multiple of
+// align, which must be a power of two.
+func (a *Allocator) AllocAligned(length, align uint64) (Extent, error) {
+	offset, err := a.a.AllocAligned(length, align)
+	if err != nil {
+		return Extent{}, err
+	}
+	return Extent{offset, length}, nil
+}
+
+// Reserve allocates e, which must be free, as when loading the extents in use
+// from an existing file.
+func (a *Allocator) Reserve(e Extent) error {
+	last, ok := e.last()
+	if !ok {
+		return intervaltree.InvalidIntervalError[uint64]{X: e.Offset, Y: last}
+	}
+	return a.a.Reserve(e.Offset, last)
+}
+
+// Free releases e, or the part of an allocated extent it covers.
+func (a *Allocator) Free(e Extent) error {
+	if e.Length == 0 {
+		return intervaltree.InvalidIntervalError[uint64]{X: e.Offset, Y: e.Offset - 1}
+	}
+	return a.a.FreeN(e.Offset, e.Length)
+}
+
+// Summary returns a summary of the free space.
+func (a *Allocator) Summary() intervaltree.FreeSpace {
+	return a.a.FreeSpace()
+}
@@ -0,0 +1,58 @@
+package extentalloc
+
+import (
+	"testing"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+func TestAllocator(t *testing.T) {
+	a, err := New(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Reserve(Extent{0, 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		length, align uint64
+		expected      Extent
+	}{
+		{28, 1, Extent{100, 28}},
+		{4096, 4096, Extent{4096, 4096}},
+		{10, 1, Extent{128, 10}},
+		{512, 512, Extent{512, 512}},
+	} {
+		if e, err := a.AllocAligned(c.length, c.align); err != nil || e != c.expected {
+			t.Fatalf("Unexpected extent: %v, %v, expected %v", e, err, c.expected)
+		}
+	}
+
+	if err := a.Free(Extent{4096, 4096}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Free(Extent{4096, 1}); err == nil {
+		t.Fatal("Freed extent twice")
+	}
+	s := a.Summary()
+	if s.Free != 1<<20-650 || s.Largest != 1<<20-1024 || s.Runs != 2 {
+		t.Fatalf("Unexpected summary: %+v", s)
+	}
+
+	if _, err := a.Alloc(1 << 20); err == nil {
+		t.Fatal("Allocated extent larger than the free space")
+	}
+	if err := a.Reserve(Extent{1<<20 - 1, 2}); err == nil {
+		t.Fatal("Reserved extent past the end of the address space")
+	}
+}
+
+func TestBestFit(t *testing.T) {
+	a, _ := New(1000, intervaltree.WithStrategy(intervaltree.BestFit))
+	a.Reserve(Extent{100, 100})
+	a.Reserve(Extent{210, 700})
+	if e, _ := a.Alloc(10); e != (Extent{200, 10}) {
+		t.Fatalf("Unexpected extent: %v", e)
+	}
+}
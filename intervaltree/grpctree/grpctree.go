@@ -0,0 +1,122 @@
+// Package grpctree implements the IntervalTree service of intervaltree.proto
+// on top of an intervaltree.Allocator of uint64 values. Server takes and
+// returns the fields of the messages of the service, so the stubs generated
+// by protoc-gen-go-grpc forward each call to it in a line, and carry the Code
+// of its errors into the gRPC status.
+package grpctree
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+// Code is a gRPC status code, with the values of google.golang.org/grpc/codes.
+type Code uint32
+
+// Codes answered by the service.
+const (
+	InvalidArgument   Code = 3
+	NotFound          Code = 5
+	AlreadyExists     Code = 6
+	ResourceExhausted Code = 8
+	OutOfRange        Code = 11
+)
+
+// Error is returned whenever a call of the service fails, along with the code
+// of the status to answer it with.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
+// Server answers the calls of the service from an allocator.
+type Server struct {
+	a *intervaltree.Allocator[uint64]
+}
+
+// NewServer returns a pointer to a Server answering from a.
+func NewServer(a *intervaltree.Allocator[uint64]) *Server {
+	return &Server{a: a}
+}
+
+// Insert reserves [x, y] and returns the revision of the tree right after,
+// which may include changes made meanwhile by other callers.
+func (s *Server) Insert(x, y uint64) (uint64, error) {
+	if err := s.a.Reserve(x, y); err != nil {
+		return 0, wrap(err)
+	}
+	return s.a.Tree().Revision(), nil
+}
+
+// Remove releases [x, y] and returns the revision of the tree right after,
+// which may include changes made meanwhile by other callers.
+func (s *Server) Remove(x, y uint64) (uint64, error) {
+	if err := s.a.Tree().Remove(x, y); err != nil {
+		return 0, wrap(err)
+	}
+	return s.a.Tree().Revision(), nil
+}
+
+// Query returns whether x is contained in the tree, the least value not
+// contained that is greater or equal to x, and whether there is such a value.
+func (s *Server) Query(x uint64) (contained bool, next uint64, hasNext bool) {
+	next, hasNext = s.a.Tree().Next(x)
+	return s.a.Allocated(x), next, hasNext
+}
+
+// Allocate reserves n consecutive free values starting at a multiple of align
+// and returns the interval they make. Unset, n and align are 1.
+func (s *Server) Allocate(n, align uint64) (x, y uint64, err error) {
+	n, align = max(n, 1), max(align, 1)
+	if x, err = s.a.AllocAligned(n, align); err != nil {
+		return 0, 0, wrap(err)
+	}
+	return x, x + n - 1, nil
+}
+
+// Watch calls send with the changes made to the tree after revision from, and
+// the revision each takes the tree to, as they are made, until ctx is done or
+// send fails. Changes are only kept while a watch needs them, as Tree.Watch
+// does, so from must be the current revision, as returned by the other calls,
+// unless another watch or a checkpoint of the tree retains older changes.
+func (s *Server) Watch(ctx context.Context, from uint64, send func(rev uint64, c intervaltree.Change[uint64]) error) error {
+	err := s.a.Tree().Watch(ctx, from, func(d intervaltree.Delta[uint64]) error {
+		for k, c := range d.Changes {
+			if err := send(d.From+uint64(k)+1, c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.As(err, new(intervaltree.RevisionError)) {
+		return wrap(err)
+	}
+	return err
+}
+
+// wrap returns err along with the code of its kind.
+func wrap(err error) error {
+	code := InvalidArgument
+	switch {
+	case errors.Is(err, intervaltree.ErrNotFound):
+		code = NotFound
+	case errors.Is(err, intervaltree.ErrOverlap):
+		code = AlreadyExists
+	case errors.As(err, new(intervaltree.ExhaustedError[uint64])),
+		errors.As(err, new(intervaltree.CapacityError)):
+		code = ResourceExhausted
+	case errors.As(err, new(intervaltree.RevisionError)):
+		code = OutOfRange
+	}
+	return Error{code, err}
+}
@@ -0,0 +1,161 @@
+package grpctree
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+func code(err error) Code {
+	var e Error
+	if !errors.As(err, &e) {
+		return 0
+	}
+	return e.Code
+}
+
+func TestServer(t *testing.T) {
+	a, _ := intervaltree.NewAllocator[uint64](0, math.MaxUint64)
+	s := NewServer(a)
+
+	if rev, err := s.Insert(10, 19); rev != 1 || err != nil {
+		t.Fatalf("Unexpected insertion result: %d, %v", rev, err)
+	}
+	if _, err := s.Insert(15, 30); code(err) != AlreadyExists {
+		t.Fatalf("Unexpected error inserting overlapping interval: %v", err)
+	}
+	if _, err := s.Insert(5, 1); code(err) != InvalidArgument {
+		t.Fatalf("Unexpected error inserting invalid interval: %v", err)
+	}
+	if contained, next, hasNext := s.Query(12); !contained || next != 20 || !hasNext {
+		t.Fatalf("Unexpected query result: %t, %d, %t", contained, next, hasNext)
+	}
+	if x, y, err := s.Allocate(4, 4); x != 0 || y != 3 || err != nil {
+		t.Fatalf("Unexpected allocation: [%d, %d], %v", x, y, err)
+	}
+	if x, y, err := s.Allocate(0, 0); x != 4 || y != 4 || err != nil {
+		t.Fatalf("Unexpected default allocation: [%d, %d], %v", x, y, err)
+	}
+	if rev, err := s.Remove(0, 3); rev != 4 || err != nil {
+		t.Fatalf("Unexpected removal result: %d, %v", rev, err)
+	}
+	if _, err := s.Remove(0, 3); code(err) != NotFound {
+		t.Fatalf("Unexpected error removing uncontained interval: %v", err)
+	}
+
+	if _, err := s.Insert(20, math.MaxUint64); err != nil {
+		t.Fatal(err)
+	}
+	if contained, _, hasNext := s.Query(30); !contained || hasNext {
+		t.Fatalf("Unexpected query result past the last free value: %t, %t", contained, hasNext)
+	}
+	if _, _, err := s.Allocate(100, 1); code(err) != ResourceExhausted {
+		t.Fatalf("Unexpected error allocating in a full domain: %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	a, _ := intervaltree.NewAllocator[uint64](0, 100)
+	s := NewServer(a)
+	from, _ := s.Insert(1, 2)
+	baseline := a.Tree().MemoryUsage()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan intervaltree.Change[uint64])
+	revs := make(chan uint64)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.Watch(ctx, from, func(rev uint64, c intervaltree.Change[uint64]) error {
+			revs <- rev
+			changes <- c
+			return nil
+		})
+	}()
+	waitJournal(a.Tree(), baseline)
+
+	s.Insert(5, 6)
+	expected := []intervaltree.Change[uint64]{
+		{Interval: intervaltree.Interval[uint64]{I: 5, J: 6}},
+		{Removed: true, Interval: intervaltree.Interval[uint64]{I: 1, J: 2}},
+	}
+	for k, e := range expected {
+		if k == 1 {
+			s.Remove(1, 2)
+		}
+		if rev, c := <-revs, <-changes; rev != from+uint64(k)+1 || c != e {
+			t.Fatalf("Unexpected change %d: %d, %+v", k, rev, c)
+		}
+	}
+
+	// The watch drops the changes it has sent, so another one cannot start
+	// from an older revision.
+	if err := s.Watch(context.Background(), from, nil); code(err) != OutOfRange {
+		t.Fatalf("Unexpected error watching from a revision no longer kept: %v", err)
+	}
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Fatalf("Unexpected error ending the watch: %v", err)
+	}
+
+	if err := s.Watch(context.Background(), 10, nil); code(err) != OutOfRange {
+		t.Fatalf("Unexpected error watching from a future revision: %v", err)
+	}
+}
+
+func TestWatchMemory(t *testing.T) {
+	a, _ := intervaltree.NewAllocator[uint64](0, 100)
+	s := NewServer(a)
+	baseline := a.Tree().MemoryUsage()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	from := a.Tree().Revision()
+	revs := make(chan uint64)
+	errs := make(chan error, 1)
+	const batch = 100
+	go func() {
+		errs <- s.Watch(ctx, from, func(rev uint64, c intervaltree.Change[uint64]) error {
+			if (rev-from)%batch == 0 {
+				revs <- rev
+			}
+			return nil
+		})
+	}()
+	waitJournal(a.Tree(), baseline)
+
+	bound := baseline + 2*batch*uint64(unsafe.Sizeof(intervaltree.Change[uint64]{})) + 256
+	for range 100 {
+		var rev uint64
+		for range batch / 2 {
+			s.Insert(1, 1)
+			rev, _ = s.Remove(1, 1)
+		}
+		if r := <-revs; r != rev {
+			t.Fatalf("Unexpected revision watched: %d, expected %d", r, rev)
+		}
+		if usage := a.Tree().MemoryUsage(); usage > bound {
+			t.Fatalf("Unexpected memory usage after %d revisions: %d, expected at most %d", rev, usage, bound)
+		}
+	}
+
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Fatalf("Unexpected error ending the watch: %v", err)
+	}
+	if usage := a.Tree().MemoryUsage(); usage != baseline {
+		t.Fatalf("Unexpected memory usage once the watch is done: %d, expected %d", usage, baseline)
+	}
+}
+
+// waitJournal waits for a watch to start journaling the changes to t, which
+// then uses more memory than baseline.
+func waitJournal(t *intervaltree.Tree[uint64], baseline uint64) {
+	for t.MemoryUsage() == baseline {
+		runtime.Gosched()
+	}
+}
@@ -0,0 +1,229 @@
+// Package httptree serves an intervaltree.Allocator over HTTP with JSON
+// bodies, so several processes can share a single authoritative set of
+// intervals. Values are encoded as JSON numbers. The endpoints are:
+//
+//	POST /insert   {"x": 1, "y": 5}          reserves [x, y]
+//	POST /remove   {"x": 1, "y": 5}          releases [x, y]
+//	GET  /contains?x=3                       {"contained": true}
+//	GET  /next?x=3                           {"next": 6}, null if none
+//	POST /allocate {"n": 4, "align": 1}      {"x": 8, "y": 11}
+//	GET  /intervals?after=3&limit=2          {"intervals": [{"x": 6, "y": 7}, ...], "after": 9}
+//
+// The intervals endpoint pages through the allocated intervals: it lists up to
+// limit of them, 100 by default and 1000 at most, starting after the value
+// after, or from the least one if it is missing. The after value of the
+// response requests the next page, and is null past the last one.
+//
+// Failures are answered with {"error": "..."} and a status code telling the
+// kind of error apart: 400 for malformed requests and invalid intervals, 404
+// for removing values not contained, 409 for overlaps, exhausted domains and
+// exceeded capacities, and 413 for request bodies over 4 KiB.
+package httptree
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+// Handler serves the intervals of an allocator.
+type Handler[T intervaltree.Integer] struct {
+	a *intervaltree.Allocator[T]
+}
+
+// interval is the body of insert and remove requests and of allocate
+// responses.
+type interval[T intervaltree.Integer] struct {
+	X T `json:"x"`
+	Y T `json:"y"`
+}
+
+// Default and maximum number of intervals listed by a request.
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// maxBody is the greatest number of bytes read from the body of a request.
+const maxBody = 4096
+
+// New returns a pointer to a Handler serving a.
+func New[T intervaltree.Integer](a *intervaltree.Allocator[T]) *Handler[T] {
+	return &Handler[T]{a: a}
+}
+
+// ServeHTTP dispatches the request to its endpoint. Paths are matched by their
+// last element, so the Handler can be mounted under any prefix.
+func (h *Handler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var endpoint func(http.ResponseWriter, *http.Request)
+	method := http.MethodPost
+	switch path.Base(r.URL.Path) {
+	case "insert":
+		endpoint = h.insert
+	case "remove":
+		endpoint = h.remove
+	case "contains":
+		endpoint, method = h.contains, http.MethodGet
+	case "next":
+		endpoint, method = h.next, http.MethodGet
+	case "allocate":
+		endpoint = h.allocate
+	case "intervals":
+		endpoint, method = h.intervals, http.MethodGet
+	default:
+		reply(w, http.StatusNotFound, map[string]string{"error": "Unknown endpoint " + r.URL.Path})
+		return
+	}
+
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		reply(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed: " + r.Method})
+		return
+	}
+	endpoint(w, r)
+}
+
+func (h *Handler[T]) insert(w http.ResponseWriter, r *http.Request) {
+	var i interval[T]
+	if !decode(w, r, &i) {
+		return
+	}
+	if err := h.a.Reserve(i.X, i.Y); err != nil {
+		fail[T](w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler[T]) remove(w http.ResponseWriter, r *http.Request) {
+	var i interval[T]
+	if !decode(w, r, &i) {
+		return
+	}
+	if err := h.a.Tree().Remove(i.X, i.Y); err != nil {
+		fail[T](w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler[T]) contains(w http.ResponseWriter, r *http.Request) {
+	if x, ok := query[T](w, r, "x"); ok {
+		reply(w, http.StatusOK, map[string]bool{"contained": h.a.Allocated(x)})
+	}
+}
+
+func (h *Handler[T]) next(w http.ResponseWriter, r *http.Request) {
+	if x, ok := query[T](w, r, "x"); ok {
+		next, ok := h.a.Tree().Next(x)
+		if !ok {
+			reply(w, http.StatusOK, map[string]*T{"next": nil})
+			return
+		}
+		reply(w, http.StatusOK, map[string]T{"next": next})
+	}
+}
+
+func (h *Handler[T]) allocate(w http.ResponseWriter, r *http.Request) {
+	req := struct {
+		N     uint64 `json:"n"`
+		Align uint64 `json:"align"`
+	}{N: 1, Align: 1}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	x, err := h.a.AllocAligned(req.N, req.Align)
+	if err != nil {
+		fail[T](w, err)
+		return
+	}
+	reply(w, http.StatusOK, interval[T]{x, x + T(req.N-1)})
+}
+
+func (h *Handler[T]) intervals(w http.ResponseWriter, r *http.Request) {
+	limit := defaultLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		var err error
+		if limit, err = strconv.Atoi(s); err != nil || limit < 1 || limit > maxLimit {
+			reply(w, http.StatusBadRequest, map[string]string{"error": "Malformed parameter limit: " + s})
+			return
+		}
+	}
+
+	var page []intervaltree.Interval[T]
+	if r.URL.Query().Has("after") {
+		after, ok := query[T](w, r, "after")
+		if !ok {
+			return
+		}
+		page = h.a.Tree().IntervalsAfter(after, limit)
+	} else {
+		least, _ := intervaltree.Limits[T]()
+		page = h.a.Tree().IntervalsFrom(least, limit)
+	}
+
+	resp := struct {
+		Intervals []interval[T] `json:"intervals"`
+		After     *T            `json:"after"`
+	}{Intervals: make([]interval[T], len(page))}
+	for k, i := range page {
+		resp.Intervals[k] = interval[T]{i.I, i.J}
+	}
+	if len(page) == limit {
+		resp.After = &page[len(page)-1].I
+	}
+	reply(w, http.StatusOK, resp)
+}
+
+// decode reads the JSON body of r into v, answering with an error if it is
+// malformed or longer than maxBody.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	d := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody))
+	d.DisallowUnknownFields()
+	if err := d.Decode(v); err != nil {
+		status := http.StatusBadRequest
+		if errors.As(err, new(*http.MaxBytesError)) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		reply(w, status, map[string]string{"error": "Malformed request: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+// query returns the value in the parameter name of r, answering with an error
+// if it is missing or malformed.
+func query[T intervaltree.Integer](w http.ResponseWriter, r *http.Request, name string) (T, bool) {
+	var x T
+	if err := json.Unmarshal([]byte(r.URL.Query().Get(name)), &x); err != nil {
+		reply(w, http.StatusBadRequest, map[string]string{"error": "Malformed parameter " + name + ": " + err.Error()})
+		return x, false
+	}
+	return x, true
+}
+
+// fail answers with err and a status code for its kind.
+func fail[T intervaltree.Integer](w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, intervaltree.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, intervaltree.ErrOverlap),
+		errors.As(err, new(intervaltree.ExhaustedError[T])),
+		errors.As(err, new(intervaltree.CapacityError)):
+		status = http.StatusConflict
+	}
+	reply(w, status, map[string]string{"error": err.Error()})
+}
+
+// reply writes v as the JSON body of the response.
+func reply(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
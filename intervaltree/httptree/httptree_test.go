@@ -0,0 +1,66 @@
+package httptree
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+func TestHandler(t *testing.T) {
+	a, _ := intervaltree.NewAllocator[int16](-100, 100)
+	s := httptest.NewServer(New(a))
+	defer s.Close()
+
+	for _, c := range []struct {
+		method, path, body string
+		status             int
+		response           string
+	}{
+		{"POST", "/insert", `{"x": -100, "y": -91}`, http.StatusNoContent, ""},
+		{"POST", "/insert", `{"x": -95, "y": 0}`, http.StatusConflict, `{"error":"Tried to insert value already inserted: -95"}`},
+		{"POST", "/insert", `{"x": 5, "y": 1}`, http.StatusBadRequest, `{"error":"Invalid interval: [5, 1]"}`},
+		{"POST", "/insert", `{"x": "a"}`, http.StatusBadRequest, ""},
+		{"POST", "/insert", `{"x": -1` + strings.Repeat(" ", maxBody) + `, "y": 1}`, http.StatusRequestEntityTooLarge, ""},
+		{"GET", "/contains?x=-91", "", http.StatusOK, `{"contained":true}`},
+		{"GET", "/contains?x=-90", "", http.StatusOK, `{"contained":false}`},
+		{"GET", "/next?x=-95", "", http.StatusOK, `{"next":-90}`},
+		{"GET", "/next?x=abc", "", http.StatusBadRequest, ""},
+		{"POST", "/allocate", `{"n": 4, "align": 4}`, http.StatusOK, `{"x":-88,"y":-85}`},
+		{"POST", "/allocate", `{}`, http.StatusOK, `{"x":-90,"y":-90}`},
+		{"POST", "/allocate", `{"n": 1000}`, http.StatusConflict, ""},
+		{"POST", "/remove", `{"x": -100, "y": -95}`, http.StatusNoContent, ""},
+		{"POST", "/remove", `{"x": -100, "y": -95}`, http.StatusNotFound, ""},
+		{"POST", "/insert", `{"x": 50, "y": 60}`, http.StatusNoContent, ""},
+		{"GET", "/intervals", "", http.StatusOK, `{"intervals":[{"x":-94,"y":-90},{"x":-88,"y":-85},{"x":50,"y":60}],"after":null}`},
+		{"GET", "/intervals?limit=2", "", http.StatusOK, `{"intervals":[{"x":-94,"y":-90},{"x":-88,"y":-85}],"after":-88}`},
+		{"GET", "/intervals?after=-88&limit=2", "", http.StatusOK, `{"intervals":[{"x":50,"y":60}],"after":null}`},
+		{"GET", "/intervals?after=50", "", http.StatusOK, `{"intervals":[],"after":null}`},
+		{"GET", "/intervals?limit=0", "", http.StatusBadRequest, ""},
+		{"GET", "/intervals?after=x", "", http.StatusBadRequest, ""},
+		{"GET", "/remove", "", http.StatusMethodNotAllowed, ""},
+		{"GET", "/unknown", "", http.StatusNotFound, ""},
+	} {
+		req, _ := http.NewRequest(c.method, s.URL+c.path, strings.NewReader(c.body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body strings.Builder
+		_, err = io.Copy(&body, resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != c.status {
+			t.Fatalf("%s %s: unexpected status %d, expected %d: %s", c.method, c.path, resp.StatusCode, c.status, body.String())
+		}
+		if c.response != "" && strings.TrimSpace(body.String()) != c.response {
+			t.Fatalf("%s %s: unexpected response %s, expected %s", c.method, c.path, body.String(), c.response)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package interval provides arithmetic on single intervals of integers, such as
+// the ranges inserted into and read from an intervaltree.Tree, so callers do
+// not have to reimplement it. Interval converts to and from
+// intervaltree.Interval, as in interval.Interval[T](i).
+//
+// Intervals are closed: [I, J] holds I, J and every value between them. Methods
+// other than Valid expect valid intervals, with I <= J.
+package interval
+
+import "github.com/alkemir/intervaltree/intervaltree"
+
+// Interval represents the values in [I, J].
+type Interval[T intervaltree.Integer] intervaltree.Interval[T]
+
+// New returns the interval [x, y], failing with an
+// intervaltree.InvalidIntervalError if x > y.
+func New[T intervaltree.Integer](x, y T) (Interval[T], error) {
+	if x > y {
+		return Interval[T]{}, intervaltree.InvalidIntervalError[T]{X: x, Y: y}
+	}
+	return Interval[T]{x, y}, nil
+}
+
+// Point returns the interval holding only x.
+func Point[T intervaltree.Integer](x T) Interval[T] {
+	return Interval[T]{x, x}
+}
+
+// Valid checks if the bounds of i are in order.
+func (i Interval[T]) Valid() bool {
+	return i.I <= i.J
+}
+
+// Len returns the number of values in i, 0 if it holds every value of a 64-bit
+// T. Differences are taken modulo 2^64, which is exact for any Integer type.
+func (i Interval[T]) Len() uint64 {
+	return uint64(i.J) - uint64(i.I) + 1
+}
+
+// Contains checks if x is in i.
+func (i Interval[T]) Contains(x T) bool {
+	return i.I <= x && x <= i.J
+}
+
+// Covers checks if every value of o is in i.
+func (i Interval[T]) Covers(o Interval[T]) bool {
+	return i.I <= o.I && o.J <= i.J
+}
+
+// Overlaps checks if i and o have any value in common.
+func (i Interval[T]) Overlaps(o Interval[T]) bool {
+	return i.I <= o.J && o.I <= i.J
+}
+
+// Adjacent checks if i and o have no value in common but no value between
+// them either, so their union is an interval.
+func (i Interval[T]) Adjacent(o Interval[T]) bool {
+	// Comparing first keeps the increments from overflowing
+	return (i.J < o.I && i.J+1 == o.I) || (o.J < i.I && o.J+1 == i.I)
+}
+
+// Intersect returns the values i and o have in common, and false if they have
+// none.
+func (i Interval[T]) Intersect(o Interval[T]) (Interval[T], bool) {
+	if !i.Overlaps(o) {
+		return Interval[T]{}, false
+	}
+	return Interval[T]{max(i.I, o.I), min(i.J, o.J)}, true
+}
+
+// Union returns the values in i or o, and false if they are not an interval
+// because i and o neither overlap nor are adjacent.
+func (i Interval[T]) Union(o Interval[T]) (Interval[T], bool) {
+	if !i.Overlaps(o) && !i.Adjacent(o) {
+		return Interval[T]{}, false
+	}
+	return Interval[T]{min(i.I, o.I), max(i.J, o.J)}, true
+}
+
+// Split returns the values of i lesser than x and the rest of them, as
+// [I, x-1] and [x, J], and false if either would be empty.
+func (i Interval[T]) Split(x T) (Interval[T], Interval[T], bool) {
+	if x <= i.I || x > i.J {
+		return i, Interval[T]{}, false
+	}
+	return Interval[T]{i.I, x - 1}, Interval[T]{x, i.J}, true
+}
+
+// Subtract returns the values of i not in o, as up to two intervals in
+// ascending order.
+func (i Interval[T]) Subtract(o Interval[T]) []Interval[T] {
+	if !i.Overlaps(o) {
+		return []Interval[T]{i}
+	}
+
+	var ret []Interval[T]
+	if i.I < o.I {
+		ret = append(ret, Interval[T]{i.I, o.I - 1})
+	}
+	if o.J < i.J {
+		ret = append(ret, Interval[T]{o.J + 1, i.J})
+	}
+	return ret
+}
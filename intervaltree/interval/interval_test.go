@@ -0,0 +1,112 @@
+package interval
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+func TestNew(t *testing.T) {
+	if i, err := New(3, 5); err != nil || i != (Interval[int]{3, 5}) {
+		t.Fatalf("Unexpected interval: %v %v", i, err)
+	}
+	if _, err := New(5, 3); !errors.Is(err, intervaltree.ErrInvalidInterval) {
+		t.Fatalf("Expected an invalid interval error, got %v", err)
+	}
+	if !Point(7).Valid() || (Interval[int]{2, 1}).Valid() {
+		t.Fatal("Unexpected validity")
+	}
+
+	it := intervaltree.NewTree[int]()
+	i := Interval[int]{3, 5}
+	it.Insert(i.I, i.J)
+	if got, _ := it.Enclosing(4, 4); Interval[int](got) != i {
+		t.Fatalf("Unexpected conversion: %v", got)
+	}
+}
+
+func TestLen(t *testing.T) {
+	if n := (Interval[int8]{-128, 127}).Len(); n != 256 {
+		t.Fatalf("Unexpected length: %d", n)
+	}
+	if n := (Interval[int64]{math.MinInt64, math.MaxInt64}).Len(); n != 0 {
+		t.Fatalf("Unexpected length of every int64: %d", n)
+	}
+	if n := (Interval[uint16]{10, 19}).Len(); n != 10 {
+		t.Fatalf("Unexpected length: %d", n)
+	}
+	if n := Point[uint8](255).Len(); n != 1 {
+		t.Fatalf("Unexpected length: %d", n)
+	}
+}
+
+func TestRelations(t *testing.T) {
+	a := Interval[int8]{-10, 10}
+	for _, c := range []struct {
+		o                           Interval[int8]
+		overlaps, adjacent, covered bool
+		intersection, union         string
+	}{
+		{Interval[int8]{-5, 5}, true, false, true, "{-5 5} true", "{-10 10} true"},
+		{Interval[int8]{5, 20}, true, false, false, "{5 10} true", "{-10 20} true"},
+		{Interval[int8]{11, 20}, false, true, false, "{0 0} false", "{-10 20} true"},
+		{Interval[int8]{-128, -11}, false, true, false, "{0 0} false", "{-128 10} true"},
+		{Interval[int8]{12, 127}, false, false, false, "{0 0} false", "{0 0} false"},
+		{Interval[int8]{-128, 127}, true, false, false, "{-10 10} true", "{-128 127} true"},
+	} {
+		if a.Overlaps(c.o) != c.overlaps || c.o.Overlaps(a) != c.overlaps {
+			t.Fatalf("Overlaps(%v) = %v", c.o, !c.overlaps)
+		}
+		if a.Adjacent(c.o) != c.adjacent || c.o.Adjacent(a) != c.adjacent {
+			t.Fatalf("Adjacent(%v) = %v", c.o, !c.adjacent)
+		}
+		if a.Covers(c.o) != c.covered {
+			t.Fatalf("Covers(%v) = %v", c.o, !c.covered)
+		}
+		if s := fmt.Sprint(a.Intersect(c.o)); s != c.intersection {
+			t.Fatalf("Intersect(%v) = %s", c.o, s)
+		}
+		if s := fmt.Sprint(a.Union(c.o)); s != c.union {
+			t.Fatalf("Union(%v) = %s", c.o, s)
+		}
+	}
+	greatest := Interval[uint8]{255, 255}
+	if greatest.Adjacent(Interval[uint8]{0, 0}) || !greatest.Adjacent(Interval[uint8]{0, 254}) {
+		t.Fatal("Unexpected adjacency at the greatest value")
+	}
+}
+
+func TestSplitSubtract(t *testing.T) {
+	a := Interval[uint8]{10, 20}
+	for _, c := range []struct {
+		x        uint8
+		expected string
+	}{
+		{15, "{10 14} {15 20} true"},
+		{20, "{10 19} {20 20} true"},
+		{10, "{10 20} {0 0} false"},
+		{21, "{10 20} {0 0} false"},
+	} {
+		if s := fmt.Sprint(a.Split(c.x)); s != c.expected {
+			t.Fatalf("Split(%d) = %s", c.x, s)
+		}
+	}
+
+	for _, c := range []struct {
+		o        Interval[uint8]
+		expected string
+	}{
+		{Interval[uint8]{12, 15}, "[{10 11} {16 20}]"},
+		{Interval[uint8]{0, 15}, "[{16 20}]"},
+		{Interval[uint8]{15, 255}, "[{10 14}]"},
+		{Interval[uint8]{0, 255}, "[]"},
+		{Interval[uint8]{30, 40}, "[{10 20}]"},
+	} {
+		if s := fmt.Sprint(a.Subtract(c.o)); s != c.expected {
+			t.Fatalf("Subtract(%v) = %s", c.o, s)
+		}
+	}
+}
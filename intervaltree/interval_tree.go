@@ -1,139 +1,340 @@
 // Package intervaltree provides a very limited variation of IntervalTree using
-// AVL trees.
+// AVL trees. Trees can hold values of any integer type; IntervalTree holds
+// uint64 values.
 package intervaltree
 
 import (
 	"fmt"
+	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
 )
 
-// IntervalTree represents an IntervalTree to which intervals can be added
-// through Insert(x, y) and membership to an interval in the tree can be checked
-// with Contains(x). This implementation does not support overlapping intervals
-// nor common IntervalTree operations. The next uint64 not contained in the tree
-// can be obtained with Next(x).
-type IntervalTree struct {
-	root *node
-	sync.RWMutex
+// Integer is the set of types that can be used as interval bounds.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Tree represents an IntervalTree over values of type T to which intervals can
+// be added through Insert(x, y) and membership to an interval in the tree can
+// be checked with Contains(x). This implementation does not support
+// overlapping intervals nor common IntervalTree operations. The next T not
+// contained in the tree can be obtained with Next(x).
+type Tree[T Integer] struct {
+	root   *node[T]
+	mu     sync.RWMutex
+	locker rwLocker // Replaces mu if set
+
+	rev        uint64       // Number of successful mutations
+	journal    *journal[T]  // Changes since the last checkpoint, if any was taken
+	pool       *nodePool[T] // Deleted nodes for reuse, nil unless recycling
+	balancing  *Balancing   // Balancing counters, nil unless enabled
+	counters   *counters    // Operation counters, nil unless enabled
+	tracer     Tracer       // Starts spans around mutations, if set
+	logger     *slog.Logger // Logs mutations at debug level, if set
+	audit      *audit       // Log of successful mutations, if enabled
+	capacity   uint64       // Values the tree may contain, if capped
+	capped     bool         // Whether WithCapacity was given
+	remainders bool         // Whether overlaps report the free parts of the interval
+	overlaps   OverlapMode  // What inserting values already contained does
+	progress   ProgressFunc // Reports the progress of long operations, if set
+	fingered   bool         // Whether lookups go through finger
+
+	finger atomic.Pointer[finger[T]] // Result of the last lookup, if fingered
+	group  *groupCommit[T]           // Merges concurrent insertions, if set
+
+	watchMu sync.Mutex
+	watch   chan struct{} // Closed on the next change, if a stream waits for it
+}
+
+// IntervalTree is a Tree of uint64 values.
+type IntervalTree = Tree[uint64]
+
+// Int64Tree is a Tree of int64 values, which may be negative.
+type Int64Tree = Tree[int64]
+
+// Interval represents the closed interval [I, J].
+type Interval[T any] struct {
+	I, J T // Interval bounds
 }
 
 // node holds an interval [I, J] and pointers to nodes holding intervals lesser
 // and greater than its own.
-type node struct {
-	I, J        uint64 // Interval bounds
-	Left, Right *node  // Left and right children
-	height      uint8  // Nodes on the longest path to a leaf (for AVL retracing)
+type node[T Integer] struct {
+	I, J        T        // Interval bounds
+	Left, Right *node[T] // Left and right children
+	height      uint8    // Nodes on the longest path to a leaf (for AVL retracing)
+	covered     uint64   // Values contained in the subtree, 0 if every value of a 64-bit T is
+	intervals   int      // Intervals held by the subtree, for order statistics
 }
 
 // newNode returns a pointer to a new node to be added as a leaf.
-func newNode(x, y uint64) *node {
-	ret := &node{
-		I:      x,
-		J:      y,
-		height: 1,
+func newNode[T Integer](x, y T) *node[T] {
+	ret := &node[T]{
+		I:         x,
+		J:         y,
+		height:    1,
+		covered:   ordinal(y) - ordinal(x) + 1,
+		intervals: 1,
 	}
 	return ret
 }
 
-// insert adds the interval [x, y] to the tree. [x, y] cannot overlap with the
-// current tree. If prunning can be done it will be done.
-func (n *node) insert(x, y uint64, pRef **node) error {
-	if x < n.I && y >= n.I {
-		return OverlapError(n.I)
-	} else if x >= n.I && x <= n.J {
-		return OverlapError(x)
+// nodePool holds nodes deleted from a tree so they can be reused by later
+// insertions. A nil nodePool allocates every node and discards deleted ones.
+type nodePool[T Integer] struct {
+	free    *node[T] // Nodes linked by Left
+	size    int      // Nodes in free
+	recycle bool     // Whether deleted nodes are kept in free
+}
+
+// get returns a node to be added as a leaf, reusing a deleted one if possible.
+func (p *nodePool[T]) get(x, y T) *node[T] {
+	if p == nil || p.free == nil {
+		return newNode(x, y)
 	}
 
-	defer n.rebalance(pRef)
+	n := p.free
+	p.free = n.Left
+	p.size--
+	*n = node[T]{I: x, J: y, height: 1, covered: ordinal(y) - ordinal(x) + 1, intervals: 1}
+	return n
+}
+
+// put keeps n, which has been deleted from the tree, for reuse.
+func (p *nodePool[T]) put(n *node[T]) {
+	if p == nil || !p.recycle {
+		return
+	}
 
-	// New interval is to the left of this nodes interval
-	if y < n.I {
-		if n.I == y+1 { // Neighbour, expand current interval
-			if n.Left == nil {
-				n.I = x
-				return nil
-			}
+	*n = node[T]{Left: p.free}
+	p.free = n
+	p.size++
+}
+
+// maxHeight bounds the height of an AVL tree holding at most 2^63 intervals,
+// which is the most a Tree of uint64 values can hold.
+const maxHeight = 92
+
+// insert adds the interval [x, y] to the subtree rooted at this node, which is
+// referenced by pRef. [x, y] cannot overlap with the current tree. If prunning
+// can be done it will be done. Neighbours are checked by stepping from the
+// greater value towards the lesser one, so no check can overflow at the bounds
+// of T. The descent path is kept in a fixed-size stack and retraced explicitly
+// to rebalance, stopping as soon as a subtree keeps its height.
+func (n *node[T]) insert(x, y T, pRef **node[T], p *nodePool[T], b *Balancing) error {
+	var path [maxHeight]**node[T]
+	depth := 0
+
+	var err error
+	for ref := pRef; ; {
+		n = *ref
+		if x < n.I && y >= n.I {
+			err = n.overlap(n.I)
+			break
+		} else if x >= n.I && x <= n.J {
+			err = n.overlap(x)
+			break
+		}
 
-			// Check if we can join with a child interval
-			if n.Left.J == x-1 { // Absorb our child
-				n.I = n.Left.I
-				n.Left = n.Left.Left
-			} else { // Try to take child from our child
-				g, err := n.Left.tryJoinGreatestFirst(x, &n.Left)
-				if err != nil {
-					return err
+		path[depth] = ref
+		depth++
+		if y < n.I { // New interval is to the left of this nodes interval
+			if n.I == y+1 { // Neighbour, expand current interval
+				if err = n.extendLeft(x, p, b); err == nil {
+					b.coalesced()
 				}
-				n.I = g
+				break
+			} else if n.Left == nil { // Not neighbouring, create child
+				n.Left = p.get(x, y)
+				break
 			}
-			return nil
+			ref = &n.Left // We have a child, let it handle this interval
+		} else { // New interval is to the right of this nodes interval
+			if n.J == x-1 { // Neighbour, expand current interval
+				if err = n.extendRight(y, p, b); err == nil {
+					b.coalesced()
+				}
+				break
+			} else if n.Right == nil { // Not neighbouring, create child
+				n.Right = p.get(x, y)
+				break
+			}
+			ref = &n.Right // We have a child, let it handle this interval
 		}
+	}
 
-		// Not neighbouring
-		if n.Left == nil { // Create child
-			n.Left = newNode(x, y)
-			return nil
+	b.reached(depth)
+	if oe, ok := err.(OverlapError[T]); ok {
+		oe.Attempted = Interval[T]{x, y}
+		err = oe
+	}
+
+	// Retrace until a subtree keeps its height, as the balance of its ancestors
+	// is unaffected. Their covered values still have to be updated.
+	for depth > 0 {
+		depth--
+		ref := path[depth]
+		h := (*ref).height
+		(*ref).rebalance(ref, b)
+		if (*ref).height == h {
+			break
 		}
+	}
+	for depth > 0 {
+		depth--
+		(*path[depth]).updateCovered()
+	}
+	return err
+}
 
-		// We have a child, let it handle this interval
-		return n.Left.insert(x, y, &n.Left)
+// overlap returns an OverlapError at value v for colliding with the interval
+// of this node. The attempted interval is set by insert.
+func (n *node[T]) overlap(v T) OverlapError[T] {
+	return OverlapError[T]{Value: v, Existing: Interval[T]{n.I, n.J}}
+}
+
+// extendLeft expands the interval of this node down to x, joining it with the
+// greatest interval in its left subtree if they become neighbours.
+func (n *node[T]) extendLeft(x T, p *nodePool[T], b *Balancing) error {
+	if n.Left == nil {
+		n.I = x
+		return nil
 	}
 
-	// New interval is to the right of this nodes interval
-	if n.J == x-1 { // Neighbour, expand current interval
-		if n.Right == nil {
-			n.J = y
-			return nil
+	// Check if we can join with a child interval
+	if n.Left.J+1 == x { // Absorb our child
+		if n.Left.Right != nil { // Its greater intervals lie in [x, y]
+			l := n.Left.Right.least()
+			return l.overlap(l.I)
 		}
+		l := n.Left
+		n.I, n.Left = l.I, l.Left
+		p.put(l)
+		b.coalesced()
+		return nil
+	}
 
-		// Check if we can join with a child interval
-		if n.Right.I == y+1 { // Absorb our child
-			n.J = n.Right.J
-			n.Right = n.Right.Right
-		} else { // Try to take child from our child
-			l, err := n.Right.tryJoinLeastFirst(y, &n.Right)
-			if err != nil {
-				return err
-			}
-			n.J = l
-		}
+	// Try to take child from our child
+	g, err := n.Left.tryJoinGreatestFirst(x, &n.Left, p, b)
+	if err != nil {
+		return err
+	}
+	n.I = g
+	return nil
+}
+
+// extendRight expands the interval of this node up to y, joining it with the
+// least interval in its right subtree if they become neighbours.
+func (n *node[T]) extendRight(y T, p *nodePool[T], b *Balancing) error {
+	if n.Right == nil {
+		n.J = y
 		return nil
 	}
 
-	// Not neighbouring
-	if n.Right == nil { // Create child
-		n.Right = newNode(x, y)
+	// Check if we can join with a child interval
+	if n.Right.I-1 == y { // Absorb our child
+		if n.Right.Left != nil { // Its lesser intervals lie in [x, y]
+			l := n.Right.Left.least()
+			return l.overlap(l.I)
+		}
+		r := n.Right
+		n.J, n.Right = r.J, r.Right
+		p.put(r)
+		b.coalesced()
 		return nil
 	}
 
-	// We have a child, let it handle this interval
-	return n.Right.insert(x, y, &n.Right)
+	// Try to take child from our child
+	l, err := n.Right.tryJoinLeastFirst(y, &n.Right, p, b)
+	if err != nil {
+		return err
+	}
+	n.J = l
+	return nil
 }
 
 // rebalance fixes AVL invariants violations by applying rotations.
-func (n *node) rebalance(nRef **node) {
+// Rotations are counted in b.
+func (n *node[T]) rebalance(nRef **node[T], b *Balancing) {
 	bal := n.balanceFactor()
 	if bal == 2 {
 		if n.Left.balanceFactor() < 0 {
-			n.preRotateRight()
+			n.Left.rotateRight(&n.Left)
+			b.rotated()
 		}
 		n.rotateLeft(nRef)
+		b.rotated()
+		return
 	} else if bal == -2 {
 		if n.Right.balanceFactor() > 0 {
-			n.preRotateLeft()
+			n.Right.rotateLeft(&n.Right)
+			b.rotated()
 		}
 		n.rotateRight(nRef)
+		b.rotated()
+		return
 	}
 
+	n.updateHeight()
+}
+
+// updateHeight recalculates the height and the covered values of this node
+// from its children.
+func (n *node[T]) updateHeight() {
 	n.height = max(n.Left.getHeight(), n.Right.getHeight()) + 1
+	n.updateCovered()
+}
+
+// updateCovered recalculates the covered values and the intervals of this
+// node from its children.
+func (n *node[T]) updateCovered() {
+	n.covered = n.Left.getCovered() + n.Right.getCovered() + ordinal(n.J) - ordinal(n.I) + 1
+	n.intervals = n.Left.getIntervals() + n.Right.getIntervals() + 1
+}
+
+// getCovered returns the number of values contained in the subtree rooted at
+// this node, 0 if every value of a 64-bit T is.
+func (n *node[T]) getCovered() uint64 {
+	if n == nil {
+		return 0
+	}
+	return n.covered
+}
+
+// getIntervals returns the number of intervals held by the subtree rooted at
+// this node.
+func (n *node[T]) getIntervals() int {
+	if n == nil {
+		return 0
+	}
+	return n.intervals
+}
+
+// updatePath recalculates the covered values of the nodes on the path from
+// this node to the node holding the interval starting at x, bottom-up.
+func (n *node[T]) updatePath(x T) {
+	if n == nil {
+		return
+	}
+	if x < n.I {
+		n.Left.updatePath(x)
+	} else if x > n.I {
+		n.Right.updatePath(x)
+	}
+	n.updateCovered()
 }
 
 // balanceFactor calculates the balance factor for this node.
-func (n *node) balanceFactor() int8 {
+func (n *node[T]) balanceFactor() int8 {
 	return int8(n.Left.getHeight() - n.Right.getHeight())
 }
 
 // getHeight returns the number of nodes in the longest path to a leaf
-func (n *node) getHeight() uint8 {
+func (n *node[T]) getHeight() uint8 {
 	if n == nil {
 		return 0
 	}
@@ -142,141 +343,244 @@ func (n *node) getHeight() uint8 {
 
 // tryJoinGreatestFirst starts a tryJoinGreatest invocation chain. The first
 // case is special (nRef is not &p.Right), thats why this function exists.
-func (n *node) tryJoinGreatestFirst(x uint64, nRef **node) (uint64, error) {
+func (n *node[T]) tryJoinGreatestFirst(x T, nRef **node[T], p *nodePool[T], b *Balancing) (T, error) {
 	if x <= n.J {
-		return x, OverlapError(n.J)
+		return x, n.overlap(n.J)
 	}
 	if n.Right == nil {
 		return x, nil
 	}
 
-	defer n.rebalance(nRef)
-	return n.Right.tryJoinGreatest(x, n)
+	g, err := n.Right.tryJoinGreatest(x, n, p, b)
+	n.rebalance(nRef, b)
+	return g, err
 }
 
 // tryJoinLeastFirst starts a tryJoinLeast invocation chain. The first case is
 // special (nRef is not &p.Left), thats why this function exists.
-func (n *node) tryJoinLeastFirst(y uint64, nRef **node) (uint64, error) {
+func (n *node[T]) tryJoinLeastFirst(y T, nRef **node[T], p *nodePool[T], b *Balancing) (T, error) {
 	if y >= n.I {
-		return y, OverlapError(n.I)
+		return y, n.overlap(n.I)
 	}
 	if n.Left == nil {
 		return y, nil
 	}
 
-	defer n.rebalance(nRef)
-	return n.Left.tryJoinLeast(y, n)
+	l, err := n.Left.tryJoinLeast(y, n, p, b)
+	n.rebalance(nRef, b)
+	return l, err
 }
 
 // tryJoinGreatest returns the lower endpoint of the greatest interval in the
 // children of n if its upper endpoint is a neighbour of x and also removes this
 // interval. Otherwise it returns x.
-func (n *node) tryJoinGreatest(x uint64, p *node) (uint64, error) {
-	defer n.rebalance(&p.Right)
-	if n.Right == nil { // n is the greatest interval
-		if x <= n.J {
-			return x, OverlapError(n.J)
-		}
-		if n.J == x-1 { // n neighbours
-			p.Right = n.Left
-			return n.I, nil
-		}
-		return x, nil
+func (n *node[T]) tryJoinGreatest(x T, parent *node[T], p *nodePool[T], b *Balancing) (T, error) {
+	if n.Right != nil {
+		g, err := n.Right.tryJoinGreatest(x, n, p, b)
+		n.rebalance(&parent.Right, b)
+		return g, err
 	}
-	return n.Right.tryJoinGreatest(x, n)
+
+	// n is the greatest interval
+	if x <= n.J {
+		return x, n.overlap(n.J)
+	}
+	if n.J == x-1 { // n neighbours
+		i := n.I
+		parent.Right = n.Left
+		p.put(n)
+		b.coalesced()
+		return i, nil
+	}
+	return x, nil
 }
 
 // tryJoinLeast returns the upper endpoint of the least interval in the children
 // of n if its lower endpoint is a neighbour of x and also removes this
 // interval. Otherwise it returns x.
-func (n *node) tryJoinLeast(y uint64, p *node) (uint64, error) {
-	defer n.rebalance(&p.Left)
-	if n.Left == nil { // n is the least interval
-		if y >= n.I {
-			return y, OverlapError(n.I)
-		}
-		if n.I == y+1 { // n neighbours
-			p.Left = n.Right
-			return n.J, nil
-		}
-		return y, nil
+func (n *node[T]) tryJoinLeast(y T, parent *node[T], p *nodePool[T], b *Balancing) (T, error) {
+	if n.Left != nil {
+		l, err := n.Left.tryJoinLeast(y, n, p, b)
+		n.rebalance(&parent.Left, b)
+		return l, err
+	}
+
+	// n is the least interval
+	if y >= n.I {
+		return y, n.overlap(n.I)
+	}
+	if n.I == y+1 { // n neighbours
+		j := n.J
+		parent.Left = n.Right
+		p.put(n)
+		b.coalesced()
+		return j, nil
 	}
-	return n.Left.tryJoinLeast(y, n)
+	return y, nil
 }
 
 // rotateLeft performs a left tree rotation.
-func (n *node) rotateLeft(nRef **node) {
+func (n *node[T]) rotateLeft(nRef **node[T]) {
 	pivot := n.Left
 	n.Left = n.Left.Right
 	pivot.Right = n
 	*nRef = pivot
+	n.updateHeight()
+	pivot.updateHeight()
 }
 
 // rotateRight performs a right tree rotation.
-func (n *node) rotateRight(nRef **node) {
+func (n *node[T]) rotateRight(nRef **node[T]) {
 	pivot := n.Right
 	n.Right = n.Right.Left
 	pivot.Left = n
 	*nRef = pivot
+	n.updateHeight()
+	pivot.updateHeight()
 }
 
-// preRotateRight performs the first rotation in a LeftRight case
-func (n *node) preRotateRight() {
-	pivot := n.Left
-	n.Left = pivot.Right
-	pivot.Right = n.Left.Left
-	n.Left.Left = pivot
+// least returns the node holding the least interval in the subtree rooted at
+// this node.
+func (n *node[T]) least() *node[T] {
+	for n.Left != nil {
+		n = n.Left
+	}
+	return n
 }
 
-// preRotateLeft performs the first rotation in a RightLeft case
-func (n *node) preRotateLeft() {
-	pivot := n.Right
-	n.Right = pivot.Left
-	pivot.Left = n.Right.Right
-	n.Right.Right = pivot
+// greatest returns the node holding the greatest interval in the subtree
+// rooted at this node.
+func (n *node[T]) greatest() *node[T] {
+	for n.Right != nil {
+		n = n.Right
+	}
+	return n
 }
 
-// contains checks recursively if x is contained in this node or its children.
-func (n *node) contains(x uint64) bool {
-	if n == nil {
-		return false
+// contains checks if x is contained in the subtree rooted at this node.
+func (n *node[T]) contains(x T) bool {
+	return n.containingNode(x) != nil
+}
+
+// containingNode returns the node holding the interval that contains x in the
+// subtree rooted at this node. If x is not contained it returns nil. The tree
+// is descended in a loop, so lookups need no call frames.
+func (n *node[T]) containingNode(x T) *node[T] {
+	for n != nil {
+		if x < n.I {
+			n = n.Left
+		} else if x > n.J {
+			n = n.Right
+		} else {
+			return n
+		}
 	}
+	return nil
+}
 
-	if n.I <= x && x <= n.J {
-		return true
-	} else if x < n.I {
-		return n.Left.contains(x)
-	} else {
-		return n.Right.contains(x)
+// remove deletes the node holding the interval starting at x from the subtree
+// rooted at this node. Such a node must exist.
+func (n *node[T]) remove(x T, nRef **node[T], p *nodePool[T], b *Balancing) {
+	if x < n.I {
+		n.Left.remove(x, &n.Left, p, b)
+	} else if x > n.I {
+		n.Right.remove(x, &n.Right, p, b)
+	} else if n.Left == nil {
+		*nRef = n.Right
+		p.put(n)
+		return
+	} else if n.Right == nil {
+		*nRef = n.Left
+		p.put(n)
+		return
+	} else { // Replace this interval with the next one
+		next := n.Right.removeLeast(&n.Right, b)
+		n.I, n.J = next.I, next.J
+		p.put(next)
 	}
+
+	n.rebalance(nRef, b)
 }
 
-// containingNode checks recursively for the node holding the interval that
-// contains x and returns this node. If x is not contained it returns nil.
-func (n *node) containingNode(x uint64) *node {
+// removeLeast deletes the node holding the least interval from the subtree
+// rooted at this node and returns it.
+func (n *node[T]) removeLeast(nRef **node[T], b *Balancing) *node[T] {
+	if n.Left == nil {
+		*nRef = n.Right
+		return n
+	}
+
+	least := n.Left.removeLeast(&n.Left, b)
+	n.rebalance(nRef, b)
+	return least
+}
+
+// walk calls fn recursively for the intervals of this node and its children in
+// ascending order. It stops as soon as fn returns false, and reports whether
+// the walk was completed.
+func (n *node[T]) walk(fn func(x, y T) bool) bool {
 	if n == nil {
-		return nil
+		return true
 	}
 
-	if n.I <= x && x <= n.J {
-		return n
-	} else if x < n.I {
-		return n.Left.containingNode(x)
-	} else {
-		return n.Right.containingNode(x)
+	return n.Left.walk(fn) && fn(n.I, n.J) && n.Right.walk(fn)
+}
+
+// signed reports whether T is a signed integer type.
+func signed[T Integer]() bool {
+	var zero T
+	return zero-1 < zero
+}
+
+// Limits returns the least and the greatest values of type T.
+func Limits[T Integer]() (least, greatest T) {
+	var zero T
+	if !signed[T]() {
+		return zero, ^zero
+	}
+
+	greatest = T(1<<(unsafe.Sizeof(zero)*8-1) - 1)
+	return -greatest - 1, greatest
+}
+
+// ordinal maps x to a uint64 preserving the order of the values of type T, so
+// that unsigned arithmetic can be done on values of any Integer type.
+func ordinal[T Integer](x T) uint64 {
+	if signed[T]() {
+		return uint64(x) ^ 1<<63
+	}
+	return uint64(x)
+}
+
+// fromOrdinal returns the value of type T whose ordinal is u, and whether such
+// a value exists.
+func fromOrdinal[T Integer](u uint64) (T, bool) {
+	x := T(u)
+	if signed[T]() {
+		x = T(int64(u ^ 1<<63))
 	}
+	return x, ordinal(x) == u
 }
 
-// max returns the greatest of two uint8
-func max(a, b uint8) uint8 {
-	if a > b {
-		return a
+// walkRange calls fn recursively for the intervals of this node and its
+// children that overlap [x, y], in ascending order. It stops as soon as fn
+// returns false, and reports whether the walk was completed.
+func (n *node[T]) walkRange(x, y T, fn func(i, j T) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	if x < n.I && !n.Left.walkRange(x, y, fn) {
+		return false
+	}
+	if x <= n.J && n.I <= y && !fn(n.I, n.J) {
+		return false
 	}
-	return b
+	return y <= n.J || n.Right.walkRange(x, y, fn)
 }
 
 // print SPrints recursively the intervals contained in this tree
-func (n *node) print() string {
+func (n *node[T]) print() string {
 	if n == nil {
 		return ""
 	}
@@ -285,48 +589,275 @@ func (n *node) print() string {
 }
 
 // ToString returns a string representing all the intervals contained in the tree
-func (t *IntervalTree) ToString() string {
+func (t *Tree[T]) ToString() string {
 	return t.root.print()
 }
 
-// Contains checks recursively if x is contained in this node or its children.
-func (t *IntervalTree) Contains(x uint64) bool {
+// Contains checks if x is contained in the tree.
+func (t *Tree[T]) Contains(x T) bool {
 	t.RLock()
 	defer t.RUnlock()
+	return t.contains(x)
+}
+
+// contains checks if x is contained in the tree, counting the lookup and
+// checking the finger first if enabled. The caller must hold the lock.
+func (t *Tree[T]) contains(x T) bool {
+	t.counters.inc(lookups)
+	if t.fingered {
+		return t.lookup(x).holds(x)
+	}
 	return t.root.contains(x)
 }
 
 // Next returns the minimum value not contained in the tree that is greater or
-// equal to x.
-func (t *IntervalTree) Next(x uint64) uint64 {
+// equal to x. It returns false if every such value is contained.
+func (t *Tree[T]) Next(x T) (T, bool) {
 	t.RLock()
 	defer t.RUnlock()
+	t.counters.inc(lookups)
+	if t.fingered {
+		if f := t.lookup(x); f.holds(x) {
+			return after(f.j)
+		}
+		return x, true
+	}
 	c := t.root.containingNode(x)
 	if c == nil {
-		return x
+		return x, true
 	}
 
-	return c.J + 1
+	return after(c.J)
+}
+
+// after returns the value following j, and false if j is the greatest value
+// of T.
+func after[T Integer](j T) (T, bool) {
+	return j + 1, j+1 > j
+}
+
+// Walk calls fn for the intervals in the tree in ascending order. It stops as
+// soon as fn returns false. The tree cannot be changed from fn.
+func (t *Tree[T]) Walk(fn func(x, y T) bool) {
+	t.RLock()
+	defer t.RUnlock()
+	t.root.walk(fn)
+}
+
+// Gaps returns the maximal intervals within [x, y] that hold no value contained
+// in the tree, in ascending order.
+func (t *Tree[T]) Gaps(x, y T) []Interval[T] {
+	t.RLock()
+	defer t.RUnlock()
+	return t.gaps(x, y)
+}
+
+// gaps returns the maximal intervals within [x, y] that hold no value contained
+// in the tree. The caller must hold the lock.
+func (t *Tree[T]) gaps(x, y T) []Interval[T] {
+	var gaps []Interval[T]
+	next, done := x, false
+	t.root.walkRange(x, y, func(i, j T) bool {
+		if next < i {
+			gaps = append(gaps, Interval[T]{next, i - 1})
+		}
+		if j >= y {
+			done = true
+			return false
+		}
+		next = j + 1
+		return true
+	})
+
+	if !done && next <= y {
+		gaps = append(gaps, Interval[T]{next, y})
+	}
+	return gaps
+}
+
+// mutation times and traces a single insertion or removal, if enabled.
+type mutation struct {
+	start time.Time
+	span  Span
+}
+
+// observe starts timing and tracing the operation op on [x, y].
+func (t *Tree[T]) observe(op string, x, y T) mutation {
+	var m mutation
+	if t.counters != nil {
+		m.start = time.Now()
+	}
+	if t.tracer != nil {
+		m.span = t.traceInterval(op, x, y)
+	}
+	return m
+}
+
+// observed adds the time elapsed since m started to the counter k, and ends
+// its span with err.
+func (t *Tree[T]) observed(m mutation, k counter, err error) {
+	if t.counters != nil {
+		t.counters.since(k, m.start)
+	}
+	if m.span != nil {
+		m.span.End(err)
+	}
 }
 
 // Insert adds an interval to the tree. The interval cannot overlap with the
 // tree. If prunning is possible it will be done.
-func (t *IntervalTree) Insert(x, y uint64) error {
+func (t *Tree[T]) Insert(x, y T) (err error) {
+	m := t.observe("intervaltree.Insert", x, y)
+	defer func() { t.observed(m, insertTime, err) }()
 	if x > y {
-		return InvalidIntervalError{x, y}
+		return t.failed(insertErrors, x, y, InvalidIntervalError[T]{x, y})
 	}
 
+	if t.group != nil {
+		return t.group.insert(t, x, y)
+	}
 	t.Lock()
 	defer t.Unlock()
+	return t.insertObserved(m, x, y)
+}
+
+// insertObserved adds the valid interval [x, y] to the tree, as insert does,
+// recording the rotations it performs on the span of m. The caller must hold
+// the write lock.
+func (t *Tree[T]) insertObserved(m mutation, x, y T) error {
+	if m.span != nil {
+		defer t.traceRotations(m.span, t.balancing.Rotations)
+	}
+	return t.insert(x, y)
+}
+
+// insert adds the valid interval [x, y] to the tree, following its
+// OverlapMode. The caller must hold the write lock.
+func (t *Tree[T]) insert(x, y T) error {
+	if t.overlaps != OverlapFail {
+		return t.insertLenient(x, y)
+	}
+	return t.insertStrict(x, y)
+}
+
+// insertStrict adds the valid interval [x, y] to the tree, failing if it
+// overlaps the tree. The caller must hold the write lock.
+func (t *Tree[T]) insertStrict(x, y T) error {
+	if t.capped {
+		if err := t.checkCapacity(x, y); err != nil {
+			return t.failed(insertErrors, x, y, err)
+		}
+	}
+
 	if t.root == nil { // First interval
-		t.root = newNode(x, y)
-		return nil
+		t.root = t.pool.get(x, y)
+	} else if err := t.root.insert(x, y, &t.root, t.pool, t.balancing); err != nil {
+		if oe, ok := err.(OverlapError[T]); ok && t.remainders {
+			err = PartialOverlapError[T]{oe, t.gaps(x, y)}
+		}
+		return t.failed(insertErrors, x, y, err)
+	}
+
+	t.record(Change[T]{Interval: Interval[T]{x, y}})
+	if t.logger != nil {
+		t.logInserted(x, y)
+	}
+	return nil
+}
+
+// Remove deletes the interval [x, y] from the tree. Every value in [x, y] must
+// be contained in the tree. Stored intervals are shrunk or split as needed.
+func (t *Tree[T]) Remove(x, y T) (err error) {
+	m := t.observe("intervaltree.Remove", x, y)
+	defer func() { t.observed(m, removeTime, err) }()
+	if x > y {
+		return t.failed(removeErrors, x, y, InvalidIntervalError[T]{x, y})
 	}
 
-	return t.root.insert(x, y, &t.root)
+	t.Lock()
+	defer t.Unlock()
+	if m.span != nil {
+		defer t.traceRotations(m.span, t.balancing.Rotations)
+	}
+	return t.remove(x, y)
 }
 
-// New returns a pointer to an empty IntervalTree.
-func New() *IntervalTree {
-	return &IntervalTree{}
+// remove deletes the valid interval [x, y] from the tree. The caller must hold
+// the write lock.
+func (t *Tree[T]) remove(x, y T) error {
+	// Stored intervals are never adjacent, so [x, y] must lie in a single one
+	c := t.root.containingNode(x)
+	if c == nil {
+		return t.failed(removeErrors, x, y, NotContainedError[T]{x})
+	}
+	if y > c.J {
+		return t.failed(removeErrors, x, y, NotContainedError[T]{c.J + 1})
+	}
+
+	switch {
+	case x == c.I && y == c.J:
+		t.root.remove(c.I, &t.root, t.pool, t.balancing)
+	case x == c.I:
+		c.I = y + 1
+		t.root.updatePath(c.I)
+	case y == c.J:
+		c.J = x - 1
+		t.root.updatePath(c.I)
+	default: // Split, the upper part becomes a new node
+		j := c.J
+		c.J = x - 1
+		t.root.insert(y+1, j, &t.root, t.pool, t.balancing)
+	}
+
+	t.record(Change[T]{Removed: true, Interval: Interval[T]{x, y}})
+	if t.logger != nil {
+		t.log("Interval removed", slog.Any("start", x), slog.Any("end", y))
+	}
+	return nil
+}
+
+// New returns a pointer to an empty IntervalTree configured by opts.
+func New(opts ...Option) *IntervalTree {
+	return NewTree[uint64](opts...)
+}
+
+// NewInt64 returns a pointer to an empty Int64Tree configured by opts.
+func NewInt64(opts ...Option) *Int64Tree {
+	return NewTree[int64](opts...)
+}
+
+// NewUnlocked returns a pointer to an empty IntervalTree configured by opts
+// that does no locking, for use from a single goroutine.
+func NewUnlocked(opts ...Option) *IntervalTree {
+	return NewTree[uint64](append(opts, WithoutLocking())...)
+}
+
+// NewTree returns a pointer to an empty Tree of values of type T configured by
+// opts.
+func NewTree[T Integer](opts ...Option) *Tree[T] {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	t := &Tree[T]{locker: c.locker, tracer: c.tracer, logger: c.logger, capacity: c.capacity, capped: c.capped, remainders: c.remainders, overlaps: c.overlaps, progress: c.progress, fingered: c.finger}
+	if c.recycle {
+		t.pool = &nodePool[T]{recycle: true}
+	}
+	if c.balancing || c.tracer != nil {
+		t.balancing = &Balancing{}
+	}
+	if c.grouped {
+		t.group = &groupCommit[T]{}
+	}
+	if c.metrics || c.expvarName != "" {
+		t.counters = &counters{}
+	}
+	if c.audit != nil {
+		t.audit = &audit{w: c.audit}
+	}
+	if c.expvarName != "" {
+		t.publishExpvar(c.expvarName)
+	}
+	return t
 }
@@ -0,0 +1,99 @@
+package intervaltree
+
+// AckTracker tracks the acknowledged sequence numbers of a protocol, such as
+// the segments of a transport, starting at 0. Acknowledging a sequence number
+// twice is not an error. It reports them as a cumulative acknowledgement and
+// as blocks for selective acknowledgement schemes such as TCP SACK. It is safe
+// for concurrent use unless created with WithoutLocking.
+type AckTracker[T Integer] struct {
+	t     *Tree[T]
+	last  T    // Last sequence number acknowledged, guarded by the lock of t
+	acked bool // Whether any sequence number was acknowledged
+}
+
+// NewAckTracker returns a pointer to an AckTracker with no sequence number
+// acknowledged, backed by a tree created with opts. The tree always takes the
+// union of the acknowledged sequence numbers, whatever its OverlapMode.
+func NewAckTracker[T Integer](opts ...Option) *AckTracker[T] {
+	opts = append(opts[:len(opts):len(opts)], WithOverlapMode(OverlapUnion))
+	return &AckTracker[T]{t: NewTree[T](opts...)}
+}
+
+// Ack acknowledges the sequence number seq.
+func (a *AckTracker[T]) Ack(seq T) error {
+	return a.AckRange(seq, seq)
+}
+
+// AckRange acknowledges the sequence numbers in [x, y].
+func (a *AckTracker[T]) AckRange(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	a.t.Lock()
+	defer a.t.Unlock()
+	if err := a.t.insert(x, y); err != nil {
+		return err
+	}
+	a.last, a.acked = y, true
+	return nil
+}
+
+// Acked checks if the sequence number seq was acknowledged.
+func (a *AckTracker[T]) Acked(seq T) bool {
+	return a.t.Contains(seq)
+}
+
+// CumulativeAck returns the largest n such that every sequence number in
+// [0, n] was acknowledged, and false if 0 was not.
+func (a *AckTracker[T]) CumulativeAck() (T, bool) {
+	a.t.RLock()
+	defer a.t.RUnlock()
+
+	if c := a.t.root.containingNode(0); c != nil {
+		return c.J, true
+	}
+	return 0, false
+}
+
+// SackBlocks returns up to max disjoint blocks of acknowledged sequence numbers
+// past the cumulative acknowledgement, as selective acknowledgement schemes
+// report them: the block holding the last sequence number acknowledged first,
+// then the others in descending order.
+func (a *AckTracker[T]) SackBlocks(max int) []Interval[T] {
+	a.t.RLock()
+	defer a.t.RUnlock()
+
+	var blocks []Interval[T]
+	if max <= 0 || !a.acked {
+		return blocks
+	}
+	recent := a.t.root.containingNode(a.last)
+	if recent != nil && !(recent.I <= 0 && recent.J >= 0) {
+		blocks = append(blocks, Interval[T]{recent.I, recent.J})
+	}
+	a.t.root.walkReverse(func(x, y T) bool {
+		if x <= 0 && y >= 0 {
+			return false // Below here, everything is cumulatively acknowledged
+		}
+		if len(blocks) == max {
+			return false
+		}
+		if recent == nil || x != recent.I {
+			blocks = append(blocks, Interval[T]{x, y})
+		}
+		return true
+	})
+	return blocks
+}
+
+// walkReverse calls fn recursively for the intervals of this node and its
+// children in descending order. It stops as soon as fn returns false, and
+// reports whether the walk was completed.
+func (n *node[T]) walkReverse(fn func(x, y T) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	return n.Right.walkReverse(fn) && fn(n.I, n.J) && n.Left.walkReverse(fn)
+}
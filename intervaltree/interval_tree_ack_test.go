@@ -0,0 +1,55 @@
+package intervaltree
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestAckTracker(t *testing.T) {
+	a := NewAckTracker[uint32]()
+	if _, ok := a.CumulativeAck(); ok {
+		t.Fatal("Cumulative acknowledgement without acknowledging 0")
+	}
+	if b := a.SackBlocks(3); len(b) != 0 {
+		t.Fatalf("Unexpected blocks: %v", b)
+	}
+
+	a.AckRange(10, 19)
+	a.AckRange(30, 39)
+	a.AckRange(50, 59)
+	a.Ack(70)
+	a.AckRange(35, 45) // Partially acknowledged again
+	if _, ok := a.CumulativeAck(); ok {
+		t.Fatal("Cumulative acknowledgement without acknowledging 0")
+	}
+
+	for _, c := range []struct {
+		max      int
+		expected string
+	}{
+		{0, "[]"},
+		{1, "[{30 45}]"},
+		{3, "[{30 45} {70 70} {50 59}]"},
+		{10, "[{30 45} {70 70} {50 59} {10 19}]"},
+	} {
+		if b := fmt.Sprint(a.SackBlocks(c.max)); b != c.expected {
+			t.Fatalf("SackBlocks(%d) = %s, expected %s", c.max, b, c.expected)
+		}
+	}
+
+	a.AckRange(0, 12)
+	if n, ok := a.CumulativeAck(); !ok || n != 19 {
+		t.Fatalf("Unexpected cumulative acknowledgement: %d %v", n, ok)
+	}
+	if b := fmt.Sprint(a.SackBlocks(10)); b != "[{70 70} {50 59} {30 45}]" {
+		t.Fatalf("Unexpected blocks: %s", b)
+	}
+	if !a.Acked(40) || a.Acked(20) {
+		t.Fatal("Unexpected acknowledged sequence numbers")
+	}
+
+	if err := a.AckRange(5, 4); !errors.Is(err, ErrInvalidInterval) {
+		t.Fatalf("Expected an invalid interval error, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package intervaltree
+
+import "sync/atomic"
+
+// actorBatch is the greatest number of queued mutations an ActorTree applies
+// before publishing the new root and replying to their callers.
+const actorBatch = 1024
+
+// actorOp is a mutation sent to the goroutine of an ActorTree.
+type actorOp[T Integer] struct {
+	Change[T]
+	reply chan error // Receives the result of the mutation
+}
+
+// ActorTree represents a set of intervals owned by a single goroutine, which
+// applies every mutation in the order it receives them through a channel, so
+// writers never contend on a lock. Mutations queued meanwhile are applied
+// together, and their callers wait for the result through a future. Nodes are
+// copied on write, as a COWTree does, so readers load the root published after
+// each batch and never block.
+type ActorTree[T Integer] struct {
+	root atomic.Pointer[node[T]]
+	ops  chan actorOp[T]
+	done chan struct{}
+}
+
+// NewActor returns a pointer to an empty ActorTree whose queue holds up to
+// buffer mutations, and starts its goroutine. Mutations block while the queue
+// is full.
+func NewActor[T Integer](buffer int) *ActorTree[T] {
+	a := &ActorTree[T]{ops: make(chan actorOp[T], buffer), done: make(chan struct{})}
+	go a.run()
+	return a
+}
+
+// InsertAsync queues the insertion of [x, y] and returns a future receiving
+// its result, as Insert would return it, once the insertion is visible to
+// readers.
+func (a *ActorTree[T]) InsertAsync(x, y T) <-chan error {
+	return a.send(Change[T]{false, Interval[T]{x, y}})
+}
+
+// RemoveAsync queues the removal of [x, y] and returns a future receiving its
+// result, as Remove would return it, once the removal is visible to readers.
+func (a *ActorTree[T]) RemoveAsync(x, y T) <-chan error {
+	return a.send(Change[T]{true, Interval[T]{x, y}})
+}
+
+// send queues c and returns the channel receiving its result.
+func (a *ActorTree[T]) send(c Change[T]) <-chan error {
+	reply := make(chan error, 1)
+	a.ops <- actorOp[T]{c, reply}
+	return reply
+}
+
+// Insert adds an interval to the tree and waits until it is applied. The
+// interval cannot overlap with the tree.
+func (a *ActorTree[T]) Insert(x, y T) error {
+	return <-a.InsertAsync(x, y)
+}
+
+// Remove deletes the interval [x, y] from the tree and waits until it is
+// applied. Every value in [x, y] must be contained in the tree.
+func (a *ActorTree[T]) Remove(x, y T) error {
+	return <-a.RemoveAsync(x, y)
+}
+
+// Contains checks if x is contained in the tree. It never blocks.
+func (a *ActorTree[T]) Contains(x T) bool {
+	return a.root.Load().contains(x)
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to x. It returns false if every such value is contained. It never
+// blocks.
+func (a *ActorTree[T]) Next(x T) (T, bool) {
+	c := a.root.Load().containingNode(x)
+	if c == nil {
+		return x, true
+	}
+	return after(c.J)
+}
+
+// Walk calls fn for the intervals in the tree in ascending order, as they were
+// when Walk was called. It stops as soon as fn returns false. It never blocks.
+func (a *ActorTree[T]) Walk(fn func(x, y T) bool) {
+	a.root.Load().walk(fn)
+}
+
+// Snapshot returns a COWTree holding the intervals of the tree in O(1), so a
+// reader sees a consistent state across many calls. Changing either tree does
+// not affect the other.
+func (a *ActorTree[T]) Snapshot() *COWTree[T] {
+	t := NewCOW[T]()
+	t.root.Store(a.root.Load())
+	return t
+}
+
+// Close waits until the queued mutations are applied and stops the goroutine.
+// The tree can still be read, but not changed, afterwards.
+func (a *ActorTree[T]) Close() {
+	close(a.ops)
+	<-a.done
+}
+
+// run applies the queued mutations in batches until the queue is closed. The
+// results are sent once the batch is published, so callers read their writes.
+func (a *ActorTree[T]) run() {
+	defer close(a.done)
+	batch := make([]actorOp[T], 0, actorBatch)
+	errs := make([]error, 0, actorBatch)
+	for op := range a.ops {
+		batch = append(batch[:0], op)
+		for len(batch) < actorBatch && len(a.ops) > 0 {
+			batch = append(batch, <-a.ops)
+		}
+
+		root := a.root.Load()
+		errs = errs[:0]
+		for _, op := range batch {
+			var next *node[T]
+			var err error
+			if op.Removed {
+				next, err = cowRemoveInterval(root, op.I, op.J)
+			} else {
+				next, err = cowInsertInterval(root, op.I, op.J)
+			}
+			if err == nil {
+				root = next
+			}
+			errs = append(errs, err)
+		}
+		a.root.Store(root)
+		for k, op := range batch {
+			op.reply <- errs[k]
+		}
+	}
+}
+
+var _ Set[uint64] = (*ActorTree[uint64])(nil)
@@ -0,0 +1,72 @@
+package intervaltree
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestActorTree(t *testing.T) {
+	a := NewActor[int32](16)
+	if err := a.Insert(5, 1); !errors.Is(err, ErrInvalidInterval) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for w := int32(0); w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			futures := make([]<-chan error, 0, 500)
+			for k := int32(0); k < 500; k++ {
+				futures = append(futures, a.InsertAsync(w*1000+2*k, w*1000+2*k))
+			}
+			for _, f := range futures {
+				if err := <-f; err != nil {
+					t.Error(err)
+				}
+			}
+			if !a.Contains(w*1000 + 998) { // Replies follow publication
+				t.Error("Insertion not visible once applied")
+			}
+		}()
+	}
+	wg.Wait()
+
+	snapshot := a.Snapshot()
+	if err := a.Remove(0, 2); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := a.Insert(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Remove(0, 2); err != nil {
+		t.Fatal(err)
+	}
+	if !snapshot.Contains(0) || snapshot.Contains(1) || a.Contains(0) {
+		t.Fatal("Snapshot changed along with the tree")
+	}
+	snapshot.Insert(1, 1)
+	if a.Contains(1) {
+		t.Fatal("Changing the snapshot changed the tree")
+	}
+
+	a.RemoveAsync(4, 4)
+	a.Close()
+	if a.Contains(4) || walkString[int32](a) != walkString[int32](a.Snapshot()) {
+		t.Fatal("Close did not apply the queued mutations")
+	}
+}
+
+func BenchmarkActorTree(b *testing.B) {
+	a := NewActor[uint64](1 << 10)
+	defer a.Close()
+	var next atomic.Uint64
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			x := next.Add(2)
+			a.Insert(x, x)
+		}
+	})
+}
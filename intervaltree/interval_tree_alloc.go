@@ -0,0 +1,248 @@
+package intervaltree
+
+import "fmt"
+
+// ExhaustedError is returned whenever an Allocator cannot find N consecutive
+// free values in its domain [Lo, Hi].
+type ExhaustedError[T any] struct {
+	Lo, Hi T
+	N      uint64
+}
+
+func (e ExhaustedError[T]) Error() string {
+	return fmt.Sprintf("No %d consecutive free values in [%v, %v]", e.N, e.Lo, e.Hi)
+}
+
+// Strategy selects where an Allocator places the values it allocates.
+type Strategy int
+
+// Allocation strategies.
+const (
+	FirstFit Strategy = iota // Least free values, which is fast and keeps free space at the top
+	BestFit                  // Values in the smallest gap that holds them, which limits fragmentation
+	NextFit                  // Least free values past the last allocation, wrapping around
+)
+
+// WithStrategy makes an Allocator place the values it allocates following s.
+// The default is FirstFit.
+func WithStrategy(s Strategy) Option {
+	return func(c *config) {
+		c.strategy = s
+	}
+}
+
+// Allocator hands out unique values, such as IDs, from the domain [lo, hi],
+// keeping the allocated ones as intervals in a Tree. Finding and taking a free
+// value is a single operation under the lock of the tree, so it is safe for
+// concurrent use unless created with WithoutLocking.
+type Allocator[T Integer] struct {
+	t        *Tree[T]
+	lo, hi   T
+	strategy Strategy
+	cursor   T // Where NextFit starts looking
+}
+
+// NewAllocator returns a pointer to an Allocator of the values in [lo, hi],
+// backed by a tree created with opts.
+func NewAllocator[T Integer](lo, hi T, opts ...Option) (*Allocator[T], error) {
+	if lo > hi {
+		return nil, InvalidIntervalError[T]{lo, hi}
+	}
+
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &Allocator[T]{t: NewTree[T](opts...), lo: lo, hi: hi, strategy: c.strategy, cursor: lo}, nil
+}
+
+// Alloc allocates a free value, placed following the strategy of the
+// allocator, and returns it.
+func (a *Allocator[T]) Alloc() (T, error) {
+	return a.AllocN(1)
+}
+
+// AllocN allocates n consecutive free values, placed following the strategy
+// of the allocator, and returns the first of them.
+func (a *Allocator[T]) AllocN(n uint64) (T, error) {
+	return a.AllocAligned(n, 1)
+}
+
+// AllocAligned allocates n consecutive free values starting at a multiple of
+// align, which must be a power of two, placed following the strategy of the
+// allocator, and returns the first of them.
+func (a *Allocator[T]) AllocAligned(n, align uint64) (T, error) {
+	if n == 0 {
+		return a.lo, fmt.Errorf("Cannot allocate 0 values")
+	}
+	if align == 0 || align&(align-1) != 0 {
+		return a.lo, fmt.Errorf("Alignment %d is not a power of two", align)
+	}
+
+	a.t.Lock()
+	defer a.t.Unlock()
+
+	var x T
+	var ok bool
+	switch a.strategy {
+	case BestFit:
+		x, ok = a.t.bestFit(a.lo, a.hi, n, align)
+	case NextFit:
+		if x, ok = a.t.firstFit(a.cursor, a.hi, n, align); !ok {
+			x, ok = a.t.firstFit(a.lo, a.hi, n, align)
+		}
+	default:
+		x, ok = a.t.firstFit(a.lo, a.hi, n, align)
+	}
+	if !ok {
+		return a.lo, ExhaustedError[T]{a.lo, a.hi, n}
+	}
+
+	y, _ := fromOrdinal[T](ordinal(x) + n - 1)
+	m := a.t.observe("intervaltree.Insert", x, y)
+	err := a.t.insertObserved(m, x, y)
+	a.t.observed(m, insertTime, err)
+	if err != nil {
+		return a.lo, err // The tree is over its capacity
+	}
+	if a.cursor = y + 1; y == a.hi {
+		a.cursor = a.lo
+	}
+	return x, nil
+}
+
+// Reserve allocates the values in [x, y], which must lie in the domain of the
+// allocator and be free.
+func (a *Allocator[T]) Reserve(x, y T) error {
+	if x > y || x < a.lo || y > a.hi {
+		return InvalidIntervalError[T]{x, y}
+	}
+	return a.t.Insert(x, y)
+}
+
+// Free releases the allocated value x.
+func (a *Allocator[T]) Free(x T) error {
+	return a.FreeN(x, 1)
+}
+
+// FreeN releases the n allocated values starting at x.
+func (a *Allocator[T]) FreeN(x T, n uint64) error {
+	y, ok := fromOrdinal[T](ordinal(x) + n - 1)
+	if n == 0 || !ok || y < x {
+		return InvalidIntervalError[T]{x, y}
+	}
+	return a.t.Remove(x, y)
+}
+
+// FreeSpace summarizes the free values of an Allocator. Free and Largest are
+// 0 when every value of a 64-bit T is free, which Runs tells apart from having
+// no free values.
+type FreeSpace struct {
+	Free    uint64 // Free values
+	Largest uint64 // Values in the largest run of consecutive free values
+	Runs    int    // Runs of consecutive free values, as a measure of fragmentation
+}
+
+// FreeSpace returns a summary of the free values of the allocator.
+func (a *Allocator[T]) FreeSpace() FreeSpace {
+	a.t.RLock()
+	defer a.t.RUnlock()
+
+	var s FreeSpace
+	a.t.walkGaps(a.lo, a.hi, func(x T, size uint64) bool {
+		s.Free += size
+		if s.Runs == 0 || size-1 > s.Largest-1 { // 0 stands for 2^64
+			s.Largest = size
+		}
+		s.Runs++
+		return true
+	})
+	return s
+}
+
+// Tree returns the tree holding the allocated values, for queries beyond those
+// of the allocator.
+func (a *Allocator[T]) Tree() *Tree[T] {
+	return a.t
+}
+
+// Full checks if every value in the domain of the allocator is allocated, so
+// the next allocation is bound to fail.
+func (a *Allocator[T]) Full() bool {
+	return a.t.IsFull(a.lo, a.hi)
+}
+
+// Allocated checks if x is allocated.
+func (a *Allocator[T]) Allocated(x T) bool {
+	return a.t.Contains(x)
+}
+
+// walkGaps calls fn for the gaps between the intervals in the tree within
+// [lo, hi] in ascending order, with their first value and their number of
+// values, 0 if they hold every value of a 64-bit T. It stops as soon as fn
+// returns false. The caller must hold the lock.
+func (t *Tree[T]) walkGaps(lo, hi T, fn func(x T, size uint64) bool) {
+	next, more := ordinal(lo), true // Least value that may be free
+	t.root.walkRange(lo, hi, func(i, j T) bool {
+		if ordinal(i) > next {
+			x, _ := fromOrdinal[T](next)
+			if !fn(x, ordinal(i)-next) {
+				more = false
+				return false
+			}
+		}
+		more, next = j < hi, ordinal(j)+1
+		return more
+	})
+
+	if more {
+		x, _ := fromOrdinal[T](next)
+		fn(x, ordinal(hi)-next+1)
+	}
+}
+
+// fit returns where n values starting at a multiple of align fit in the gap
+// of size values starting at x, and whether they fit. Values are aligned by
+// their ordinals, which for powers of two is the same as by their values.
+func fit[T Integer](x T, size, n, align uint64) (T, bool) {
+	start := (ordinal(x) + align - 1) &^ (align - 1)
+	waste := start - ordinal(x)
+	if start < ordinal(x) || (size != 0 && waste >= size) {
+		return x, false
+	}
+
+	// Sizes of 0 stand for 2^64, so the arithmetic works modulo 2^64
+	avail := size - waste
+	y, _ := fromOrdinal[T](start)
+	return y, avail == 0 || avail >= n
+}
+
+// firstFit returns the least value in [lo, hi] starting n consecutive values
+// not contained in the tree and a multiple of align, and whether there is one.
+// The caller must hold the lock.
+func (t *Tree[T]) firstFit(lo, hi T, n, align uint64) (T, bool) {
+	var found T
+	ok := false
+	t.walkGaps(lo, hi, func(x T, size uint64) bool {
+		found, ok = fit(x, size, n, align)
+		return !ok
+	})
+	return found, ok
+}
+
+// bestFit returns the first aligned value of the smallest gap in [lo, hi]
+// holding n values starting at a multiple of align, the least one among
+// equally small gaps, and whether there is one. The caller must hold the lock.
+func (t *Tree[T]) bestFit(lo, hi T, n, align uint64) (T, bool) {
+	var found T
+	var best uint64 // Size of the best gap found
+	ok := false
+	t.walkGaps(lo, hi, func(x T, size uint64) bool {
+		// Sizes are compared minus one, so that 0 stands for 2^64
+		if y, fits := fit(x, size, n, align); fits && (!ok || size-1 < best-1) {
+			found, best, ok = y, size, true
+		}
+		return !ok || best != n // An exact fit cannot be improved
+	})
+	return found, ok
+}
@@ -0,0 +1,241 @@
+package intervaltree
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestAllocator(t *testing.T) {
+	a, err := NewAllocator[uint8](10, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for expected := uint8(10); expected <= 20; expected++ {
+		if x, err := a.Alloc(); err != nil || x != expected {
+			t.Fatalf("Unexpected allocation: %d, %v, expected %d", x, err, expected)
+		}
+	}
+	var exhausted ExhaustedError[uint8]
+	if _, err := a.Alloc(); !errors.As(err, &exhausted) || exhausted.N != 1 {
+		t.Fatalf("Unexpected error allocating from exhausted domain: %v", err)
+	}
+
+	a.Free(12)
+	a.FreeN(15, 3)
+	if err := a.Free(15); err == nil {
+		t.Fatal("Freed value twice")
+	}
+	if x, err := a.AllocN(2); err != nil || x != 15 {
+		t.Fatalf("Unexpected allocation of 2: %d, %v", x, err)
+	}
+	if _, err := a.AllocN(2); err == nil {
+		t.Fatal("Allocated 2 values with no gap large enough")
+	}
+	if x, _ := a.Alloc(); x != 12 || !a.Allocated(12) {
+		t.Fatalf("Unexpected allocation: %d", x)
+	}
+	if _, err := a.AllocN(0); err == nil {
+		t.Fatal("Allocated 0 values")
+	}
+	if _, err := NewAllocator[uint8](2, 1); err == nil {
+		t.Fatal("Created allocator of an empty domain")
+	}
+}
+
+func TestAllocatorFullDomain(t *testing.T) {
+	a, _ := NewAllocator[uint64](0, math.MaxUint64)
+	if x, err := a.AllocN(math.MaxUint64); err != nil || x != 0 {
+		t.Fatalf("Unexpected allocation: %d, %v", x, err)
+	}
+	if x, err := a.Alloc(); err != nil || x != math.MaxUint64 {
+		t.Fatalf("Unexpected allocation: %d, %v", x, err)
+	}
+	if _, err := a.Alloc(); err == nil {
+		t.Fatal("Allocated from exhausted domain")
+	}
+	if err := a.FreeN(math.MaxUint64, 2); err == nil {
+		t.Fatal("Freed values past the end of the domain")
+	}
+}
+
+func TestAllocatorConcurrent(t *testing.T) {
+	a, _ := NewAllocator[int32](-1000, 999)
+	var wg sync.WaitGroup
+	ids := make(chan int32, 2000)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < 250; k++ {
+				x, err := a.Alloc()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				ids <- x
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int32]bool)
+	for x := range ids {
+		if seen[x] {
+			t.Fatalf("Value %d allocated twice", x)
+		}
+		seen[x] = true
+	}
+	if len(seen) != 2000 {
+		t.Fatalf("Unexpected number of allocations: %d", len(seen))
+	}
+}
+
+func TestAllocatorStrategies(t *testing.T) {
+	for _, c := range []struct {
+		strategy Strategy
+		expected []uint16 // Allocations of 2 values
+	}{
+		{FirstFit, []uint16{3, 20, 22, 24, 26}},
+		{BestFit, []uint16{40, 3, 20, 22, 24}},
+		{NextFit, []uint16{3, 20, 22, 24, 26}},
+	} {
+		a, _ := NewAllocator[uint16](0, 50, WithStrategy(c.strategy))
+		// Free gaps: [3, 5], [20, 29], [40, 41], [50, 50]
+		a.AllocN(3)
+		a.t.Insert(6, 19)
+		a.t.Insert(30, 39)
+		a.t.Insert(42, 49)
+		for k, expected := range c.expected {
+			if x, err := a.AllocN(2); err != nil || x != expected {
+				t.Fatalf("Unexpected allocation %d with strategy %d: %d, %v, expected %d", k, c.strategy, x, err, expected)
+			}
+		}
+	}
+}
+
+func TestNextFitWraps(t *testing.T) {
+	a, _ := NewAllocator[uint8](0, 3, WithStrategy(NextFit))
+	for _, expected := range []uint8{0, 1, 2} {
+		if x, _ := a.Alloc(); x != expected {
+			t.Fatalf("Unexpected allocation: %d, expected %d", x, expected)
+		}
+	}
+	a.Free(0)
+	a.Free(1)
+	for _, expected := range []uint8{3, 0, 1} {
+		if x, _ := a.Alloc(); x != expected {
+			t.Fatalf("Unexpected allocation after wrapping: %d, expected %d", x, expected)
+		}
+	}
+}
+
+func TestBestFitFullDomain(t *testing.T) {
+	a, _ := NewAllocator[uint64](0, math.MaxUint64, WithStrategy(BestFit))
+	if x, err := a.Alloc(); err != nil || x != 0 {
+		t.Fatalf("Unexpected allocation: %d, %v", x, err)
+	}
+	a.t.Insert(10, 10)
+	if x, err := a.AllocN(3); err != nil || x != 1 {
+		t.Fatalf("Unexpected allocation: %d, %v", x, err)
+	}
+	if x, err := a.AllocN(7); err != nil || x != 11 {
+		t.Fatalf("Unexpected allocation: %d, %v", x, err)
+	}
+}
+
+func TestAllocatorReserve(t *testing.T) {
+	a, _ := NewAllocator[int8](-10, 10)
+	if err := a.Reserve(-10, -5); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Reserve(-6, 0); err == nil {
+		t.Fatal("Reserved allocated values")
+	}
+	if err := a.Reserve(5, 11); err == nil {
+		t.Fatal("Reserved values outside of the domain")
+	}
+	if x, _ := a.Alloc(); x != -4 {
+		t.Fatalf("Unexpected allocation: %d", x)
+	}
+}
+
+func TestAllocAligned(t *testing.T) {
+	a, _ := NewAllocator[int16](-100, 100)
+	for _, c := range []struct {
+		n, align uint64
+		expected int16
+	}{
+		{1, 1, -100},
+		{4, 8, -96},
+		{1, 1, -99},
+		{10, 16, -80},
+		{2, 4, -92},
+		{3, 128, 0},
+	} {
+		if x, err := a.AllocAligned(c.n, c.align); err != nil || x != c.expected {
+			t.Fatalf("Unexpected allocation of %d aligned to %d: %d, %v, expected %d", c.n, c.align, x, err, c.expected)
+		}
+	}
+	if _, err := a.AllocAligned(1, 256); err == nil {
+		t.Fatal("Allocated value aligned beyond the domain")
+	}
+	if _, err := a.AllocAligned(1, 3); err == nil {
+		t.Fatal("Allocated with alignment not a power of two")
+	}
+
+	full, _ := NewAllocator[uint64](0, math.MaxUint64)
+	full.Reserve(0, 0)
+	if x, err := full.AllocAligned(1, 1<<63); err != nil || x != 1<<63 {
+		t.Fatalf("Unexpected allocation: %d, %v", x, err)
+	}
+	if _, err := full.AllocAligned(1, 1<<63); err == nil {
+		t.Fatal("Allocated past the end of the domain")
+	}
+}
+
+func TestFreeSpace(t *testing.T) {
+	a, _ := NewAllocator[uint8](0, 99)
+	a.Reserve(10, 19)
+	a.Reserve(50, 59)
+	a.Reserve(95, 99)
+	if s := a.FreeSpace(); s != (FreeSpace{Free: 75, Largest: 35, Runs: 3}) {
+		t.Fatalf("Unexpected free space: %+v", s)
+	}
+	a.Reserve(0, 9)
+	a.Reserve(20, 49)
+	a.Reserve(60, 94)
+	if s := a.FreeSpace(); s != (FreeSpace{}) {
+		t.Fatalf("Unexpected free space: %+v", s)
+	}
+
+	full, _ := NewAllocator[uint64](0, math.MaxUint64)
+	if s := full.FreeSpace(); s != (FreeSpace{Free: 0, Largest: 0, Runs: 1}) {
+		t.Fatalf("Unexpected free space: %+v", s)
+	}
+}
+
+func TestAllocatorObserved(t *testing.T) {
+	tr := &recordingTracer{}
+	a, _ := NewAllocator[uint16](0, 99, WithMetrics(), WithTracer(tr), WithCapacity(4))
+	a.AllocN(3)
+	a.AllocN(2)
+
+	if m := a.Tree().Metrics(); m.Inserts != 1 || m.InsertErrors != 1 || m.InsertTime <= 0 {
+		t.Fatalf("Unexpected metrics: %+v", m)
+	}
+	if len(tr.spans) != 2 {
+		t.Fatalf("Unexpected number of spans: %d", len(tr.spans))
+	}
+	for i, e := range []struct {
+		size int64
+		err  bool
+	}{{3, false}, {2, true}} {
+		s := tr.spans[i]
+		if s.op != "intervaltree.Insert" || s.attrs["interval.size"] != e.size || !s.ended || (s.err != nil) != e.err {
+			t.Fatalf("Unexpected span %d: %+v", i, s)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package intervaltree
+
+import "sync"
+
+// ArenaTree represents the same set of intervals as Tree, but its nodes live in
+// a contiguous slice and are linked by uint32 indices instead of pointers. This
+// cuts the per-node overhead and leaves the garbage collector nothing to scan,
+// which matters for trees holding millions of intervals. Slots of deleted nodes
+// are reused by later insertions.
+type ArenaTree[T Integer] struct {
+	nodes []arenaNode[T] // Slot 0 is the empty subtree
+	root  uint32
+	free  uint32 // First slot in the list of unused slots, linked by Left
+	sync.RWMutex
+}
+
+// arenaNode holds an interval [I, J] and the indices of the nodes holding
+// intervals lesser and greater than its own.
+type arenaNode[T Integer] struct {
+	I, J        T      // Interval bounds
+	Left, Right uint32 // Left and right children, 0 if there is none
+	height      uint8  // Nodes on the longest path to a leaf (for AVL retracing)
+}
+
+// NewArena returns a pointer to an empty ArenaTree with room for capacity
+// intervals before its slice of nodes needs to grow.
+func NewArena[T Integer](capacity int) *ArenaTree[T] {
+	return &ArenaTree[T]{nodes: make([]arenaNode[T], 1, capacity+1)}
+}
+
+// alloc returns the index of a new leaf holding [x, y].
+func (a *ArenaTree[T]) alloc(x, y T) uint32 {
+	n := arenaNode[T]{I: x, J: y, height: 1}
+	if k := a.free; k != 0 {
+		a.free = a.nodes[k].Left
+		a.nodes[k] = n
+		return k
+	}
+
+	a.nodes = append(a.nodes, n)
+	return uint32(len(a.nodes) - 1)
+}
+
+// release adds the slot k to the list of unused slots.
+func (a *ArenaTree[T]) release(k uint32) {
+	a.nodes[k] = arenaNode[T]{Left: a.free}
+	a.free = k
+}
+
+// floor returns the index of the node holding the greatest interval starting
+// at or before x, or 0 if there is none.
+func (a *ArenaTree[T]) floor(x T) uint32 {
+	var ret uint32
+	for n := a.root; n != 0; {
+		if a.nodes[n].I <= x {
+			ret, n = n, a.nodes[n].Right
+		} else {
+			n = a.nodes[n].Left
+		}
+	}
+	return ret
+}
+
+// higher returns the index of the node holding the least interval starting
+// after x, or 0 if there is none.
+func (a *ArenaTree[T]) higher(x T) uint32 {
+	var ret uint32
+	for n := a.root; n != 0; {
+		if a.nodes[n].I > x {
+			ret, n = n, a.nodes[n].Left
+		} else {
+			n = a.nodes[n].Right
+		}
+	}
+	return ret
+}
+
+// insertAt adds a node holding [x, y] to the subtree rooted at n, which holds
+// no interval starting at x, and returns the new root of the subtree.
+func (a *ArenaTree[T]) insertAt(n uint32, x, y T) uint32 {
+	if n == 0 {
+		return a.alloc(x, y)
+	}
+
+	if x < a.nodes[n].I {
+		l := a.insertAt(a.nodes[n].Left, x, y) // May grow the slice of nodes
+		a.nodes[n].Left = l
+	} else {
+		r := a.insertAt(a.nodes[n].Right, x, y)
+		a.nodes[n].Right = r
+	}
+	return a.rebalance(n)
+}
+
+// removeAt deletes the node holding the interval starting at x from the
+// subtree rooted at n, and returns the new root of the subtree. Such a node
+// must exist.
+func (a *ArenaTree[T]) removeAt(n uint32, x T) uint32 {
+	nd := &a.nodes[n]
+	if x < nd.I {
+		nd.Left = a.removeAt(nd.Left, x)
+	} else if x > nd.I {
+		nd.Right = a.removeAt(nd.Right, x)
+	} else if nd.Left == 0 {
+		r := nd.Right
+		a.release(n)
+		return r
+	} else if nd.Right == 0 {
+		l := nd.Left
+		a.release(n)
+		return l
+	} else { // Replace this interval with the next one
+		var next uint32
+		nd.Right, next = a.removeLeast(nd.Right)
+		nd.I, nd.J = a.nodes[next].I, a.nodes[next].J
+		a.release(next)
+	}
+	return a.rebalance(n)
+}
+
+// removeLeast detaches the node holding the least interval from the subtree
+// rooted at n, and returns the new root of the subtree and the detached node.
+func (a *ArenaTree[T]) removeLeast(n uint32) (uint32, uint32) {
+	if a.nodes[n].Left == 0 {
+		return a.nodes[n].Right, n
+	}
+
+	l, least := a.removeLeast(a.nodes[n].Left)
+	a.nodes[n].Left = l
+	return a.rebalance(n), least
+}
+
+// rebalance fixes AVL invariants violations in the subtree rooted at n by
+// applying rotations, and returns the new root of the subtree.
+func (a *ArenaTree[T]) rebalance(n uint32) uint32 {
+	nd := &a.nodes[n]
+	bal := a.balanceFactor(n)
+	if bal == 2 {
+		if a.balanceFactor(nd.Left) < 0 {
+			nd.Left = a.rotateRight(nd.Left)
+		}
+		return a.rotateLeft(n)
+	} else if bal == -2 {
+		if a.balanceFactor(nd.Right) > 0 {
+			nd.Right = a.rotateLeft(nd.Right)
+		}
+		return a.rotateRight(n)
+	}
+
+	a.updateHeight(n)
+	return n
+}
+
+// updateHeight recalculates the height of the node n from its children.
+func (a *ArenaTree[T]) updateHeight(n uint32) {
+	nd := &a.nodes[n]
+	nd.height = max(a.nodes[nd.Left].height, a.nodes[nd.Right].height) + 1
+}
+
+// balanceFactor calculates the balance factor for the node n.
+func (a *ArenaTree[T]) balanceFactor(n uint32) int8 {
+	nd := &a.nodes[n]
+	return int8(a.nodes[nd.Left].height - a.nodes[nd.Right].height)
+}
+
+// rotateLeft performs a left tree rotation and returns the new root.
+func (a *ArenaTree[T]) rotateLeft(n uint32) uint32 {
+	pivot := a.nodes[n].Left
+	a.nodes[n].Left = a.nodes[pivot].Right
+	a.nodes[pivot].Right = n
+	a.updateHeight(n)
+	a.updateHeight(pivot)
+	return pivot
+}
+
+// rotateRight performs a right tree rotation and returns the new root.
+func (a *ArenaTree[T]) rotateRight(n uint32) uint32 {
+	pivot := a.nodes[n].Right
+	a.nodes[n].Right = a.nodes[pivot].Left
+	a.nodes[pivot].Left = n
+	a.updateHeight(n)
+	a.updateHeight(pivot)
+	return pivot
+}
+
+// Insert adds an interval to the tree. The interval cannot overlap with the
+// tree. If prunning is possible it will be done.
+func (a *ArenaTree[T]) Insert(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	l, r := a.floor(x), a.higher(x)
+	if l != 0 && a.nodes[l].J >= x {
+		return OverlapError[T]{x, Interval[T]{x, y}, Interval[T]{a.nodes[l].I, a.nodes[l].J}}
+	}
+	if r != 0 && a.nodes[r].I <= y {
+		return OverlapError[T]{a.nodes[r].I, Interval[T]{x, y}, Interval[T]{a.nodes[r].I, a.nodes[r].J}}
+	}
+
+	// Neighbours are checked stepping towards the lesser value, so no check
+	// can overflow at the bounds of T
+	joinL := l != 0 && a.nodes[l].J == x-1
+	joinR := r != 0 && a.nodes[r].I-1 == y
+	switch {
+	case joinL && joinR: // Fill the gap between l and r
+		j := a.nodes[r].J
+		a.root = a.removeAt(a.root, a.nodes[r].I)
+		a.nodes[l].J = j
+	case joinL:
+		a.nodes[l].J = y
+	case joinR:
+		a.nodes[r].I = x
+	default:
+		a.root = a.insertAt(a.root, x, y)
+	}
+	return nil
+}
+
+// Remove deletes the interval [x, y] from the tree. Every value in [x, y] must
+// be contained in the tree. Stored intervals are shrunk or split as needed.
+func (a *ArenaTree[T]) Remove(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	// Stored intervals are never adjacent, so [x, y] must lie in a single one
+	c := a.floor(x)
+	if c == 0 || a.nodes[c].J < x {
+		return NotContainedError[T]{x}
+	}
+	if y > a.nodes[c].J {
+		return NotContainedError[T]{a.nodes[c].J + 1}
+	}
+
+	i, j := a.nodes[c].I, a.nodes[c].J
+	switch {
+	case x == i && y == j:
+		a.root = a.removeAt(a.root, i)
+	case x == i:
+		a.nodes[c].I = y + 1
+	case y == j:
+		a.nodes[c].J = x - 1
+	default: // Split, the upper part becomes a new node
+		a.nodes[c].J = x - 1
+		a.root = a.insertAt(a.root, y+1, j)
+	}
+	return nil
+}
+
+// Contains checks if x is contained in the tree.
+func (a *ArenaTree[T]) Contains(x T) bool {
+	a.RLock()
+	defer a.RUnlock()
+
+	c := a.floor(x)
+	return c != 0 && x <= a.nodes[c].J
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to x. It returns false if every such value is contained.
+func (a *ArenaTree[T]) Next(x T) (T, bool) {
+	a.RLock()
+	defer a.RUnlock()
+
+	c := a.floor(x)
+	if c == 0 || x > a.nodes[c].J {
+		return x, true
+	}
+	return after(a.nodes[c].J)
+}
+
+// walk calls fn recursively for the intervals in the subtree rooted at n in
+// ascending order. It stops as soon as fn returns false, and reports whether
+// the walk was completed.
+func (a *ArenaTree[T]) walk(n uint32, fn func(x, y T) bool) bool {
+	if n == 0 {
+		return true
+	}
+
+	nd := &a.nodes[n]
+	return a.walk(nd.Left, fn) && fn(nd.I, nd.J) && a.walk(nd.Right, fn)
+}
+
+// Walk calls fn for the intervals in the tree in ascending order. It stops as
+// soon as fn returns false. The tree cannot be changed from fn.
+func (a *ArenaTree[T]) Walk(fn func(x, y T) bool) {
+	a.RLock()
+	defer a.RUnlock()
+	a.walk(a.root, fn)
+}
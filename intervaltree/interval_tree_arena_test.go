@@ -0,0 +1,103 @@
+package intervaltree
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// isAVL checks the AVL invariants of the subtree rooted at n.
+func (a *ArenaTree[T]) isAVL(n uint32) error {
+	if n == 0 {
+		return nil
+	}
+
+	nd := &a.nodes[n]
+	bal := a.balanceFactor(n)
+	if nd.height != max(a.nodes[nd.Left].height, a.nodes[nd.Right].height)+1 || bal > 1 || bal < -1 {
+		return fmt.Errorf("Node [%d, %d] is inconsistent: height %d, balance factor %d", nd.I, nd.J, nd.height, bal)
+	}
+
+	if err := a.isAVL(nd.Left); err != nil {
+		return err
+	}
+	return a.isAVL(nd.Right)
+}
+
+// String returns the intervals in the tree in the format of ToString.
+func (a *ArenaTree[T]) String() string {
+	s := ""
+	a.Walk(func(x, y T) bool {
+		s += fmt.Sprintf("[%d -- %d]", x, y)
+		return true
+	})
+	return s
+}
+
+func TestArenaTree(t *testing.T) {
+	a := NewArena[uint8](0)
+	for _, i := range []Interval[uint8]{{10, 20}, {30, 40}, {0, 5}, {250, 255}, {21, 29}} {
+		if err := a.Insert(i.I, i.J); err != nil {
+			t.Fatalf("Failed to insert %v: %v", i, err)
+		}
+	}
+	if err := a.Insert(40, 45); err != (OverlapError[uint8]{40, Interval[uint8]{40, 45}, Interval[uint8]{10, 40}}) {
+		t.Fatalf("Unexpected error inserting overlapping interval: %v", err)
+	}
+	if s := a.String(); s != "[0 -- 5][10 -- 40][250 -- 255]" {
+		t.Fatalf("Unexpected intervals: %s", s)
+	}
+	if !full(a, 255) || next(a, 12) != 41 || next(a, 7) != 7 {
+		t.Fatal("Unexpected Next")
+	}
+
+	if err := a.Remove(15, 16); err != nil {
+		t.Fatalf("Failed to split interval: %v", err)
+	}
+	if err := a.Remove(30, 41); err != (NotContainedError[uint8]{41}) {
+		t.Fatalf("Unexpected error removing uncontained interval: %v", err)
+	}
+	if s := a.String(); s != "[0 -- 5][10 -- 14][17 -- 40][250 -- 255]" {
+		t.Fatalf("Unexpected intervals after removal: %s", s)
+	}
+}
+
+func TestArenaTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a, it := NewArena[int8](16), NewTree[int8]()
+	for i := 0; i < 5000; i++ {
+		x := int8(r.Intn(math.MaxUint8+1) + math.MinInt8)
+		y := x + int8(r.Intn(math.MaxInt8-int(x)+1)%16)
+		if r.Intn(3) == 0 {
+			if errA, errT := a.Remove(x, y), it.Remove(x, y); (errA == nil) != (errT == nil) {
+				t.Fatalf("Remove [%d, %d] failed differently: %v, %v", x, y, errA, errT)
+			}
+		} else if errA, errT := a.Insert(x, y), it.Insert(x, y); (errA == nil) != (errT == nil) {
+			t.Fatalf("Insert [%d, %d] failed differently: %v, %v", x, y, errA, errT)
+		}
+
+		if err := a.isAVL(a.root); err != nil {
+			t.Fatalf("Tree is not AVL after %d operations: %v", i, err)
+		}
+		if a.String() != it.ToString() {
+			t.Fatalf("Trees differ after %d operations:\n%s\n%s", i, a.String(), it.ToString())
+		}
+	}
+}
+
+func BenchmarkArenaInsertRandom(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	xs := make([]uint64, 1024)
+	for k := range xs {
+		xs[k] = 10 * uint64(r.Int63n(1<<40))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a := NewArena[uint64](len(xs))
+		for _, x := range xs {
+			a.Insert(x, x+4)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package intervaltree
+
+import (
+	"errors"
+	"slices"
+	"sync"
+)
+
+// asyncBatch is the greatest number of queued intervals an AsyncTree applies
+// under a single lock acquisition, so readers are not starved by bursts.
+const asyncBatch = 4096
+
+// AsyncTree represents a Tree whose insertions are queued and applied in the
+// background, trading the latency until they are visible for the throughput of
+// writers under bursty loads: Insert only sends the interval to a buffered
+// channel, without taking the lock of the tree, and a goroutine applies the
+// queued intervals in batches under a single lock acquisition each.
+type AsyncTree[T Integer] struct {
+	tree    *Tree[T]
+	queue   chan Interval[T]
+	flushes chan chan struct{}
+	done    chan struct{}
+
+	mu     sync.Mutex
+	queued int     // Intervals queued so far
+	errs   []error // Failed insertions since the last flush
+}
+
+// NewAsync returns a pointer to an AsyncTree applying insertions to t, whose
+// queue holds up to buffer intervals. Insert blocks while the queue is full.
+// The tree can be read and changed directly, but changes made directly are not
+// ordered with the queued ones.
+func NewAsync[T Integer](t *Tree[T], buffer int) *AsyncTree[T] {
+	a := &AsyncTree[T]{
+		tree:    t,
+		queue:   make(chan Interval[T], buffer),
+		flushes: make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// Tree returns the tree the insertions are applied to.
+func (a *AsyncTree[T]) Tree() *Tree[T] {
+	return a.tree
+}
+
+// Insert queues the interval [x, y] for insertion and returns without waiting
+// for it to be applied. Only invalid intervals are reported here; other
+// failures are reported by the next Flush.
+func (a *AsyncTree[T]) Insert(x, y T) error {
+	if x > y {
+		return a.tree.failed(insertErrors, x, y, InvalidIntervalError[T]{x, y})
+	}
+	a.queue <- Interval[T]{x, y}
+	return nil
+}
+
+// Flush waits until every interval queued before the call is applied, and
+// returns the errors of the insertions that failed since the previous flush,
+// as BatchErrors joined with errors.Join. The Index of a BatchError is the
+// position of the interval among those queued since the AsyncTree was created,
+// which is the order of the calls to Insert of a single goroutine.
+func (a *AsyncTree[T]) Flush() error {
+	applied := make(chan struct{})
+	a.flushes <- applied
+	<-applied
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	err := errors.Join(a.errs...)
+	a.errs = nil
+	return err
+}
+
+// Close flushes the queue, as Flush does, and stops the goroutine applying it.
+// The AsyncTree must not be used afterwards, but its tree can.
+func (a *AsyncTree[T]) Close() error {
+	err := a.Flush()
+	close(a.queue)
+	<-a.done
+	return err
+}
+
+// run applies the queued intervals until the queue is closed.
+func (a *AsyncTree[T]) run() {
+	defer close(a.done)
+	batch := make([]Interval[T], 0, asyncBatch)
+	for {
+		select {
+		case i, ok := <-a.queue:
+			if !ok {
+				return
+			}
+			batch = append(batch[:0], i)
+			for len(batch) < asyncBatch && len(a.queue) > 0 {
+				batch = append(batch, <-a.queue)
+			}
+			a.apply(batch)
+		case applied := <-a.flushes:
+			// The intervals queued before the flush are already buffered
+			for n := len(a.queue); n > 0; n -= len(batch) {
+				batch = batch[:0]
+				for len(batch) < min(n, asyncBatch) {
+					batch = append(batch, <-a.queue)
+				}
+				a.apply(batch)
+			}
+			close(applied)
+		}
+	}
+}
+
+// apply inserts the intervals of batch in ascending order, so runs of adjacent
+// ones extend the interval of the previous insertion in place, as
+// InsertNearHint does. When queued intervals overlap, the greater fails.
+func (a *AsyncTree[T]) apply(batch []Interval[T]) {
+	order := make([]int, len(batch))
+	for k := range order {
+		order[k] = k
+	}
+	slices.SortFunc(order, func(p, q int) int { return compareIntervals(batch[p], batch[q]) })
+
+	var errs []error
+	a.tree.Lock()
+	var h Hint[T]
+	for _, k := range order {
+		var err error
+		if h, err = a.tree.insertNear(h, batch[k].I, batch[k].J); err != nil {
+			errs = append(errs, BatchError{a.queued + k, err})
+		}
+	}
+	a.tree.Unlock()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.queued += len(batch)
+	a.errs = append(a.errs, errs...)
+}
@@ -0,0 +1,80 @@
+package intervaltree
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAsyncTree(t *testing.T) {
+	a := NewAsync(NewTree[int32](), 64)
+	if err := a.Insert(5, 1); !errors.Is(err, ErrInvalidInterval) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for w := int32(0); w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := int32(0); k < 1000; k++ {
+				a.Insert(w*1000+k, w*1000+k)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := a.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if s := a.Tree().ToString(); s != "[0 -- 3999]" {
+		t.Fatalf("Unexpected tree: %s", s)
+	}
+
+	a.Insert(5000, 5010)
+	a.Insert(3990, 4010) // Overlaps the tree
+	a.Insert(5005, 5020) // Overlaps the first one
+	err := a.Flush()
+	var failed []int
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var be BatchError
+		if !errors.As(e, &be) || !errors.Is(e, ErrOverlap) {
+			t.Fatalf("Unexpected error: %v", e)
+		}
+		failed = append(failed, be.Index)
+	}
+	if len(failed) != 2 || failed[0] != 4001 || failed[1] != 4002 {
+		t.Fatalf("Unexpected failed insertions: %v", failed)
+	}
+	if err := a.Flush(); err != nil {
+		t.Fatalf("Errors reported twice: %v", err)
+	}
+
+	a.Insert(6000, 6000)
+	if err := a.Close(); err != nil || !a.Tree().Contains(6000) {
+		t.Fatalf("Close did not flush the queue: %v", err)
+	}
+}
+
+func BenchmarkAsyncTree(b *testing.B) {
+	var next atomic.Uint64 // Spreads the values among writers
+	b.Run("Insert", func(b *testing.B) {
+		tree := NewTree[uint64]()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				x := next.Add(2)
+				tree.Insert(x, x)
+			}
+		})
+	})
+	b.Run("Async", func(b *testing.B) {
+		a := NewAsync(NewTree[uint64](), 1<<16)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				x := next.Add(2)
+				a.Insert(x, x)
+			}
+		})
+		a.Close()
+	})
+}
@@ -0,0 +1,160 @@
+package intervaltree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditRecord describes a successful mutation of a tree, as written to its
+// audit log.
+type AuditRecord[T Integer] struct {
+	Change[T]
+	Revision uint64    // Revision of the tree after the change
+	Time     time.Time // When the change was made
+}
+
+// audit writes the records of a tree to its audit log.
+type audit struct {
+	w   io.Writer
+	err error // First write error, after which nothing is written
+}
+
+// WithAudit makes the tree append a record of every successful mutation to w,
+// one line per record holding its time, revision, operation and interval, as
+// in "2006-01-02T15:04:05.999999999Z 7 insert 10 20". Writes are made while
+// holding the write lock, so w should be buffered if it is slow. After a write
+// fails nothing else is written, and AuditError reports the error.
+func WithAudit(w io.Writer) Option {
+	return func(c *config) {
+		c.audit = w
+	}
+}
+
+// AuditError returns the error that stopped the audit log of the tree, if any.
+func (t *Tree[T]) AuditError() error {
+	t.RLock()
+	defer t.RUnlock()
+
+	if t.audit == nil {
+		return nil
+	}
+	return t.audit.err
+}
+
+// writeAudit appends the record of c, which took the tree to its current
+// revision, to the audit log. The caller must hold the write lock.
+func (t *Tree[T]) writeAudit(c Change[T]) {
+	if t.audit.err != nil {
+		return
+	}
+
+	op := "insert"
+	if c.Removed {
+		op = "remove"
+	}
+	buf := time.Now().UTC().AppendFormat(nil, time.RFC3339Nano)
+	buf = fmt.Appendf(buf, " %d %s ", t.rev, op)
+	buf = appendInt(buf, c.I)
+	buf = append(buf, ' ')
+	buf = appendInt(buf, c.J)
+	buf = append(buf, '\n')
+	_, t.audit.err = t.audit.w.Write(buf)
+}
+
+// ReadAudit reads the records of an audit log written by a tree created with
+// WithAudit from r.
+func ReadAudit[T Integer](r io.Reader) ([]AuditRecord[T], error) {
+	var records []AuditRecord[T]
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		rec, err := parseAuditRecord[T](s.Text())
+		if err != nil {
+			return records, fmt.Errorf("Malformed audit log at line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, s.Err()
+}
+
+// ReplayAudit reads the audit log of a tree from r and returns a new tree,
+// created with opts, holding the result of replaying its records in order.
+// The log must hold every change since the tree was created.
+func ReplayAudit[T Integer](r io.Reader, opts ...Option) (*Tree[T], error) {
+	records, err := ReadAudit[T](r)
+	if err != nil {
+		return nil, err
+	}
+
+	t := NewTree[T](opts...)
+	t.Lock()
+	defer t.Unlock()
+	for _, rec := range records {
+		if rec.Revision != t.rev+1 {
+			return nil, RevisionError(t.rev + 1)
+		}
+
+		if rec.Removed {
+			err = t.remove(rec.I, rec.J)
+		} else {
+			err = t.insert(rec.I, rec.J)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// parseAuditRecord parses a line of an audit log.
+func parseAuditRecord[T Integer](line string) (AuditRecord[T], error) {
+	var rec AuditRecord[T]
+	fields := strings.Fields(line)
+	if len(fields) != 5 {
+		return rec, fmt.Errorf("expected 5 fields, found %d", len(fields))
+	}
+
+	var err error
+	if rec.Time, err = time.Parse(time.RFC3339Nano, fields[0]); err != nil {
+		return rec, err
+	}
+	if rec.Revision, err = strconv.ParseUint(fields[1], 10, 64); err != nil {
+		return rec, err
+	}
+	switch fields[2] {
+	case "insert":
+	case "remove":
+		rec.Removed = true
+	default:
+		return rec, fmt.Errorf("unknown operation %q", fields[2])
+	}
+	if rec.I, err = parseValue[T](fields[3]); err != nil {
+		return rec, err
+	}
+	if rec.J, err = parseValue[T](fields[4]); err != nil {
+		return rec, err
+	}
+	if rec.I > rec.J {
+		return rec, InvalidIntervalError[T]{rec.I, rec.J}
+	}
+	return rec, nil
+}
+
+// parseValue parses the decimal representation of a value of type T.
+func parseValue[T Integer](s string) (T, error) {
+	if signed[T]() {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err == nil && int64(T(v)) != v {
+			err = fmt.Errorf("value %s out of range", s)
+		}
+		return T(v), err
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err == nil && uint64(T(v)) != v {
+		err = fmt.Errorf("value %s out of range", s)
+	}
+	return T(v), err
+}
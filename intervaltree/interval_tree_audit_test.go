@@ -0,0 +1,78 @@
+package intervaltree
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	var buf bytes.Buffer
+	it := NewTree[int16](WithAudit(&buf))
+	it.Insert(-10, 20)
+	it.Insert(0, 5) // Fails, not audited
+	it.Remove(0, 5)
+	it.Insert(30, 40)
+
+	records, err := ReadAudit[int16](bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Change[int16]{
+		{false, Interval[int16]{-10, 20}},
+		{true, Interval[int16]{0, 5}},
+		{false, Interval[int16]{30, 40}},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("Unexpected records: %+v", records)
+	}
+	for i, rec := range records {
+		if rec.Change != expected[i] || rec.Revision != uint64(i+1) || rec.Time.IsZero() {
+			t.Fatalf("Unexpected record %d: %+v", i, rec)
+		}
+	}
+
+	replayed, err := ReplayAudit[int16](bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.ToString() != it.ToString() || replayed.Revision() != it.Revision() {
+		t.Fatalf("Unexpected replayed tree: %s", replayed.ToString())
+	}
+}
+
+func TestReplayAuditErrors(t *testing.T) {
+	for _, tc := range []struct {
+		log string
+		err string
+	}{
+		{"2026-01-01T00:00:00Z 2 insert 1 2\n", "Revision not available: 1"},
+		{"2026-01-01T00:00:00Z 1 insert 1 2\n2026-01-01T00:00:00Z 2 insert 2 3\n", "Tried to insert value already inserted: 2"},
+		{"2026-01-01T00:00:00Z 1 insert 1\n", "Malformed audit log at line 1: expected 5 fields, found 4"},
+		{"2026-01-01T00:00:00Z 1 move 1 2\n", `Malformed audit log at line 1: unknown operation "move"`},
+		{"2026-01-01T00:00:00Z 1 insert 1 300\n", "Malformed audit log at line 1: value 300 out of range"},
+	} {
+		_, err := ReplayAudit[uint8](strings.NewReader(tc.log))
+		if err == nil || err.Error() != tc.err {
+			t.Fatalf("Unexpected error replaying %q: %v", tc.log, err)
+		}
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestAuditError(t *testing.T) {
+	it := New(WithAudit(failingWriter{}))
+	if err := it.Insert(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := it.AuditError(); err == nil || err.Error() != "disk full" {
+		t.Fatalf("Unexpected audit error: %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package intervaltree
+
+// Balancing holds counters describing the balancing work done by a tree, to
+// compare its behavior across workloads.
+type Balancing struct {
+	Rotations uint64 // Rotations performed, a double rotation counting as two
+	Coalesces uint64 // Intervals joined with a neighbour on insertion
+	MaxDepth  int    // Greatest number of nodes visited by an insertion
+}
+
+// WithBalancingStats makes the tree count the rotations and coalesces it
+// performs and the depth its insertions reach, as reported by Balancing.
+func WithBalancingStats() Option {
+	return func(c *config) {
+		c.balancing = true
+	}
+}
+
+// Balancing returns the balancing counters of the tree. They are all zero
+// unless the tree was created with WithBalancingStats or WithTracer.
+func (t *Tree[T]) Balancing() Balancing {
+	t.RLock()
+	defer t.RUnlock()
+
+	if t.balancing == nil {
+		return Balancing{}
+	}
+	return *t.balancing
+}
+
+// rotated counts a rotation. A nil Balancing counts nothing.
+func (b *Balancing) rotated() {
+	if b != nil {
+		b.Rotations++
+	}
+}
+
+// coalesced counts an interval joined with a neighbour.
+func (b *Balancing) coalesced() {
+	if b != nil {
+		b.Coalesces++
+	}
+}
+
+// reached records an insertion visiting depth nodes.
+func (b *Balancing) reached(depth int) {
+	if b != nil {
+		b.MaxDepth = max(b.MaxDepth, depth)
+	}
+}
@@ -0,0 +1,53 @@
+package intervaltree
+
+import "testing"
+
+func TestBalancing(t *testing.T) {
+	it := New()
+	it.Insert(1, 1)
+	if b := it.Balancing(); b != (Balancing{}) {
+		t.Fatalf("Unexpected counters without WithBalancingStats: %+v", b)
+	}
+
+	it = New(WithBalancingStats())
+	for i := uint64(0); i < 7; i++ { // Ascending insertions rotate at 3, 5, 6 and 7
+		it.Insert(i*10, i*10)
+	}
+	if b := it.Balancing(); b.Rotations != 4 || b.Coalesces != 0 || b.MaxDepth != 3 {
+		t.Fatalf("Unexpected counters: %+v", b)
+	}
+
+	it.Insert(1, 9)   // Joins 0 and 10
+	it.Insert(11, 19) // Joins 0-10 and 20
+	if b := it.Balancing(); b.Coalesces != 4 {
+		t.Fatalf("Unexpected coalesces: %+v", b)
+	}
+	if err := it.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBalancingWithoutRecycling(t *testing.T) {
+	it := New(WithBalancingStats())
+	it.Insert(1, 1)
+	it.Remove(1, 1)
+	if it.pool != nil {
+		t.Fatal("Node pool created without WithNodeRecycling")
+	}
+}
+
+func TestBalancingFailedInsert(t *testing.T) {
+	it := New(WithBalancingStats())
+	it.Insert(0, 5)
+	it.Insert(10, 20)
+	before := it.Balancing()
+	if err := it.Insert(4, 9); err == nil {
+		t.Fatal("Overlapping insertion succeeded")
+	}
+	if err := it.Insert(6, 10); err == nil {
+		t.Fatal("Overlapping insertion succeeded")
+	}
+	if b := it.Balancing(); b.Coalesces != before.Coalesces || b.Rotations != before.Rotations {
+		t.Fatalf("Failed insertions changed the counters from %+v to %+v", before, b)
+	}
+}
@@ -0,0 +1,181 @@
+package intervaltree
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+)
+
+// cancelCheck is the number of items bulk operations process between checks
+// of the cancellation of their context, which is cheap but not free.
+const cancelCheck = 1024
+
+// cancelled returns the error of ctx if the kth item processed by a bulk
+// operation is due for a check and ctx is done, or nil.
+func cancelled(ctx context.Context, k int) error {
+	if k%cancelCheck != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
+
+// ContainsAll checks if every value in xs is contained in the tree, under a
+// single lock acquisition.
+func (t *Tree[T]) ContainsAll(xs []T) bool {
+	t.RLock()
+	defer t.RUnlock()
+
+	for _, x := range xs {
+		if !t.root.contains(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsAny checks if some value in xs is contained in the tree, under a
+// single lock acquisition.
+func (t *Tree[T]) ContainsAny(xs []T) bool {
+	t.RLock()
+	defer t.RUnlock()
+
+	for _, x := range xs {
+		if t.root.contains(x) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsEach checks which values in xs are contained in the tree, under a
+// single lock acquisition. Bit k%64 of word k/64 of the returned bitmap is set
+// if xs[k] is contained.
+func (t *Tree[T]) ContainsEach(xs []T) []uint64 {
+	t.RLock()
+	defer t.RUnlock()
+
+	bitmap := make([]uint64, (len(xs)+63)/64)
+	for k, x := range xs {
+		if t.root.contains(x) {
+			bitmap[k/64] |= 1 << (k % 64)
+		}
+	}
+	return bitmap
+}
+
+// ContainsSorted checks which values in xs, which must be in ascending order,
+// are contained in the tree, as ContainsEach does. Instead of a descent per
+// value, the values are split among the subtrees as the tree is traversed in
+// order, so each node is visited once at most and scans of sequential values
+// cost O(n + m) for n intervals and m values. Values out of order are answered
+// by ContainsEach.
+func (t *Tree[T]) ContainsSorted(xs []T) []uint64 {
+	if !slices.IsSorted(xs) {
+		return t.ContainsEach(xs)
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+	bitmap := make([]uint64, (len(xs)+63)/64)
+	t.root.containsSorted(xs, 0, bitmap)
+	return bitmap
+}
+
+// containsSorted sets the bits of bitmap for the values in xs, which are in
+// ascending order and start at index k, contained in the subtree rooted at n.
+func (n *node[T]) containsSorted(xs []T, k int, bitmap []uint64) {
+	for n != nil && len(xs) > 0 {
+		l, _ := slices.BinarySearch(xs, n.I)
+		r := l + sort.Search(len(xs)-l, func(i int) bool { return xs[l+i] > n.J })
+		n.Left.containsSorted(xs[:l], k, bitmap)
+		for i := l; i < r; i++ {
+			bitmap[(k+i)/64] |= 1 << ((k + i) % 64)
+		}
+		n, xs, k = n.Right, xs[r:], k+r
+	}
+}
+
+// InsertAll adds every interval in intervals to the tree, in order, under a
+// single lock acquisition. Items that cannot be inserted are skipped rather
+// than stopping the batch, and reported as BatchErrors joined with errors.Join.
+func (t *Tree[T]) InsertAll(intervals []Interval[T]) error {
+	return t.InsertAllContext(context.Background(), intervals)
+}
+
+// InsertAllContext is like InsertAll, but checks periodically whether ctx is
+// done, in which case it stops and releases the lock, returning the error of
+// ctx joined with those of the items that failed. Items before the point it
+// stopped at are left inserted.
+func (t *Tree[T]) InsertAllContext(ctx context.Context, intervals []Interval[T]) (err error) {
+	t.Lock()
+	defer t.Unlock()
+	if t.tracer != nil {
+		span := t.traceBulk("intervaltree.InsertAll", "intervals", len(intervals))
+		defer func(rotations uint64) {
+			t.traceRotations(span, rotations)
+			span.End(err)
+		}(t.balancing.Rotations)
+	}
+
+	var errs []error
+	for k, i := range intervals {
+		if err := cancelled(ctx, k); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		var err error
+		if i.I > i.J {
+			err = t.failed(insertErrors, i.I, i.J, InvalidIntervalError[T]{i.I, i.J})
+		} else {
+			err = t.insert(i.I, i.J)
+		}
+		if err != nil {
+			errs = append(errs, BatchError{k, err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RemoveAll deletes every interval in intervals from the tree, in order, under
+// a single lock acquisition. Items that cannot be removed are skipped rather
+// than stopping the batch, and reported as BatchErrors joined with errors.Join.
+func (t *Tree[T]) RemoveAll(intervals []Interval[T]) error {
+	return t.RemoveAllContext(context.Background(), intervals)
+}
+
+// RemoveAllContext is like RemoveAll, but checks periodically whether ctx is
+// done, in which case it stops and releases the lock, returning the error of
+// ctx joined with those of the items that failed. Items before the point it
+// stopped at are left removed.
+func (t *Tree[T]) RemoveAllContext(ctx context.Context, intervals []Interval[T]) (err error) {
+	t.Lock()
+	defer t.Unlock()
+	if t.tracer != nil {
+		span := t.traceBulk("intervaltree.RemoveAll", "intervals", len(intervals))
+		defer func(rotations uint64) {
+			t.traceRotations(span, rotations)
+			span.End(err)
+		}(t.balancing.Rotations)
+	}
+
+	var errs []error
+	for k, i := range intervals {
+		if err := cancelled(ctx, k); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		var err error
+		if i.I > i.J {
+			err = t.failed(removeErrors, i.I, i.J, InvalidIntervalError[T]{i.I, i.J})
+		} else {
+			err = t.remove(i.I, i.J)
+		}
+		if err != nil {
+			errs = append(errs, BatchError{k, err})
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,152 @@
+package intervaltree
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestBatchContains(t *testing.T) {
+	it := New()
+	it.Insert(10, 20)
+	it.Insert(30, 40)
+
+	if !it.ContainsAll([]uint64{10, 15, 35}) || it.ContainsAll([]uint64{10, 25}) || !it.ContainsAll(nil) {
+		t.Fatal("Unexpected ContainsAll")
+	}
+	if !it.ContainsAny([]uint64{0, 25, 40}) || it.ContainsAny([]uint64{0, 25}) || it.ContainsAny(nil) {
+		t.Fatal("Unexpected ContainsAny")
+	}
+
+	xs := make([]uint64, 70)
+	for k := range xs {
+		xs[k] = uint64(k)
+	}
+	bitmap := it.ContainsEach(xs)
+	if len(bitmap) != 2 {
+		t.Fatalf("Unexpected bitmap length: %d", len(bitmap))
+	}
+	for k, x := range xs {
+		if (bitmap[k/64]&(1<<(k%64)) != 0) != it.Contains(x) {
+			t.Fatalf("Unexpected bit for %d", x)
+		}
+	}
+}
+
+func TestContainsSorted(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tree := NewTree[int16]()
+	for k := 0; k < 500; k++ {
+		x := int16(r.Intn(1 << 16))
+		tree.Insert(x, x+int16(r.Intn(50)))
+	}
+
+	for _, n := range []int{0, 1, 63, 64, 1000, 5000} {
+		xs := make([]int16, n)
+		for k := range xs {
+			xs[k] = int16(r.Intn(1 << 16))
+		}
+		if !slices.Equal(tree.ContainsSorted(xs), tree.ContainsEach(xs)) { // Unsorted
+			t.Fatalf("Unexpected bitmap for %d unsorted values", n)
+		}
+		slices.Sort(xs)
+		if !slices.Equal(tree.ContainsSorted(xs), tree.ContainsEach(xs)) {
+			t.Fatalf("Unexpected bitmap for %d sorted values", n)
+		}
+	}
+}
+
+func BenchmarkContainsSorted(b *testing.B) {
+	tree := NewTree[uint64]()
+	for k := uint64(0); k < 1<<16; k++ {
+		tree.Insert(k*64, k*64+31)
+	}
+	xs := make([]uint64, 1<<22)
+	for k := range xs {
+		xs[k] = uint64(k)
+	}
+
+	b.Run("Each", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tree.ContainsEach(xs)
+		}
+	})
+	b.Run("Sorted", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tree.ContainsSorted(xs)
+		}
+	})
+}
+
+func TestInsertAll(t *testing.T) {
+	it := New()
+	err := it.InsertAll([]Interval[uint64]{{10, 20}, {15, 25}, {30, 40}, {9, 5}, {21, 29}})
+	if s := it.ToString(); s != "[10 -- 40]" {
+		t.Fatalf("Valid items were not inserted: %s", s)
+	}
+
+	var failed []int
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var be BatchError
+		if !errors.As(e, &be) {
+			t.Fatalf("Unexpected error in batch: %v", e)
+		}
+		failed = append(failed, be.Index)
+	}
+	if !slices.Equal(failed, []int{1, 3}) {
+		t.Fatalf("Unexpected failed items: %v", failed)
+	}
+	if !errors.Is(err, ErrOverlap) || !errors.Is(err, ErrInvalidInterval) {
+		t.Fatalf("Causes are not reported: %v", err)
+	}
+
+	if err := it.InsertAll([]Interval[uint64]{{50, 60}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestRemoveAll(t *testing.T) {
+	it := New()
+	it.Insert(0, 100)
+	err := it.RemoveAll([]Interval[uint64]{{10, 20}, {15, 25}, {200, 300}, {50, 50}})
+	if s := it.ToString(); s != "[0 -- 9][21 -- 49][51 -- 100]" {
+		t.Fatalf("Valid items were not removed: %s", s)
+	}
+
+	var be BatchError
+	if !errors.As(err, &be) || be.Index != 1 || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err.Error() != "Item 1: Tried to remove value not contained: 15\nItem 2: Tried to remove value not contained: 200" {
+		t.Fatalf("Unexpected message: %q", err.Error())
+	}
+}
+
+func TestBatchContext(t *testing.T) {
+	it := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	intervals := make([]Interval[uint64], 3*cancelCheck)
+	for k := range intervals {
+		intervals[k] = Interval[uint64]{uint64(2 * k), uint64(2 * k)}
+	}
+	if err := it.InsertAllContext(ctx, intervals); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Unexpected error inserting with a cancelled context: %v", err)
+	}
+	if it.Len() != 0 {
+		t.Fatalf("Cancelled batch inserted %d intervals", it.Len())
+	}
+
+	if err := it.InsertAllContext(context.Background(), intervals); err != nil {
+		t.Fatal(err)
+	}
+	if err := it.RemoveAllContext(ctx, intervals); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Unexpected error removing with a cancelled context: %v", err)
+	}
+	if it.Len() != len(intervals) {
+		t.Fatalf("Cancelled batch removed %d intervals", len(intervals)-it.Len())
+	}
+}
@@ -0,0 +1,330 @@
+package intervaltree
+
+import (
+	"slices"
+	"sort"
+	"sync"
+)
+
+// BTree represents the same set of intervals as Tree, but each of its nodes
+// holds a small sorted array of intervals, as in a B-tree. Lookups touch one
+// node per level of a much shallower tree, which gives far better cache
+// behavior than the binary nodes of Tree on large trees.
+type BTree[T Integer] struct {
+	root   *bnode[T]
+	degree int // Minimum number of children of inner nodes but the root
+	sync.RWMutex
+}
+
+// bnode holds between degree-1 and 2*degree-1 intervals in ascending order,
+// except for the root which may hold less. Inner nodes hold one child more than
+// intervals, the child k holding the intervals between the intervals k-1 and k.
+type bnode[T Integer] struct {
+	items    []Interval[T]
+	children []*bnode[T] // nil for leaves
+}
+
+// NewBTree returns a pointer to an empty BTree whose nodes have at most fanout
+// children. The fanout is rounded down to an even number not lesser than 4;
+// values between 16 and 64 suit most caches.
+func NewBTree[T Integer](fanout int) *BTree[T] {
+	if fanout < 4 {
+		fanout = 4
+	}
+	return &BTree[T]{root: &bnode[T]{}, degree: fanout / 2}
+}
+
+// search returns the number of intervals in this node starting at or before x.
+func (n *bnode[T]) search(x T) int {
+	return sort.Search(len(n.items), func(k int) bool { return n.items[k].I > x })
+}
+
+// floor returns the greatest interval starting at or before x in the subtree
+// rooted at this node, or nil if there is none. The interval can be changed in
+// place as long as it keeps its place in the order.
+func (n *bnode[T]) floor(x T) *Interval[T] {
+	var ret *Interval[T]
+	for n != nil {
+		k := n.search(x)
+		if k > 0 {
+			ret = &n.items[k-1]
+		}
+		if n.children == nil {
+			break
+		}
+		n = n.children[k]
+	}
+	return ret
+}
+
+// higher returns the least interval starting after x in the subtree rooted at
+// this node, or nil if there is none. The interval can be changed in place as
+// long as it keeps its place in the order.
+func (n *bnode[T]) higher(x T) *Interval[T] {
+	var ret *Interval[T]
+	for n != nil {
+		k := n.search(x)
+		if k < len(n.items) {
+			ret = &n.items[k]
+		}
+		if n.children == nil {
+			break
+		}
+		n = n.children[k]
+	}
+	return ret
+}
+
+// splitChild splits the full child k of this node in two, moving its median
+// interval up into this node.
+func (n *bnode[T]) splitChild(k, degree int) {
+	c := n.children[k]
+	median := c.items[degree-1]
+	sibling := &bnode[T]{items: slices.Clone(c.items[degree:])}
+	if c.children != nil {
+		sibling.children = slices.Clone(c.children[degree:])
+		c.children = c.children[:degree]
+	}
+	c.items = c.items[:degree-1]
+
+	n.items = slices.Insert(n.items, k, median)
+	n.children = slices.Insert(n.children, k+1, sibling)
+}
+
+// mergeChildren joins the child k+1 of this node and the interval k into the
+// child k.
+func (n *bnode[T]) mergeChildren(k int) {
+	c, sibling := n.children[k], n.children[k+1]
+	c.items = append(append(c.items, n.items[k]), sibling.items...)
+	c.children = append(c.children, sibling.children...)
+
+	n.items = slices.Delete(n.items, k, k+1)
+	n.children = slices.Delete(n.children, k+1, k+2)
+}
+
+// insert adds i to the tree, which holds no interval starting at i.I.
+func (b *BTree[T]) insert(i Interval[T]) {
+	if len(b.root.items) == 2*b.degree-1 {
+		b.root = &bnode[T]{children: []*bnode[T]{b.root}}
+		b.root.splitChild(0, b.degree)
+	}
+
+	// Full nodes are split on the way down, so there is always room
+	n := b.root
+	for {
+		k := n.search(i.I)
+		if n.children == nil {
+			n.items = slices.Insert(n.items, k, i)
+			return
+		}
+		if len(n.children[k].items) == 2*b.degree-1 {
+			n.splitChild(k, b.degree)
+			if i.I > n.items[k].I {
+				k++
+			}
+		}
+		n = n.children[k]
+	}
+}
+
+// remove deletes the interval starting at x from the tree. Such an interval
+// must exist.
+func (b *BTree[T]) remove(x T) {
+	// Children are given at least degree intervals before descending into them,
+	// so they can lose one
+	n := b.root
+	for {
+		k := sort.Search(len(n.items), func(k int) bool { return n.items[k].I >= x })
+		found := k < len(n.items) && n.items[k].I == x
+		if n.children == nil {
+			if found {
+				n.items = slices.Delete(n.items, k, k+1)
+			}
+			break
+		}
+
+		if found {
+			l, r := n.children[k], n.children[k+1]
+			switch {
+			case len(l.items) >= b.degree: // Replace with the previous interval
+				prev := l.greatest()
+				n.items[k] = prev
+				n, x = l, prev.I
+			case len(r.items) >= b.degree: // Replace with the next interval
+				next := r.least()
+				n.items[k] = next
+				n, x = r, next.I
+			default:
+				n.mergeChildren(k)
+				n = l
+			}
+			continue
+		}
+
+		c := n.children[k]
+		if len(c.items) < b.degree {
+			switch {
+			case k > 0 && len(n.children[k-1].items) >= b.degree: // Borrow from the left
+				l := n.children[k-1]
+				last := len(l.items) - 1
+				c.items = slices.Insert(c.items, 0, n.items[k-1])
+				n.items[k-1] = l.items[last]
+				l.items = l.items[:last]
+				if c.children != nil {
+					c.children = slices.Insert(c.children, 0, l.children[last+1])
+					l.children = l.children[:last+1]
+				}
+			case k < len(n.items) && len(n.children[k+1].items) >= b.degree: // Borrow from the right
+				r := n.children[k+1]
+				c.items = append(c.items, n.items[k])
+				n.items[k] = r.items[0]
+				r.items = slices.Delete(r.items, 0, 1)
+				if c.children != nil {
+					c.children = append(c.children, r.children[0])
+					r.children = slices.Delete(r.children, 0, 1)
+				}
+			case k < len(n.items):
+				n.mergeChildren(k)
+			default:
+				n.mergeChildren(k - 1)
+				c = n.children[k-1]
+			}
+		}
+		n = c
+	}
+
+	if len(b.root.items) == 0 && b.root.children != nil {
+		b.root = b.root.children[0]
+	}
+}
+
+// least returns the least interval in the subtree rooted at this node.
+func (n *bnode[T]) least() Interval[T] {
+	for n.children != nil {
+		n = n.children[0]
+	}
+	return n.items[0]
+}
+
+// greatest returns the greatest interval in the subtree rooted at this node.
+func (n *bnode[T]) greatest() Interval[T] {
+	for n.children != nil {
+		n = n.children[len(n.children)-1]
+	}
+	return n.items[len(n.items)-1]
+}
+
+// walk calls fn recursively for the intervals of this node and its children in
+// ascending order. It stops as soon as fn returns false, and reports whether
+// the walk was completed.
+func (n *bnode[T]) walk(fn func(x, y T) bool) bool {
+	for k, i := range n.items {
+		if n.children != nil && !n.children[k].walk(fn) {
+			return false
+		}
+		if !fn(i.I, i.J) {
+			return false
+		}
+	}
+	return n.children == nil || n.children[len(n.items)].walk(fn)
+}
+
+// Insert adds an interval to the tree. The interval cannot overlap with the
+// tree. If prunning is possible it will be done.
+func (b *BTree[T]) Insert(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	l, r := b.root.floor(x), b.root.higher(x)
+	if l != nil && l.J >= x {
+		return OverlapError[T]{x, Interval[T]{x, y}, Interval[T]{l.I, l.J}}
+	}
+	if r != nil && r.I <= y {
+		return OverlapError[T]{r.I, Interval[T]{x, y}, Interval[T]{r.I, r.J}}
+	}
+
+	// Neighbours are checked stepping towards the lesser value, so no check
+	// can overflow at the bounds of T
+	joinL := l != nil && l.J == x-1
+	joinR := r != nil && r.I-1 == y
+	switch {
+	case joinL && joinR: // Fill the gap between l and r
+		i, j := l.I, r.J
+		b.remove(r.I)
+		b.root.floor(i).J = j // Removing may have moved l
+	case joinL:
+		l.J = y
+	case joinR:
+		r.I = x
+	default:
+		b.insert(Interval[T]{x, y})
+	}
+	return nil
+}
+
+// Remove deletes the interval [x, y] from the tree. Every value in [x, y] must
+// be contained in the tree. Stored intervals are shrunk or split as needed.
+func (b *BTree[T]) Remove(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	// Stored intervals are never adjacent, so [x, y] must lie in a single one
+	c := b.root.floor(x)
+	if c == nil || c.J < x {
+		return NotContainedError[T]{x}
+	}
+	if y > c.J {
+		return NotContainedError[T]{c.J + 1}
+	}
+
+	switch i, j := c.I, c.J; {
+	case x == i && y == j:
+		b.remove(i)
+	case x == i:
+		c.I = y + 1
+	case y == j:
+		c.J = x - 1
+	default: // Split, the upper part becomes a new interval
+		c.J = x - 1
+		b.insert(Interval[T]{y + 1, j})
+	}
+	return nil
+}
+
+// Contains checks if x is contained in the tree.
+func (b *BTree[T]) Contains(x T) bool {
+	b.RLock()
+	defer b.RUnlock()
+
+	c := b.root.floor(x)
+	return c != nil && x <= c.J
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to x. It returns false if every such value is contained.
+func (b *BTree[T]) Next(x T) (T, bool) {
+	b.RLock()
+	defer b.RUnlock()
+
+	c := b.root.floor(x)
+	if c == nil || x > c.J {
+		return x, true
+	}
+	return after(c.J)
+}
+
+// Walk calls fn for the intervals in the tree in ascending order. It stops as
+// soon as fn returns false. The tree cannot be changed from fn.
+func (b *BTree[T]) Walk(fn func(x, y T) bool) {
+	b.RLock()
+	defer b.RUnlock()
+	b.root.walk(fn)
+}
@@ -0,0 +1,121 @@
+package intervaltree
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// isBTree checks the B-tree invariants of the subtree rooted at n, which lies
+// at the given depth, and returns the depth of its leaves.
+func (b *BTree[T]) isBTree(n *bnode[T], depth int) (int, error) {
+	if n != b.root && (len(n.items) < b.degree-1 || len(n.items) > 2*b.degree-1) {
+		return 0, fmt.Errorf("Node at depth %d holds %d intervals", depth, len(n.items))
+	}
+	for k := 1; k < len(n.items); k++ {
+		if n.items[k].I <= n.items[k-1].J {
+			return 0, fmt.Errorf("Intervals %v and %v are not ascending", n.items[k-1], n.items[k])
+		}
+	}
+	if n.children == nil {
+		return depth, nil
+	}
+	if len(n.children) != len(n.items)+1 {
+		return 0, fmt.Errorf("Node at depth %d holds %d intervals and %d children", depth, len(n.items), len(n.children))
+	}
+
+	leaves := -1
+	for _, c := range n.children {
+		d, err := b.isBTree(c, depth+1)
+		if err != nil {
+			return 0, err
+		}
+		if leaves >= 0 && d != leaves {
+			return 0, fmt.Errorf("Leaves at depths %d and %d", leaves, d)
+		}
+		leaves = d
+	}
+	return leaves, nil
+}
+
+// String returns the intervals in the tree in the format of ToString.
+func (b *BTree[T]) String() string {
+	var s strings.Builder
+	b.Walk(func(x, y T) bool {
+		fmt.Fprintf(&s, "[%d -- %d]", x, y)
+		return true
+	})
+	return s.String()
+}
+
+func TestBTree(t *testing.T) {
+	b := NewBTree[uint8](4)
+	for _, i := range []Interval[uint8]{{10, 20}, {30, 40}, {0, 5}, {250, 255}, {21, 29}, {100, 100}, {90, 90}} {
+		if err := b.Insert(i.I, i.J); err != nil {
+			t.Fatalf("Failed to insert %v: %v", i, err)
+		}
+	}
+	if err := b.Insert(40, 45); err != (OverlapError[uint8]{40, Interval[uint8]{40, 45}, Interval[uint8]{10, 40}}) {
+		t.Fatalf("Unexpected error inserting overlapping interval: %v", err)
+	}
+	if s := b.String(); s != "[0 -- 5][10 -- 40][90 -- 90][100 -- 100][250 -- 255]" {
+		t.Fatalf("Unexpected intervals: %s", s)
+	}
+	if !full(b, 255) || next(b, 12) != 41 || next(b, 7) != 7 || !b.Contains(90) {
+		t.Fatal("Unexpected lookups")
+	}
+
+	if err := b.Remove(15, 16); err != nil {
+		t.Fatalf("Failed to split interval: %v", err)
+	}
+	if err := b.Remove(30, 41); err != (NotContainedError[uint8]{41}) {
+		t.Fatalf("Unexpected error removing uncontained interval: %v", err)
+	}
+	if s := b.String(); s != "[0 -- 5][10 -- 14][17 -- 40][90 -- 90][100 -- 100][250 -- 255]" {
+		t.Fatalf("Unexpected intervals after removal: %s", s)
+	}
+}
+
+func TestBTreeRandom(t *testing.T) {
+	for _, fanout := range []int{4, 5, 16} {
+		r := rand.New(rand.NewSource(1))
+		b, it := NewBTree[int16](fanout), NewTree[int16]()
+		for i := 0; i < 3000; i++ {
+			x := int16(r.Intn(2000) - 1000)
+			y := x + int16(r.Intn(10))
+			if r.Intn(3) == 0 {
+				if errA, errB := b.Remove(x, y), it.Remove(x, y); (errA == nil) != (errB == nil) {
+					t.Fatalf("Remove [%d, %d] failed differently: %v, %v", x, y, errA, errB)
+				}
+			} else if errA, errB := b.Insert(x, y), it.Insert(x, y); (errA == nil) != (errB == nil) {
+				t.Fatalf("Insert [%d, %d] failed differently: %v, %v", x, y, errA, errB)
+			}
+
+			if _, err := b.isBTree(b.root, 0); err != nil {
+				t.Fatalf("Tree with fanout %d is not a B-tree after %d operations: %v", fanout, i, err)
+			}
+			if b.String() != it.ToString() {
+				t.Fatalf("Trees with fanout %d differ after %d operations", fanout, i)
+			}
+		}
+
+		for x := math.MinInt16; x <= math.MaxInt16; x += 7 {
+			if b.Contains(int16(x)) != it.Contains(int16(x)) || !sameNext(b, it, int16(x)) {
+				t.Fatalf("Trees with fanout %d differ at %d", fanout, x)
+			}
+		}
+	}
+}
+
+func BenchmarkBTreeContains(b *testing.B) {
+	bt := NewBTree[uint64](32)
+	for i := uint64(0); i < 1<<16; i++ {
+		bt.Insert(10*i, 10*i+4)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bt.Contains(uint64(i*7919) % (10 << 16))
+	}
+}
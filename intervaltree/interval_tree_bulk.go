@@ -0,0 +1,157 @@
+package intervaltree
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// BuildTree returns a pointer to a Tree configured by opts holding the union of
+// intervals, which may be unsorted, overlapping or adjacent. Intervals are
+// validated and sorted by up to workers goroutines, then coalesced and
+// assembled into a balanced tree, so tens of millions of intervals can be
+// loaded in seconds. intervals is not modified. It fails with a CapacityError
+// if the union holds more values than a capacity set by WithCapacity.
+func BuildTree[T Integer](intervals []Interval[T], workers int, opts ...Option) (*Tree[T], error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	sorted, err := parallelSort(slices.Clone(intervals), workers)
+	if err != nil {
+		return nil, err
+	}
+
+	t := NewTree[T](opts...)
+	root := buildParallel(coalesce(sorted), workers)
+	if err := t.checkContents(root); err != nil {
+		return nil, err
+	}
+	t.root = root
+	return t, nil
+}
+
+// BuildTreeFromPoints returns a pointer to a Tree configured by opts holding
+// the values in points, which may be unsorted and repeated, as BuildTree does.
+// It returns nil if they exceed a capacity set by WithCapacity.
+func BuildTreeFromPoints[T Integer](points []T, workers int, opts ...Option) *Tree[T] {
+	intervals := make([]Interval[T], len(points))
+	for k, p := range points {
+		intervals[k] = Interval[T]{p, p}
+	}
+
+	t, _ := BuildTree(intervals, workers, opts...) // Points are valid intervals
+	return t
+}
+
+// compareIntervals orders intervals by their lower bound, then by their upper
+// bound.
+func compareIntervals[T Integer](a, b Interval[T]) int {
+	if c := cmp.Compare(a.I, b.I); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.J, b.J)
+}
+
+// parallelSort validates and sorts s using up to workers goroutines, and
+// returns the sorted intervals, which may be held in a new slice.
+func parallelSort[T Integer](s []Interval[T], workers int) ([]Interval[T], error) {
+	width := max(1, (len(s)+workers-1)/workers)
+
+	// Sort chunks of width intervals concurrently
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	for k, lo := 0, 0; lo < len(s); k, lo = k+1, lo+width {
+		chunk := s[lo:min(lo+width, len(s))]
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			for _, i := range chunk {
+				if i.I > i.J {
+					errs[k] = InvalidIntervalError[T]{i.I, i.J}
+					return
+				}
+			}
+			slices.SortFunc(chunk, compareIntervals[T])
+		}(k)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Merge pairs of sorted runs concurrently, doubling their width each round
+	buf := make([]Interval[T], len(s))
+	for ; width < len(s); width *= 2 {
+		for lo := 0; lo < len(s); lo += 2 * width {
+			mid, hi := min(lo+width, len(s)), min(lo+2*width, len(s))
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				mergeRuns(s[lo:mid], s[mid:hi], buf[lo:hi])
+			}()
+		}
+		wg.Wait()
+		s, buf = buf, s
+	}
+	return s, nil
+}
+
+// mergeRuns merges the sorted runs a and b into dst, which has room for both.
+func mergeRuns[T Integer](a, b, dst []Interval[T]) {
+	k := 0
+	for len(a) > 0 && len(b) > 0 {
+		if compareIntervals(b[0], a[0]) < 0 {
+			dst[k], b = b[0], b[1:]
+		} else {
+			dst[k], a = a[0], a[1:]
+		}
+		k++
+	}
+	k += copy(dst[k:], a)
+	copy(dst[k:], b)
+}
+
+// coalesce joins in place the overlapping and adjacent intervals in the sorted
+// slice s, returning the ascending, non adjacent intervals left.
+func coalesce[T Integer](s []Interval[T]) []Interval[T] {
+	if len(s) == 0 {
+		return s
+	}
+
+	ret := s[:1]
+	for _, i := range s[1:] {
+		last := &ret[len(ret)-1]
+		if i.I <= last.J || i.I-1 == last.J { // i.I > last.J cannot underflow
+			if i.J > last.J {
+				last.J = i.J
+			}
+			continue
+		}
+		ret = append(ret, i)
+	}
+	return ret
+}
+
+// buildParallel returns the root of a balanced tree holding the intervals,
+// which must be ascending and not adjacent, as build does. Subtrees are built
+// concurrently by up to workers goroutines.
+func buildParallel[T Integer](intervals []Interval[T], workers int) *node[T] {
+	if workers < 2 || len(intervals) < 4096 {
+		return build(intervals)
+	}
+
+	m := len(intervals) / 2
+	n := newNode(intervals[m].I, intervals[m].J)
+	done := make(chan struct{})
+	go func() {
+		n.Left = buildParallel(intervals[:m], workers/2)
+		close(done)
+	}()
+	n.Right = buildParallel(intervals[m+1:], workers-workers/2)
+	<-done
+	n.updateHeight()
+	return n
+}
@@ -0,0 +1,51 @@
+package intervaltree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBuildTree(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	intervals := make([]Interval[int32], 20000)
+	ref := NewTree[int32]()
+	for k := range intervals {
+		x := int32(r.Intn(1<<20) - 1<<19)
+		intervals[k] = Interval[int32]{x, x + int32(r.Intn(50))}
+		for _, g := range ref.Gaps(intervals[k].I, intervals[k].J) {
+			ref.Insert(g.I, g.J)
+		}
+	}
+	first := intervals[0]
+
+	for _, workers := range []int{1, 3, 8} {
+		bt, err := BuildTree(intervals, workers)
+		if err != nil {
+			t.Fatalf("Failed to build with %d workers: %v", workers, err)
+		}
+		if err := bt.root.isAVL(); err != nil {
+			t.Fatalf("Tree built with %d workers is not AVL: %v", workers, err)
+		}
+		if bt.ToString() != ref.ToString() {
+			t.Fatalf("Tree built with %d workers differs", workers)
+		}
+	}
+	if intervals[0] != first {
+		t.Fatal("Input was modified")
+	}
+
+	intervals[5000] = Interval[int32]{3, 2}
+	if _, err := BuildTree(intervals, 4); err != (InvalidIntervalError[int32]{3, 2}) {
+		t.Fatalf("Unexpected error building from invalid interval: %v", err)
+	}
+}
+
+func TestBuildTreeFromPoints(t *testing.T) {
+	bt := BuildTreeFromPoints([]uint8{9, 3, 255, 4, 3, 7, 8, 0}, 2)
+	if s := bt.ToString(); s != "[0 -- 0][3 -- 4][7 -- 9][255 -- 255]" {
+		t.Fatalf("Unexpected intervals: %s", s)
+	}
+	if BuildTreeFromPoints[uint8](nil, 4).root != nil {
+		t.Fatal("Tree built from no points is not empty")
+	}
+}
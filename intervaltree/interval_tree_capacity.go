@@ -0,0 +1,56 @@
+package intervaltree
+
+import "fmt"
+
+// CapacityError is returned whenever an insertion would make a tree created
+// with WithCapacity contain more values than its capacity.
+type CapacityError struct {
+	Capacity  uint64 // Values the tree may contain
+	Remaining uint64 // Values that could still be inserted
+	Requested uint64 // Values in the rejected interval, 0 if 2^64
+}
+
+func (e CapacityError) Error() string {
+	return fmt.Sprintf("Capacity of %d values exceeded: %d requested, %d remaining", e.Capacity, e.Requested, e.Remaining)
+}
+
+// WithCapacity caps the number of values the tree may contain, such as the
+// size of a domain or a budget. Insertions that would exceed it fail with a
+// CapacityError, which is checked in O(1) from the number of values held by
+// the root.
+func WithCapacity(capacity uint64) Option {
+	return func(c *config) {
+		c.capacity, c.capped = capacity, true
+	}
+}
+
+// Remaining returns how many more values the tree may contain, and false if it
+// was not created with WithCapacity.
+func (t *Tree[T]) Remaining() (uint64, bool) {
+	t.RLock()
+	defer t.RUnlock()
+	return t.capacity - t.root.getCovered(), t.capped
+}
+
+// checkCapacity checks that inserting [x, y], which must not overlap the tree,
+// keeps it within its capacity. The caller must hold the lock.
+func (t *Tree[T]) checkCapacity(x, y T) error {
+	remaining := t.capacity - t.root.getCovered()
+	requested := ordinal(y) - ordinal(x) + 1
+	if requested == 0 || requested > remaining {
+		return CapacityError{t.capacity, remaining, requested}
+	}
+	return nil
+}
+
+// checkContents checks that root, which replaces the contents of the tree,
+// holds no more values than its capacity. The caller must hold the lock.
+func (t *Tree[T]) checkContents(root *node[T]) error {
+	if !t.capped {
+		return nil
+	}
+	if c := root.getCovered(); c > t.capacity || (c == 0 && root != nil) {
+		return CapacityError{t.capacity, t.capacity - t.root.getCovered(), c}
+	}
+	return nil
+}
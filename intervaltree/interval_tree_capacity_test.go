@@ -0,0 +1,93 @@
+package intervaltree
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCapacity(t *testing.T) {
+	it := NewTree[int](WithCapacity(10))
+	if r, ok := it.Remaining(); !ok || r != 10 {
+		t.Fatalf("Unexpected remaining capacity: %d %v", r, ok)
+	}
+
+	if err := it.Insert(0, 5); err != nil {
+		t.Fatal(err)
+	}
+	var ce CapacityError
+	if err := it.Insert(10, 14); !errors.As(err, &ce) {
+		t.Fatalf("Expected a capacity error, got %v", err)
+	}
+	if ce != (CapacityError{10, 4, 5}) {
+		t.Fatalf("Unexpected capacity error: %+v", ce)
+	}
+	if it.Contains(10) {
+		t.Fatal("Rejected interval was inserted")
+	}
+
+	if err := it.Insert(10, 13); err != nil {
+		t.Fatal(err)
+	}
+	if r, _ := it.Remaining(); r != 0 {
+		t.Fatalf("Unexpected remaining capacity: %d", r)
+	}
+	if err := it.Remove(2, 3); err != nil {
+		t.Fatal(err)
+	}
+	if r, _ := it.Remaining(); r != 2 {
+		t.Fatalf("Unexpected remaining capacity: %d", r)
+	}
+	if err := it.Insert(2, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := it.Restore(Snapshot[int]{Intervals: []Interval[int]{{0, 10}}}); !errors.As(err, &ce) || ce != (CapacityError{10, 0, 11}) {
+		t.Fatalf("Unexpected error restoring over capacity: %v", err)
+	}
+	if err := it.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := NewTree[int]().Remaining(); ok {
+		t.Fatal("Uncapped tree reported a capacity")
+	}
+	if _, err := BuildTree([]Interval[int]{{0, 3}, {5, 9}}, 1, WithCapacity(8)); !errors.As(err, &ce) || ce != (CapacityError{8, 8, 9}) {
+		t.Fatalf("Unexpected error building over capacity: %v", err)
+	}
+}
+
+func TestCapacityAllocator(t *testing.T) {
+	a, _ := NewAllocator[uint8](0, 255, WithCapacity(3), WithStrategy(NextFit))
+	if _, err := a.AllocN(2); err != nil {
+		t.Fatal(err)
+	}
+	var ce CapacityError
+	if _, err := a.AllocN(2); !errors.As(err, &ce) {
+		t.Fatalf("Expected a capacity error, got %v", err)
+	}
+	if x, err := a.Alloc(); err != nil || x != 2 {
+		t.Fatalf("Unexpected allocation: %d %v", x, err)
+	}
+}
+
+func TestCapacityWAL(t *testing.T) {
+	dir := t.TempDir()
+	w, err := OpenWAL[int](dir, WithCapacity(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Insert(0, 3)
+	var ce CapacityError
+	if err := w.Insert(10, 10); !errors.As(err, &ce) {
+		t.Fatalf("Expected a capacity error, got %v", err)
+	}
+	w.Close()
+
+	if w, err = OpenWAL[int](dir, WithCapacity(4)); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if w.Contains(10) || !w.Contains(3) {
+		t.Fatal("Unexpected contents after recovery")
+	}
+}
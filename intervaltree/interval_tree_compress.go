@@ -0,0 +1,188 @@
+package intervaltree
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// compressedMagic starts every compressed snapshot.
+const compressedMagic = "ITREESNZ"
+
+// Codec compresses the body of compressed snapshots. Gzip is provided, and
+// other algorithms, such as zstd, can be plugged in by wrapping their
+// libraries in a few lines.
+type Codec interface {
+	// Name identifies the codec in the header of compressed snapshots, so
+	// they are not decompressed with another one.
+	Name() string
+	// NewWriter returns a writer compressing to w, which is closed to flush
+	// it but must not close w.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	// NewReader returns a reader decompressing from r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// Gzip is a Codec compressing with compress/gzip at Level, or at the default
+// level if it is 0.
+type Gzip struct {
+	Level int
+}
+
+// Name returns "gzip".
+func (Gzip) Name() string {
+	return "gzip"
+}
+
+// NewWriter returns a gzip writer compressing to w.
+func (g Gzip) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	if g.Level == 0 {
+		return gzip.NewWriter(w), nil
+	}
+	return gzip.NewWriterLevel(w, g.Level)
+}
+
+// NewReader returns a gzip reader decompressing a single gzip stream from r,
+// so it does not read past its end.
+func (Gzip) NewReader(r io.Reader) (io.ReadCloser, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	zr.Multistream(false)
+	return zr, nil
+}
+
+// CompressedHeader is the uncompressed header of a compressed snapshot, which
+// tells its size without decompressing it.
+type CompressedHeader struct {
+	Codec     string // Name of the codec of the body
+	Revision  uint64 // Revision of the snapshot
+	Intervals uint64 // Number of intervals in the snapshot
+	valueType uint64 // Type of the values, as identified by mappedType
+}
+
+// WriteCompressed writes s to w as a frame holding an uncompressed header,
+// with the number of intervals, followed by its binary encoding, as written by
+// WriteTo, compressed with c. It can be read with ReadCompressed.
+func (s Snapshot[T]) WriteCompressed(w io.Writer, c Codec) error {
+	vw := &varintWriter{w: bufio.NewWriter(w)}
+	vw.w.WriteString(compressedMagic)
+	vw.put(mappedType[T]())
+	vw.put(uint64(len(c.Name())))
+	vw.w.WriteString(c.Name())
+	vw.put(s.Revision)
+	vw.put(uint64(len(s.Intervals)))
+
+	cw, err := c.NewWriter(vw.w)
+	if err != nil {
+		return err
+	}
+	if _, err := s.WriteTo(cw); err != nil {
+		return err
+	}
+	if err := cw.Close(); err != nil {
+		return err
+	}
+	_, err = vw.flush()
+	return err
+}
+
+// ReadCompressedHeader reads the header of a compressed snapshot written by
+// Snapshot.WriteCompressed from r, leaving r at the start of its body.
+func ReadCompressedHeader(r io.ByteReader) (CompressedHeader, error) {
+	var h CompressedHeader
+	magic := make([]byte, len(compressedMagic))
+	for k := range magic {
+		var err error
+		if magic[k], err = r.ReadByte(); err != nil {
+			return h, err
+		}
+	}
+	if string(magic) != compressedMagic {
+		return h, fmt.Errorf("Malformed compressed snapshot: bad header")
+	}
+
+	var length uint64
+	if err := readUvarints(r, &h.valueType, &length); err != nil {
+		return h, err
+	}
+	if length > 255 {
+		return h, fmt.Errorf("Malformed compressed snapshot: codec name of %d bytes", length)
+	}
+	name := make([]byte, length)
+	for k := range name {
+		var err error
+		if name[k], err = r.ReadByte(); err != nil {
+			return h, io.ErrUnexpectedEOF
+		}
+	}
+	h.Codec = string(name)
+	return h, readUvarints(r, &h.Revision, &h.Intervals)
+}
+
+// ReadCompressed reads a compressed snapshot written by
+// Snapshot.WriteCompressed from r, decompressing it with c, which must be the
+// codec it was written with. Unless r is a *bufio.Reader, it may read past the
+// end of the snapshot.
+func ReadCompressed[T Integer](r io.Reader, c Codec) (Snapshot[T], error) {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	h, err := ReadCompressedHeader(br)
+	if err != nil {
+		return Snapshot[T]{}, err
+	}
+	if h.valueType != mappedType[T]() {
+		return Snapshot[T]{}, fmt.Errorf("Compressed snapshot holds values of another type")
+	}
+	if h.Codec != c.Name() {
+		return Snapshot[T]{}, fmt.Errorf("Compressed snapshot written with codec %q, not %q", h.Codec, c.Name())
+	}
+
+	cr, err := c.NewReader(br)
+	if err != nil {
+		return Snapshot[T]{}, err
+	}
+	defer cr.Close()
+	body := bufio.NewReader(cr)
+	s, err := ReadSnapshot[T](body)
+	if err != nil {
+		return Snapshot[T]{}, err
+	}
+	if _, err := io.Copy(io.Discard, body); err != nil { // Checks the trailer of the stream
+		return Snapshot[T]{}, err
+	}
+	if s.Revision != h.Revision || uint64(len(s.Intervals)) != h.Intervals {
+		return Snapshot[T]{}, fmt.Errorf("Malformed compressed snapshot: header does not match body")
+	}
+	return s, nil
+}
+
+// SaveCompressed writes a snapshot of the tree to w compressed with c, as
+// Snapshot.WriteCompressed does. Unlike Checkpoint, it does not start
+// journaling changes.
+func (t *Tree[T]) SaveCompressed(w io.Writer, c Codec) error {
+	t.RLock()
+	s := Snapshot[T]{Revision: t.rev, Intervals: make([]Interval[T], 0, t.root.getIntervals())}
+	t.root.walk(func(x, y T) bool {
+		s.Intervals = append(s.Intervals, Interval[T]{x, y})
+		return true
+	})
+	t.RUnlock()
+
+	return s.WriteCompressed(w, c)
+}
+
+// LoadCompressed replaces the contents and the revision of the tree with those
+// of the compressed snapshot read from r with ReadCompressed, as Restore does.
+func (t *Tree[T]) LoadCompressed(r io.Reader, c Codec) error {
+	s, err := ReadCompressed[T](r, c)
+	if err != nil {
+		return err
+	}
+	return t.Restore(s)
+}
@@ -0,0 +1,76 @@
+package intervaltree
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestCompressedSnapshot(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	it := NewTree[int32]()
+	for i := 0; i < 10000; i++ {
+		x := int32(r.Intn(1 << 24))
+		it.Insert(x, x+int32(r.Intn(4)))
+	}
+
+	var b bytes.Buffer
+	if err := it.SaveCompressed(&b, Gzip{Level: gzip.BestCompression}); err != nil {
+		t.Fatal(err)
+	}
+	var plain bytes.Buffer
+	s := Snapshot[int32]{Revision: it.Revision(), Intervals: it.Freeze().intervals}
+	s.WriteTo(&plain)
+	if b.Len() >= plain.Len() {
+		t.Fatalf("Compressed snapshot takes %d bytes, uncompressed %d", b.Len(), plain.Len())
+	}
+
+	h, err := ReadCompressedHeader(bytes.NewReader(b.Bytes()))
+	if err != nil || h.Codec != "gzip" || h.Revision != s.Revision || h.Intervals != uint64(len(s.Intervals)) {
+		t.Fatalf("Unexpected header %+v: %v", h, err)
+	}
+
+	// Snapshots can be framed one after another in the same stream
+	s.Revision = 0
+	s.WriteCompressed(&b, Gzip{})
+	br := bufio.NewReader(&b)
+	restored := NewTree[int32]()
+	if err := restored.LoadCompressed(br, Gzip{}); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Revision() != it.Revision() || !slices.Equal(restored.Freeze().intervals, s.Intervals) {
+		t.Fatal("Restored tree differs from the saved one")
+	}
+	if second, err := ReadCompressed[int32](br, Gzip{}); err != nil || second.Revision != 0 || len(second.Intervals) != len(s.Intervals) {
+		t.Fatalf("Failed to read the second snapshot: %v", err)
+	}
+}
+
+// otherCodec is a Codec with another name than Gzip.
+type otherCodec struct{ Gzip }
+
+func (otherCodec) Name() string { return "other" }
+
+func TestCompressedSnapshotErrors(t *testing.T) {
+	it := New()
+	it.Insert(10, 20)
+	var b bytes.Buffer
+	it.SaveCompressed(&b, Gzip{})
+	data := b.Bytes()
+
+	if _, err := ReadCompressed[uint64](bytes.NewReader(data), otherCodec{}); err == nil {
+		t.Fatal("Read snapshot with another codec")
+	}
+	if _, err := ReadCompressed[int64](bytes.NewReader(data), Gzip{}); err == nil {
+		t.Fatal("Read snapshot of another type")
+	}
+	if _, err := ReadCompressed[uint64](bytes.NewReader(data[:len(data)-3]), Gzip{}); err == nil {
+		t.Fatal("Read truncated snapshot")
+	}
+	if _, err := ReadCompressed[uint64](bytes.NewReader([]byte("ITREEMAP")), Gzip{}); err == nil {
+		t.Fatal("Read snapshot with bad magic")
+	}
+}
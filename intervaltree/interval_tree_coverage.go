@@ -0,0 +1,72 @@
+package intervaltree
+
+import "math"
+
+// CoverageRatio returns the fraction of the values in [lo, hi] that are
+// contained in the tree, or 0 if lo > hi. It is computed in O(log n) from the
+// number of values contained in each subtree.
+func (t *Tree[T]) CoverageRatio(lo, hi T) float64 {
+	if lo > hi {
+		return 0
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+
+	if t.root != nil && t.root.covered == 0 { // Every value of a 64-bit T
+		return 1
+	}
+	covered := t.root.coveredUpTo(hi)
+	if least, _ := Limits[T](); lo > least {
+		covered -= t.root.coveredUpTo(lo - 1)
+	}
+
+	length := ordinal(hi) - ordinal(lo) + 1
+	if length == 0 { // Every value of a 64-bit T
+		return float64(covered) / (math.MaxUint64 + 1.0)
+	}
+	return float64(covered) / float64(length)
+}
+
+// coveredUpTo returns the number of values lesser or equal to x contained in
+// the subtree rooted at n, which cannot contain every value of a 64-bit T.
+func (n *node[T]) coveredUpTo(x T) uint64 {
+	var covered uint64
+	for n != nil {
+		if x < n.I {
+			n = n.Left
+			continue
+		}
+
+		covered += n.Left.getCovered()
+		if x <= n.J {
+			return covered + ordinal(x) - ordinal(n.I) + 1
+		}
+		covered += ordinal(n.J) - ordinal(n.I) + 1
+		n = n.Right
+	}
+	return covered
+}
+
+// IsFull checks if every value in [lo, hi] is contained in the tree, which is
+// false if lo > hi. As adjacent intervals are joined, it is a single lookup in
+// O(log n).
+func (t *Tree[T]) IsFull(lo, hi T) bool {
+	if lo > hi {
+		return false
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+	c := t.root.containingNode(lo)
+	return c != nil && hi <= c.J
+}
+
+// Full checks if every value of T is contained in the tree, in O(1) from the
+// number of values held by the root.
+func (t *Tree[T]) Full() bool {
+	t.RLock()
+	defer t.RUnlock()
+	least, greatest := Limits[T]()
+	return t.root != nil && t.root.covered == ordinal(greatest)-ordinal(least)+1
+}
@@ -0,0 +1,128 @@
+package intervaltree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestCoverageRatio(t *testing.T) {
+	it := NewTree[int8]()
+	it.Insert(-128, -119)
+	it.Insert(0, 9)
+	it.Insert(100, 127)
+
+	for _, c := range []struct {
+		lo, hi   int8
+		expected float64
+	}{
+		{-128, 127, 48.0 / 256},
+		{-128, -119, 1},
+		{-120, -110, 2.0 / 11},
+		{-10, 10, 10.0 / 21},
+		{10, 99, 0},
+		{5, 4, 0},
+		{120, 127, 1},
+	} {
+		if r := it.CoverageRatio(c.lo, c.hi); r != c.expected {
+			t.Fatalf("Unexpected coverage of [%d, %d]: %v, expected %v", c.lo, c.hi, r, c.expected)
+		}
+	}
+
+	full := New()
+	full.Insert(0, math.MaxUint64)
+	if r := full.CoverageRatio(0, math.MaxUint64); r != 1 {
+		t.Fatalf("Unexpected coverage of full tree: %v", r)
+	}
+	full.Remove(0, math.MaxUint64/2)
+	if r := full.CoverageRatio(0, math.MaxUint64); r != 0.5 {
+		t.Fatalf("Unexpected coverage of half tree: %v", r)
+	}
+}
+
+func TestCoverageRatioRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	it := NewTree[uint16]()
+	for i := 0; i < 5000; i++ {
+		x := uint16(r.Intn(math.MaxUint16))
+		y := x + uint16(r.Intn(math.MaxUint16-int(x)+1)%50)
+		if r.Intn(3) == 0 && it.Contains(x) { // Shrink, split or remove an interval
+			if n, ok := it.Next(x); ok {
+				y = min(y, n-1)
+			}
+			if err := it.Remove(x, y); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			it.Insert(x, y)
+		}
+
+		if i%100 == 0 {
+			if err := it.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			lo := uint16(r.Intn(math.MaxUint16))
+			hi := lo + uint16(r.Intn(math.MaxUint16-int(lo)+1))
+			var covered int
+			for _, g := range it.Gaps(lo, hi) {
+				covered -= int(g.J) - int(g.I) + 1
+			}
+			covered += int(hi) - int(lo) + 1
+			if ratio := it.CoverageRatio(lo, hi); ratio != float64(covered)/float64(int(hi)-int(lo)+1) {
+				t.Fatalf("Unexpected coverage of [%d, %d]: %v", lo, hi, ratio)
+			}
+		}
+	}
+}
+
+func TestIsFull(t *testing.T) {
+	it := NewTree[uint8]()
+	it.Insert(10, 20)
+	it.Insert(21, 30)
+	it.Insert(40, 50)
+
+	for _, c := range []struct {
+		lo, hi   uint8
+		expected bool
+	}{
+		{10, 30, true},
+		{15, 15, true},
+		{10, 31, false},
+		{9, 20, false},
+		{25, 45, false},
+		{40, 50, true},
+		{50, 40, false},
+		{0, 255, false},
+	} {
+		if full := it.IsFull(c.lo, c.hi); full != c.expected {
+			t.Fatalf("IsFull(%d, %d) = %v, expected %v", c.lo, c.hi, full, c.expected)
+		}
+	}
+	if it.Full() || NewTree[uint8]().Full() {
+		t.Fatal("Partial tree reported full")
+	}
+
+	full := New()
+	full.Insert(0, math.MaxUint64)
+	signed := NewTree[int8]()
+	signed.Insert(-128, 127)
+	if !full.Full() || !signed.Full() || !full.IsFull(0, math.MaxUint64) {
+		t.Fatal("Full tree not reported full")
+	}
+	signed.Remove(127, 127)
+	if signed.Full() {
+		t.Fatal("Partial tree reported full")
+	}
+}
+
+func TestAllocatorFull(t *testing.T) {
+	a, _ := NewAllocator[uint16](100, 103)
+	a.AllocN(3)
+	if a.Full() {
+		t.Fatal("Allocator with a free value reported full")
+	}
+	a.Alloc()
+	if !a.Full() {
+		t.Fatal("Exhausted allocator not reported full")
+	}
+}
@@ -0,0 +1,276 @@
+package intervaltree
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// COWTree represents the same set of intervals as Tree, but its readers never
+// take a lock. Nodes reachable from the root are immutable: readers load the
+// current root atomically, while writers copy the nodes along the path they
+// modify and install the new root once done. Writers are serialized by a
+// mutex, so COWTree suits read-heavy workloads.
+type COWTree[T Integer] struct {
+	root atomic.Pointer[node[T]]
+	mu   sync.Mutex // Serializes writers
+}
+
+// NewCOW returns a pointer to an empty COWTree.
+func NewCOW[T Integer]() *COWTree[T] {
+	return &COWTree[T]{}
+}
+
+// clone returns a private copy of this node, which can be modified.
+func (n *node[T]) clone() *node[T] {
+	c := *n
+	return &c
+}
+
+// floor returns the node holding the greatest interval starting at or before
+// x in the subtree rooted at n, or nil if there is none.
+func (n *node[T]) floor(x T) *node[T] {
+	var ret *node[T]
+	for n != nil {
+		if n.I <= x {
+			ret, n = n, n.Right
+		} else {
+			n = n.Left
+		}
+	}
+	return ret
+}
+
+// higher returns the node holding the least interval starting after x in the
+// subtree rooted at n, or nil if there is none.
+func (n *node[T]) higher(x T) *node[T] {
+	var ret *node[T]
+	for n != nil {
+		if n.I > x {
+			ret, n = n, n.Left
+		} else {
+			n = n.Right
+		}
+	}
+	return ret
+}
+
+// cowInsert returns a copy of the subtree rooted at n with a node holding
+// [x, y] added. The subtree cannot hold an interval starting at x.
+func (n *node[T]) cowInsert(x, y T) *node[T] {
+	if n == nil {
+		return newNode(x, y)
+	}
+
+	c := n.clone()
+	if x < c.I {
+		c.Left = c.Left.cowInsert(x, y)
+	} else {
+		c.Right = c.Right.cowInsert(x, y)
+	}
+	return c.cowRebalance()
+}
+
+// cowRemove returns a copy of the subtree rooted at n without the node holding
+// the interval starting at x. Such a node must exist.
+func (n *node[T]) cowRemove(x T) *node[T] {
+	if x == n.I {
+		switch {
+		case n.Left == nil:
+			return n.Right
+		case n.Right == nil:
+			return n.Left
+		}
+	}
+
+	c := n.clone()
+	if x < c.I {
+		c.Left = c.Left.cowRemove(x)
+	} else if x > c.I {
+		c.Right = c.Right.cowRemove(x)
+	} else { // Replace this interval with the next one
+		var next *node[T]
+		c.Right, next = c.Right.cowRemoveLeast()
+		c.I, c.J = next.I, next.J
+	}
+	return c.cowRebalance()
+}
+
+// cowRemoveLeast returns a copy of the subtree rooted at n without the node
+// holding the least interval, and that node.
+func (n *node[T]) cowRemoveLeast() (*node[T], *node[T]) {
+	if n.Left == nil {
+		return n.Right, n
+	}
+
+	c := n.clone()
+	var least *node[T]
+	c.Left, least = c.Left.cowRemoveLeast()
+	return c.cowRebalance(), least
+}
+
+// cowSet returns a copy of the subtree rooted at n where the interval starting
+// at i is replaced by [x, y], which must keep its place in the order.
+func (n *node[T]) cowSet(i, x, y T) *node[T] {
+	c := n.clone()
+	if i < c.I {
+		c.Left = c.Left.cowSet(i, x, y)
+	} else if i > c.I {
+		c.Right = c.Right.cowSet(i, x, y)
+	} else {
+		c.I, c.J = x, y
+	}
+	c.updateCovered()
+	return c
+}
+
+// cowRebalance fixes AVL invariants violations by applying rotations to this
+// node, which must be a private copy, and returns the new root of its subtree.
+func (n *node[T]) cowRebalance() *node[T] {
+	bal := n.balanceFactor()
+	if bal == 2 {
+		if n.Left.balanceFactor() < 0 {
+			n.Left = n.Left.clone().cowRotateRight()
+		}
+		return n.cowRotateLeft()
+	} else if bal == -2 {
+		if n.Right.balanceFactor() > 0 {
+			n.Right = n.Right.clone().cowRotateLeft()
+		}
+		return n.cowRotateRight()
+	}
+
+	n.updateHeight()
+	return n
+}
+
+// cowRotateLeft performs a left tree rotation on this node, which must be a
+// private copy, copying the pivot, and returns the new root.
+func (n *node[T]) cowRotateLeft() *node[T] {
+	pivot := n.Left.clone()
+	n.Left = pivot.Right
+	pivot.Right = n
+	n.updateHeight()
+	pivot.updateHeight()
+	return pivot
+}
+
+// cowRotateRight performs a right tree rotation on this node, which must be a
+// private copy, copying the pivot, and returns the new root.
+func (n *node[T]) cowRotateRight() *node[T] {
+	pivot := n.Right.clone()
+	n.Right = pivot.Left
+	pivot.Left = n
+	n.updateHeight()
+	pivot.updateHeight()
+	return pivot
+}
+
+// Insert adds an interval to the tree. The interval cannot overlap with the
+// tree. If prunning is possible it will be done.
+func (t *COWTree[T]) Insert(x, y T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	root, err := cowInsertInterval(t.root.Load(), x, y)
+	if err != nil {
+		return err
+	}
+	t.root.Store(root)
+	return nil
+}
+
+// cowInsertInterval returns the root of a copy of the tree rooted at root with
+// [x, y] added, which cannot overlap with it. The tree itself is not modified.
+func cowInsertInterval[T Integer](root *node[T], x, y T) (*node[T], error) {
+	if x > y {
+		return nil, InvalidIntervalError[T]{x, y}
+	}
+
+	l, r := root.floor(x), root.higher(x)
+	if l != nil && l.J >= x {
+		return nil, OverlapError[T]{x, Interval[T]{x, y}, Interval[T]{l.I, l.J}}
+	}
+	if r != nil && r.I <= y {
+		return nil, OverlapError[T]{r.I, Interval[T]{x, y}, Interval[T]{r.I, r.J}}
+	}
+
+	// Neighbours are checked stepping towards the lesser value, so no check
+	// can overflow at the bounds of T
+	joinL := l != nil && l.J == x-1
+	joinR := r != nil && r.I-1 == y
+	switch {
+	case joinL && joinR: // Fill the gap between l and r
+		return root.cowRemove(r.I).cowSet(l.I, l.I, r.J), nil
+	case joinL:
+		return root.cowSet(l.I, l.I, y), nil
+	case joinR:
+		return root.cowSet(r.I, x, r.J), nil
+	}
+	return root.cowInsert(x, y), nil
+}
+
+// Remove deletes the interval [x, y] from the tree. Every value in [x, y] must
+// be contained in the tree. Stored intervals are shrunk or split as needed.
+func (t *COWTree[T]) Remove(x, y T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	root, err := cowRemoveInterval(t.root.Load(), x, y)
+	if err != nil {
+		return err
+	}
+	t.root.Store(root)
+	return nil
+}
+
+// cowRemoveInterval returns the root of a copy of the tree rooted at root with
+// [x, y] deleted, which must be contained in it. The tree itself is not
+// modified.
+func cowRemoveInterval[T Integer](root *node[T], x, y T) (*node[T], error) {
+	if x > y {
+		return nil, InvalidIntervalError[T]{x, y}
+	}
+
+	// Stored intervals are never adjacent, so [x, y] must lie in a single one
+	c := root.containingNode(x)
+	if c == nil {
+		return nil, NotContainedError[T]{x}
+	}
+	if y > c.J {
+		return nil, NotContainedError[T]{c.J + 1}
+	}
+
+	switch {
+	case x == c.I && y == c.J:
+		return root.cowRemove(c.I), nil
+	case x == c.I:
+		return root.cowSet(c.I, y+1, c.J), nil
+	case y == c.J:
+		return root.cowSet(c.I, c.I, x-1), nil
+	}
+	// Split, the upper part becomes a new node
+	return root.cowSet(c.I, c.I, x-1).cowInsert(y+1, c.J), nil
+}
+
+// Contains checks if x is contained in the tree. It never blocks.
+func (t *COWTree[T]) Contains(x T) bool {
+	return t.root.Load().contains(x)
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to x. It returns false if every such value is contained. It never
+// blocks.
+func (t *COWTree[T]) Next(x T) (T, bool) {
+	c := t.root.Load().containingNode(x)
+	if c == nil {
+		return x, true
+	}
+	return after(c.J)
+}
+
+// Walk calls fn for the intervals in the tree in ascending order, as they were
+// when Walk was called. It stops as soon as fn returns false. It never blocks,
+// and the tree can be changed from fn.
+func (t *COWTree[T]) Walk(fn func(x, y T) bool) {
+	t.root.Load().walk(fn)
+}
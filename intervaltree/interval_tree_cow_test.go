@@ -0,0 +1,65 @@
+package intervaltree
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestCOWTree(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	ct, it := NewCOW[uint8](), NewTree[uint8]()
+	for i := 0; i < 5000; i++ {
+		x := uint8(r.Intn(256))
+		y := x + uint8(r.Intn(256-int(x))%16)
+
+		before := ct.root.Load()
+		snapshot := before.print()
+		if r.Intn(3) == 0 {
+			if errA, errB := ct.Remove(x, y), it.Remove(x, y); errA != errB {
+				t.Fatalf("Remove [%d, %d] failed differently: %v, %v", x, y, errA, errB)
+			}
+		} else if errA, errB := ct.Insert(x, y), it.Insert(x, y); (errA == nil) != (errB == nil) {
+			t.Fatalf("Insert [%d, %d] failed differently: %v, %v", x, y, errA, errB)
+		}
+
+		if before.print() != snapshot {
+			t.Fatalf("Previous version changed after %d operations", i)
+		}
+		if err := ct.root.Load().isAVL(); err != nil {
+			t.Fatalf("Tree is not AVL after %d operations: %v", i, err)
+		}
+		if ct.root.Load().print() != it.ToString() {
+			t.Fatalf("Trees differ after %d operations", i)
+		}
+	}
+
+	for x := 0; x < 256; x++ {
+		if ct.Contains(uint8(x)) != it.Contains(uint8(x)) || !sameNext(ct, it, uint8(x)) {
+			t.Fatalf("Trees differ at %d", x)
+		}
+	}
+}
+
+func TestCOWTreeConcurrentReads(t *testing.T) {
+	ct := NewCOW[uint64]()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for x := uint64(0); x < 10000; x++ {
+				// Even values are inserted and never removed
+				if x%2 == 0 && x < 1000 && ct.Contains(x) && next(ct, x) != x+1 {
+					t.Errorf("Unexpected Next(%d) = %d", x, next(ct, x))
+					return
+				}
+			}
+		}()
+	}
+
+	for x := uint64(0); x < 1000; x += 2 {
+		ct.Insert(x, x)
+	}
+	wg.Wait()
+}
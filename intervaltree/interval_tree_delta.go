@@ -0,0 +1,341 @@
+package intervaltree
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Change represents a single successful mutation of a tree: the insertion or
+// the removal of an interval.
+type Change[T Integer] struct {
+	Removed bool // Whether the interval was removed instead of inserted
+	Interval[T]
+}
+
+// Snapshot holds all the intervals in a tree at a given revision.
+type Snapshot[T Integer] struct {
+	Revision  uint64
+	Intervals []Interval[T] // Intervals in ascending order
+}
+
+// Delta holds the changes that take a tree from revision From to revision To.
+type Delta[T Integer] struct {
+	From, To uint64
+	Changes  []Change[T] // Changes in the order they were made
+}
+
+// journal records the changes made to a tree since revision from. A journal
+// started by Checkpoint is kept whole, while one started by Stream only keeps
+// the changes its live streams have yet to send.
+type journal[T Integer] struct {
+	from    uint64
+	changes []Change[T]
+	pinned  bool                 // Whether it was started by Checkpoint
+	streams map[*uint64]struct{} // Revisions sent by the live streams
+}
+
+// trim discards the changes every live stream has sent, unless the journal is
+// pinned. The caller must hold the write lock.
+func (j *journal[T]) trim() {
+	if j.pinned || len(j.streams) == 0 {
+		return
+	}
+	least := j.from + uint64(len(j.changes))
+	for sent := range j.streams {
+		least = min(least, *sent)
+	}
+	if least > j.from {
+		n := copy(j.changes, j.changes[least-j.from:])
+		j.changes = j.changes[:n]
+		j.from = least
+	}
+}
+
+// record registers a successful mutation of the tree. The caller must hold
+// the write lock.
+func (t *Tree[T]) record(c Change[T]) {
+	if c.Removed {
+		t.counters.inc(removes)
+	} else {
+		t.counters.inc(inserts)
+	}
+	t.rev++
+	if t.journal != nil {
+		t.journal.changes = append(t.journal.changes, c)
+	}
+	if t.audit != nil {
+		t.writeAudit(c)
+	}
+	t.notify()
+}
+
+// Revision returns the number of successful mutations applied to the tree.
+// Restore sets it to the revision of the snapshot.
+func (t *Tree[T]) Revision() uint64 {
+	t.RLock()
+	defer t.RUnlock()
+	return t.rev
+}
+
+// Checkpoint returns a snapshot of the tree and starts journaling the changes
+// made from its revision on, discarding any previous journal. Changes are not
+// journaled until the first checkpoint is taken.
+func (t *Tree[T]) Checkpoint() Snapshot[T] {
+	t.Lock()
+	defer t.Unlock()
+
+	s := Snapshot[T]{Revision: t.rev}
+	t.root.walk(func(x, y T) bool {
+		s.Intervals = append(s.Intervals, Interval[T]{x, y})
+		return true
+	})
+	t.journal = &journal[T]{from: t.rev, pinned: true}
+	return s
+}
+
+// Delta returns the changes made to the tree since revision since. The
+// revision must not be older than the last checkpoint.
+func (t *Tree[T]) Delta(since uint64) (Delta[T], error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	if t.journal == nil || since < t.journal.from || since > t.rev {
+		return Delta[T]{}, RevisionError(since)
+	}
+
+	changes := t.journal.changes[since-t.journal.from:]
+	return Delta[T]{
+		From:    since,
+		To:      t.rev,
+		Changes: append([]Change[T](nil), changes...),
+	}, nil
+}
+
+// Restore replaces the contents and the revision of the tree with those of s.
+// Adjacent intervals in s are joined. It fails with a CapacityError if s holds
+// more values than a capacity set by WithCapacity.
+func (t *Tree[T]) Restore(s Snapshot[T]) (err error) {
+	if t.tracer != nil {
+		span := t.traceBulk("intervaltree.Restore", "intervals", len(s.Intervals))
+		defer func() { span.End(err) }()
+	}
+
+	intervals := make([]Interval[T], 0, len(s.Intervals))
+	for _, i := range s.Intervals {
+		if i.I > i.J {
+			return InvalidIntervalError[T]{i.I, i.J}
+		}
+
+		if k := len(intervals) - 1; k >= 0 {
+			if i.I <= intervals[k].J {
+				return OverlapError[T]{i.I, i, intervals[k]}
+			}
+			if i.I == intervals[k].J+1 {
+				intervals[k].J = i.J
+				continue
+			}
+		}
+		intervals = append(intervals, i)
+	}
+
+	root := build(intervals)
+	t.Lock()
+	defer t.Unlock()
+	if err := t.checkContents(root); err != nil {
+		return err
+	}
+	t.root = root
+	t.rev = s.Revision
+	if t.journal != nil {
+		t.journal = &journal[T]{from: t.rev, pinned: t.journal.pinned}
+	}
+	t.notify()
+	return nil
+}
+
+// Apply makes the changes in d to the tree, which must be at revision d.From.
+// If a change fails the tree is left with the changes preceding it applied.
+func (t *Tree[T]) Apply(d Delta[T]) (err error) {
+	if t.tracer != nil {
+		span := t.traceBulk("intervaltree.Apply", "changes", len(d.Changes))
+		defer func() { span.End(err) }()
+	}
+
+	if d.To-d.From != uint64(len(d.Changes)) {
+		return RevisionError(d.To)
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	if t.rev != d.From {
+		return RevisionError(d.From)
+	}
+
+	for _, c := range d.Changes {
+		if c.I > c.J {
+			return InvalidIntervalError[T]{c.I, c.J}
+		}
+
+		var err error
+		if c.Removed {
+			err = t.remove(c.I, c.J)
+		} else {
+			err = t.insert(c.I, c.J)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// build returns the root of a balanced tree holding the intervals, which must
+// be ascending and not adjacent.
+func build[T Integer](intervals []Interval[T]) *node[T] {
+	if len(intervals) == 0 {
+		return nil
+	}
+
+	m := len(intervals) / 2
+	n := newNode(intervals[m].I, intervals[m].J)
+	n.Left = build(intervals[:m])
+	n.Right = build(intervals[m+1:])
+	n.updateHeight()
+	return n
+}
+
+// varintWriter writes unsigned varints to a buffered writer, keeping count of
+// the bytes written and of the first error found.
+type varintWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+	buf [binary.MaxVarintLen64]byte
+}
+
+// put writes v unless a previous write failed.
+func (vw *varintWriter) put(v uint64) {
+	if vw.err != nil {
+		return
+	}
+
+	k, err := vw.w.Write(vw.buf[:binary.PutUvarint(vw.buf[:], v)])
+	vw.n += int64(k)
+	vw.err = err
+}
+
+// flush flushes the underlying writer and returns the results of the writes.
+func (vw *varintWriter) flush() (int64, error) {
+	if vw.err != nil {
+		return vw.n, vw.err
+	}
+	return vw.n, vw.w.Flush()
+}
+
+// WriteTo writes s to w in a compact binary format that can be read with
+// ReadSnapshot.
+func (s Snapshot[T]) WriteTo(w io.Writer) (int64, error) {
+	vw := &varintWriter{w: bufio.NewWriter(w)}
+	vw.put(s.Revision)
+	vw.put(uint64(len(s.Intervals)))
+
+	var prev uint64
+	for _, i := range s.Intervals {
+		vw.put(ordinal(i.I) - prev) // Gap from the previous interval
+		vw.put(ordinal(i.J) - ordinal(i.I))
+		prev = ordinal(i.J)
+	}
+	return vw.flush()
+}
+
+// ReadSnapshot reads a snapshot written by Snapshot.WriteTo from r.
+func ReadSnapshot[T Integer](r io.ByteReader) (Snapshot[T], error) {
+	var s Snapshot[T]
+	var count uint64
+	if err := readUvarints(r, &s.Revision, &count); err != nil {
+		return s, err
+	}
+
+	var prev uint64
+	for ; count > 0; count-- {
+		var gap, length uint64
+		if err := readUvarints(r, &gap, &length); err != nil {
+			return s, err
+		}
+
+		i, ok := readInterval[T](prev+gap, length)
+		if !ok || prev+gap < prev {
+			return s, fmt.Errorf("Malformed snapshot: interval out of range after %d", prev)
+		}
+		s.Intervals = append(s.Intervals, i)
+		prev = ordinal(i.J)
+	}
+	return s, nil
+}
+
+// WriteTo writes d to w in a compact binary format that can be read with
+// ReadDelta.
+func (d Delta[T]) WriteTo(w io.Writer) (int64, error) {
+	vw := &varintWriter{w: bufio.NewWriter(w)}
+	vw.put(d.From)
+	vw.put(d.To)
+	vw.put(uint64(len(d.Changes)))
+
+	for _, c := range d.Changes {
+		op := uint64(0)
+		if c.Removed {
+			op = 1
+		}
+		vw.put(op)
+		vw.put(ordinal(c.I))
+		vw.put(ordinal(c.J) - ordinal(c.I))
+	}
+	return vw.flush()
+}
+
+// ReadDelta reads a delta written by Delta.WriteTo from r.
+func ReadDelta[T Integer](r io.ByteReader) (Delta[T], error) {
+	var d Delta[T]
+	var count uint64
+	if err := readUvarints(r, &d.From, &d.To, &count); err != nil {
+		return d, err
+	}
+
+	for ; count > 0; count-- {
+		var op, x, length uint64
+		if err := readUvarints(r, &op, &x, &length); err != nil {
+			return d, err
+		}
+
+		i, ok := readInterval[T](x, length)
+		if op > 1 || !ok {
+			return d, fmt.Errorf("Malformed delta: invalid change %d [%d, +%d]", op, x, length)
+		}
+		d.Changes = append(d.Changes, Change[T]{op == 1, i})
+	}
+	return d, nil
+}
+
+// readInterval returns the interval starting at the ordinal x and holding
+// length+1 values, and whether it is representable by T.
+func readInterval[T Integer](x, length uint64) (Interval[T], bool) {
+	i, ok := fromOrdinal[T](x)
+	j, okJ := fromOrdinal[T](x + length)
+	return Interval[T]{i, j}, ok && okJ && x+length >= x
+}
+
+// readUvarints reads unsigned varints from r into vs.
+func readUvarints(r io.ByteReader, vs ...*uint64) error {
+	for _, v := range vs {
+		var err error
+		if *v, err = binary.ReadUvarint(r); err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package intervaltree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCheckpointAndDelta(t *testing.T) {
+	primary := New()
+	primary.Insert(1, 5)
+	primary.Insert(10, 20)
+	if _, err := primary.Delta(0); err == nil {
+		t.Fatal("Delta succeeded before any checkpoint was taken")
+	}
+
+	s := primary.Checkpoint()
+	if s.Revision != 2 || len(s.Intervals) != 2 {
+		t.Fatalf("Unexpected checkpoint: %+v", s)
+	}
+
+	var b bytes.Buffer
+	if _, err := s.WriteTo(&b); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+	s, err := ReadSnapshot[uint64](&b)
+	if err != nil {
+		t.Fatalf("Failed to read snapshot: %v", err)
+	}
+
+	follower := New()
+	if err := follower.Restore(s); err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+	if follower.ToString() != primary.ToString() || follower.Revision() != 2 {
+		t.Fatalf("Restored tree differs: %s at %d", follower.ToString(), follower.Revision())
+	}
+
+	primary.Insert(6, 9)
+	primary.Remove(12, 14)
+	primary.Insert(30, 30)
+	d, err := primary.Delta(s.Revision)
+	if err != nil {
+		t.Fatalf("Failed to get delta: %v", err)
+	}
+	if d.From != 2 || d.To != 5 || len(d.Changes) != 3 || !d.Changes[1].Removed {
+		t.Fatalf("Unexpected delta: %+v", d)
+	}
+
+	b.Reset()
+	if _, err := d.WriteTo(&b); err != nil {
+		t.Fatalf("Failed to write delta: %v", err)
+	}
+	if d, err = ReadDelta[uint64](&b); err != nil {
+		t.Fatalf("Failed to read delta: %v", err)
+	}
+	if err := follower.Apply(d); err != nil {
+		t.Fatalf("Failed to apply delta: %v", err)
+	}
+	if follower.ToString() != primary.ToString() || follower.Revision() != primary.Revision() {
+		t.Fatalf("Follower differs. Got %s at %d, expected %s at %d", follower.ToString(), follower.Revision(), primary.ToString(), primary.Revision())
+	}
+	if err := follower.root.isAVL(); err != nil {
+		t.Fatalf("Follower is not AVL: %v", err)
+	}
+
+	if err := follower.Apply(d); err != RevisionError(2) {
+		t.Fatalf("Unexpected error applying stale delta: %v", err)
+	}
+	if _, err := primary.Delta(1); err != RevisionError(1) {
+		t.Fatalf("Unexpected error getting delta before checkpoint: %v", err)
+	}
+}
+
+func TestRestoreErrors(t *testing.T) {
+	for _, s := range []Snapshot[uint64]{
+		{Intervals: []Interval[uint64]{{5, 1}}},
+		{Intervals: []Interval[uint64]{{1, 5}, {5, 6}}},
+		{Intervals: []Interval[uint64]{{10, 20}, {1, 5}}},
+	} {
+		if err := New().Restore(s); err == nil {
+			t.Fatalf("Restored invalid snapshot %+v", s)
+		}
+	}
+
+	it := New()
+	if err := it.Restore(Snapshot[uint64]{Intervals: []Interval[uint64]{{1, 5}, {6, 8}, {10, 12}}}); err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+	if it.ToString() != "[1 -- 8][10 -- 12]" {
+		t.Fatalf("Adjacent intervals were not joined: %s", it.ToString())
+	}
+}
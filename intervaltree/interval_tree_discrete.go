@@ -0,0 +1,316 @@
+package intervaltree
+
+import "sync"
+
+// DiscreteTree represents an IntervalTree over an arbitrary discrete domain of
+// values of type K, defined by a comparison function, a successor function and
+// a predecessor function. As in Tree, intervals cannot overlap and adjacent
+// intervals are joined, two intervals being adjacent when the successor of the
+// end of one of them is the start of the other.
+type DiscreteTree[K any] struct {
+	root *discreteNode[K, struct{}]
+	cmp  func(a, b K) int
+	succ func(k K) (K, bool)
+	pred func(k K) (K, bool)
+	sync.RWMutex
+}
+
+// discreteNode holds an interval [I, J], the value attached to it and pointers
+// to nodes holding intervals lesser and greater than its own.
+type discreteNode[K, V any] struct {
+	I, J        K                   // Interval bounds
+	Value       V                   // Value attached to the interval
+	Left, Right *discreteNode[K, V] // Left and right children
+	height      uint8               // Nodes on the longest path to a leaf (for AVL retracing)
+}
+
+// NewDiscrete returns a pointer to an empty DiscreteTree. cmp returns a
+// negative number, zero or a positive number when a is lesser than, equal to or
+// greater than b. succ returns the value following k, and false if k is the
+// greatest value of the domain. pred returns the value preceding k, and false if
+// k is the least value of the domain.
+func NewDiscrete[K any](cmp func(a, b K) int, succ, pred func(k K) (K, bool)) *DiscreteTree[K] {
+	return &DiscreteTree[K]{cmp: cmp, succ: succ, pred: pred}
+}
+
+// adjacent checks if b is the successor of a.
+func (t *DiscreteTree[K]) adjacent(a, b K) bool {
+	s, ok := t.succ(a)
+	return ok && t.cmp(s, b) == 0
+}
+
+// Insert adds an interval to the tree. The interval cannot overlap with the
+// tree. If prunning is possible it will be done.
+func (t *DiscreteTree[K]) Insert(x, y K) error {
+	if t.cmp(x, y) > 0 {
+		return InvalidIntervalError[K]{x, y}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	return t.insert(x, y)
+}
+
+// insert adds the valid interval [x, y] to the tree. The caller must hold the
+// write lock.
+func (t *DiscreteTree[K]) insert(x, y K) error {
+	l, r := t.root.floor(t.cmp, x), t.root.higher(t.cmp, x)
+	if l != nil && t.cmp(l.J, x) >= 0 {
+		return OverlapError[K]{x, Interval[K]{x, y}, Interval[K]{l.I, l.J}}
+	}
+	if r != nil && t.cmp(r.I, y) <= 0 {
+		return OverlapError[K]{r.I, Interval[K]{x, y}, Interval[K]{r.I, r.J}}
+	}
+
+	joinL := l != nil && t.adjacent(l.J, x)
+	joinR := r != nil && t.adjacent(y, r.I)
+	switch {
+	case joinL && joinR: // Fill the gap between l and r
+		j := r.J
+		t.root.remove(t.cmp, r.I, &t.root)
+		l.J = j
+	case joinL:
+		l.J = y
+	case joinR:
+		r.I = x
+	default:
+		t.root.insert(t.cmp, x, y, struct{}{}, &t.root)
+	}
+	return nil
+}
+
+// Remove deletes the interval [x, y] from the tree. Every value in [x, y] must
+// be contained in the tree. Stored intervals are shrunk or split as needed.
+func (t *DiscreteTree[K]) Remove(x, y K) error {
+	if t.cmp(x, y) > 0 {
+		return InvalidIntervalError[K]{x, y}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	return t.remove(x, y)
+}
+
+// remove deletes the valid interval [x, y] from the tree. The caller must hold
+// the write lock.
+func (t *DiscreteTree[K]) remove(x, y K) error {
+	// Stored intervals are never adjacent, so [x, y] must lie in a single one
+	c := t.root.floor(t.cmp, x)
+	if c == nil || t.cmp(c.J, x) < 0 {
+		return NotContainedError[K]{x}
+	}
+	if t.cmp(y, c.J) > 0 {
+		s, _ := t.succ(c.J)
+		return NotContainedError[K]{s}
+	}
+
+	fromStart, toEnd := t.cmp(x, c.I) == 0, t.cmp(y, c.J) == 0
+	switch {
+	case fromStart && toEnd:
+		t.root.remove(t.cmp, c.I, &t.root)
+	case fromStart:
+		c.I, _ = t.succ(y)
+	case toEnd:
+		c.J, _ = t.pred(x)
+	default: // Split, the upper part becomes a new node
+		j := c.J
+		c.J, _ = t.pred(x)
+		s, _ := t.succ(y)
+		t.root.insert(t.cmp, s, j, struct{}{}, &t.root)
+	}
+	return nil
+}
+
+// Intervals returns the intervals in the tree that overlap [x, y], in
+// ascending order.
+func (t *DiscreteTree[K]) Intervals(x, y K) []Interval[K] {
+	t.RLock()
+	defer t.RUnlock()
+	return t.intervals(x, y)
+}
+
+// intervals returns the intervals in the tree that overlap [x, y], in
+// ascending order. The caller must hold the lock.
+func (t *DiscreteTree[K]) intervals(x, y K) []Interval[K] {
+	var ret []Interval[K]
+	t.root.walkRange(t.cmp, x, y, func(n *discreteNode[K, struct{}]) {
+		ret = append(ret, Interval[K]{n.I, n.J})
+	})
+	return ret
+}
+
+// Contains checks if k is contained in the tree.
+func (t *DiscreteTree[K]) Contains(k K) bool {
+	t.RLock()
+	defer t.RUnlock()
+
+	c := t.root.floor(t.cmp, k)
+	return c != nil && t.cmp(k, c.J) <= 0
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to k. It returns false if every such value is contained.
+func (t *DiscreteTree[K]) Next(k K) (K, bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	c := t.root.floor(t.cmp, k)
+	if c == nil || t.cmp(k, c.J) > 0 {
+		return k, true
+	}
+	return t.succ(c.J)
+}
+
+// walkRange calls fn recursively for this node and its children if they hold
+// intervals that overlap [x, y], in ascending order.
+func (n *discreteNode[K, V]) walkRange(cmp func(a, b K) int, x, y K, fn func(n *discreteNode[K, V])) {
+	if n == nil {
+		return
+	}
+
+	if cmp(x, n.I) < 0 {
+		n.Left.walkRange(cmp, x, y, fn)
+	}
+	if cmp(x, n.J) <= 0 && cmp(n.I, y) <= 0 {
+		fn(n)
+	}
+	if cmp(y, n.J) > 0 {
+		n.Right.walkRange(cmp, x, y, fn)
+	}
+}
+
+// floor returns the node holding the greatest interval starting at or before
+// k in the subtree rooted at n, or nil if there is none.
+func (n *discreteNode[K, V]) floor(cmp func(a, b K) int, k K) *discreteNode[K, V] {
+	var ret *discreteNode[K, V]
+	for n != nil {
+		if cmp(n.I, k) <= 0 {
+			ret, n = n, n.Right
+		} else {
+			n = n.Left
+		}
+	}
+	return ret
+}
+
+// higher returns the node holding the least interval starting after k in the
+// subtree rooted at n, or nil if there is none.
+func (n *discreteNode[K, V]) higher(cmp func(a, b K) int, k K) *discreteNode[K, V] {
+	var ret *discreteNode[K, V]
+	for n != nil {
+		if cmp(n.I, k) > 0 {
+			ret, n = n, n.Left
+		} else {
+			n = n.Right
+		}
+	}
+	return ret
+}
+
+// insert adds a node holding [x, y] and v to the subtree rooted at n, which
+// holds no interval starting at x.
+func (n *discreteNode[K, V]) insert(cmp func(a, b K) int, x, y K, v V, nRef **discreteNode[K, V]) {
+	if n == nil {
+		*nRef = &discreteNode[K, V]{I: x, J: y, Value: v, height: 1}
+		return
+	}
+
+	if cmp(x, n.I) < 0 {
+		n.Left.insert(cmp, x, y, v, &n.Left)
+	} else {
+		n.Right.insert(cmp, x, y, v, &n.Right)
+	}
+	n.rebalance(nRef)
+}
+
+// remove deletes the node holding the interval starting at x from the subtree
+// rooted at n. Such a node must exist.
+func (n *discreteNode[K, V]) remove(cmp func(a, b K) int, x K, nRef **discreteNode[K, V]) {
+	if c := cmp(x, n.I); c < 0 {
+		n.Left.remove(cmp, x, &n.Left)
+	} else if c > 0 {
+		n.Right.remove(cmp, x, &n.Right)
+	} else if n.Left == nil {
+		*nRef = n.Right
+		return
+	} else if n.Right == nil {
+		*nRef = n.Left
+		return
+	} else { // Replace this interval with the next one
+		next := n.Right.removeLeast(&n.Right)
+		n.I, n.J, n.Value = next.I, next.J, next.Value
+	}
+
+	n.rebalance(nRef)
+}
+
+// removeLeast deletes the node holding the least interval from the subtree
+// rooted at n and returns it.
+func (n *discreteNode[K, V]) removeLeast(nRef **discreteNode[K, V]) *discreteNode[K, V] {
+	if n.Left == nil {
+		*nRef = n.Right
+		return n
+	}
+
+	least := n.Left.removeLeast(&n.Left)
+	n.rebalance(nRef)
+	return least
+}
+
+// rebalance fixes AVL invariants violations by applying rotations.
+func (n *discreteNode[K, V]) rebalance(nRef **discreteNode[K, V]) {
+	bal := n.balanceFactor()
+	if bal == 2 {
+		if n.Left.balanceFactor() < 0 {
+			n.Left.rotateRight(&n.Left)
+		}
+		n.rotateLeft(nRef)
+		return
+	} else if bal == -2 {
+		if n.Right.balanceFactor() > 0 {
+			n.Right.rotateLeft(&n.Right)
+		}
+		n.rotateRight(nRef)
+		return
+	}
+
+	n.updateHeight()
+}
+
+// updateHeight recalculates the height of this node from its children.
+func (n *discreteNode[K, V]) updateHeight() {
+	n.height = max(n.Left.getHeight(), n.Right.getHeight()) + 1
+}
+
+// balanceFactor calculates the balance factor for this node.
+func (n *discreteNode[K, V]) balanceFactor() int8 {
+	return int8(n.Left.getHeight() - n.Right.getHeight())
+}
+
+// getHeight returns the number of nodes in the longest path to a leaf
+func (n *discreteNode[K, V]) getHeight() uint8 {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+// rotateLeft performs a left tree rotation.
+func (n *discreteNode[K, V]) rotateLeft(nRef **discreteNode[K, V]) {
+	pivot := n.Left
+	n.Left = n.Left.Right
+	pivot.Right = n
+	*nRef = pivot
+	n.updateHeight()
+	pivot.updateHeight()
+}
+
+// rotateRight performs a right tree rotation.
+func (n *discreteNode[K, V]) rotateRight(nRef **discreteNode[K, V]) {
+	pivot := n.Right
+	n.Right = n.Right.Left
+	pivot.Left = n
+	*nRef = pivot
+	n.updateHeight()
+	pivot.updateHeight()
+}
@@ -0,0 +1,93 @@
+package intervaltree
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestDiscreteTreeStride(t *testing.T) {
+	// Block numbers are multiples of 10
+	it := NewDiscrete(cmp.Compare[int], func(k int) (int, bool) { return k + 10, true }, func(k int) (int, bool) { return k - 10, true })
+	it.Insert(100, 150)
+	it.Insert(200, 200)
+	if it.Contains(160) || !it.Contains(150) {
+		t.Fatal("Unexpected membership in stride tree")
+	}
+
+	it.Insert(160, 190)
+	if n, ok := it.Next(100); !ok || n != 210 {
+		t.Fatalf("Adjacent intervals were not joined, Next(100) = %d", n)
+	}
+	if err := it.Insert(0, 100); err != (OverlapError[int]{100, Interval[int]{0, 100}, Interval[int]{100, 200}}) {
+		t.Fatalf("Unexpected error inserting overlapping interval: %v", err)
+	}
+	if err := it.Insert(20, 10); err == nil {
+		t.Fatal("Inserted invalid interval [20, 10]")
+	}
+}
+
+func TestDiscreteTreeGreatestValue(t *testing.T) {
+	it := NewDiscrete(cmp.Compare[uint8], func(k uint8) (uint8, bool) { return k + 1, k < 255 }, func(k uint8) (uint8, bool) { return k - 1, k > 0 })
+	it.Insert(250, 255)
+	if _, ok := it.Next(252); ok {
+		t.Fatal("Next found a value after 255")
+	}
+	if n, ok := it.Next(3); !ok || n != 3 {
+		t.Fatalf("Unexpected Next(3) = %d, %v", n, ok)
+	}
+}
+
+func TestDiscreteTreeAgainstTree(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	dt := NewDiscrete(cmp.Compare[int], func(k int) (int, bool) { return k + 1, true }, func(k int) (int, bool) { return k - 1, true })
+	it := NewTree[int]()
+	for i := 0; i < 2000; i++ {
+		x := r.Intn(3000)
+		y := x + r.Intn(5)
+		if r.Intn(3) == 0 {
+			if (dt.Remove(x, y) == nil) != (it.Remove(x, y) == nil) {
+				t.Fatalf("Trees disagree removing [%d, %d]", x, y)
+			}
+		} else if (dt.Insert(x, y) == nil) != (it.Insert(x, y) == nil) {
+			t.Fatalf("Trees disagree inserting [%d, %d]", x, y)
+		}
+	}
+
+	var expected []Interval[int]
+	it.root.walkRange(100, 2000, func(i, j int) bool {
+		expected = append(expected, Interval[int]{i, j})
+		return true
+	})
+	if fmt.Sprint(dt.Intervals(100, 2000)) != fmt.Sprint(expected) {
+		t.Fatalf("Trees disagree on intervals. Got %v, expected %v", dt.Intervals(100, 2000), expected)
+	}
+	if err := dt.root.isAVL(); err != nil {
+		t.Fatalf("DiscreteTree is not AVL: %v", err)
+	}
+	for x := 0; x < 3010; x++ {
+		if dt.Contains(x) != it.Contains(x) {
+			t.Fatalf("Trees disagree on Contains(%d)", x)
+		}
+		if n, _ := dt.Next(x); n != next(it, x) {
+			t.Fatalf("Trees disagree on Next(%d)", x)
+		}
+	}
+}
+
+func (n *discreteNode[K, V]) isAVL() error {
+	if n == nil {
+		return nil
+	}
+
+	bal := n.balanceFactor()
+	if n.height != max(n.Left.getHeight(), n.Right.getHeight())+1 || bal > 1 || bal < -1 {
+		return fmt.Errorf("Node [%v, %v] is unbalanced: height %d, balance factor %d", n.I, n.J, n.height, bal)
+	}
+
+	if err := n.Left.isAVL(); err != nil {
+		return err
+	}
+	return n.Right.isAVL()
+}
@@ -0,0 +1,94 @@
+package intervaltree
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// dumpDOT writes recursively the DOT statements for this node and its
+// children. id is the identifier of this node; the identifier of the next
+// unused node is returned.
+func (n *node[T]) dumpDOT(w io.Writer, id int) (int, error) {
+	if _, err := fmt.Fprintf(w, "\tn%d [label=\"[%d, %d]\\nh=%d b=%d\"];\n", id, n.I, n.J, n.height, n.balanceFactor()); err != nil {
+		return id, err
+	}
+
+	next := id + 1
+	for _, c := range []struct {
+		child *node[T]
+		side  string
+	}{{n.Left, "L"}, {n.Right, "R"}} {
+		if c.child == nil {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "\tn%d -> n%d [label=\"%s\"];\n", id, next, c.side); err != nil {
+			return next, err
+		}
+
+		var err error
+		if next, err = c.child.dumpDOT(w, next); err != nil {
+			return next, err
+		}
+	}
+	return next, nil
+}
+
+// DumpDOT writes the structure of the tree to w as a Graphviz DOT graph. Each
+// node is labeled with its interval, its height and its balance factor.
+func (t *Tree[T]) DumpDOT(w io.Writer) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	if _, err := io.WriteString(w, "digraph IntervalTree {\n"); err != nil {
+		return err
+	}
+	if t.root != nil {
+		if _, err := t.root.dumpDOT(w, 0); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "}\n")
+	return err
+}
+
+// dumpTree writes recursively an indented rendering of this node and its
+// children to b. prefix is the indentation of the node's children.
+func (n *node[T]) dumpTree(b *strings.Builder, prefix string) {
+	fmt.Fprintf(b, "[%d, %d] h=%d b=%d\n", n.I, n.J, n.height, n.balanceFactor())
+
+	if n.Left == nil && n.Right == nil {
+		return
+	}
+	for _, c := range []struct {
+		child *node[T]
+		side  string
+		last  bool
+	}{{n.Left, "L", false}, {n.Right, "R", true}} {
+		branch, indent := "|-- ", "|   "
+		if c.last {
+			branch, indent = "`-- ", "    "
+		}
+		b.WriteString(prefix + branch + c.side + " ")
+		if c.child == nil {
+			b.WriteString("-\n")
+			continue
+		}
+		c.child.dumpTree(b, prefix+indent)
+	}
+}
+
+// DumpTree returns an indented ASCII rendering of the structure of the tree.
+// Each node is shown with its interval, its height and its balance factor.
+func (t *Tree[T]) DumpTree() string {
+	t.RLock()
+	defer t.RUnlock()
+
+	if t.root == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	t.root.dumpTree(&b, "")
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package intervaltree
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDumpDOT(t *testing.T) {
+	it := New()
+	var b bytes.Buffer
+	if err := it.DumpDOT(&b); err != nil {
+		t.Fatalf("DumpDOT failed on empty tree: %v", err)
+	}
+	if b.String() != "digraph IntervalTree {\n}\n" {
+		t.Fatalf("Unexpected DOT output for empty tree: %q", b.String())
+	}
+
+	it.Insert(10, 20)
+	it.Insert(0, 5)
+	it.Insert(30, 40)
+	b.Reset()
+	if err := it.DumpDOT(&b); err != nil {
+		t.Fatalf("DumpDOT failed: %v", err)
+	}
+
+	out := b.String()
+	for _, s := range []string{
+		`n0 [label="[10, 20]\nh=2 b=0"];`,
+		`n0 -> n1 [label="L"];`,
+		`n1 [label="[0, 5]\nh=1 b=0"];`,
+		`n0 -> n2 [label="R"];`,
+		`n2 [label="[30, 40]\nh=1 b=0"];`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Fatalf("DOT output does not contain %q:\n%s", s, out)
+		}
+	}
+}
+
+func TestDumpTree(t *testing.T) {
+	it := New()
+	if s := it.DumpTree(); s != "" {
+		t.Fatalf("Unexpected dump for empty tree: %q", s)
+	}
+
+	it.Insert(10, 20)
+	it.Insert(0, 5)
+	it.Insert(30, 40)
+	it.Insert(50, 60)
+	expected := "[10, 20] h=3 b=-1\n" +
+		"|-- L [0, 5] h=1 b=0\n" +
+		"`-- R [30, 40] h=2 b=-1\n" +
+		"    |-- L -\n" +
+		"    `-- R [50, 60] h=1 b=0\n"
+	if s := it.DumpTree(); s != expected {
+		t.Fatalf("Unexpected dump. Got:\n%s\nExpected:\n%s", s, expected)
+	}
+}
@@ -0,0 +1,18 @@
+package intervaltree
+
+// Enclosing returns the interval in the tree holding every value in [x, y], and
+// whether there is one. Since intervals are joined when adjacent, it is the
+// interval containing x, provided it reaches y.
+func (t *Tree[T]) Enclosing(x, y T) (Interval[T], bool) {
+	if x > y {
+		return Interval[T]{}, false
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+	t.counters.inc(lookups)
+	if c := t.root.containingNode(x); c != nil && y <= c.J {
+		return Interval[T]{c.I, c.J}, true
+	}
+	return Interval[T]{}, false
+}
@@ -0,0 +1,28 @@
+package intervaltree
+
+import "testing"
+
+func TestEnclosing(t *testing.T) {
+	it := NewTree[uint16]()
+	it.Insert(10, 20)
+	it.Insert(21, 30) // Joined with [10, 20]
+	it.Insert(40, 50)
+
+	for _, c := range []struct {
+		x, y     uint16
+		expected Interval[uint16]
+		ok       bool
+	}{
+		{10, 30, Interval[uint16]{10, 30}, true},
+		{15, 25, Interval[uint16]{10, 30}, true},
+		{45, 45, Interval[uint16]{40, 50}, true},
+		{25, 45, Interval[uint16]{}, false},
+		{5, 15, Interval[uint16]{}, false},
+		{31, 39, Interval[uint16]{}, false},
+		{20, 15, Interval[uint16]{}, false},
+	} {
+		if i, ok := it.Enclosing(c.x, c.y); i != c.expected || ok != c.ok {
+			t.Fatalf("Enclosing(%d, %d) = %v %v", c.x, c.y, i, ok)
+		}
+	}
+}
@@ -1,22 +1,120 @@
 package intervaltree
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors wrapped by the errors of this package, so callers can tell
+// them apart with errors.Is regardless of the type of the values.
+var (
+	ErrOverlap         = errors.New("Interval overlaps the tree")
+	ErrInvalidInterval = errors.New("Invalid interval")
+	ErrNotFound        = errors.New("Value not contained in the tree")
+)
 
 // OverlapError is returned whenever an Insert() call tries to insert a value
-// previously inserted.
-type OverlapError uint64
+// previously inserted. It holds the interval that was attempted and an
+// interval already inserted that it collided with, so callers can work out
+// what is still free.
+type OverlapError[T any] struct {
+	Value     T           // Value already inserted
+	Attempted Interval[T] // Interval that was attempted
+	Existing  Interval[T] // Interval already inserted holding Value
+}
+
+func (e OverlapError[T]) Error() string {
+	return fmt.Sprintf("Tried to insert value already inserted: %v", e.Value)
+}
+
+// Unwrap returns ErrOverlap.
+func (e OverlapError[T]) Unwrap() error {
+	return ErrOverlap
+}
+
+// Intervals returns the interval that was attempted and the one it collided
+// with.
+func (e OverlapError[T]) Intervals() (attempted, existing Interval[T]) {
+	return e.Attempted, e.Existing
+}
 
-func (e OverlapError) Error() string {
-	return fmt.Sprintf("Tried to insert value already inserted: %d", uint64(e))
+// Is reports whether target is an OverlapError of the same type, whatever its
+// fields, so errors.Is(err, OverlapError[T]{}) tells if err is any overlap.
+// Fields are not compared, as T may not be comparable.
+func (e OverlapError[T]) Is(target error) bool {
+	_, ok := target.(OverlapError[T])
+	return ok
+}
+
+// PartialOverlapError is returned instead of an OverlapError by trees created
+// with WithRemainders. It wraps the OverlapError, so errors.As and errors.Is
+// treat it as one.
+type PartialOverlapError[T any] struct {
+	OverlapError[T]
+	Free []Interval[T] // Maximal parts of the attempted interval not contained, in ascending order
+}
+
+func (e PartialOverlapError[T]) Error() string {
+	return fmt.Sprintf("%v, %d free parts remain", e.OverlapError, len(e.Free))
+}
+
+// Unwrap returns the OverlapError.
+func (e PartialOverlapError[T]) Unwrap() error {
+	return e.OverlapError
 }
 
 // InvalidIntervalError is returned whenever an Insert() call tries to insert a
 // interval [x, y] where x > y.
-type InvalidIntervalError struct {
-	x uint64
-	y uint64
+type InvalidIntervalError[T any] struct {
+	X T
+	Y T
+}
+
+func (e InvalidIntervalError[T]) Error() string {
+	return fmt.Sprintf("Invalid interval: [%v, %v]", e.X, e.Y)
 }
 
-func (e InvalidIntervalError) Error() string {
-	return fmt.Sprintf("Invalid interval: [%d, %d]", e.x, e.y)
+// Unwrap returns ErrInvalidInterval.
+func (e InvalidIntervalError[T]) Unwrap() error {
+	return ErrInvalidInterval
+}
+
+// NotContainedError is returned whenever a Remove() call tries to remove a
+// value not contained in the tree.
+type NotContainedError[T any] struct {
+	Value T // Value not contained
+}
+
+func (e NotContainedError[T]) Error() string {
+	return fmt.Sprintf("Tried to remove value not contained: %v", e.Value)
+}
+
+// Unwrap returns ErrNotFound.
+func (e NotContainedError[T]) Unwrap() error {
+	return ErrNotFound
+}
+
+// BatchError is returned, joined with errors.Join, for every item of a batch
+// operation that failed, identifying the item by its index in the batch.
+type BatchError struct {
+	Index int   // Index of the item in the batch
+	Err   error // Cause of the failure
+}
+
+func (e BatchError) Error() string {
+	return fmt.Sprintf("Item %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the cause of the failure.
+func (e BatchError) Unwrap() error {
+	return e.Err
+}
+
+// RevisionError is returned whenever a Delta() call asks for changes since a
+// revision that was not journaled, or an Apply() call receives a delta that
+// does not start at the revision of the tree.
+type RevisionError uint64
+
+func (e RevisionError) Error() string {
+	return fmt.Sprintf("Revision not available: %d", uint64(e))
 }
@@ -0,0 +1,54 @@
+package intervaltree
+
+// finger caches the interval found by the last lookup of a tree and the gap
+// following it, so lookups of nearby values skip the descent. It is only
+// valid for the root and the revision it was taken at.
+type finger[T Integer] struct {
+	root     *node[T]
+	rev      uint64
+	from, to T    // Values described by the finger
+	j        T    // Last value contained, if contains
+	contains bool // Whether [from, j] is an interval of the tree
+}
+
+// WithFinger makes Contains and Next remember the interval and the gap they
+// last landed in and check them before descending the tree, so sequential
+// probes take constant time for all but one value of every interval and
+// gap. The finger is shared by every reader, so it pays off for workloads
+// with locality and slows scattered concurrent lookups down.
+func WithFinger() Option {
+	return func(c *config) {
+		c.finger = true
+	}
+}
+
+// lookup returns the part of the finger of the tree holding x, or a new one
+// if x is not in it. The tree must have been created with WithFinger, and the
+// caller must hold the lock.
+func (t *Tree[T]) lookup(x T) *finger[T] {
+	f := t.finger.Load()
+	if f != nil && f.root == t.root && f.rev == t.rev && f.from <= x && x <= f.to {
+		return f
+	}
+
+	lo, hi := Limits[T]()
+	f = &finger[T]{root: t.root, rev: t.rev, from: lo, to: hi}
+	for n := t.root; n != nil; {
+		if x < n.I {
+			f.to, n = n.I-1, n.Left
+		} else {
+			f.from, f.j, f.contains, n = n.I, n.J, true, n.Right
+		}
+	}
+	if f.contains && x > f.j { // x is in the gap after the interval
+		f.from, f.contains = f.j+1, false
+	}
+	t.finger.Store(f)
+	return f
+}
+
+// holds checks if x, which must be described by the finger, is contained in
+// the tree.
+func (f *finger[T]) holds(x T) bool {
+	return f.contains && x <= f.j
+}
@@ -0,0 +1,71 @@
+package intervaltree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestFinger(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	plain, fingered := NewTree[int8](), NewTree[int8](WithFinger())
+	for i := 0; i < 2000; i++ {
+		x := int8(r.Intn(256) - 128)
+		y := x + int8(r.Intn(128-int(x))/16)
+		if r.Intn(2) == 0 {
+			plain.Insert(x, y)
+			fingered.Insert(x, y)
+		} else {
+			plain.Remove(x, y)
+			fingered.Remove(x, y)
+		}
+
+		// Sequential and random probes must agree with a plain descent
+		for k := 0; k < 8; k++ {
+			x := int8(r.Intn(256) - 128)
+			if k%2 == 0 {
+				x = int8(i + k)
+			}
+			if plain.Contains(x) != fingered.Contains(x) {
+				t.Fatalf("Operation %d: Contains(%d) differs", i, x)
+			}
+			n1, ok1 := plain.Next(x)
+			n2, ok2 := fingered.Next(x)
+			if n1 != n2 || ok1 != ok2 {
+				t.Fatalf("Operation %d: Next(%d) = %d, %v, expected %d, %v", i, x, n2, ok2, n1, ok1)
+			}
+		}
+	}
+
+	// Replacing the contents without changing the revision drops the finger
+	fingered.Restore(Snapshot[int8]{Intervals: []Interval[int8]{{0, 10}}})
+	fingered.Contains(5)
+	fingered.Restore(Snapshot[int8]{Intervals: []Interval[int8]{{20, 30}}})
+	if fingered.Contains(5) {
+		t.Fatal("Finger outlived the contents it was taken from")
+	}
+	if n, ok := fingered.Next(127); !ok || n != 127 {
+		t.Fatalf("Unexpected Next(127): %d, %v", n, ok)
+	}
+	fingered.Insert(100, 127)
+	if _, ok := fingered.Next(127); ok {
+		t.Fatal("Next(127) found a value past the greatest one")
+	}
+}
+
+func BenchmarkFingerSequential(b *testing.B) {
+	for _, opts := range [][]Option{nil, {WithFinger()}} {
+		tree := NewTree[uint64](opts...)
+		for k := uint64(0); k < 1<<16; k++ {
+			tree.Insert(k*64, k*64+31)
+		}
+		name := "Descent"
+		if opts != nil {
+			name = "Finger"
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				tree.Contains(uint64(i) % (1 << 22))
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package intervaltree
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+)
+
+// Bounds tells which endpoints of a FloatInterval are included in it.
+type Bounds uint8
+
+// Possible Bounds of a FloatInterval.
+const (
+	Closed    Bounds = iota // [Lo, Hi]
+	LeftOpen                // (Lo, Hi]
+	RightOpen               // [Lo, Hi)
+	Open                    // (Lo, Hi)
+)
+
+// FloatInterval represents an interval of float64 values.
+type FloatInterval struct {
+	Lo, Hi float64
+	Bounds Bounds
+}
+
+func (i FloatInterval) String() string {
+	l, r := "[", "]"
+	if i.Bounds == LeftOpen || i.Bounds == Open {
+		l = "("
+	}
+	if i.Bounds == RightOpen || i.Bounds == Open {
+		r = ")"
+	}
+	return fmt.Sprintf("%s%v, %v%s", l, i.Lo, i.Hi, r)
+}
+
+// FloatTree represents a set of float64 values built from intervals. Unlike in
+// Tree, values are not discrete: intervals are joined only when they overlap or
+// touch, as [1, 2) and [2, 3] do, while (1, 2) and (2, 3) are kept apart.
+// Inserting an interval overlapping the tree is not an error. It is safe for
+// concurrent use through the lock of its DiscreteTree.
+type FloatTree struct {
+	t *DiscreteTree[point]
+}
+
+// point represents a position on the real line: either a value, or the
+// position just before or just after it. Open endpoints are stored as the
+// position next to their value, so touching intervals hold adjacent points.
+type point struct {
+	v    float64
+	side int8 // -1 just before v, 0 at v, 1 just after v
+}
+
+// comparePoints orders points along the real line.
+func comparePoints(a, b point) int {
+	if c := cmp.Compare(a.v, b.v); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.side, b.side)
+}
+
+// nextPoint returns the point following p. Only positions at a value and just
+// before it have one, as nothing is adjacent to the position just after it.
+func nextPoint(p point) (point, bool) {
+	return point{p.v, p.side + 1}, p.side < 1
+}
+
+// prevPoint returns the point preceding p. Only positions at a value and just
+// after it have one, as nothing is adjacent to the position just before it.
+func prevPoint(p point) (point, bool) {
+	return point{p.v, p.side - 1}, p.side > -1
+}
+
+// NewFloat returns a pointer to an empty FloatTree.
+func NewFloat() *FloatTree {
+	return &FloatTree{t: NewDiscrete(comparePoints, nextPoint, prevPoint)}
+}
+
+// points returns the first and the last points in i.
+func (i FloatInterval) points() (point, point) {
+	lo, hi := point{i.Lo, 0}, point{i.Hi, 0}
+	if i.Bounds == LeftOpen || i.Bounds == Open {
+		lo.side = 1
+	}
+	if i.Bounds == RightOpen || i.Bounds == Open {
+		hi.side = -1
+	}
+	return lo, hi
+}
+
+// floatInterval returns the interval holding the points in [lo, hi].
+func floatInterval(lo, hi point) FloatInterval {
+	i := FloatInterval{Lo: lo.v, Hi: hi.v}
+	switch {
+	case lo.side > 0 && hi.side < 0:
+		i.Bounds = Open
+	case lo.side > 0:
+		i.Bounds = LeftOpen
+	case hi.side < 0:
+		i.Bounds = RightOpen
+	}
+	return i
+}
+
+// Insert adds i to the tree, joining it with every interval in the tree it
+// overlaps or touches. i cannot be empty nor have NaN endpoints.
+func (t *FloatTree) Insert(i FloatInterval) error {
+	lo, hi := i.points()
+	if math.IsNaN(i.Lo) || math.IsNaN(i.Hi) || i.Bounds > Open || comparePoints(lo, hi) > 0 {
+		return InvalidIntervalError[float64]{i.Lo, i.Hi}
+	}
+
+	t.t.Lock()
+	defer t.t.Unlock()
+
+	// Widen the query by one point on each side to find touching intervals
+	x, ok := prevPoint(lo)
+	if !ok {
+		x = lo
+	}
+	y, ok := nextPoint(hi)
+	if !ok {
+		y = hi
+	}
+
+	joined := t.t.intervals(x, y)
+	for _, j := range joined {
+		t.t.remove(j.I, j.J)
+	}
+	if len(joined) > 0 {
+		if first := joined[0].I; comparePoints(first, lo) < 0 {
+			lo = first
+		}
+		if last := joined[len(joined)-1].J; comparePoints(last, hi) > 0 {
+			hi = last
+		}
+	}
+	return t.t.insert(lo, hi)
+}
+
+// Contains checks if x is contained in the tree.
+func (t *FloatTree) Contains(x float64) bool {
+	return t.t.Contains(point{x, 0})
+}
+
+// Intervals returns the intervals in the tree in ascending order.
+func (t *FloatTree) Intervals() []FloatInterval {
+	var ret []FloatInterval
+	all := t.t.Intervals(point{math.Inf(-1), -1}, point{math.Inf(1), 1})
+	for _, i := range all {
+		ret = append(ret, floatInterval(i.I, i.J))
+	}
+	return ret
+}
@@ -0,0 +1,71 @@
+package intervaltree
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestFloatTree(t *testing.T) {
+	ft := NewFloat()
+	for _, c := range []struct {
+		i        FloatInterval
+		expected string
+	}{
+		{FloatInterval{1, 2, RightOpen}, "[[1, 2)]"},
+		{FloatInterval{2, 3, Open}, "[[1, 2) (2, 3)]"},
+		{FloatInterval{3, 4, Closed}, "[[1, 2) (2, 4]]"},
+		{FloatInterval{0.5, 1.5, LeftOpen}, "[(0.5, 2) (2, 4]]"},
+		{FloatInterval{5, 6, Closed}, "[(0.5, 2) (2, 4] [5, 6]]"},
+		{FloatInterval{2, 5, Closed}, "[(0.5, 6]]"},
+		{FloatInterval{math.Inf(-1), 0, Open}, "[(-Inf, 0) (0.5, 6]]"},
+	} {
+		if err := ft.Insert(c.i); err != nil {
+			t.Fatalf("Failed to insert %v: %v", c.i, err)
+		}
+		if s := fmt.Sprint(ft.Intervals()); s != c.expected {
+			t.Fatalf("Unexpected tree after inserting %v. Got %s, expected %s", c.i, s, c.expected)
+		}
+	}
+
+	for x, expected := range map[float64]bool{
+		-1e300: true, 0: false, 0.5: false, 0.50001: true, 6: true, 6.00001: false,
+	} {
+		if ft.Contains(x) != expected {
+			t.Fatalf("Contains(%v) is not %v", x, expected)
+		}
+	}
+
+	for _, i := range []FloatInterval{
+		{2, 1, Closed},
+		{1, 1, RightOpen},
+		{1, 1, Open},
+		{math.NaN(), 1, Closed},
+		{1, 2, Bounds(9)},
+	} {
+		if err := ft.Insert(i); err == nil {
+			t.Fatalf("Inserted invalid interval %v", i)
+		}
+	}
+	if err := ft.Insert(FloatInterval{7, 7, Closed}); err != nil || !ft.Contains(7) {
+		t.Fatalf("Failed to insert degenerate interval [7, 7]: %v", err)
+	}
+}
+
+func TestFloatTreeConcurrent(t *testing.T) {
+	ft := NewFloat()
+	var wg sync.WaitGroup
+	for k := 0; k < 100; k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ft.Insert(FloatInterval{float64(k), float64(k + 1), RightOpen})
+		}()
+	}
+	wg.Wait()
+
+	if all := ft.Intervals(); len(all) != 1 || all[0] != (FloatInterval{0, 100, RightOpen}) {
+		t.Fatalf("Touching intervals were not joined: %v", all)
+	}
+}
@@ -0,0 +1,65 @@
+package intervaltree
+
+import "sort"
+
+// FrozenTree represents a read-only copy of a Tree, holding its intervals in a
+// sorted array that is searched with binary search. It is meant for sets that
+// are built once and queried many times, and since it cannot change it needs
+// no locking.
+type FrozenTree[T Integer] struct {
+	intervals []Interval[T] // Intervals in ascending order
+}
+
+// Freeze returns a FrozenTree holding the intervals currently in the tree. The
+// tree can still be changed afterwards without affecting it.
+func (t *Tree[T]) Freeze() *FrozenTree[T] {
+	t.RLock()
+	defer t.RUnlock()
+
+	var intervals []Interval[T]
+	t.root.walk(func(x, y T) bool {
+		intervals = append(intervals, Interval[T]{x, y})
+		return true
+	})
+	return &FrozenTree[T]{intervals[:len(intervals):len(intervals)]}
+}
+
+// containing returns the index of the interval that contains x, or -1 if x is
+// not contained.
+func (f *FrozenTree[T]) containing(x T) int {
+	k := sort.Search(len(f.intervals), func(k int) bool { return f.intervals[k].J >= x })
+	if k == len(f.intervals) || f.intervals[k].I > x {
+		return -1
+	}
+	return k
+}
+
+// Contains checks if x is contained in the tree.
+func (f *FrozenTree[T]) Contains(x T) bool {
+	return f.containing(x) >= 0
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to x. It returns false if every such value is contained.
+func (f *FrozenTree[T]) Next(x T) (T, bool) {
+	k := f.containing(x)
+	if k < 0 {
+		return x, true
+	}
+	return after(f.intervals[k].J)
+}
+
+// Len returns the number of intervals in the tree.
+func (f *FrozenTree[T]) Len() int {
+	return len(f.intervals)
+}
+
+// Walk calls fn for the intervals in the tree in ascending order. It stops as
+// soon as fn returns false.
+func (f *FrozenTree[T]) Walk(fn func(x, y T) bool) {
+	for _, i := range f.intervals {
+		if !fn(i.I, i.J) {
+			return
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package intervaltree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	it := New()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		x := uint64(r.Intn(10000))
+		it.Insert(x, x+uint64(r.Intn(20)))
+	}
+
+	f := it.Freeze()
+	for x := uint64(0); x < 10100; x++ {
+		if f.Contains(x) != it.Contains(x) || !sameNext(f, it, x) {
+			t.Fatalf("Frozen tree differs from tree at %d", x)
+		}
+	}
+
+	var walked []Interval[uint64]
+	f.Walk(func(x, y uint64) bool {
+		walked = append(walked, Interval[uint64]{x, y})
+		return true
+	})
+	if len(walked) != f.Len() || len(walked) != len(it.Checkpoint().Intervals) {
+		t.Fatalf("Walked %d intervals, expected %d", len(walked), f.Len())
+	}
+	for k := 1; k < len(walked); k++ {
+		if walked[k].I <= walked[k-1].J+1 {
+			t.Fatalf("Intervals are not ascending: %v %v", walked[k-1], walked[k])
+		}
+	}
+
+	it.Insert(20000, 20000)
+	if f.Contains(20000) {
+		t.Fatal("Frozen tree changed with the tree")
+	}
+}
+
+func TestFreezeEmpty(t *testing.T) {
+	f := New().Freeze()
+	if f.Contains(0) || next(f, 7) != 7 || f.Len() != 0 {
+		t.Fatal("Unexpected contents in frozen empty tree")
+	}
+}
+
+func BenchmarkFrozenContains(b *testing.B) {
+	f := benchmarkTree(1 << 16).Freeze()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Contains(uint64(i*7919) % (10 << 16))
+	}
+}
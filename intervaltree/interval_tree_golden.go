@@ -0,0 +1,94 @@
+package intervaltree
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GoldenForm selects what a golden dump of a tree records.
+type GoldenForm int
+
+// Golden dump forms.
+const (
+	// GoldenCanonical records the values held by the tree, one interval per
+	// line, so it only changes when they do, whatever the shape of the tree.
+	GoldenCanonical GoldenForm = iota
+	// GoldenStructural also records the shape of the tree, as DumpTree does,
+	// for tests pinning down the balancing behavior.
+	GoldenStructural
+)
+
+// goldenVersion is the version of the format of golden dumps. It is bumped
+// whenever the format changes, so stale golden files fail loudly.
+const goldenVersion = 1
+
+// Golden returns a deterministic dump of the tree in the given form, meant to
+// be checked into golden files and compared with CompareGolden. It starts
+// with a header holding the version of the format, the type of the values,
+// the number of intervals and the number of values held, 0 standing for every
+// value of a 64-bit T.
+func (t *Tree[T]) Golden(form GoldenForm) string {
+	t.RLock()
+	defer t.RUnlock()
+
+	var b strings.Builder
+	var zero T
+	fmt.Fprintf(&b, "# intervaltree golden v%d\n", goldenVersion)
+	fmt.Fprintf(&b, "# type %T\n", zero)
+	fmt.Fprintf(&b, "# intervals %d\n", t.root.getIntervals())
+	fmt.Fprintf(&b, "# values %d\n", t.root.getCovered())
+	switch {
+	case form == GoldenStructural && t.root != nil:
+		t.root.dumpTree(&b, "")
+	case form != GoldenStructural:
+		t.root.walk(func(i, j T) bool {
+			fmt.Fprintf(&b, "[%d, %d]\n", i, j)
+			return true
+		})
+	}
+	return b.String()
+}
+
+// GoldenMismatchError is returned by CompareGolden with the first line, counted
+// from 1, in which two golden dumps differ. Got or Want is empty if the line
+// is past the end of the corresponding dump.
+type GoldenMismatchError struct {
+	Line      int
+	Got, Want string
+}
+
+func (e GoldenMismatchError) Error() string {
+	return fmt.Sprintf("Golden dump differs at line %d: got %q, want %q", e.Line, e.Got, e.Want)
+}
+
+// CompareGolden compares the golden dump got with want, such as the contents
+// of a golden file, and returns a GoldenMismatchError with the first line in
+// which they differ, or nil if they are equal. Line endings are normalized, so
+// golden files checked out with CRLF line endings still compare equal.
+func CompareGolden(got, want string) error {
+	g := goldenLines(got)
+	w := goldenLines(want)
+	for i := 0; i < len(g) || i < len(w); i++ {
+		var gl, wl string
+		if i < len(g) {
+			gl = g[i]
+		}
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i >= len(g) || i >= len(w) || gl != wl {
+			return GoldenMismatchError{Line: i + 1, Got: gl, Want: wl}
+		}
+	}
+	return nil
+}
+
+// goldenLines splits a golden dump in lines, without a trailing empty one.
+func goldenLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,58 @@
+package intervaltree
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGolden(t *testing.T) {
+	it := NewTree[int16]()
+	it.Insert(10, 20)
+	it.Insert(-5, 0)
+	it.Insert(30, 40)
+
+	canonical := "# intervaltree golden v1\n# type int16\n# intervals 3\n# values 28\n[-5, 0]\n[10, 20]\n[30, 40]\n"
+	if g := it.Golden(GoldenCanonical); g != canonical {
+		t.Fatalf("Unexpected canonical dump:\n%s", g)
+	}
+	structural := "# intervaltree golden v1\n# type int16\n# intervals 3\n# values 28\n" + it.DumpTree()
+	if g := it.Golden(GoldenStructural); g != structural {
+		t.Fatalf("Unexpected structural dump:\n%s", g)
+	}
+
+	// The canonical dump does not depend on the shape of the tree
+	other := NewTree[int16]()
+	other.Insert(30, 40)
+	other.Insert(-5, 0)
+	other.Insert(10, 15)
+	other.Insert(16, 20)
+	if err := CompareGolden(other.Golden(GoldenCanonical), canonical); err != nil {
+		t.Fatal(err)
+	}
+
+	empty := "# intervaltree golden v1\n# type uint64\n# intervals 0\n# values 0\n"
+	if g := New().Golden(GoldenStructural); g != empty {
+		t.Fatalf("Unexpected dump of empty tree:\n%s", g)
+	}
+}
+
+func TestCompareGolden(t *testing.T) {
+	if err := CompareGolden("a\nb\n", "a\r\nb"); err != nil {
+		t.Fatalf("Equal dumps reported different: %v", err)
+	}
+
+	for _, c := range []struct {
+		got, want string
+		expected  GoldenMismatchError
+	}{
+		{"a\nb\n", "a\nc\n", GoldenMismatchError{2, "b", "c"}},
+		{"a\n", "a\nb\n", GoldenMismatchError{2, "", "b"}},
+		{"a\nb\n", "a\n", GoldenMismatchError{2, "b", ""}},
+		{"", "a\n", GoldenMismatchError{1, "", "a"}},
+	} {
+		var e GoldenMismatchError
+		if err := CompareGolden(c.got, c.want); !errors.As(err, &e) || e != c.expected {
+			t.Fatalf("Unexpected error comparing %q with %q: %v", c.got, c.want, err)
+		}
+	}
+}
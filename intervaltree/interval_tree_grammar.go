@@ -0,0 +1,113 @@
+package intervaltree
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Grammar describes the syntax of a list of ranges, such as the port
+// specifications of nmap ("22,80,1000-") or the page ranges of a printer
+// dialog ("1-5, 8, 11-"). Empty fields take their defaults.
+type Grammar struct {
+	Separators string // Characters separating ranges, "," by default. Spaces around ranges are ignored
+	Dash       string // Text between the bounds of a range, "-" by default
+	Step       string // Text before the step of a range, as "/" in "1-9/2", or none to disallow steps
+	OpenEnded  bool   // Whether a missing bound, as in "1000-" or "-5", stands for the greatest or least value
+}
+
+// Predefined grammars.
+var (
+	PortGrammar = Grammar{OpenEnded: true}                              // As nmap port specifications: "22,80,1000-2000,60000-"
+	PageGrammar = Grammar{Separators: ", ", OpenEnded: true}            // As printer page ranges: "1-5, 8, 11-"
+	StepGrammar = Grammar{Separators: ", ", Step: "/", OpenEnded: true} // As crontab fields: "0-30/5, 45"
+)
+
+// ParseRanges parses the list of ranges in s following g and returns a pointer
+// to a Tree configured by opts holding their union, as BuildTree does. Ranges
+// with a step hold only the values a multiple of the step past their first
+// one, each held by an interval of its own until the tree is built, so their
+// length should be bounded by the caller. A missing bound stands for the least
+// or greatest value of T, and takes precedence over a sign if the dash is "-".
+// Empty ranges are skipped.
+func ParseRanges[T Integer](s string, g Grammar, opts ...Option) (*Tree[T], error) {
+	seps := g.Separators
+	if seps == "" {
+		seps = ","
+	}
+
+	var intervals []Interval[T]
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return strings.ContainsRune(seps, r) }) {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		parsed, err := parseRange(g, field, intervals)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid range %q: %w", field, err)
+		}
+		intervals = parsed
+	}
+	return BuildTree(intervals, 1, opts...)
+}
+
+// parseRange parses the range in field following g and appends its intervals
+// to intervals.
+func parseRange[T Integer](g Grammar, field string, intervals []Interval[T]) ([]Interval[T], error) {
+	dash := g.Dash
+	if dash == "" {
+		dash = "-"
+	}
+
+	var step uint64 = 1
+	if k := strings.LastIndex(field, g.Step); g.Step != "" && k >= 0 {
+		var err error
+		if step, err = parseValue[uint64](strings.TrimSpace(field[k+len(g.Step):])); err != nil || step == 0 {
+			return intervals, fmt.Errorf("invalid step")
+		}
+		field = strings.TrimSpace(field[:k])
+	}
+
+	// A leading sign is not a dash, unless the bound is missing
+	least, greatest := Limits[T]()
+	start := 0
+	if strings.HasPrefix(field, "-") || strings.HasPrefix(field, "+") {
+		start = 1
+	}
+	if g.OpenEnded && strings.HasPrefix(field, dash) {
+		start = 0
+	}
+	k := strings.Index(field[start:], dash)
+	if k < 0 {
+		x, err := parseValue[T](field)
+		return append(intervals, Interval[T]{x, x}), err
+	}
+	lo, hi := strings.TrimSpace(field[:start+k]), strings.TrimSpace(field[start+k+len(dash):])
+	if (lo == "" || hi == "") && !g.OpenEnded {
+		return intervals, fmt.Errorf("missing bound")
+	}
+
+	x, y := least, greatest
+	var err error
+	if lo != "" {
+		if x, err = parseValue[T](lo); err != nil {
+			return intervals, err
+		}
+	}
+	if hi != "" {
+		if y, err = parseValue[T](hi); err != nil {
+			return intervals, err
+		}
+	}
+	if x > y {
+		return intervals, InvalidIntervalError[T]{x, y}
+	}
+
+	if step == 1 {
+		return append(intervals, Interval[T]{x, y}), nil
+	}
+	for v := ordinal(x); v <= ordinal(y) && v >= ordinal(x); v += step {
+		p, _ := fromOrdinal[T](v)
+		intervals = append(intervals, Interval[T]{p, p})
+	}
+	return intervals, nil
+}
@@ -0,0 +1,49 @@
+package intervaltree
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseRanges(t *testing.T) {
+	for _, c := range []struct {
+		s        string
+		g        Grammar
+		expected []Interval[int16]
+	}{
+		{"22,80,1000-2000,30000-", PortGrammar, []Interval[int16]{{22, 22}, {80, 80}, {1000, 2000}, {30000, 32767}}},
+		{"-5, 8 11-12,10", PageGrammar, []Interval[int16]{{-32768, 5}, {8, 8}, {10, 12}}},
+		{"0-30/10, 45, 50-/10000", StepGrammar, []Interval[int16]{{0, 0}, {10, 10}, {20, 20}, {30, 30}, {45, 45}, {50, 50}, {10050, 10050}, {20050, 20050}, {30050, 30050}}},
+		{"-10..-5; 3 ; ..-20000", Grammar{Separators: ";", Dash: "..", OpenEnded: true}, []Interval[int16]{{-32768, -20000}, {-10, -5}, {3, 3}}},
+		{"-10--5,-3", Grammar{}, []Interval[int16]{{-10, -5}, {-3, -3}}},
+		{"", Grammar{}, nil},
+	} {
+		it, err := ParseRanges[int16](c.s, c.g)
+		if err != nil {
+			t.Fatalf("Parsing %q: %v", c.s, err)
+		}
+		expected, _ := BuildTree(c.expected, 1)
+		if it.ToString() != expected.ToString() {
+			t.Fatalf("Parsing %q: unexpected tree %s", c.s, it.ToString())
+		}
+	}
+
+	for _, c := range []struct {
+		s string
+		g Grammar
+	}{
+		{"1000-", Grammar{}},
+		{"10-5", PortGrammar},
+		{"1-x", PortGrammar},
+		{"1-9/0", StepGrammar},
+		{"1-9/", StepGrammar},
+		{"40000", PortGrammar},
+	} {
+		if _, err := ParseRanges[int16](c.s, c.g); err == nil {
+			t.Fatalf("Parsed %q", c.s)
+		}
+	}
+	if _, err := ParseRanges[uint16]("1-100", PortGrammar, WithCapacity(10)); !errors.As(err, new(CapacityError)) {
+		t.Fatalf("Expected a capacity error, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package intervaltree
+
+import "sync"
+
+// groupLimit is the greatest number of insertions a leader applies before
+// handing the lead to a waiting caller, so no caller waits for long.
+const groupLimit = 1024
+
+// groupCommit merges concurrent insertions into passes under a single lock
+// acquisition. The first caller to find no pass running leads one, applying
+// its insertion and those of the callers queued meanwhile, who just wait for
+// their results.
+type groupCommit[T Integer] struct {
+	mu      sync.Mutex
+	pending []*groupWrite[T]
+	leading bool // Whether a caller is leading a pass
+}
+
+// groupWrite is an insertion waiting for a pass.
+type groupWrite[T Integer] struct {
+	x, y T
+	err  error
+	done chan bool // Receives whether the caller must lead the next pass
+}
+
+// WithGroupCommit makes concurrent callers of Insert merge their insertions:
+// while one of them holds the lock, the others queue theirs, and it applies
+// them all before releasing it, as databases group the commits of concurrent
+// transactions. This saves a lock handoff per insertion under heavy write
+// contention, at the cost of a small allocation per insertion.
+func WithGroupCommit() Option {
+	return func(c *config) {
+		c.grouped = true
+	}
+}
+
+// insert adds the valid interval [x, y] to t in a pass, leading it if none is
+// running, and returns the result of the insertion.
+func (g *groupCommit[T]) insert(t *Tree[T], x, y T) error {
+	w := &groupWrite[T]{x: x, y: y, done: make(chan bool, 1)}
+	g.mu.Lock()
+	g.pending = append(g.pending, w)
+	lead := !g.leading
+	g.leading = true
+	g.mu.Unlock()
+
+	if !lead {
+		lead = <-w.done
+	}
+	if lead { // The insertion is the first pending one, so the pass applies it
+		g.lead(t)
+		<-w.done
+	}
+	return w.err
+}
+
+// lead applies the pending insertions under a single lock acquisition, until
+// none is left or groupLimit were applied, in which case the lead passes to
+// the first caller still waiting.
+func (g *groupCommit[T]) lead(t *Tree[T]) {
+	t.Lock()
+	defer t.Unlock()
+
+	for applied := 0; ; {
+		g.mu.Lock()
+		if len(g.pending) == 0 {
+			g.leading = false
+			g.mu.Unlock()
+			return
+		}
+		if applied >= groupLimit {
+			g.pending[0].done <- true
+			g.mu.Unlock()
+			return
+		}
+		n := min(len(g.pending), groupLimit-applied)
+		batch := g.pending[:n:n]
+		g.pending = g.pending[n:]
+		g.mu.Unlock()
+
+		for _, w := range batch {
+			w.err = t.insert(w.x, w.y)
+			w.done <- false
+		}
+		applied += n
+	}
+}
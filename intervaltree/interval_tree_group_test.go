@@ -0,0 +1,72 @@
+package intervaltree
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGroupCommit(t *testing.T) {
+	tree := NewTree[int32](WithGroupCommit())
+	const n = 3000 // Enough for several passes
+
+	// Holding the lock makes every insertion queue behind the first one
+	tree.Lock()
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for k := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			x := int32(k / 2 * 2) // Pairs of callers insert the same value
+			errs[k] = tree.Insert(x, x)
+		}()
+	}
+	for {
+		tree.group.mu.Lock()
+		queued := len(tree.group.pending)
+		tree.group.mu.Unlock()
+		if queued == n {
+			break
+		}
+		runtime.Gosched()
+	}
+	tree.Unlock()
+	wg.Wait()
+
+	for k := 0; k < n; k += 2 {
+		if (errs[k] == nil) == (errs[k+1] == nil) {
+			t.Fatalf("Insertions of %d returned %v and %v", k, errs[k], errs[k+1])
+		}
+		if err := errors.Join(errs[k], errs[k+1]); !errors.Is(err, ErrOverlap) {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if tree.Len() != n/2 || tree.group.leading {
+		t.Fatalf("Unexpected state after passes: %d intervals", tree.Len())
+	}
+	if err := tree.Insert(1, 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkGroupCommit(b *testing.B) {
+	for _, opts := range [][]Option{nil, {WithGroupCommit()}} {
+		tree := NewTree[uint64](opts...)
+		name := "Lock"
+		if opts != nil {
+			name = "Group"
+		}
+		b.Run(name, func(b *testing.B) {
+			var next atomic.Uint64
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					x := next.Add(2)
+					tree.Insert(x, x)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package intervaltree
+
+import "time"
+
+// Hint is a position in a tree returned by InsertNearHint: the interval the
+// last insertion ended in and the gap following it. It is only valid until the
+// tree is changed by other means, after which it is ignored. The zero Hint is
+// valid and holds no position.
+type Hint[T Integer] struct {
+	n    *node[T]
+	root *node[T]
+	rev  uint64
+	to   T    // Last value of the gap following the interval of n
+	open bool // Whether no interval follows n, so the gap has no end
+}
+
+// InsertNearHint adds the interval [x, y] to the tree, as Insert does, and
+// returns a hint to pass to the next call. An interval starting right after
+// the one the hint points to, and ending before the next one, extends it in
+// place, skipping the overlap checks and the rebalancing of a regular
+// insertion: only the values covered by the nodes on its path are updated, so
+// bulk insertions of nearly sorted adjacent ranges, such as sequential
+// allocations, run a few times faster. Other intervals are inserted as Insert
+// does, followed by a descent for the new hint.
+func (t *Tree[T]) InsertNearHint(h Hint[T], x, y T) (_ Hint[T], err error) {
+	if t.counters != nil {
+		defer t.counters.since(insertTime, time.Now())
+	}
+	if t.tracer != nil {
+		span := t.traceInterval("intervaltree.InsertNearHint", x, y)
+		defer func() { span.End(err) }()
+	}
+	if x > y {
+		return h, t.failed(insertErrors, x, y, InvalidIntervalError[T]{x, y})
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	return t.insertNear(h, x, y)
+}
+
+// insertNear adds the valid interval [x, y] to the tree, as InsertNearHint
+// does. The caller must hold the write lock.
+func (t *Tree[T]) insertNear(h Hint[T], x, y T) (Hint[T], error) {
+	if h.n != nil && h.root == t.root && h.rev == t.rev && x-1 == h.n.J && x > h.n.J && (y < h.to || h.open) {
+		if t.capped {
+			if err := t.checkCapacity(x, y); err != nil {
+				return h, t.failed(insertErrors, x, y, err)
+			}
+		}
+		h.n.J = y
+		for n, d := t.root, ordinal(y)-ordinal(x)+1; ; { // Only the covered values of the path change
+			n.covered += d
+			if n == h.n {
+				break
+			} else if h.n.I < n.I {
+				n = n.Left
+			} else {
+				n = n.Right
+			}
+		}
+		t.balancing.coalesced()
+		t.record(Change[T]{Interval: Interval[T]{x, y}})
+		if t.logger != nil {
+			t.logInserted(x, y)
+		}
+		h.rev = t.rev
+		return h, nil
+	}
+
+	if err := t.insert(x, y); err != nil {
+		return h, err
+	}
+	h = Hint[T]{root: t.root, rev: t.rev, open: true}
+	for n := t.root; n != nil; { // Finds the interval holding y and the next one
+		if y < n.I {
+			h.to, h.open, n = n.I-1, false, n.Left
+		} else {
+			h.n, n = n, n.Right
+		}
+	}
+	return h, nil
+}
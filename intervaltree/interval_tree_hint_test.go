@@ -0,0 +1,76 @@
+package intervaltree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestInsertNearHint(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	plain, hinted := NewTree[int16](), NewTree[int16]()
+	var h Hint[int16]
+	x := int16(-30000)
+	for i := 0; i < 5000; i++ {
+		// Mostly adjacent ranges, sometimes skipping or going back
+		switch r.Intn(8) {
+		case 0:
+			x += int16(r.Intn(20))
+		case 1:
+			x -= int16(r.Intn(200))
+		}
+		y := x + int16(r.Intn(5))
+
+		err1 := plain.Insert(x, y)
+		var err2 error
+		h, err2 = hinted.InsertNearHint(h, x, y)
+		if (err1 == nil) != (err2 == nil) {
+			t.Fatalf("Insertion %d of [%d, %d]: %v, expected %v", i, x, y, err2, err1)
+		}
+		if r.Intn(50) == 0 {
+			plain.Remove(x, x)
+			hinted.Remove(x, x) // Invalidates the hint
+		}
+		if err1 == nil {
+			x = y + 1
+		}
+	}
+	if s1, s2 := walkString[int16](plain), walkString[int16](hinted); s1 != s2 {
+		t.Fatalf("Unexpected tree: %s, expected %s", s2, s1)
+	}
+	if plain.Len() != hinted.Len() || plain.CoverageRatio(-32768, 32767) != hinted.CoverageRatio(-32768, 32767) || plain.Revision() != hinted.Revision() {
+		t.Fatal("Hinted insertions left wrong counts")
+	}
+	if err := hinted.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An interval adjacent to the next one joins it
+	hinted = NewTree[int16]()
+	hinted.Insert(10, 20)
+	h, _ = hinted.InsertNearHint(Hint[int16]{}, 0, 5)
+	h, _ = hinted.InsertNearHint(h, 6, 9)
+	if s := walkString[int16](hinted); s != "[0 -- 20]" {
+		t.Fatalf("Unexpected tree: %s", s)
+	}
+	h, _ = hinted.InsertNearHint(h, 32767, 32767)
+	if _, err := hinted.InsertNearHint(h, 21, 32767); err == nil {
+		t.Fatal("Overlapping insertion succeeded")
+	}
+}
+
+func BenchmarkInsertNearHint(b *testing.B) {
+	// Runs of 8 adjacent values separated by a free one
+	b.Run("Insert", func(b *testing.B) {
+		tree := NewTree[uint64]()
+		for i := uint64(0); i < uint64(b.N); i++ {
+			tree.Insert(i+i/8, i+i/8)
+		}
+	})
+	b.Run("Hint", func(b *testing.B) {
+		tree := NewTree[uint64]()
+		var h Hint[uint64]
+		for i := uint64(0); i < uint64(b.N); i++ {
+			h, _ = tree.InsertNearHint(h, i+i/8, i+i/8)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package intervaltree
+
+import (
+	"math"
+	"slices"
+	"sort"
+)
+
+// Histogram counts the lengths of the intervals in a tree and of the gaps
+// between them, as a measure of its fragmentation.
+type Histogram struct {
+	Bounds    []uint64 // Inclusive upper bounds of the buckets but the last one, in ascending order
+	Intervals []int    // Intervals per bucket, one more than Bounds
+	Gaps      []int    // Gaps between intervals per bucket, one more than Bounds
+}
+
+// Histogram returns a histogram of the lengths of the intervals in the tree
+// and of the gaps between them. A length l falls in the first bucket whose
+// bound is at least l, or in the last bucket if it exceeds every bound. A
+// length too large for a uint64 is counted as math.MaxUint64.
+func (t *Tree[T]) Histogram(bounds ...uint64) Histogram {
+	bounds = slices.Compact(slices.Sorted(slices.Values(bounds)))
+	h := Histogram{
+		Bounds:    bounds,
+		Intervals: make([]int, len(bounds)+1),
+		Gaps:      make([]int, len(bounds)+1),
+	}
+	bucket := func(l uint64) int {
+		return sort.Search(len(bounds), func(k int) bool { return bounds[k] >= l })
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+
+	var prev uint64
+	first := true
+	t.root.walk(func(x, y T) bool {
+		i, j := ordinal(x), ordinal(y)
+		l := j - i + 1
+		if l == 0 { // Every value of a 64-bit T
+			l = math.MaxUint64
+		}
+		h.Intervals[bucket(l)]++
+		if !first {
+			h.Gaps[bucket(i-prev-1)]++
+		}
+		prev, first = j, false
+		return true
+	})
+	return h
+}
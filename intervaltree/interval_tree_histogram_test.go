@@ -0,0 +1,35 @@
+package intervaltree
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	it := NewTree[int8]()
+	it.Insert(-128, -128) // Length 1
+	it.Insert(-120, -111) // Length 10, gap 7
+	it.Insert(-100, -1)   // Length 100, gap 10
+	it.Insert(1, 2)       // Length 2, gap 1
+
+	h := it.Histogram(10, 1, 2, 10)
+	if !slices.Equal(h.Bounds, []uint64{1, 2, 10}) {
+		t.Fatalf("Unexpected bounds: %v", h.Bounds)
+	}
+	if !slices.Equal(h.Intervals, []int{1, 1, 1, 1}) {
+		t.Fatalf("Unexpected interval counts: %v", h.Intervals)
+	}
+	if !slices.Equal(h.Gaps, []int{1, 0, 2, 0}) {
+		t.Fatalf("Unexpected gap counts: %v", h.Gaps)
+	}
+
+	full := New()
+	full.Insert(0, math.MaxUint64)
+	if h := full.Histogram(); !slices.Equal(h.Intervals, []int{1}) || !slices.Equal(h.Gaps, []int{0}) {
+		t.Fatalf("Unexpected histogram: %+v", h)
+	}
+	if h := full.Histogram(math.MaxUint64 - 1); !slices.Equal(h.Intervals, []int{0, 1}) {
+		t.Fatalf("Unexpected histogram: %+v", h)
+	}
+}
@@ -0,0 +1,164 @@
+package intervaltree
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RecordFormat selects how the interval records of a stream are framed.
+type RecordFormat int
+
+// Record formats.
+const (
+	// TextRecords are written as "x-y", or as "x" for a single value, and
+	// separated by commas, spaces or newlines, as in "1-5,8 10-12".
+	TextRecords RecordFormat = iota
+	// BinaryRecords are pairs of unsigned varints: the ordinal of the first
+	// value, which is the value itself for unsigned types and the value plus
+	// 2^63 for signed ones, and the number of values minus one.
+	BinaryRecords
+)
+
+// ingestBatch is the number of records read before inserting them under a
+// single lock acquisition, which bounds the memory used by UnionFrom.
+const ingestBatch = 1024
+
+// UnionFrom reads interval records framed following f from r until EOF and
+// inserts the values they hold not contained in the tree, whatever its
+// OverlapMode, so the tree becomes their union. Records are inserted in
+// batches as they are read, so memory use is bounded whatever the length of the
+// stream, and the lock is not held while reading. It returns the number of
+// records read. On error, the records read before the failing one have been
+// inserted.
+func (t *Tree[T]) UnionFrom(r io.Reader, f RecordFormat) (int, error) {
+	next, err := recordReader[T](r, f)
+	if err != nil {
+		return 0, err
+	}
+
+	batch := make([]Interval[T], 0, ingestBatch)
+	n := 0
+	for {
+		i, err := next()
+		if err == nil {
+			batch = append(batch, i)
+			if len(batch) < cap(batch) {
+				continue
+			}
+		}
+		if ferr := t.unionBatch(batch); ferr != nil {
+			return n, ferr
+		}
+		n += len(batch)
+		batch = batch[:0]
+
+		switch {
+		case err == io.EOF:
+			return n, nil
+		case err != nil:
+			return n, fmt.Errorf("Record %d: %w", n, err)
+		}
+	}
+}
+
+// unionBatch inserts the values in intervals not contained in the tree, under
+// a single lock acquisition.
+func (t *Tree[T]) unionBatch(intervals []Interval[T]) error {
+	t.Lock()
+	defer t.Unlock()
+
+	for _, i := range intervals {
+		plan, err := t.unionPlan(i.I, i.J)
+		if err != nil {
+			return t.failed(insertErrors, i.I, i.J, err)
+		}
+		for _, p := range plan {
+			if err := t.insertStrict(p.I, p.J); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recordReader returns a function reading the next record framed following f
+// from r, or io.EOF once r is exhausted.
+func recordReader[T Integer](r io.Reader, f RecordFormat) (func() (Interval[T], error), error) {
+	switch f {
+	case TextRecords:
+		return textRecords[T](r), nil
+	case BinaryRecords:
+		return binaryRecords[T](bufio.NewReader(r)), nil
+	}
+	return nil, fmt.Errorf("Unknown record format %d", f)
+}
+
+// textRecords returns a function reading the next record in text format from
+// r, or io.EOF once r is exhausted.
+func textRecords[T Integer](r io.Reader) func() (Interval[T], error) {
+	s := bufio.NewScanner(r)
+	s.Split(bufio.ScanWords)
+	var fields []string // Fields of the current word left to read
+	return func() (Interval[T], error) {
+		for len(fields) == 0 {
+			if !s.Scan() {
+				if err := s.Err(); err != nil {
+					return Interval[T]{}, err
+				}
+				return Interval[T]{}, io.EOF
+			}
+			fields = strings.FieldsFunc(s.Text(), func(r rune) bool { return r == ',' })
+		}
+
+		field := fields[0]
+		fields = fields[1:]
+		return parseRecord[T](field)
+	}
+}
+
+// parseRecord parses an interval written as "x-y" or "x", where both bounds
+// may be negative.
+func parseRecord[T Integer](s string) (Interval[T], error) {
+	sep := strings.IndexByte(s[1:], '-') + 1 // A leading sign is not a separator
+	if sep == 0 {
+		x, err := parseValue[T](s)
+		return Interval[T]{x, x}, err
+	}
+
+	x, err := parseValue[T](s[:sep])
+	if err != nil {
+		return Interval[T]{}, err
+	}
+	y, err := parseValue[T](s[sep+1:])
+	if err != nil {
+		return Interval[T]{}, err
+	}
+	if x > y {
+		return Interval[T]{}, InvalidIntervalError[T]{x, y}
+	}
+	return Interval[T]{x, y}, nil
+}
+
+// binaryRecords returns a function reading the next record in binary format
+// from r, or io.EOF once r is exhausted between records.
+func binaryRecords[T Integer](r io.ByteReader) func() (Interval[T], error) {
+	return func() (Interval[T], error) {
+		x, err := binary.ReadUvarint(r)
+		if err != nil {
+			return Interval[T]{}, err // io.EOF if no byte was read
+		}
+		var length uint64
+		if err := readUvarints(r, &length); err != nil {
+			return Interval[T]{}, err
+		}
+
+		i, ok := readInterval[T](x, length)
+		if !ok {
+			return i, fmt.Errorf("Interval out of range [%d, +%d]", x, length)
+		}
+		return i, nil
+	}
+}
@@ -0,0 +1,66 @@
+package intervaltree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestUnionFromText(t *testing.T) {
+	it := NewTree[int16]()
+	it.Insert(3, 4)
+	n, err := it.UnionFrom(strings.NewReader("1-5,8\n-20--10 ,, 7 9-9\n"), TextRecords)
+	if err != nil || n != 5 {
+		t.Fatalf("Unexpected result: %d %v", n, err)
+	}
+	expected, _ := BuildTree([]Interval[int16]{{-20, -10}, {1, 5}, {7, 9}}, 1)
+	if it.ToString() != expected.ToString() {
+		t.Fatalf("Unexpected tree: %s", it.ToString())
+	}
+
+	for _, c := range []string{"1-x", "5-3", "40000", "--"} {
+		if _, err := it.UnionFrom(strings.NewReader("100 "+c), TextRecords); err == nil || !strings.HasPrefix(err.Error(), "Record 1:") {
+			t.Fatalf("Unexpected error for %q: %v", c, err)
+		}
+	}
+	if !it.Contains(100) {
+		t.Fatal("Records before a failing one were not inserted")
+	}
+	if _, err := it.UnionFrom(strings.NewReader(""), RecordFormat(5)); err == nil {
+		t.Fatal("Read records in an unknown format")
+	}
+}
+
+func TestUnionFromBinary(t *testing.T) {
+	var buf []byte
+	expected := NewTree[uint32](WithOverlapMode(OverlapUnion))
+	records := 3*ingestBatch + 10
+	for k := uint64(0); k < uint64(records); k++ {
+		x, length := k*k%100000, k%7
+		buf = binary.AppendUvarint(buf, x)
+		buf = binary.AppendUvarint(buf, length)
+		expected.Insert(uint32(x), uint32(x+length))
+	}
+
+	it := NewTree[uint32]()
+	if n, err := it.UnionFrom(bytes.NewReader(buf), BinaryRecords); err != nil || n != records {
+		t.Fatalf("Unexpected result: %d %v", n, err)
+	}
+	if err := it.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if it.ToString() != expected.ToString() {
+		t.Fatal("Unexpected tree")
+	}
+
+	if _, err := it.UnionFrom(bytes.NewReader(buf[:len(buf)-1]), BinaryRecords); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Expected an unexpected EOF error, got %v", err)
+	}
+	outOfRange := binary.AppendUvarint(binary.AppendUvarint(nil, 250), 10)
+	if _, err := NewTree[uint8]().UnionFrom(bytes.NewReader(outOfRange), BinaryRecords); err == nil {
+		t.Fatal("Read an interval out of range")
+	}
+}
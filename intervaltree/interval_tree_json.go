@@ -0,0 +1,136 @@
+package intervaltree
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// EncodeJSON writes the intervals in the tree to w as a JSON array of
+// [start, end] pairs in ascending order. Intervals are streamed as the tree is
+// traversed, so the array is never held in memory.
+func (t *Tree[T]) EncodeJSON(w io.Writer) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	bw.WriteByte('[')
+	buf := make([]byte, 0, 48)
+	var err error
+	var n, total int64 = 0, int64(t.root.getIntervals())
+	reportProgress(t.progress, "intervaltree.EncodeJSON", 0, total)
+	t.root.walk(func(x, y T) bool {
+		if len(buf) > 0 { // Not the first interval
+			buf = append(buf[:0], ',')
+		}
+		buf = append(buf, '[')
+		buf = appendInt(buf, x)
+		buf = append(buf, ',')
+		buf = appendInt(buf, y)
+		buf = append(buf, ']')
+		_, err = bw.Write(buf)
+		n++
+		reportProgress(t.progress, "intervaltree.EncodeJSON", n, total)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+
+	bw.WriteString("]\n")
+	return bw.Flush()
+}
+
+// DecodeJSON reads a JSON array of [start, end] pairs from r, as written by
+// EncodeJSON, and inserts every interval into the tree. The whole input is
+// decoded into a staging tree held in memory before the lock is taken, so a
+// slow reader does not block the tree, and its intervals are only inserted
+// once checked against the OverlapMode and the capacity of the tree, so an
+// error leaves the tree unchanged. Adjacent intervals are joined before
+// insertion.
+func (t *Tree[T]) DecodeJSON(r io.Reader) (err error) {
+	var n int // Intervals decoded
+	if t.tracer != nil {
+		span := t.tracer.Start("intervaltree.DecodeJSON")
+		defer func() {
+			span.SetAttribute("intervals", int64(n))
+			span.End(err)
+		}()
+	}
+
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+
+	staged := NewTree[T]()
+	reportProgress(t.progress, "intervaltree.DecodeJSON", 0, -1)
+	for dec.More() {
+		var pair [2]T
+		if err := dec.Decode(&pair); err != nil {
+			return err
+		}
+		if pair[0] > pair[1] {
+			return InvalidIntervalError[T]{pair[0], pair[1]}
+		}
+		if err := staged.insert(pair[0], pair[1]); err != nil {
+			return err
+		}
+		n++
+		reportProgress(t.progress, "intervaltree.DecodeJSON", int64(n), -1)
+	}
+	if err := expectDelim(dec, ']'); err != nil {
+		return err
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	if err := t.checkStaged(staged.root); err != nil {
+		return err
+	}
+	staged.root.walk(func(x, y T) bool {
+		err = t.insert(x, y)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	reportProgress(t.progress, "intervaltree.DecodeJSON", int64(n), int64(n))
+	return nil
+}
+
+// checkStaged checks that the intervals under root can all be inserted into
+// the tree following its OverlapMode, and together fit in its capacity. The
+// caller must hold the lock.
+func (t *Tree[T]) checkStaged(root *node[T]) (err error) {
+	var requested uint64
+	root.walk(func(x, y T) bool {
+		if err = t.checkInsert(x, y, &requested); err != nil {
+			err = t.failed(insertErrors, x, y, err)
+		}
+		return err == nil
+	})
+	return err
+}
+
+// appendInt appends the decimal representation of x to buf.
+func appendInt[T Integer](buf []byte, x T) []byte {
+	if signed[T]() {
+		return strconv.AppendInt(buf, int64(x), 10)
+	}
+	return strconv.AppendUint(buf, uint64(x), 10)
+}
+
+// expectDelim reads the next token from dec and checks it is the delimiter d.
+func expectDelim(dec *json.Decoder, d json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != d {
+		return fmt.Errorf("Expected %q in JSON input, found %v", d, tok)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package intervaltree
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestEncodeJSON(t *testing.T) {
+	it := New()
+	var b bytes.Buffer
+	if err := it.EncodeJSON(&b); err != nil {
+		t.Fatalf("EncodeJSON failed on empty tree: %v", err)
+	}
+	if b.String() != "[]\n" {
+		t.Fatalf("Unexpected JSON for empty tree: %q", b.String())
+	}
+
+	it.Insert(30, 40)
+	it.Insert(0, 5)
+	it.Insert(10, 20)
+	b.Reset()
+	if err := it.EncodeJSON(&b); err != nil {
+		t.Fatalf("EncodeJSON failed: %v", err)
+	}
+	if b.String() != "[[0,5],[10,20],[30,40]]\n" {
+		t.Fatalf("Unexpected JSON: %q", b.String())
+	}
+
+	decoded := New()
+	if err := decoded.DecodeJSON(&b); err != nil {
+		t.Fatalf("DecodeJSON failed: %v", err)
+	}
+	if decoded.ToString() != it.ToString() {
+		t.Fatalf("Decoded tree differs. Got %s, expected %s", decoded.ToString(), it.ToString())
+	}
+	if err := decoded.root.isAVL(); err != nil {
+		t.Fatalf("Decoded tree is not AVL: %v", err)
+	}
+}
+
+func TestDecodeJSONErrors(t *testing.T) {
+	for _, in := range []string{
+		`{}`,
+		`[[1,2]`,
+		`[[1,2],"a"]`,
+		`[[5,1]]`,
+		`[[1,5],[3,4]]`,
+	} {
+		if err := New().DecodeJSON(strings.NewReader(in)); err == nil {
+			t.Fatalf("DecodeJSON accepted invalid input %s", in)
+		}
+	}
+}
+
+func TestDecodeJSONAtomic(t *testing.T) {
+	it := NewTree[uint8](WithCapacity(20))
+	it.Insert(10, 20)
+	for in, expected := range map[string]error{
+		`[[1,2],[15,30]]`: OverlapError[uint8]{15, Interval[uint8]{15, 30}, Interval[uint8]{10, 20}},
+		`[[0,4],[5,9]]`:   CapacityError{20, 9, 10},
+	} {
+		if err := it.DecodeJSON(strings.NewReader(in)); err != expected {
+			t.Fatalf("Unexpected error decoding %s: %v", in, err)
+		}
+		if it.ToString() != "[10 -- 20]" {
+			t.Fatalf("Failed DecodeJSON changed the tree: %s", it.ToString())
+		}
+	}
+}
+
+func TestDecodeJSONUnlocked(t *testing.T) {
+	it := New()
+	r, w := io.Pipe()
+	done := make(chan error)
+	go func() { done <- it.DecodeJSON(r) }()
+
+	io.WriteString(w, "[[1,2],")
+	if err := it.Insert(10, 10); err != nil { // Would block if DecodeJSON held the lock while reading
+		t.Fatal(err)
+	}
+	io.WriteString(w, "[4,5]]")
+	w.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if it.ToString() != "[1 -- 2][4 -- 5][10 -- 10]" {
+		t.Fatalf("Unexpected contents: %s", it.ToString())
+	}
+}
+
+func TestDecodeJSONLenientAtomic(t *testing.T) {
+	it := NewTree[uint64](WithCapacity(10), WithOverlapMode(OverlapIgnoreCovered))
+	var ce CapacityError
+	if err := it.DecodeJSON(strings.NewReader("[[0,4],[10,19]]")); !errors.As(err, &ce) {
+		t.Fatalf("Expected a capacity error, got %v", err)
+	}
+	if it.Contains(0) {
+		t.Fatal("Failed DecodeJSON changed the tree")
+	}
+
+	it = NewTree[uint64](WithCapacity(10), WithOverlapMode(OverlapUnion))
+	it.Insert(0, 4)
+	if err := it.DecodeJSON(strings.NewReader("[[0,9]]")); err != nil { // Only [5, 9] is requested
+		t.Fatalf("Failed to decode intervals fitting once covered values are left out: %v", err)
+	}
+	if err := it.DecodeJSON(strings.NewReader("[[5,5],[20,20]]")); !errors.As(err, &ce) || it.Contains(20) {
+		t.Fatalf("Unexpected result decoding over capacity: %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package intervaltree
+
+import (
+	"container/heap"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Lease represents an interval reserved until a deadline.
+type Lease[T Integer] struct {
+	Interval[T]
+	Deadline time.Time
+}
+
+// WithClock makes Leases read the current time from now instead of time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(c *config) {
+		c.clock = now
+	}
+}
+
+// Leases reserves intervals until a deadline, after which they are released
+// automatically unless renewed. Leases are kept apart from each other even if
+// adjacent, so each one is renewed or released on its own. It is safe for
+// concurrent use.
+type Leases[T Integer] struct {
+	mu     sync.Mutex
+	t      *Tree[T]
+	now    func() time.Time
+	leases map[Interval[T]]*leaseEntry[T]
+	queue  leaseQueue[T] // Leases by ascending deadline
+}
+
+// leaseEntry is a lease and its position in the queue.
+type leaseEntry[T Integer] struct {
+	Lease[T]
+	k int
+}
+
+// NewLeases returns a pointer to an empty Leases backed by a tree created with
+// opts.
+func NewLeases[T Integer](opts ...Option) *Leases[T] {
+	c := config{clock: time.Now}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &Leases[T]{t: NewTree[T](opts...), now: c.clock, leases: make(map[Interval[T]]*leaseEntry[T])}
+}
+
+// Acquire reserves [x, y] until deadline. It fails as Tree.Insert does if
+// [x, y] overlaps a lease that has not expired.
+func (l *Leases[T]) Acquire(x, y T, deadline time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	if err := l.t.Insert(x, y); err != nil {
+		return err
+	}
+	e := &leaseEntry[T]{Lease: Lease[T]{Interval[T]{x, y}, deadline}}
+	l.leases[e.Interval] = e
+	heap.Push(&l.queue, e)
+	return nil
+}
+
+// Renew moves the deadline of the lease of [x, y] to deadline. Unlike
+// releasing and acquiring it again, no other holder can take the interval in
+// between. It fails with a NotContainedError if there is no such lease or it
+// has expired.
+func (l *Leases[T]) Renew(x, y T, deadline time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	e, ok := l.leases[Interval[T]{x, y}]
+	if !ok {
+		return NotContainedError[T]{x}
+	}
+	e.Deadline = deadline
+	heap.Fix(&l.queue, e.k)
+	return nil
+}
+
+// Release releases the lease of [x, y] before its deadline. It fails with a
+// NotContainedError if there is no such lease or it has expired.
+func (l *Leases[T]) Release(x, y T) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	e, ok := l.leases[Interval[T]{x, y}]
+	if !ok {
+		return NotContainedError[T]{x}
+	}
+	heap.Remove(&l.queue, e.k)
+	l.drop(e)
+	return nil
+}
+
+// Contains checks if x is reserved by a lease that has not expired.
+func (l *Leases[T]) Contains(x T) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+	return l.t.Contains(x)
+}
+
+// Expire releases the leases whose deadline has passed and returns them in
+// the order they expired. Other methods release them as well, so calling it is
+// only needed to learn which ones expired.
+func (l *Leases[T]) Expire() []Lease[T] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.expire()
+}
+
+// Expiring returns the leases that expire before deadline, by ascending
+// deadline, so holders can renew them in time.
+func (l *Leases[T]) Expiring(deadline time.Time) []Lease[T] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	var leases []Lease[T]
+	for _, e := range l.queue {
+		if e.Deadline.Before(deadline) {
+			leases = append(leases, e.Lease)
+		}
+	}
+	slices.SortFunc(leases, func(a, b Lease[T]) int {
+		return a.Deadline.Compare(b.Deadline)
+	})
+	return leases
+}
+
+// Len returns the number of leases that have not expired.
+func (l *Leases[T]) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+	return len(l.queue)
+}
+
+// expire releases the leases whose deadline has passed and returns them. The
+// caller must hold the lock.
+func (l *Leases[T]) expire() []Lease[T] {
+	var expired []Lease[T]
+	now := l.now()
+	for len(l.queue) > 0 && !l.queue[0].Deadline.After(now) {
+		e := heap.Pop(&l.queue).(*leaseEntry[T])
+		l.drop(e)
+		expired = append(expired, e.Lease)
+	}
+	return expired
+}
+
+// drop removes e, which is no longer in the queue, from the tree and the map.
+func (l *Leases[T]) drop(e *leaseEntry[T]) {
+	l.t.Remove(e.I, e.J) // Leases never overlap, so this cannot fail
+	delete(l.leases, e.Interval)
+}
+
+// leaseQueue is a min-heap of leases by deadline.
+type leaseQueue[T Integer] []*leaseEntry[T]
+
+func (q leaseQueue[T]) Len() int           { return len(q) }
+func (q leaseQueue[T]) Less(a, b int) bool { return q[a].Deadline.Before(q[b].Deadline) }
+
+func (q leaseQueue[T]) Swap(a, b int) {
+	q[a], q[b] = q[b], q[a]
+	q[a].k, q[b].k = a, b
+}
+
+func (q *leaseQueue[T]) Push(x any) {
+	e := x.(*leaseEntry[T])
+	e.k = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *leaseQueue[T]) Pop() any {
+	old := *q
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return e
+}
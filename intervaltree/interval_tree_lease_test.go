@@ -0,0 +1,73 @@
+package intervaltree
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLeases(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := NewLeases[int](WithClock(func() time.Time { return now }))
+
+	if err := l.Acquire(0, 4, now.Add(10*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Acquire(5, 9, now.Add(20*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Acquire(20, 29, now.Add(5*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	var oe OverlapError[int]
+	if err := l.Acquire(3, 6, now.Add(time.Hour)); !errors.As(err, &oe) {
+		t.Fatalf("Expected an overlap error, got %v", err)
+	}
+
+	expiring := l.Expiring(now.Add(15 * time.Second))
+	expected := []Lease[int]{
+		{Interval[int]{20, 29}, now.Add(5 * time.Second)},
+		{Interval[int]{0, 4}, now.Add(10 * time.Second)},
+	}
+	if !reflect.DeepEqual(expiring, expected) {
+		t.Fatalf("Unexpected expiring leases: %v", expiring)
+	}
+
+	if err := l.Renew(0, 4, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	var nce NotContainedError[int]
+	if err := l.Renew(0, 3, now.Add(time.Minute)); !errors.As(err, &nce) {
+		t.Fatalf("Expected a not contained error, got %v", err)
+	}
+
+	now = now.Add(30 * time.Second)
+	expired := l.Expire()
+	expected = []Lease[int]{
+		{Interval[int]{20, 29}, now.Add(-25 * time.Second)},
+		{Interval[int]{5, 9}, now.Add(-10 * time.Second)},
+	}
+	if !reflect.DeepEqual(expired, expected) {
+		t.Fatalf("Unexpected expired leases: %v", expired)
+	}
+	if l.Contains(5) || l.Contains(20) || !l.Contains(4) || l.Len() != 1 {
+		t.Fatal("Unexpected leases after expiry")
+	}
+	if err := l.Renew(5, 9, now.Add(time.Minute)); !errors.As(err, &nce) {
+		t.Fatalf("Expected a not contained error renewing an expired lease, got %v", err)
+	}
+
+	if err := l.Acquire(5, 9, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Release(0, 4); err != nil {
+		t.Fatal(err)
+	}
+	if l.Contains(0) || !l.Contains(5) {
+		t.Fatal("Unexpected leases after release")
+	}
+	if err := l.Release(0, 4); !errors.As(err, &nce) {
+		t.Fatalf("Expected a not contained error, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package intervaltree
+
+// OverlapMode selects what inserting values already contained in a Tree does.
+type OverlapMode uint8
+
+// Overlap modes.
+const (
+	OverlapFail          OverlapMode = iota // Fail with an OverlapError, which is the default
+	OverlapIgnoreCovered                    // Do nothing if every value is contained, fail on partial overlaps
+	OverlapUnion                            // Insert the values not contained, so the tree becomes the union
+)
+
+// WithOverlapMode makes the tree handle insertions of values already contained
+// following m, for every call of Insert, InsertAll, Apply or an Allocator
+// built on it.
+func WithOverlapMode(m OverlapMode) Option {
+	return func(c *config) {
+		c.overlaps = m
+	}
+}
+
+// plan returns the intervals to insert in order to insert [x, y] following the
+// OverlapMode of the tree. Those conflicting with the tree are left for
+// insertStrict to reject. The caller must hold the lock.
+func (t *Tree[T]) plan(x, y T) ([]Interval[T], error) {
+	switch t.overlaps {
+	case OverlapIgnoreCovered:
+		if c := t.root.containingNode(x); c != nil && y <= c.J {
+			return nil, nil
+		}
+	case OverlapUnion:
+		return t.unionPlan(x, y)
+	}
+	return []Interval[T]{{x, y}}, nil
+}
+
+// unionPlan returns the intervals to insert in order to insert the values in
+// [x, y] not contained in the tree, and fails if they exceed its capacity. The
+// caller must hold the lock.
+func (t *Tree[T]) unionPlan(x, y T) ([]Interval[T], error) {
+	gaps := t.gaps(x, y)
+	if t.capped {
+		var requested uint64
+		for _, g := range gaps {
+			requested += ordinal(g.J) - ordinal(g.I) + 1
+		}
+		if remaining := t.capacity - t.root.getCovered(); requested > remaining {
+			return nil, CapacityError{t.capacity, remaining, requested}
+		}
+	}
+	return gaps, nil
+}
+
+// checkInsert checks that inserting the valid interval [x, y] following the
+// OverlapMode of the tree would succeed, without changing it, and adds the
+// number of values it would insert to *requested, which holds those of
+// earlier checked insertions, so that several disjoint and non adjacent
+// intervals can be inserted all or none. It fails with the error the
+// insertion would, the capacity being checked against *requested. The caller
+// must hold the lock.
+func (t *Tree[T]) checkInsert(x, y T, requested *uint64) error {
+	plan, err := t.plan(x, y)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range plan {
+		if oe, ok := t.collision(i.I, i.J); ok {
+			if t.remainders {
+				return PartialOverlapError[T]{oe, t.gaps(i.I, i.J)}
+			}
+			return oe
+		}
+
+		n := ordinal(i.J) - ordinal(i.I) + 1
+		if t.capped {
+			remaining := t.capacity - t.root.getCovered()
+			if n == 0 || *requested+n < n || *requested+n > remaining {
+				return CapacityError{t.capacity, remaining, *requested + n}
+			}
+		}
+		*requested += n
+	}
+	return nil
+}
+
+// collision returns the OverlapError inserting [x, y] fails with, and whether
+// [x, y] overlaps the tree at all. The caller must hold the lock.
+func (t *Tree[T]) collision(x, y T) (OverlapError[T], bool) {
+	var oe OverlapError[T]
+	if l := t.root.floor(x); l != nil && l.J >= x {
+		oe = l.overlap(x)
+	} else if r := t.root.higher(x); r != nil && r.I <= y {
+		oe = r.overlap(r.I)
+	} else {
+		return oe, false
+	}
+	oe.Attempted = Interval[T]{x, y}
+	return oe, true
+}
+
+// insertLenient adds the valid interval [x, y] to the tree following its
+// OverlapMode. Every part inserted is recorded as a change of its own. The
+// caller must hold the write lock.
+func (t *Tree[T]) insertLenient(x, y T) error {
+	plan, err := t.plan(x, y)
+	if err != nil {
+		return t.failed(insertErrors, x, y, err)
+	}
+	for _, i := range plan {
+		if err := t.insertStrict(i.I, i.J); err != nil {
+			return err
+		}
+	}
+	return nil
+}
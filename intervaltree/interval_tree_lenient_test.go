@@ -0,0 +1,87 @@
+package intervaltree
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOverlapModes(t *testing.T) {
+	strict := NewTree[int]()
+	strict.Insert(10, 20)
+	if err := strict.Insert(12, 15); !errors.Is(err, ErrOverlap) {
+		t.Fatalf("Expected an overlap error, got %v", err)
+	}
+
+	ignore := NewTree[int](WithOverlapMode(OverlapIgnoreCovered))
+	ignore.Insert(10, 20)
+	if err := ignore.Insert(12, 15); err != nil {
+		t.Fatalf("Covered insertion failed: %v", err)
+	}
+	if err := ignore.Insert(15, 25); !errors.Is(err, ErrOverlap) {
+		t.Fatalf("Expected an overlap error, got %v", err)
+	}
+	if s := ignore.ToString(); s != "[10 -- 20]" || ignore.Revision() != 1 {
+		t.Fatalf("Unexpected tree: %s at revision %d", s, ignore.Revision())
+	}
+
+	union := NewTree[int](WithOverlapMode(OverlapUnion))
+	union.Checkpoint()
+	union.Insert(10, 20)
+	union.Insert(30, 40)
+	if err := union.Insert(0, 35); err != nil {
+		t.Fatalf("Union insertion failed: %v", err)
+	}
+	if err := union.Insert(12, 15); err != nil {
+		t.Fatalf("Covered insertion failed: %v", err)
+	}
+	if s := union.ToString(); s != "[0 -- 40]" {
+		t.Fatalf("Unexpected union: %s", s)
+	}
+
+	// Every part inserted is a change of its own, so a strict follower can
+	// apply them
+	d, _ := union.Delta(0)
+	follower := NewTree[int]()
+	if err := follower.Apply(d); err != nil || follower.ToString() != "[0 -- 40]" {
+		t.Fatalf("Follower failed to apply the union: %s, %v", follower.ToString(), err)
+	}
+	if err := union.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOverlapUnionCapacity(t *testing.T) {
+	it := NewTree[int](WithOverlapMode(OverlapUnion), WithCapacity(20))
+	it.Insert(10, 19)
+	if err := it.Insert(5, 24); err != nil { // 20 values of which 10 are new
+		t.Fatalf("Union within capacity failed: %v", err)
+	}
+	var ce CapacityError
+	if err := it.Insert(0, 30); !errors.As(err, &ce) || ce.Requested != 11 {
+		t.Fatalf("Expected a capacity error, got %v", err)
+	}
+	if s := it.ToString(); s != "[5 -- 24]" {
+		t.Fatalf("Tree changed after a failed union: %s", s)
+	}
+}
+
+func TestOverlapUnionWAL(t *testing.T) {
+	dir := t.TempDir()
+	w, err := OpenWAL[int](dir, WithOverlapMode(OverlapUnion))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Insert(10, 20)
+	if err := w.Insert(0, 30); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	if w, err = OpenWAL[int](dir); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if !w.Contains(0) || !w.Contains(30) || w.Contains(31) {
+		t.Fatal("Union was not recovered from the log")
+	}
+}
@@ -0,0 +1,162 @@
+package intervaltree
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"slices"
+)
+
+// loadFanIn is the number of runs spilled by Load that are merged into one.
+const loadFanIn = 64
+
+// Load returns a pointer to a Tree configured by opts holding the union of the
+// interval records framed following f read from r, such as a file far larger
+// than memory. Records are read in chunks of up to chunk intervals, which are
+// sorted and coalesced one at a time. Unless a single chunk is read, each is
+// spilled as a sorted run to a temporary file in dir, or in os.TempDir if dir
+// is empty, and the runs are merged in a single pass once r is exhausted, or
+// whenever there are 64 of them, to bound the files open. The raw input is thus
+// never held in memory, only a chunk of it and the tree. It fails with a
+// CapacityError if the union holds more values than a capacity set by
+// WithCapacity.
+func Load[T Integer](r io.Reader, f RecordFormat, chunk int, dir string, opts ...Option) (*Tree[T], error) {
+	return LoadContext[T](context.Background(), r, f, chunk, dir, opts...)
+}
+
+// LoadContext is like Load, but checks periodically while reading and merging
+// records whether ctx is done, in which case it fails with the error of ctx,
+// removing the temporary files.
+func LoadContext[T Integer](ctx context.Context, r io.Reader, f RecordFormat, chunk int, dir string, opts ...Option) (*Tree[T], error) {
+	next, err := recordReader[T](r, f)
+	if err != nil {
+		return nil, err
+	}
+	chunk = max(chunk, 1)
+
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	var read int64 // Records read, for progress reports
+	reportProgress(c.progress, "intervaltree.Load", 0, -1)
+	finish := func(intervals []Interval[T]) (*Tree[T], error) {
+		t, err := buildChecked(intervals, opts)
+		if err == nil {
+			reportProgress(c.progress, "intervaltree.Load", read, read)
+		}
+		return t, err
+	}
+
+	var runs []*os.File
+	defer func() {
+		for _, run := range runs {
+			run.Close()
+			os.Remove(run.Name())
+		}
+	}()
+
+	buf := make([]Interval[T], 0, min(chunk, 1<<16))
+	for done := false; !done; {
+		buf = buf[:0]
+		for len(buf) < chunk {
+			if err := cancelled(ctx, len(buf)); err != nil {
+				return nil, err
+			}
+			i, err := next()
+			if err == io.EOF {
+				done = true
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			buf = append(buf, i)
+			read++
+			reportProgress(c.progress, "intervaltree.Load", read, -1)
+		}
+		slices.SortFunc(buf, compareIntervals[T])
+		sorted := coalesce(buf)
+
+		if done && len(runs) == 0 { // A single chunk needs no merging
+			return finish(slices.Clone(sorted))
+		}
+		run, err := spill(sorted, dir)
+		if run != nil {
+			runs = append(runs, run)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Merge the runs into one before they take too many file descriptors.
+		// Their union is no larger than the tree, so it fits in memory.
+		if len(runs) == loadFanIn {
+			merged, err := mergeRunFiles[T](ctx, runs)
+			if err != nil {
+				return nil, err
+			}
+			run, err := spill(merged, dir)
+			for _, old := range runs {
+				old.Close()
+				os.Remove(old.Name())
+			}
+			runs = runs[:0]
+			if run != nil {
+				runs = append(runs, run)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	merged, err := mergeRunFiles[T](ctx, runs)
+	if err != nil {
+		return nil, err
+	}
+	return finish(merged)
+}
+
+// buildChecked returns a pointer to a Tree configured by opts holding the
+// ascending, non adjacent intervals, failing if they exceed its capacity.
+func buildChecked[T Integer](intervals []Interval[T], opts []Option) (*Tree[T], error) {
+	t := NewTree[T](opts...)
+	root := build(intervals)
+	if err := t.checkContents(root); err != nil {
+		return nil, err
+	}
+	t.root = root
+	return t, nil
+}
+
+// spill writes the intervals as a run of binary records to a new temporary
+// file in dir, and returns it positioned at its start.
+func spill[T Integer](intervals []Interval[T], dir string) (*os.File, error) {
+	f, err := os.CreateTemp(dir, "intervaltree-run-*")
+	if err != nil {
+		return nil, err
+	}
+
+	vw := &varintWriter{w: bufio.NewWriter(f)}
+	for _, i := range intervals {
+		vw.put(ordinal(i.I))
+		vw.put(ordinal(i.J) - ordinal(i.I))
+	}
+	if _, err := vw.flush(); err != nil {
+		return f, err
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	return f, err
+}
+
+// mergeRunFiles merges the sorted runs of binary records in files into
+// ascending, non adjacent intervals. It fails with the error of ctx if ctx is
+// done.
+func mergeRunFiles[T Integer](ctx context.Context, files []*os.File) ([]Interval[T], error) {
+	runs := make([]func() (Interval[T], error), len(files))
+	for k, f := range files {
+		runs[k] = binaryRecords[T](bufio.NewReader(f))
+	}
+	return unionRuns(ctx, runs)
+}
@@ -0,0 +1,68 @@
+package intervaltree
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var b strings.Builder
+	for k := 0; k < 10000; k++ {
+		x := r.Intn(100000) - 50000
+		if k%3 == 0 {
+			fmt.Fprintf(&b, "%d\n", x)
+		} else {
+			fmt.Fprintf(&b, "%d-%d,", x, x+r.Intn(40))
+		}
+	}
+	expected := NewTree[int32]()
+	if _, err := expected.UnionFrom(strings.NewReader(b.String()), TextRecords); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	for _, chunk := range []int{1, 7, 1000, 20000} {
+		it, err := Load[int32](strings.NewReader(b.String()), TextRecords, chunk, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := it.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if it.ToString() != expected.ToString() {
+			t.Fatalf("Unexpected tree loaded in chunks of %d", chunk)
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("Temporary runs left behind: %d", len(entries))
+	}
+
+	if _, err := Load[int32](strings.NewReader("1-5 7-x"), TextRecords, 1, dir); err == nil {
+		t.Fatal("Loaded a malformed record")
+	}
+	if _, err := Load[uint8](bytes.NewReader([]byte{1, 2, 3}), BinaryRecords, 1, dir); err == nil {
+		t.Fatal("Loaded a truncated record")
+	}
+	var ce CapacityError
+	if _, err := Load[int32](strings.NewReader(b.String()), TextRecords, 100, dir, WithCapacity(1000)); !errors.As(err, &ce) {
+		t.Fatalf("Expected a capacity error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("Temporary runs left behind: %d", len(entries))
+	}
+}
+
+func TestLoadContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := LoadContext[uint64](ctx, strings.NewReader("1-5 8"), TextRecords, 1, t.TempDir()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Unexpected error with a cancelled context: %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package intervaltree
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// rwLocker is the set of methods used to synchronize access to a Tree.
+// sync.RWMutex implements it.
+type rwLocker interface {
+	Lock()
+	Unlock()
+	RLock()
+	RUnlock()
+}
+
+// noLocker is a rwLocker that does nothing.
+type noLocker struct{}
+
+func (noLocker) Lock()          {}
+func (noLocker) Unlock()        {}
+func (noLocker) RLock()         {}
+func (noLocker) RUnlock()       {}
+func (noLocker) TryLock() bool  { return true }
+func (noLocker) TryRLock() bool { return true }
+
+// exclusiveLocker is a rwLocker that takes a sync.Locker for reading too.
+type exclusiveLocker struct {
+	sync.Locker
+}
+
+func (l exclusiveLocker) RLock()         { l.Lock() }
+func (l exclusiveLocker) RUnlock()       { l.Unlock() }
+func (l exclusiveLocker) TryRLock() bool { return l.TryLock() }
+
+// TryLock tries to lock l and reports whether it succeeded. If the underlying
+// sync.Locker has no TryLock method it blocks until locked.
+func (l exclusiveLocker) TryLock() bool {
+	if tl, ok := l.Locker.(interface{ TryLock() bool }); ok {
+		return tl.TryLock()
+	}
+	l.Lock()
+	return true
+}
+
+// Lock locks the tree for writing.
+func (t *Tree[T]) Lock() {
+	if t.locker != nil {
+		t.locker.Lock()
+		return
+	}
+	t.mu.Lock()
+}
+
+// Unlock unlocks the tree for writing.
+func (t *Tree[T]) Unlock() {
+	if t.locker != nil {
+		t.locker.Unlock()
+		return
+	}
+	t.mu.Unlock()
+}
+
+// RLock locks the tree for reading.
+func (t *Tree[T]) RLock() {
+	if t.locker != nil {
+		t.locker.RLock()
+		return
+	}
+	t.mu.RLock()
+}
+
+// RUnlock undoes a single RLock call.
+func (t *Tree[T]) RUnlock() {
+	if t.locker != nil {
+		t.locker.RUnlock()
+		return
+	}
+	t.mu.RUnlock()
+}
+
+// rlockPair locks t and o for reading, in the order of their addresses so
+// that readers of the same two trees cannot deadlock each other through a
+// waiting writer, and only once if they share a lock.
+func rlockPair[T Integer](t, o *Tree[T]) {
+	if uintptr(unsafe.Pointer(o)) < uintptr(unsafe.Pointer(t)) {
+		t, o = o, t
+	}
+	t.RLock()
+	if !sharesLock(t, o) {
+		o.RLock()
+	}
+}
+
+// runlockPair undoes a single rlockPair call.
+func runlockPair[T Integer](t, o *Tree[T]) {
+	if !sharesLock(t, o) {
+		o.RUnlock()
+	}
+	t.RUnlock()
+}
+
+// sharesLock reports whether t and o are synchronized through the same lock.
+func sharesLock[T Integer](t, o *Tree[T]) bool {
+	return t == o || t.locker != nil && t.locker == o.locker
+}
+
+// TryLock tries to lock the tree for writing and reports whether it succeeded.
+// If the locker set with WithLocker has no TryLock method it blocks until
+// locked.
+func (t *Tree[T]) TryLock() bool {
+	if t.locker == nil {
+		return t.mu.TryLock()
+	}
+	if l, ok := t.locker.(interface{ TryLock() bool }); ok {
+		return l.TryLock()
+	}
+	t.locker.Lock()
+	return true
+}
+
+// TryRLock tries to lock the tree for reading and reports whether it
+// succeeded. If the locker set with WithLocker has no TryRLock method it
+// blocks until locked.
+func (t *Tree[T]) TryRLock() bool {
+	if t.locker == nil {
+		return t.mu.TryRLock()
+	}
+	if l, ok := t.locker.(interface{ TryRLock() bool }); ok {
+		return l.TryRLock()
+	}
+	t.locker.RLock()
+	return true
+}
+
+// TryInsert adds an interval to the tree as Insert does, unless the tree is
+// locked. It returns false without waiting if it could not lock the tree, in
+// which case the attempt is neither timed nor traced. Having locked the tree,
+// it inserts the interval itself rather than through WithGroupCommit.
+func (t *Tree[T]) TryInsert(x, y T) (_ bool, err error) {
+	if !t.TryLock() {
+		return false, nil
+	}
+	m := t.observe("intervaltree.TryInsert", x, y)
+	defer func() { t.observed(m, insertTime, err) }()
+	defer t.Unlock()
+
+	if x > y {
+		return true, t.failed(insertErrors, x, y, InvalidIntervalError[T]{x, y})
+	}
+	return true, t.insertObserved(m, x, y)
+}
+
+// TryContains checks if x is contained in the tree as Contains does, unless
+// the tree is locked for writing. Its second result is false if it could not
+// lock the tree, in which case it returned without waiting.
+func (t *Tree[T]) TryContains(x T) (bool, bool) {
+	if !t.TryRLock() {
+		return false, false
+	}
+	defer t.RUnlock()
+	return t.contains(x), true
+}
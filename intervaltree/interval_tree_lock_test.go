@@ -0,0 +1,103 @@
+package intervaltree
+
+import (
+	"sync"
+	"testing"
+)
+
+// countingLocker is a sync.Locker counting the times it is locked.
+type countingLocker struct {
+	sync.Mutex
+	locks int
+}
+
+func (l *countingLocker) Lock() {
+	l.Mutex.Lock()
+	l.locks++
+}
+
+func TestWithLocker(t *testing.T) {
+	l := &countingLocker{}
+	it := New(WithLocker(l))
+	it.Insert(1, 5)
+	it.Contains(3)
+	it.Next(3)
+	if l.locks != 3 {
+		t.Fatalf("Locker was used %d times, expected 3", l.locks)
+	}
+
+	// A shared lock excludes other users of it
+	l.Lock()
+	done := make(chan struct{})
+	go func() {
+		it.Contains(3)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Tree was read while its locker was held")
+	default:
+	}
+	l.Unlock()
+	<-done
+}
+
+func TestWithRWLocker(t *testing.T) {
+	var mu sync.RWMutex
+	it := New(WithLocker(&mu))
+	it.Insert(1, 5)
+
+	mu.RLock()
+	if !it.Contains(3) { // Readers share the lock
+		t.Fatal("Unexpected contents")
+	}
+	mu.RUnlock()
+}
+
+func TestTryInsertAndContains(t *testing.T) {
+	it := New()
+	if ok, err := it.TryInsert(1, 5); !ok || err != nil {
+		t.Fatalf("Failed to insert into unlocked tree: %v, %v", ok, err)
+	}
+
+	it.RLock()
+	if contained, ok := it.TryContains(3); !ok || !contained {
+		t.Fatal("Failed to read tree locked for reading")
+	}
+	if ok, _ := it.TryInsert(7, 9); ok {
+		t.Fatal("Inserted into tree locked for reading")
+	}
+	it.RUnlock()
+
+	it.Lock()
+	if _, ok := it.TryContains(3); ok {
+		t.Fatal("Read tree locked for writing")
+	}
+	it.Unlock()
+
+	if ok, err := it.TryInsert(3, 4); !ok || err == nil {
+		t.Fatalf("Inserted overlapping interval: %v, %v", ok, err)
+	}
+	if contained, ok := it.TryContains(8); !ok || contained {
+		t.Fatal("Failed insert changed the tree")
+	}
+}
+
+func TestTryInsertAndContainsInstrumented(t *testing.T) {
+	tr := &recordingTracer{}
+	it := New(WithMetrics(), WithTracer(tr), WithFinger())
+	it.TryInsert(1, 5)
+	it.TryInsert(4, 2)
+	it.TryContains(3)
+	it.TryContains(4)
+
+	if m := it.Metrics(); m.Inserts != 1 || m.InsertErrors != 1 || m.Lookups != 2 || m.InsertTime == 0 {
+		t.Fatalf("Unexpected metrics: %+v", m)
+	}
+	if len(tr.spans) != 2 || tr.spans[0].op != "intervaltree.TryInsert" || tr.spans[0].err != nil || tr.spans[1].err == nil {
+		t.Fatalf("Unexpected spans: %+v", tr.spans)
+	}
+	if f := it.finger.Load(); f == nil || f.from != 1 || f.j != 5 {
+		t.Fatal("TryContains did not use the finger")
+	}
+}
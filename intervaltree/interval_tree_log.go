@@ -0,0 +1,35 @@
+package intervaltree
+
+import (
+	"context"
+	"log/slog"
+)
+
+// failed counts and logs the error err of the operation on [x, y] counted by
+// k, and returns it.
+func (t *Tree[T]) failed(k counter, x, y T, err error) error {
+	t.counters.inc(k)
+	if t.logger != nil {
+		msg := "Insert failed"
+		if k == removeErrors {
+			msg = "Remove failed"
+		}
+		t.log(msg, slog.Any("start", x), slog.Any("end", y), slog.Any("error", err))
+	}
+	return err
+}
+
+// logInserted logs the insertion of [x, y], along with the interval holding it
+// if it was joined with its neighbors.
+func (t *Tree[T]) logInserted(x, y T) {
+	attrs := []slog.Attr{slog.Any("start", x), slog.Any("end", y)}
+	if c := t.root.containingNode(x); c.I != x || c.J != y {
+		attrs = append(attrs, slog.Any("joined_start", c.I), slog.Any("joined_end", c.J))
+	}
+	t.log("Interval inserted", attrs...)
+}
+
+// log writes msg and attrs to the logger of the tree at debug level.
+func (t *Tree[T]) log(msg string, attrs ...slog.Attr) {
+	t.logger.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
+}
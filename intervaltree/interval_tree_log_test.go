@@ -0,0 +1,38 @@
+package intervaltree
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	it := New(WithLogger(slog.New(h)))
+	it.Insert(1, 5)
+	it.Insert(6, 9)
+	it.Insert(3, 4)
+	it.Remove(2, 3)
+	it.Remove(20, 20)
+
+	expected := strings.Join([]string{
+		`level=DEBUG msg="Interval inserted" start=1 end=5`,
+		`level=DEBUG msg="Interval inserted" start=6 end=9 joined_start=1 joined_end=9`,
+		`level=DEBUG msg="Insert failed" start=3 end=4 error="Tried to insert value already inserted: 3"`,
+		`level=DEBUG msg="Interval removed" start=2 end=3`,
+		`level=DEBUG msg="Remove failed" start=20 end=20 error="Tried to remove value not contained: 20"`,
+	}, "\n") + "\n"
+	if buf.String() != expected {
+		t.Fatalf("Unexpected log:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
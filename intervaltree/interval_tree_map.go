@@ -0,0 +1,148 @@
+package intervaltree
+
+import (
+	"cmp"
+	"sync"
+)
+
+// IntervalMap represents a map from the values in a set of intervals of type T
+// to values of type V. As in Tree, intervals cannot overlap, but adjacent
+// intervals are joined only when the values attached to them can be merged.
+type IntervalMap[T Integer, V any] struct {
+	root  *discreteNode[T, V]
+	merge func(a, b V) (V, bool)
+	sync.RWMutex
+}
+
+// Entry represents an interval in an IntervalMap and the value attached to it.
+type Entry[T Integer, V any] struct {
+	Interval[T]
+	Value V
+}
+
+// NewMap returns a pointer to an empty IntervalMap in which adjacent intervals
+// are joined when they hold equal values.
+func NewMap[T Integer, V comparable]() *IntervalMap[T, V] {
+	return NewMapFunc[T](func(a, b V) (V, bool) {
+		return a, a == b
+	})
+}
+
+// NewMapFunc returns a pointer to an empty IntervalMap in which adjacent
+// intervals are joined according to merge. Given the values a and b attached
+// to an interval and to the one following it, merge returns the value for the
+// joined interval and whether they can be joined.
+func NewMapFunc[T Integer, V any](merge func(a, b V) (V, bool)) *IntervalMap[T, V] {
+	return &IntervalMap[T, V]{merge: merge}
+}
+
+// Insert attaches v to the values in [x, y]. The interval cannot overlap with
+// the map. It is joined with its neighbours if their values can be merged.
+func (m *IntervalMap[T, V]) Insert(x, y T, v V) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	l, r := m.root.floor(cmp.Compare[T], x), m.root.higher(cmp.Compare[T], x)
+	if l != nil && l.J >= x {
+		return OverlapError[T]{x, Interval[T]{x, y}, Interval[T]{l.I, l.J}}
+	}
+	if r != nil && r.I <= y {
+		return OverlapError[T]{r.I, Interval[T]{x, y}, Interval[T]{r.I, r.J}}
+	}
+
+	if l != nil && l.J+1 == x {
+		if lv, ok := m.merge(l.Value, v); ok {
+			l.J, l.Value = y, lv
+			if r != nil && r.I-1 == y {
+				if rv, ok := m.merge(l.Value, r.Value); ok { // Fill the gap between l and r
+					j := r.J
+					m.root.remove(cmp.Compare[T], r.I, &m.root)
+					l.J, l.Value = j, rv
+				}
+			}
+			return nil
+		}
+	}
+	if r != nil && r.I-1 == y {
+		if rv, ok := m.merge(v, r.Value); ok {
+			r.I, r.Value = x, rv
+			return nil
+		}
+	}
+
+	m.root.insert(cmp.Compare[T], x, y, v, &m.root)
+	return nil
+}
+
+// Remove deletes the values in [x, y] from the map. Every value in [x, y] must
+// be contained in the map. Stored intervals are shrunk or split as needed.
+func (m *IntervalMap[T, V]) Remove(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	// Adjacent intervals may hold different values, so [x, y] may span many
+	var entries []Entry[T, V]
+	m.root.walkRange(cmp.Compare[T], x, y, func(n *discreteNode[T, V]) {
+		entries = append(entries, Entry[T, V]{Interval[T]{n.I, n.J}, n.Value})
+	})
+
+	next, covered := x, false // Least value not found contained yet
+	for _, e := range entries {
+		if e.I > next {
+			break
+		}
+		if e.J >= y {
+			covered = true
+			break
+		}
+		next = e.J + 1
+	}
+	if !covered {
+		return NotContainedError[T]{next}
+	}
+
+	for _, e := range entries {
+		m.root.remove(cmp.Compare[T], e.I, &m.root)
+		if e.I < x {
+			m.root.insert(cmp.Compare[T], e.I, x-1, e.Value, &m.root)
+		}
+		if e.J > y {
+			m.root.insert(cmp.Compare[T], y+1, e.J, e.Value, &m.root)
+		}
+	}
+	return nil
+}
+
+// Get returns the value attached to x, and whether x is contained in the map.
+func (m *IntervalMap[T, V]) Get(x T) (V, bool) {
+	m.RLock()
+	defer m.RUnlock()
+
+	c := m.root.floor(cmp.Compare[T], x)
+	if c == nil || c.J < x {
+		var zero V
+		return zero, false
+	}
+	return c.Value, true
+}
+
+// Entries returns the intervals in the map that overlap [x, y] and their
+// values, in ascending order.
+func (m *IntervalMap[T, V]) Entries(x, y T) []Entry[T, V] {
+	m.RLock()
+	defer m.RUnlock()
+
+	var ret []Entry[T, V]
+	m.root.walkRange(cmp.Compare[T], x, y, func(n *discreteNode[T, V]) {
+		ret = append(ret, Entry[T, V]{Interval[T]{n.I, n.J}, n.Value})
+	})
+	return ret
+}
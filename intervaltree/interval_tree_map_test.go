@@ -0,0 +1,68 @@
+package intervaltree
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIntervalMap(t *testing.T) {
+	m := NewMap[uint64, string]()
+	m.Insert(10, 19, "a")
+	m.Insert(20, 29, "a")
+	m.Insert(30, 39, "b")
+	m.Insert(0, 9, "b")
+	if s := fmt.Sprint(m.Entries(0, 100)); s != "[{{0 9} b} {{10 29} a} {{30 39} b}]" {
+		t.Fatalf("Unexpected entries: %s", s)
+	}
+
+	m.Insert(50, 59, "c")
+	m.Insert(41, 49, "c")
+	m.Insert(40, 40, "c")
+	if s := fmt.Sprint(m.Entries(35, 100)); s != "[{{30 39} b} {{40 59} c}]" {
+		t.Fatalf("Unexpected entries: %s", s)
+	}
+
+	if v, ok := m.Get(25); !ok || v != "a" {
+		t.Fatalf("Get(25) = %q, %v", v, ok)
+	}
+	if _, ok := m.Get(60); ok {
+		t.Fatal("Get(60) found a value")
+	}
+	if err := m.Insert(5, 12, "d"); err != (OverlapError[uint64]{5, Interval[uint64]{5, 12}, Interval[uint64]{0, 9}}) {
+		t.Fatalf("Unexpected error inserting overlapping interval: %v", err)
+	}
+
+	if err := m.Remove(5, 35); err != nil {
+		t.Fatalf("Failed to remove [5, 35] across values: %v", err)
+	}
+	if s := fmt.Sprint(m.Entries(0, 100)); s != "[{{0 4} b} {{36 39} b} {{40 59} c}]" {
+		t.Fatalf("Unexpected entries after removal: %s", s)
+	}
+	if err := m.Remove(3, 37); err != (NotContainedError[uint64]{5}) {
+		t.Fatalf("Unexpected error removing over a gap: %v", err)
+	}
+	if err := m.Remove(50, 60); err != (NotContainedError[uint64]{60}) {
+		t.Fatalf("Unexpected error removing past the end: %v", err)
+	}
+}
+
+func TestIntervalMapFunc(t *testing.T) {
+	// Owners merge when equal, counting how many intervals were joined
+	type owner struct {
+		name  string
+		count int
+	}
+	m := NewMapFunc[int](func(a, b owner) (owner, bool) {
+		return owner{a.name, a.count + b.count}, a.name == b.name
+	})
+	m.Insert(0, 4, owner{"x", 1})
+	m.Insert(10, 14, owner{"x", 1})
+	m.Insert(5, 9, owner{"x", 1})
+	if v, ok := m.Get(12); !ok || v.count != 3 || len(m.Entries(0, 20)) != 1 {
+		t.Fatalf("Intervals were not joined: %v", m.Entries(0, 20))
+	}
+	m.Insert(15, 20, owner{"y", 1})
+	if len(m.Entries(0, 20)) != 2 {
+		t.Fatalf("Intervals with different owners were joined: %v", m.Entries(0, 20))
+	}
+}
@@ -0,0 +1,236 @@
+package intervaltree
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sort"
+	"unsafe"
+)
+
+// A mapped file starts with a header page, followed by pages of intervals and
+// by an index holding the greatest value of every page of intervals. Intervals
+// are stored as the little-endian ordinals of their bounds, so a lookup binary
+// searches the index and then reads a single page of intervals.
+const (
+	mappedMagic     = "ITREEMAP"
+	mappedPageSize  = 4096
+	mappedEntrySize = 16 // Ordinals of the bounds of an interval
+	mappedPerPage   = mappedPageSize / mappedEntrySize
+)
+
+// mappedType identifies the type of the values in a mapped file.
+func mappedType[T Integer]() uint64 {
+	var x T
+	t := uint64(unsafe.Sizeof(x)) << 1
+	if signed[T]() {
+		t |= 1
+	}
+	return t
+}
+
+// MappedWriter writes a file that can be opened with OpenMapped, streaming
+// intervals to disk as they are added so the set never has to fit in memory.
+type MappedWriter[T Integer] struct {
+	f       *os.File
+	w       *bufio.Writer
+	count   uint64
+	last    []uint64 // Greatest value of each full page
+	pending Interval[T]
+	started bool
+	err     error // First write error
+}
+
+// CreateMapped creates the file at path, truncating it if it exists, and
+// returns a MappedWriter writing to it.
+func CreateMapped[T Integer](path string) (*MappedWriter[T], error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &MappedWriter[T]{f: f, w: bufio.NewWriterSize(f, 1<<16)}
+	w.w.Write(make([]byte, mappedPageSize)) // Header, written on Close
+	return w, nil
+}
+
+// Add appends [x, y] to the file. Intervals must be added in ascending order
+// and cannot overlap; adjacent intervals are joined.
+func (w *MappedWriter[T]) Add(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+	if w.started {
+		if x <= w.pending.J {
+			return OverlapError[T]{x, Interval[T]{x, y}, w.pending}
+		}
+		if x-1 == w.pending.J {
+			w.pending.J = y
+			return nil
+		}
+		w.write(w.pending)
+	}
+	w.pending, w.started = Interval[T]{x, y}, true
+	return w.err
+}
+
+// write appends i to the pages of intervals.
+func (w *MappedWriter[T]) write(i Interval[T]) {
+	var buf [mappedEntrySize]byte
+	binary.LittleEndian.PutUint64(buf[:8], ordinal(i.I))
+	binary.LittleEndian.PutUint64(buf[8:], ordinal(i.J))
+	if _, err := w.w.Write(buf[:]); err != nil && w.err == nil {
+		w.err = err
+	}
+
+	w.count++
+	if w.count%mappedPerPage == 0 {
+		w.last = append(w.last, ordinal(i.J))
+	}
+}
+
+// Close writes the last interval, the index and the header, and closes the
+// file.
+func (w *MappedWriter[T]) Close() error {
+	if w.started {
+		w.write(w.pending)
+	}
+	if w.count%mappedPerPage != 0 { // Last page is partial
+		w.last = append(w.last, ordinal(w.pending.J))
+	}
+	if pad := (mappedPerPage - w.count%mappedPerPage) % mappedPerPage; pad > 0 {
+		w.w.Write(make([]byte, pad*mappedEntrySize))
+	}
+
+	var buf [8]byte
+	for _, l := range w.last {
+		binary.LittleEndian.PutUint64(buf[:], l)
+		w.w.Write(buf[:])
+	}
+	if err := w.w.Flush(); err != nil && w.err == nil {
+		w.err = err
+	}
+
+	header := make([]byte, 0, 32)
+	header = append(header, mappedMagic...)
+	header = binary.LittleEndian.AppendUint64(header, mappedType[T]())
+	header = binary.LittleEndian.AppendUint64(header, w.count)
+	if _, err := w.f.WriteAt(header, 0); err != nil && w.err == nil {
+		w.err = err
+	}
+	if err := w.f.Sync(); err != nil && w.err == nil {
+		w.err = err
+	}
+	if err := w.f.Close(); err != nil && w.err == nil {
+		w.err = err
+	}
+	return w.err
+}
+
+// MappedTree represents a read-only set of intervals held in a memory-mapped
+// file written by MappedWriter. Pages are loaded by the operating system as
+// they are touched, so the set can be much larger than the available memory.
+// It needs no locking.
+type MappedTree[T Integer] struct {
+	data    []byte // Mapped file
+	count   int    // Number of intervals
+	indexAt int    // Offset of the index
+}
+
+// OpenMapped maps the file at path, which must have been written by a
+// MappedWriter of the same type.
+func OpenMapped[T Integer](path string) (*MappedTree[T], error) {
+	data, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < mappedPageSize || string(data[:8]) != mappedMagic {
+		unmapFile(data)
+		return nil, fmt.Errorf("Malformed mapped file: bad header")
+	}
+	if t := binary.LittleEndian.Uint64(data[8:]); t != mappedType[T]() {
+		unmapFile(data)
+		return nil, fmt.Errorf("Mapped file holds values of another type")
+	}
+
+	count := binary.LittleEndian.Uint64(data[16:])
+	pages := (count + mappedPerPage - 1) / mappedPerPage
+	indexAt := mappedPageSize * (1 + pages)
+	if indexAt+8*pages != uint64(len(data)) {
+		unmapFile(data)
+		return nil, fmt.Errorf("Malformed mapped file: %d intervals in %d bytes", count, len(data))
+	}
+	return &MappedTree[T]{data: data, count: int(count), indexAt: int(indexAt)}, nil
+}
+
+// Close unmaps the file. The tree must not be used afterwards.
+func (m *MappedTree[T]) Close() error {
+	return unmapFile(m.data)
+}
+
+// entry returns the ordinals of the bounds of the k-th interval.
+func (m *MappedTree[T]) entry(k int) (uint64, uint64) {
+	off := mappedPageSize + k*mappedEntrySize
+	return binary.LittleEndian.Uint64(m.data[off:]), binary.LittleEndian.Uint64(m.data[off+8:])
+}
+
+// containing returns the index of the interval that contains x, or -1 if x is
+// not contained.
+func (m *MappedTree[T]) containing(x T) int {
+	o := ordinal(x)
+	pages := (m.count + mappedPerPage - 1) / mappedPerPage
+	p := sort.Search(pages, func(p int) bool {
+		return binary.LittleEndian.Uint64(m.data[m.indexAt+8*p:]) >= o
+	})
+	if p == pages {
+		return -1
+	}
+
+	first := p * mappedPerPage
+	n := min(mappedPerPage, m.count-first)
+	k := first + sort.Search(n, func(k int) bool {
+		_, j := m.entry(first + k)
+		return j >= o
+	})
+	if i, _ := m.entry(k); i > o {
+		return -1
+	}
+	return k
+}
+
+// Contains checks if x is contained in the tree.
+func (m *MappedTree[T]) Contains(x T) bool {
+	return m.containing(x) >= 0
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to x. It returns false if every such value is contained.
+func (m *MappedTree[T]) Next(x T) (T, bool) {
+	k := m.containing(x)
+	if k < 0 {
+		return x, true
+	}
+	_, j := m.entry(k)
+	y, _ := fromOrdinal[T](j)
+	return after(y)
+}
+
+// Len returns the number of intervals in the tree.
+func (m *MappedTree[T]) Len() int {
+	return m.count
+}
+
+// Walk calls fn for the intervals in the tree in ascending order. It stops as
+// soon as fn returns false.
+func (m *MappedTree[T]) Walk(fn func(x, y T) bool) {
+	for k := 0; k < m.count; k++ {
+		i, j := m.entry(k)
+		x, _ := fromOrdinal[T](i)
+		y, _ := fromOrdinal[T](j)
+		if !fn(x, y) {
+			return
+		}
+	}
+}
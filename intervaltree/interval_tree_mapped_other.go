@@ -0,0 +1,16 @@
+//go:build !unix
+
+package intervaltree
+
+import "os"
+
+// mapFile reads the file at path into memory, as memory mapping is not
+// supported on this platform.
+func mapFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// unmapFile releases data, as returned by mapFile.
+func unmapFile(data []byte) error {
+	return nil
+}
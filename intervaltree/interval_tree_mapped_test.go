@@ -0,0 +1,88 @@
+package intervaltree
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMapped(t *testing.T) {
+	for _, n := range []int{0, 1, mappedPerPage - 1, mappedPerPage, 3*mappedPerPage + 7} {
+		path := filepath.Join(t.TempDir(), "tree")
+		w, err := CreateMapped[int32](path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ref := NewTree[int32]()
+		for k := 0; k < n; k++ {
+			x := int32(k*10 - 1000)
+			if err := w.Add(x, x+4); err != nil {
+				t.Fatal(err)
+			}
+			ref.Insert(x, x+4)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		m, err := OpenMapped[int32](path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Len() != n || walkString[int32](m) != walkString[int32](ref) {
+			t.Fatalf("Unexpected mapped tree of %d intervals: %d", n, m.Len())
+		}
+		r := rand.New(rand.NewSource(int64(n)))
+		for k := 0; k < 1000; k++ {
+			x := int32(r.Intn(n*10+2000) - 2000)
+			if m.Contains(x) != ref.Contains(x) || !sameNext(m, ref, x) {
+				t.Fatalf("Unexpected lookup of %d in %d intervals", x, n)
+			}
+		}
+		if err := m.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMappedWriterJoins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree")
+	w, err := CreateMapped[uint8](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Add(0, 10)
+	w.Add(11, 20)
+	if err := w.Add(15, 30); err == nil {
+		t.Fatal("Overlapping interval added")
+	}
+	w.Add(22, 255)
+	w.Close()
+
+	m, err := OpenMapped[uint8](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if s := walkString[uint8](m); s != "[0 -- 20][22 -- 255]" {
+		t.Fatalf("Unexpected mapped tree: %s", s)
+	}
+}
+
+func TestOpenMappedErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tree")
+	w, _ := CreateMapped[uint16](path)
+	w.Add(1, 2)
+	w.Close()
+	if _, err := OpenMapped[int16](path); err == nil {
+		t.Fatal("Opened file of another type")
+	}
+
+	garbage := filepath.Join(dir, "garbage")
+	os.WriteFile(garbage, []byte("not a tree"), 0o644)
+	if _, err := OpenMapped[uint16](garbage); err == nil {
+		t.Fatal("Opened malformed file")
+	}
+}
@@ -0,0 +1,25 @@
+package intervaltree
+
+import "unsafe"
+
+// MemoryUsage returns an estimate of the bytes retained by the tree: its
+// nodes, the deleted nodes kept for reuse, the journal of changes and the tree
+// itself, in O(1). Allocator overhead is not accounted for.
+func (t *Tree[T]) MemoryUsage() uint64 {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodes := uint64(t.root.getIntervals()) // Every interval is held by its own node
+	if t.pool != nil {
+		nodes += uint64(t.pool.size)
+	}
+
+	usage := uint64(unsafe.Sizeof(*t)) + nodes*uint64(unsafe.Sizeof(node[T]{}))
+	if t.pool != nil {
+		usage += uint64(unsafe.Sizeof(*t.pool))
+	}
+	if t.journal != nil {
+		usage += uint64(unsafe.Sizeof(*t.journal)) + uint64(cap(t.journal.changes))*uint64(unsafe.Sizeof(Change[T]{}))
+	}
+	return usage
+}
@@ -0,0 +1,39 @@
+package intervaltree
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestMemoryUsage(t *testing.T) {
+	it := New(WithNodeRecycling())
+	empty := it.MemoryUsage()
+	nodeSize := uint64(unsafe.Sizeof(node[uint64]{}))
+
+	for x := uint64(0); x < 100; x++ {
+		it.Insert(2*x, 2*x)
+	}
+	if u := it.MemoryUsage(); u != empty+100*nodeSize {
+		t.Fatalf("Unexpected usage with 100 nodes: %d", u)
+	}
+
+	// Joined nodes are kept for reuse, so usage does not shrink
+	for x := uint64(0); x < 100; x++ {
+		it.Insert(2*x+1, 2*x+1)
+	}
+	if u := it.MemoryUsage(); u != empty+100*nodeSize {
+		t.Fatalf("Unexpected usage after joining every node: %d", u)
+	}
+	for x := uint64(0); x < 50; x++ { // Reuses the kept nodes
+		it.Insert(300+2*x, 300+2*x)
+	}
+	if u := it.MemoryUsage(); u != empty+100*nodeSize {
+		t.Fatalf("Unexpected usage after reusing nodes: %d", u)
+	}
+
+	it.Checkpoint()
+	it.Insert(1000, 1000)
+	if u := it.MemoryUsage(); u <= empty+100*nodeSize {
+		t.Fatalf("Journal is not accounted for: %d", u)
+	}
+}
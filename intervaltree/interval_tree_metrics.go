@@ -0,0 +1,89 @@
+package intervaltree
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics holds the operation counters of a tree created with WithMetrics or
+// WithExpvar.
+type Metrics struct {
+	Inserts      uint64        // Successful insertions
+	Removes      uint64        // Successful removals
+	InsertErrors uint64        // Failed insertions
+	RemoveErrors uint64        // Failed removals
+	Lookups      uint64        // Calls to Contains and Next
+	InsertTime   time.Duration // Total time spent in Insert, allocations included
+	RemoveTime   time.Duration // Total time spent in Remove
+}
+
+// counter identifies one of the operation counters of a tree.
+type counter int
+
+// Operation counters.
+const (
+	inserts      counter = iota // Successful insertions
+	removes                     // Successful removals
+	insertErrors                // Failed insertions
+	removeErrors                // Failed removals
+	lookups                     // Calls to Contains and Next
+	insertTime                  // Nanoseconds spent in Insert
+	removeTime                  // Nanoseconds spent in Remove
+	numCounters
+)
+
+// counters holds the operation counters of a tree. They are updated
+// atomically, so readers holding the read lock can update them.
+type counters [numCounters]atomic.Uint64
+
+// inc increments the counter k. A nil counters ignores it.
+func (c *counters) inc(k counter) {
+	if c != nil {
+		c[k].Add(1)
+	}
+}
+
+// since adds the nanoseconds elapsed since start to the counter k.
+func (c *counters) since(k counter, start time.Time) {
+	c[k].Add(uint64(time.Since(start)))
+}
+
+// get returns the value of the counter k. A nil counters returns 0.
+func (c *counters) get(k counter) uint64 {
+	if c == nil {
+		return 0
+	}
+	return c[k].Load()
+}
+
+// Metrics returns the operation counters of the tree. They are all zero unless
+// the tree was created with WithMetrics or WithExpvar.
+func (t *Tree[T]) Metrics() Metrics {
+	return Metrics{
+		Inserts:      t.counters.get(inserts),
+		Removes:      t.counters.get(removes),
+		InsertErrors: t.counters.get(insertErrors),
+		RemoveErrors: t.counters.get(removeErrors),
+		Lookups:      t.counters.get(lookups),
+		InsertTime:   time.Duration(t.counters.get(insertTime)),
+		RemoveTime:   time.Duration(t.counters.get(removeTime)),
+	}
+}
+
+// publishExpvar publishes the counters, the number of intervals and the height
+// of the tree as the expvar variable name.
+func (t *Tree[T]) publishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		m, s := t.Metrics(), t.Stats()
+		return map[string]uint64{
+			"inserts":       m.Inserts,
+			"removes":       m.Removes,
+			"insert_errors": m.InsertErrors,
+			"remove_errors": m.RemoveErrors,
+			"lookups":       m.Lookups,
+			"intervals":     uint64(s.Intervals),
+			"height":        uint64(s.Height),
+		}
+	}))
+}
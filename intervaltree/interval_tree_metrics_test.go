@@ -0,0 +1,54 @@
+package intervaltree
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestExpvar(t *testing.T) {
+	it := New(WithExpvar("intervaltree_test"))
+	it.Insert(1, 5)
+	it.Insert(7, 9)
+	it.Insert(3, 4)
+	it.Insert(5, 4)
+	it.Remove(2, 3)
+	it.Remove(20, 30)
+	it.Contains(1)
+	it.Next(1)
+
+	var vars map[string]uint64
+	if err := json.Unmarshal([]byte(expvar.Get("intervaltree_test").String()), &vars); err != nil {
+		t.Fatalf("Failed to decode published variable: %v", err)
+	}
+	expected := map[string]uint64{
+		"inserts": 2, "removes": 1, "insert_errors": 2, "remove_errors": 1,
+		"lookups": 2, "intervals": 3, "height": 2,
+	}
+	for k, v := range expected {
+		if vars[k] != v {
+			t.Fatalf("Unexpected %s: %d, expected %d", k, vars[k], v)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	it := New()
+	it.Insert(1, 5)
+	if m := it.Metrics(); m != (Metrics{}) {
+		t.Fatalf("Unexpected metrics without WithMetrics: %+v", m)
+	}
+
+	it = New(WithMetrics())
+	it.Insert(1, 5)
+	it.Insert(2, 3)
+	it.Remove(1, 1)
+	it.Contains(3)
+	m := it.Metrics()
+	if m.Inserts != 1 || m.InsertErrors != 1 || m.Removes != 1 || m.Lookups != 1 {
+		t.Fatalf("Unexpected metrics: %+v", m)
+	}
+	if m.InsertTime <= 0 || m.RemoveTime <= 0 {
+		t.Fatalf("Unexpected times: %+v", m)
+	}
+}
@@ -0,0 +1,36 @@
+package intervaltree
+
+// lower returns the node holding the greatest interval starting before x in the
+// subtree rooted at n, or nil if there is none.
+func (n *node[T]) lower(x T) *node[T] {
+	var ret *node[T]
+	for n != nil {
+		if n.I < x {
+			ret, n = n, n.Right
+		} else {
+			n = n.Left
+		}
+	}
+	return ret
+}
+
+// Neighbors returns the nearest interval in the tree below x and the nearest one
+// above it, each with whether there is one, in O( log n ). If x is contained,
+// they are the neighbors of the interval containing it.
+func (t *Tree[T]) Neighbors(x T) (below Interval[T], hasBelow bool, above Interval[T], hasAbove bool) {
+	t.RLock()
+	defer t.RUnlock()
+	t.counters.inc(lookups)
+
+	lo, hi := x, x
+	if c := t.root.containingNode(x); c != nil {
+		lo, hi = c.I, c.J
+	}
+	if n := t.root.lower(lo); n != nil {
+		below, hasBelow = Interval[T]{n.I, n.J}, true
+	}
+	if n := t.root.higher(hi); n != nil {
+		above, hasAbove = Interval[T]{n.I, n.J}, true
+	}
+	return below, hasBelow, above, hasAbove
+}
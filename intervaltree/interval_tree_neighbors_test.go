@@ -0,0 +1,35 @@
+package intervaltree
+
+import "testing"
+
+func TestNeighbors(t *testing.T) {
+	it := NewTree[int8]()
+	if _, okB, _, okA := it.Neighbors(0); okB || okA {
+		t.Fatal("Neighbors found in an empty tree")
+	}
+
+	it.Insert(-128, -100)
+	it.Insert(-10, 10)
+	it.Insert(50, 60)
+	it.Insert(100, 127)
+
+	none := Interval[int8]{}
+	for _, c := range []struct {
+		x            int8
+		below, above Interval[int8]
+		okB, okA     bool
+	}{
+		{-50, Interval[int8]{-128, -100}, Interval[int8]{-10, 10}, true, true},
+		{0, Interval[int8]{-128, -100}, Interval[int8]{50, 60}, true, true},
+		{11, Interval[int8]{-10, 10}, Interval[int8]{50, 60}, true, true},
+		{49, Interval[int8]{-10, 10}, Interval[int8]{50, 60}, true, true},
+		{99, Interval[int8]{50, 60}, Interval[int8]{100, 127}, true, true},
+		{127, Interval[int8]{50, 60}, none, true, false},
+		{-128, none, Interval[int8]{-10, 10}, false, true},
+	} {
+		below, okB, above, okA := it.Neighbors(c.x)
+		if below != c.below || okB != c.okB || above != c.above || okA != c.okA {
+			t.Fatalf("Neighbors(%d) = %v %v %v %v", c.x, below, okB, above, okA)
+		}
+	}
+}
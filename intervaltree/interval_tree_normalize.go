@@ -0,0 +1,29 @@
+package intervaltree
+
+import "slices"
+
+// Normalize returns the union of intervals, which may be unsorted, overlapping
+// or adjacent, as ascending, non adjacent intervals in a new slice, without
+// building a tree. It fails with an InvalidIntervalError if an interval has
+// bounds in the wrong order. intervals is not modified.
+func Normalize[T Integer](intervals []Interval[T]) ([]Interval[T], error) {
+	sorted, err := parallelSort(slices.Clone(intervals), 1)
+	if err != nil {
+		return nil, err
+	}
+	return coalesce(sorted), nil
+}
+
+// NewFromSorted returns a pointer to a Tree configured by opts holding
+// intervals, which must be valid, ascending and not adjacent, as Normalize
+// returns them. The tree is assembled in O(n), without sorting. It fails with
+// an InvalidIntervalError at the first interval breaking the order, or with a
+// CapacityError if they exceed a capacity set by WithCapacity.
+func NewFromSorted[T Integer](intervals []Interval[T], opts ...Option) (*Tree[T], error) {
+	for k, i := range intervals {
+		if i.I > i.J || (k > 0 && (i.I <= intervals[k-1].J || i.I-1 == intervals[k-1].J)) {
+			return nil, InvalidIntervalError[T]{i.I, i.J}
+		}
+	}
+	return buildChecked(intervals, opts)
+}
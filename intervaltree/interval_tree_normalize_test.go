@@ -0,0 +1,50 @@
+package intervaltree
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	raw := []Interval[int8]{{50, 60}, {-128, -120}, {10, 20}, {61, 70}, {15, 30}, {-119, -119}, {100, 100}}
+	n, err := Normalize(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := fmt.Sprint(n); s != "[{-128 -119} {10 30} {50 70} {100 100}]" {
+		t.Fatalf("Unexpected intervals: %s", s)
+	}
+	if raw[0] != (Interval[int8]{50, 60}) {
+		t.Fatal("Input was modified")
+	}
+	if n, err := Normalize[int8](nil); err != nil || len(n) != 0 {
+		t.Fatalf("Unexpected normalization of nothing: %v %v", n, err)
+	}
+	if _, err := Normalize([]Interval[int8]{{1, 2}, {5, 3}}); !errors.Is(err, ErrInvalidInterval) {
+		t.Fatalf("Expected an invalid interval error, got %v", err)
+	}
+
+	it, err := NewFromSorted(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := it.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := BuildTree(raw, 1)
+	if it.ToString() != expected.ToString() {
+		t.Fatalf("Unexpected tree: %s", it.ToString())
+	}
+
+	for _, unsorted := range [][]Interval[int8]{
+		{{10, 20}, {5, 8}},
+		{{10, 20}, {15, 30}},
+		{{10, 20}, {21, 30}},
+		{{10, 5}},
+	} {
+		if _, err := NewFromSorted(unsorted); !errors.Is(err, ErrInvalidInterval) {
+			t.Fatalf("Built a tree from %v: %v", unsorted, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package intervaltree
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Option configures a Tree when it is created.
+type Option func(*config)
+
+// config holds the settings applied by options.
+type config struct {
+	recycle    bool
+	balancing  bool
+	locker     rwLocker
+	metrics    bool
+	expvarName string
+	tracer     Tracer
+	logger     *slog.Logger
+	audit      io.Writer
+	compaction int
+	strategy   Strategy
+	capacity   uint64
+	capped     bool
+	clock      func() time.Time
+	remainders bool
+	overlaps   OverlapMode
+	progress   ProgressFunc
+	finger     bool
+	grouped    bool
+}
+
+// WithNodeRecycling makes the tree keep the nodes it deletes and reuse them
+// for later insertions, so steady-state workloads mixing inserts and removals
+// do not hammer the allocator. Kept nodes are never returned to the allocator.
+func WithNodeRecycling() Option {
+	return func(c *config) {
+		c.recycle = true
+	}
+}
+
+// WithoutLocking makes the tree skip all locking. Such a tree must not be used
+// from several goroutines at once, but saves the cost of uncontended locking
+// in tight single-goroutine loops.
+func WithoutLocking() Option {
+	return func(c *config) {
+		c.locker = noLocker{}
+	}
+}
+
+// WithLocker makes the tree synchronize through l instead of its own
+// RWMutex, for instance to share a lock with other structures or to wrap it
+// with metrics. If l also has RLock and RUnlock methods, as sync.RWMutex does,
+// readers use them; otherwise readers take l exclusively.
+func WithLocker(l sync.Locker) Option {
+	return func(c *config) {
+		if rw, ok := l.(rwLocker); ok {
+			c.locker = rw
+		} else {
+			c.locker = exclusiveLocker{l}
+		}
+	}
+}
+
+// WithRemainders makes Insert fail with a PartialOverlapError, holding the
+// parts of the attempted interval that are still free, instead of an
+// OverlapError, so callers can insert what they can without probing with Next.
+func WithRemainders() Option {
+	return func(c *config) {
+		c.remainders = true
+	}
+}
+
+// WithMetrics makes the tree count its operations and the time spent in
+// Insert and Remove, as reported by Metrics.
+func WithMetrics() Option {
+	return func(c *config) {
+		c.metrics = true
+	}
+}
+
+// WithExpvar enables metrics and publishes the operation counts, the number of
+// intervals and the height of the tree as the expvar variable name, so it shows
+// up in /debug/vars. Like expvar.Publish, it panics if name is already in use.
+func WithExpvar(name string) Option {
+	return func(c *config) {
+		c.expvarName = name
+	}
+}
+
+// WithLogger makes the tree log every mutation, including the intervals an
+// insertion was joined with, and every failed one to l at debug level, for
+// post-incident reconstruction of allocator behavior.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = l
+	}
+}
@@ -0,0 +1,252 @@
+package intervaltree
+
+import "sync"
+
+// OverlapTree represents a classical IntervalTree, in which intervals may
+// overlap and are never joined. Intervals containing a value are found with
+// Stab(x), and those overlapping an interval with QueryRange(x, y). Each node
+// keeps the greatest upper bound in its subtree so that queries skip subtrees
+// that cannot overlap.
+type OverlapTree[T Integer] struct {
+	root *overlapNode[T]
+	size int
+	sync.RWMutex
+}
+
+// overlapNode holds an interval [I, J], the greatest upper bound of the
+// intervals in its subtree and pointers to nodes holding intervals lesser and
+// greater than its own, intervals being ordered by I and then by J.
+type overlapNode[T Integer] struct {
+	I, J        T               // Interval bounds
+	MaxJ        T               // Greatest upper bound in the subtree
+	Left, Right *overlapNode[T] // Left and right children
+	height      uint8           // Nodes on the longest path to a leaf (for AVL retracing)
+}
+
+// NewOverlap returns a pointer to an empty OverlapTree.
+func NewOverlap[T Integer]() *OverlapTree[T] {
+	return &OverlapTree[T]{}
+}
+
+// less checks if [x, y] is ordered before the interval of this node.
+func (n *overlapNode[T]) less(x, y T) bool {
+	return x < n.I || x == n.I && y < n.J
+}
+
+// insert adds a node holding [x, y] to the subtree rooted at n.
+func (n *overlapNode[T]) insert(x, y T, nRef **overlapNode[T]) {
+	if n == nil {
+		*nRef = &overlapNode[T]{I: x, J: y, MaxJ: y, height: 1}
+		return
+	}
+
+	if n.less(x, y) {
+		n.Left.insert(x, y, &n.Left)
+	} else {
+		n.Right.insert(x, y, &n.Right)
+	}
+	n.rebalance(nRef)
+}
+
+// remove deletes a node holding [x, y] from the subtree rooted at n, and
+// reports whether one was found.
+func (n *overlapNode[T]) remove(x, y T, nRef **overlapNode[T]) bool {
+	if n == nil {
+		return false
+	}
+
+	if x == n.I && y == n.J {
+		switch {
+		case n.Left == nil:
+			*nRef = n.Right
+			return true
+		case n.Right == nil:
+			*nRef = n.Left
+			return true
+		}
+		next := n.Right.removeLeast(&n.Right)
+		n.I, n.J = next.I, next.J
+	} else if n.less(x, y) {
+		if !n.Left.remove(x, y, &n.Left) {
+			return false
+		}
+	} else if !n.Right.remove(x, y, &n.Right) {
+		return false
+	}
+
+	n.rebalance(nRef)
+	return true
+}
+
+// removeLeast deletes the node holding the least interval from the subtree
+// rooted at n and returns it.
+func (n *overlapNode[T]) removeLeast(nRef **overlapNode[T]) *overlapNode[T] {
+	if n.Left == nil {
+		*nRef = n.Right
+		return n
+	}
+
+	least := n.Left.removeLeast(&n.Left)
+	n.rebalance(nRef)
+	return least
+}
+
+// query calls fn recursively for the intervals of this node and its children
+// that overlap [x, y], in ascending order. It stops as soon as fn returns
+// false, and reports whether the query was completed.
+func (n *overlapNode[T]) query(x, y T, fn func(i, j T) bool) bool {
+	if n == nil || n.MaxJ < x { // Every interval in the subtree ends before x
+		return true
+	}
+
+	if !n.Left.query(x, y, fn) {
+		return false
+	}
+	if n.I > y { // This and the following intervals start after y
+		return true
+	}
+	if n.J >= x && !fn(n.I, n.J) {
+		return false
+	}
+	return n.Right.query(x, y, fn)
+}
+
+// rebalance fixes AVL invariants violations by applying rotations.
+func (n *overlapNode[T]) rebalance(nRef **overlapNode[T]) {
+	bal := n.balanceFactor()
+	if bal == 2 {
+		if n.Left.balanceFactor() < 0 {
+			n.Left.rotateRight(&n.Left)
+		}
+		n.rotateLeft(nRef)
+		return
+	} else if bal == -2 {
+		if n.Right.balanceFactor() > 0 {
+			n.Right.rotateLeft(&n.Right)
+		}
+		n.rotateRight(nRef)
+		return
+	}
+
+	n.update()
+}
+
+// update recalculates the height and the greatest upper bound of this node
+// from its children.
+func (n *overlapNode[T]) update() {
+	n.height = max(n.Left.getHeight(), n.Right.getHeight()) + 1
+	n.MaxJ = n.J
+	if n.Left != nil && n.Left.MaxJ > n.MaxJ {
+		n.MaxJ = n.Left.MaxJ
+	}
+	if n.Right != nil && n.Right.MaxJ > n.MaxJ {
+		n.MaxJ = n.Right.MaxJ
+	}
+}
+
+// balanceFactor calculates the balance factor for this node.
+func (n *overlapNode[T]) balanceFactor() int8 {
+	return int8(n.Left.getHeight() - n.Right.getHeight())
+}
+
+// getHeight returns the number of nodes in the longest path to a leaf
+func (n *overlapNode[T]) getHeight() uint8 {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+// rotateLeft performs a left tree rotation.
+func (n *overlapNode[T]) rotateLeft(nRef **overlapNode[T]) {
+	pivot := n.Left
+	n.Left = n.Left.Right
+	pivot.Right = n
+	*nRef = pivot
+	n.update()
+	pivot.update()
+}
+
+// rotateRight performs a right tree rotation.
+func (n *overlapNode[T]) rotateRight(nRef **overlapNode[T]) {
+	pivot := n.Right
+	n.Right = n.Right.Left
+	pivot.Left = n
+	*nRef = pivot
+	n.update()
+	pivot.update()
+}
+
+// Insert adds [x, y] to the tree. It may overlap or be equal to intervals
+// already in the tree.
+func (t *OverlapTree[T]) Insert(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	t.root.insert(x, y, &t.root)
+	t.size++
+	return nil
+}
+
+// Remove deletes one occurrence of [x, y] from the tree, which must hold it.
+func (t *OverlapTree[T]) Remove(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	if !t.root.remove(x, y, &t.root) {
+		return NotContainedError[Interval[T]]{Interval[T]{x, y}}
+	}
+	t.size--
+	return nil
+}
+
+// Stab returns the intervals in the tree that contain x, in ascending order.
+func (t *OverlapTree[T]) Stab(x T) []Interval[T] {
+	return t.QueryRange(x, x)
+}
+
+// QueryRange returns the intervals in the tree that overlap [x, y], in
+// ascending order.
+func (t *OverlapTree[T]) QueryRange(x, y T) []Interval[T] {
+	return t.QueryRangeLimit(x, y, -1)
+}
+
+// StabLimit returns up to limit intervals in the tree that contain x, in
+// ascending order, or every one of them if limit is negative.
+func (t *OverlapTree[T]) StabLimit(x T, limit int) []Interval[T] {
+	return t.QueryRangeLimit(x, x, limit)
+}
+
+// QueryRangeLimit returns up to limit intervals in the tree that overlap
+// [x, y], in ascending order, or every one of them if limit is negative. The
+// traversal stops once limit intervals are found, so it takes O( log n + limit )
+// however many intervals overlap [x, y], for callers that only need a few
+// examples of conflicts.
+func (t *OverlapTree[T]) QueryRangeLimit(x, y T, limit int) []Interval[T] {
+	if limit == 0 {
+		return nil
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+
+	var ret []Interval[T]
+	t.root.query(x, y, func(i, j T) bool {
+		ret = append(ret, Interval[T]{i, j})
+		return len(ret) != limit
+	})
+	return ret
+}
+
+// Len returns the number of intervals in the tree.
+func (t *OverlapTree[T]) Len() int {
+	t.RLock()
+	defer t.RUnlock()
+	return t.size
+}
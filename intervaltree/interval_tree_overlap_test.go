@@ -0,0 +1,106 @@
+package intervaltree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func (n *overlapNode[T]) isAVL() error {
+	if n == nil {
+		return nil
+	}
+
+	maxJ := n.J
+	for _, c := range []*overlapNode[T]{n.Left, n.Right} {
+		if c != nil && c.MaxJ > maxJ {
+			maxJ = c.MaxJ
+		}
+	}
+	bal := n.balanceFactor()
+	if n.height != max(n.Left.getHeight(), n.Right.getHeight())+1 || bal > 1 || bal < -1 || n.MaxJ != maxJ {
+		return fmt.Errorf("Node [%d, %d] is inconsistent: height %d, balance factor %d, MaxJ %d", n.I, n.J, n.height, bal, n.MaxJ)
+	}
+
+	if err := n.Left.isAVL(); err != nil {
+		return err
+	}
+	return n.Right.isAVL()
+}
+
+func TestOverlapTree(t *testing.T) {
+	ot := NewOverlap[uint64]()
+	ot.Insert(10, 20)
+	ot.Insert(15, 25)
+	ot.Insert(15, 25)
+	ot.Insert(30, 40)
+	ot.Insert(0, 100)
+
+	if s := fmt.Sprint(ot.Stab(17)); s != "[{0 100} {10 20} {15 25} {15 25}]" {
+		t.Fatalf("Unexpected Stab(17): %s", s)
+	}
+	if s := fmt.Sprint(ot.QueryRange(21, 30)); s != "[{0 100} {15 25} {15 25} {30 40}]" {
+		t.Fatalf("Unexpected QueryRange(21, 30): %s", s)
+	}
+	if len(ot.Stab(101)) != 0 {
+		t.Fatal("Stab(101) found intervals")
+	}
+	if s := fmt.Sprint(ot.StabLimit(17, 2)); s != "[{0 100} {10 20}]" {
+		t.Fatalf("Unexpected StabLimit(17, 2): %s", s)
+	}
+	if s := fmt.Sprint(ot.QueryRangeLimit(21, 30, 3)); s != "[{0 100} {15 25} {15 25}]" {
+		t.Fatalf("Unexpected QueryRangeLimit(21, 30, 3): %s", s)
+	}
+	if s := fmt.Sprint(ot.QueryRangeLimit(21, 30, 10)); s != "[{0 100} {15 25} {15 25} {30 40}]" {
+		t.Fatalf("Unexpected QueryRangeLimit(21, 30, 10): %s", s)
+	}
+	if len(ot.QueryRangeLimit(0, 100, 0)) != 0 {
+		t.Fatal("QueryRangeLimit with limit 0 found intervals")
+	}
+
+	if err := ot.Remove(15, 25); err != nil || ot.Len() != 4 {
+		t.Fatalf("Failed to remove one occurrence of [15, 25]: %v", err)
+	}
+	if err := ot.Remove(15, 26); err == nil {
+		t.Fatal("Removed interval not in the tree")
+	}
+	if s := fmt.Sprint(ot.Stab(22)); s != "[{0 100} {15 25}]" {
+		t.Fatalf("Unexpected Stab(22) after removal: %s", s)
+	}
+}
+
+func TestOverlapTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	ot := NewOverlap[int]()
+	var ref []Interval[int]
+	for i := 0; i < 1000; i++ {
+		x := r.Intn(1000)
+		y := x + r.Intn(50)
+		if r.Intn(4) == 0 && len(ref) > 0 {
+			k := r.Intn(len(ref))
+			if err := ot.Remove(ref[k].I, ref[k].J); err != nil {
+				t.Fatalf("Failed to remove %v: %v", ref[k], err)
+			}
+			ref = append(ref[:k], ref[k+1:]...)
+		} else {
+			ot.Insert(x, y)
+			ref = append(ref, Interval[int]{x, y})
+		}
+
+		if err := ot.root.isAVL(); err != nil {
+			t.Fatalf("Tree is inconsistent after %d operations: %v", i, err)
+		}
+	}
+
+	for x := 0; x < 1100; x += 7 {
+		found := 0
+		for _, i := range ref {
+			if i.I <= x+5 && x <= i.J {
+				found++
+			}
+		}
+		if got := len(ot.QueryRange(x, x+5)); got != found {
+			t.Fatalf("QueryRange(%d, %d) found %d intervals, expected %d", x, x+5, got, found)
+		}
+	}
+}
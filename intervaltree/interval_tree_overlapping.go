@@ -0,0 +1,23 @@
+package intervaltree
+
+// Overlapping returns up to limit intervals in the tree holding values in
+// [x, y], in ascending order, or every one of them if limit is negative, such
+// as the allocations an insertion of [x, y] would collide with. The traversal
+// stops once limit intervals are found, so it takes O( log n + limit ) however
+// large the range is.
+func (t *Tree[T]) Overlapping(x, y T, limit int) []Interval[T] {
+	if x > y || limit == 0 {
+		return nil
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+	t.counters.inc(lookups)
+
+	var ret []Interval[T]
+	t.root.walkRange(x, y, func(i, j T) bool {
+		ret = append(ret, Interval[T]{i, j})
+		return len(ret) != limit
+	})
+	return ret
+}
@@ -0,0 +1,33 @@
+package intervaltree
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestOverlapping(t *testing.T) {
+	it := NewTree[int8]()
+	it.Insert(-128, -100)
+	it.Insert(-10, 10)
+	it.Insert(50, 60)
+	it.Insert(100, 127)
+
+	for _, c := range []struct {
+		x, y     int8
+		limit    int
+		expected string
+	}{
+		{-128, 127, -1, "[{-128 -100} {-10 10} {50 60} {100 127}]"},
+		{-128, 127, 2, "[{-128 -100} {-10 10}]"},
+		{0, 55, 1, "[{-10 10}]"},
+		{0, 55, 5, "[{-10 10} {50 60}]"},
+		{11, 49, -1, "[]"},
+		{127, 127, 1, "[{100 127}]"},
+		{10, 0, -1, "[]"},
+		{-128, 127, 0, "[]"},
+	} {
+		if s := fmt.Sprint(it.Overlapping(c.x, c.y, c.limit)); s != c.expected {
+			t.Fatalf("Unexpected Overlapping(%d, %d, %d): %s, expected %s", c.x, c.y, c.limit, s, c.expected)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package intervaltree
+
+// appendFrom appends to page, in ascending order, the intervals of the subtree
+// rooted at n starting after x, or at x too if inclusive, until page holds
+// limit intervals, and returns it.
+func (n *node[T]) appendFrom(x T, inclusive bool, limit int, page []Interval[T]) []Interval[T] {
+	for n != nil && len(page) < limit {
+		if n.I < x || (n.I == x && !inclusive) {
+			n = n.Right
+			continue
+		}
+
+		page = n.Left.appendFrom(x, inclusive, limit, page)
+		if len(page) < limit {
+			page = append(page, Interval[T]{n.I, n.J})
+		}
+		// Every interval to the right starts after x
+		x, inclusive = n.I, false
+		n = n.Right
+	}
+	return page
+}
+
+// IntervalsAfter returns up to limit intervals of the tree starting after x, in
+// ascending order, in O( log n + limit ). It pages through a large tree by
+// keyset: passing the start of the last interval of a page returns the next
+// one, which is empty past the last page, and each page is read under its own
+// short lock hold. The first page is returned by IntervalsFrom.
+func (t *Tree[T]) IntervalsAfter(x T, limit int) []Interval[T] {
+	return t.intervalsFrom(x, false, limit)
+}
+
+// IntervalsFrom returns up to limit intervals of the tree starting at or after
+// x, in ascending order, in O( log n + limit ), such as the first page of a
+// listing continued with IntervalsAfter.
+func (t *Tree[T]) IntervalsFrom(x T, limit int) []Interval[T] {
+	return t.intervalsFrom(x, true, limit)
+}
+
+// intervalsFrom returns up to limit intervals starting after x, or at x too if
+// inclusive.
+func (t *Tree[T]) intervalsFrom(x T, inclusive bool, limit int) []Interval[T] {
+	if limit <= 0 {
+		return nil
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+	t.counters.inc(lookups)
+	return t.root.appendFrom(x, inclusive, limit, make([]Interval[T], 0, min(limit, t.root.getIntervals())))
+}
@@ -0,0 +1,69 @@
+package intervaltree
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestIntervalsAfter(t *testing.T) {
+	it := NewTree[int8]()
+	if page := it.IntervalsFrom(-128, 10); len(page) != 0 {
+		t.Fatalf("Unexpected page of empty tree: %v", page)
+	}
+
+	it.Insert(-128, -100)
+	it.Insert(-10, 10)
+	it.Insert(50, 60)
+	it.Insert(100, 127)
+
+	for _, c := range []struct {
+		x         int8
+		inclusive bool
+		limit     int
+		expected  []Interval[int8]
+	}{
+		{-128, true, 2, []Interval[int8]{{-128, -100}, {-10, 10}}},
+		{-128, false, 2, []Interval[int8]{{-10, 10}, {50, 60}}},
+		{-10, false, 10, []Interval[int8]{{50, 60}, {100, 127}}},
+		{0, true, 1, []Interval[int8]{{50, 60}}},
+		{100, true, 5, []Interval[int8]{{100, 127}}},
+		{100, false, 5, nil},
+		{-128, true, 0, nil},
+	} {
+		var page []Interval[int8]
+		if c.inclusive {
+			page = it.IntervalsFrom(c.x, c.limit)
+		} else {
+			page = it.IntervalsAfter(c.x, c.limit)
+		}
+		if !slices.Equal(page, c.expected) {
+			t.Fatalf("Unexpected page from %d (inclusive %v, limit %d): %v", c.x, c.inclusive, c.limit, page)
+		}
+	}
+}
+
+func TestIntervalsAfterPaging(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	it := NewTree[uint16]()
+	for i := 0; i < 2000; i++ {
+		x := uint16(r.Intn(65000))
+		it.Insert(x, x+uint16(r.Intn(20)))
+	}
+	all := it.Freeze().intervals
+
+	for _, limit := range []int{1, 7, 100, len(all)} {
+		var listed []Interval[uint16]
+		page := it.IntervalsFrom(0, limit)
+		for len(page) > 0 {
+			if len(page) > limit {
+				t.Fatalf("Page of %d intervals over limit %d", len(page), limit)
+			}
+			listed = append(listed, page...)
+			page = it.IntervalsAfter(page[len(page)-1].I, limit)
+		}
+		if !slices.Equal(listed, all) {
+			t.Fatalf("Paging by %d listed %d intervals, expected %d", limit, len(listed), len(all))
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package intervaltree
+
+import "context"
+
+// Patch holds the values to add to and remove from a set to turn it into
+// another, as maximal intervals in ascending order, as returned by Diff.
+type Patch[T Integer] struct {
+	Added   []Interval[T] // Values to insert, none of them contained
+	Removed []Interval[T] // Values to remove, all of them contained
+}
+
+// Diff returns the patch turning the tree into o, computed in a single linear
+// merge of their intervals. Each tree is read under its own lock. Applying it
+// with ApplyPatch to a tree equal to this one makes it equal to o.
+func (t *Tree[T]) Diff(o *Tree[T]) Patch[T] {
+	p, _ := t.DiffContext(context.Background(), o) // Only fails if ctx is done
+	return p
+}
+
+// DiffContext is like Diff, but checks periodically while merging whether ctx
+// is done, in which case it fails with the error of ctx.
+func (t *Tree[T]) DiffContext(ctx context.Context, o *Tree[T]) (Patch[T], error) {
+	from, to := t.Freeze().intervals, o.Freeze().intervals
+	added, err := subtract(ctx, to, from)
+	if err != nil {
+		return Patch[T]{}, err
+	}
+	removed, err := subtract(ctx, from, to)
+	if err != nil {
+		return Patch[T]{}, err
+	}
+	return Patch[T]{Added: added, Removed: removed}, nil
+}
+
+// subtract returns the values in a not in b, where both hold ascending, non
+// adjacent intervals, as maximal intervals in ascending order. It fails with
+// the error of ctx if ctx is done.
+func subtract[T Integer](ctx context.Context, a, b []Interval[T]) ([]Interval[T], error) {
+	var ret []Interval[T]
+	for k, i := range a {
+		if err := cancelled(ctx, k); err != nil {
+			return nil, err
+		}
+		for len(b) > 0 && b[0].J < i.I {
+			b = b[1:]
+		}
+		// Carve the intervals of b overlapping i out of it
+		left := true // Whether values of i are left
+		for k := 0; left && k < len(b) && b[k].I <= i.J; k++ {
+			if b[k].I > i.I {
+				ret = append(ret, Interval[T]{i.I, b[k].I - 1})
+			}
+			if left = b[k].J < i.J; left {
+				i.I = b[k].J + 1
+			}
+		}
+		if left {
+			ret = append(ret, i)
+		}
+	}
+	return ret, nil
+}
+
+// ApplyPatch adds and removes the values in p to and from the tree, whatever
+// its OverlapMode. Either every change is made or none is: the values added
+// must not be contained in the tree and those removed must be, or it fails as
+// Insert or Remove would, without changing the tree. Intervals in each list
+// must be ascending and not overlap.
+func (t *Tree[T]) ApplyPatch(p Patch[T]) (err error) {
+	if t.tracer != nil {
+		span := t.traceBulk("intervaltree.ApplyPatch", "changes", len(p.Added)+len(p.Removed))
+		defer func() { span.End(err) }()
+	}
+	if err := checkSorted(p.Added); err != nil {
+		return err
+	}
+	if err := checkSorted(p.Removed); err != nil {
+		return err
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	var added, removed uint64
+	for _, i := range p.Removed {
+		if !t.canRemove(i.I, i.J) {
+			return t.remove(i.I, i.J) // Fails as Tree.Remove would
+		}
+		removed += ordinal(i.J) - ordinal(i.I) + 1
+	}
+	for _, i := range p.Added {
+		if !t.disjoint(i.I, i.J) {
+			return t.insertStrict(i.I, i.J) // Fails as Tree.Insert would
+		}
+		added += ordinal(i.J) - ordinal(i.I) + 1
+	}
+	if remaining := t.capacity - t.root.getCovered() + removed; t.capped && added > remaining {
+		return t.failed(insertErrors, p.Added[0].I, p.Added[len(p.Added)-1].J, CapacityError{t.capacity, remaining, added})
+	}
+
+	// Values added are not contained, so they are disjoint from those removed
+	for _, i := range p.Removed {
+		t.remove(i.I, i.J)
+	}
+	for _, i := range p.Added {
+		t.insertStrict(i.I, i.J)
+	}
+	return nil
+}
+
+// checkSorted checks that intervals are valid, ascending and do not overlap.
+func checkSorted[T Integer](intervals []Interval[T]) error {
+	for k, i := range intervals {
+		if i.I > i.J || (k > 0 && i.I <= intervals[k-1].J) {
+			return InvalidIntervalError[T]{i.I, i.J}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package intervaltree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a, b := NewTree[int8](), NewTree[int8]()
+	a.Insert(-128, -100)
+	a.Insert(0, 20)
+	a.Insert(50, 60)
+	b.Insert(-110, -90)
+	b.Insert(5, 8)
+	b.Insert(12, 30)
+	b.Insert(100, 127)
+
+	p := a.Diff(b)
+	if s := fmt.Sprint(p.Added); s != "[{-99 -90} {21 30} {100 127}]" {
+		t.Fatalf("Unexpected added intervals: %s", s)
+	}
+	if s := fmt.Sprint(p.Removed); s != "[{-128 -111} {0 4} {9 11} {50 60}]" {
+		t.Fatalf("Unexpected removed intervals: %s", s)
+	}
+	if err := a.ApplyPatch(p); err != nil {
+		t.Fatal(err)
+	}
+	if a.ToString() != b.ToString() {
+		t.Fatalf("Patched tree differs: %s", a.ToString())
+	}
+	if p := a.Diff(b); len(p.Added) != 0 || len(p.Removed) != 0 {
+		t.Fatalf("Unexpected patch between equal trees: %v", p)
+	}
+
+	full, empty := New(), New()
+	full.Insert(0, math.MaxUint64)
+	if p := full.Diff(empty); fmt.Sprint(p) != "{[] [{0 18446744073709551615}]}" {
+		t.Fatalf("Unexpected patch: %v", p)
+	}
+	least := NewTree[int8]()
+	least.Insert(-128, -128)
+	wide := NewTree[int8]()
+	wide.Insert(-128, 0)
+	if p := least.Diff(wide); fmt.Sprint(p) != "{[{-127 0}] []}" {
+		t.Fatalf("Unexpected patch: %v", p)
+	}
+}
+
+func TestDiffRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 20; round++ {
+		a, b := NewTree[uint16](), NewTree[uint16]()
+		for i := 0; i < 200; i++ {
+			x := uint16(r.Intn(5000))
+			a.Insert(x, x+uint16(r.Intn(20)))
+			x = uint16(r.Intn(5000))
+			b.Insert(x, x+uint16(r.Intn(20)))
+		}
+		if err := a.ApplyPatch(a.Diff(b)); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if a.ToString() != b.ToString() {
+			t.Fatal("Patched tree differs")
+		}
+	}
+}
+
+func TestApplyPatchAtomic(t *testing.T) {
+	it := NewTree[int](WithCapacity(30))
+	it.Insert(0, 9)
+	it.Insert(20, 29)
+	before := it.ToString()
+
+	for _, c := range []struct {
+		p   Patch[int]
+		err error
+	}{
+		{Patch[int]{Added: []Interval[int]{{40, 45}}, Removed: []Interval[int]{{0, 4}, {8, 12}}}, ErrNotFound},
+		{Patch[int]{Added: []Interval[int]{{40, 45}, {25, 30}}}, ErrInvalidInterval},
+		{Patch[int]{Added: []Interval[int]{{10, 15}, {25, 30}}}, ErrOverlap},
+		{Patch[int]{Added: []Interval[int]{{10, 19}, {30, 35}}, Removed: []Interval[int]{{0, 4}}}, nil},
+	} {
+		err := it.ApplyPatch(c.p)
+		if c.err != nil && !errors.Is(err, c.err) {
+			t.Fatalf("Expected %v, got %v", c.err, err)
+		}
+		if c.err == nil {
+			var ce CapacityError
+			if !errors.As(err, &ce) || ce.Remaining != 15 || ce.Requested != 16 {
+				t.Fatalf("Expected a capacity error, got %v", err)
+			}
+		}
+		if it.ToString() != before {
+			t.Fatalf("Tree changed by a failed patch: %s", it.ToString())
+		}
+	}
+
+	if err := it.ApplyPatch(Patch[int]{Added: []Interval[int]{{10, 19}, {30, 34}}, Removed: []Interval[int]{{0, 4}}}); err != nil {
+		t.Fatal(err)
+	}
+	if it.Contains(4) || !it.Contains(5) || !it.Contains(34) || it.Contains(35) {
+		t.Fatalf("Unexpected patched tree: %s", it.ToString())
+	}
+}
+
+func TestDiffContext(t *testing.T) {
+	a, b := New(), New()
+	a.Insert(0, 10)
+	b.Insert(5, 20)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p, err := a.DiffContext(ctx, b)
+	if err != nil || fmt.Sprint(p) != "{[{11 20}] [{0 4}]}" {
+		t.Fatalf("Unexpected patch %v: %v", p, err)
+	}
+	cancel()
+	if _, err := a.DiffContext(ctx, b); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Unexpected error with a cancelled context: %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package intervaltree
+
+// progressEvery is the number of items processed between progress reports.
+const progressEvery = 4096
+
+// ProgressFunc is called by long-running operations of a tree with the name of
+// the operation, such as "intervaltree.Load", the number of items processed so
+// far and their total, or -1 if it is not known yet. The last call of an
+// operation has done equal to total, and the first has done equal to 0.
+type ProgressFunc func(op string, done, total int64)
+
+// WithProgress makes Load, Rebuild, EncodeJSON and DecodeJSON report their
+// progress to fn every few thousand intervals and once they are done, so
+// services can tell a slow operation from a stuck one. fn may be called with
+// the lock of the tree held, so it must not use the tree.
+func WithProgress(fn ProgressFunc) Option {
+	return func(c *config) {
+		c.progress = fn
+	}
+}
+
+// reportProgress calls fn, if set, with the progress of op every progressEvery
+// items and once done reaches total.
+func reportProgress(fn ProgressFunc, op string, done, total int64) {
+	if fn != nil && (done%progressEvery == 0 || done == total) {
+		fn(op, done, total)
+	}
+}
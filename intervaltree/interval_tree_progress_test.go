@@ -0,0 +1,73 @@
+package intervaltree
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// progressRecorder records the calls to its report method.
+type progressRecorder struct {
+	calls []string
+}
+
+func (p *progressRecorder) report(op string, done, total int64) {
+	p.calls = append(p.calls, fmt.Sprintf("%s %d/%d", op, done, total))
+}
+
+func TestProgress(t *testing.T) {
+	var p progressRecorder
+	it := New(WithProgress(p.report))
+	for k := uint64(0); k < progressEvery+10; k++ {
+		it.Insert(2*k, 2*k)
+	}
+
+	it.Rebuild()
+	expected := []string{"intervaltree.Rebuild 0/4106", "intervaltree.Rebuild 4096/4106", "intervaltree.Rebuild 4106/4106"}
+	if fmt.Sprint(p.calls) != fmt.Sprint(expected) {
+		t.Fatalf("Unexpected progress of Rebuild: %v", p.calls)
+	}
+
+	p.calls = nil
+	var b bytes.Buffer
+	if err := it.EncodeJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"intervaltree.EncodeJSON 0/4106", "intervaltree.EncodeJSON 4096/4106", "intervaltree.EncodeJSON 4106/4106"}
+	if fmt.Sprint(p.calls) != fmt.Sprint(expected) {
+		t.Fatalf("Unexpected progress of EncodeJSON: %v", p.calls)
+	}
+
+	p.calls = nil
+	decoded := New(WithProgress(p.report))
+	if err := decoded.DecodeJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"intervaltree.DecodeJSON 0/-1", "intervaltree.DecodeJSON 4096/-1", "intervaltree.DecodeJSON 4106/4106"}
+	if fmt.Sprint(p.calls) != fmt.Sprint(expected) {
+		t.Fatalf("Unexpected progress of DecodeJSON: %v", p.calls)
+	}
+
+	p.calls = nil
+	New(WithProgress(p.report)).Rebuild()
+	if fmt.Sprint(p.calls) != "[intervaltree.Rebuild 0/0]" {
+		t.Fatalf("Unexpected progress of Rebuild of empty tree: %v", p.calls)
+	}
+}
+
+func TestLoadProgress(t *testing.T) {
+	var in strings.Builder
+	for k := 0; k < progressEvery+1; k++ {
+		fmt.Fprintf(&in, "%d\n", k*3)
+	}
+
+	var p progressRecorder
+	if _, err := Load[uint64](strings.NewReader(in.String()), TextRecords, 1000, t.TempDir(), WithProgress(p.report)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"intervaltree.Load 0/-1", "intervaltree.Load 4096/-1", "intervaltree.Load 4097/4097"}
+	if fmt.Sprint(p.calls) != fmt.Sprint(expected) {
+		t.Fatalf("Unexpected progress of Load: %v", p.calls)
+	}
+}
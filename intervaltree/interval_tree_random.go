@@ -0,0 +1,93 @@
+package intervaltree
+
+import "math/rand/v2"
+
+// RandomCovered returns a value contained in the tree chosen uniformly at
+// random, and false if the tree is empty. It descends the tree in O(log n),
+// choosing each subtree in proportion to the number of values it holds.
+func (t *Tree[T]) RandomCovered() (T, bool) {
+	t.RLock()
+	defer t.RUnlock()
+	t.counters.inc(lookups)
+
+	var zero T
+	switch {
+	case t.root == nil:
+		return zero, false
+	case t.root.covered == 0: // Every value of a 64-bit T
+		return fromOrdinal[T](rand.Uint64())
+	}
+	return t.root.nthCovered(rand.Uint64N(t.root.covered)), true
+}
+
+// nthCovered returns the k-th value contained in the subtree rooted at this
+// node in ascending order, counting from 0, which must exist.
+func (n *node[T]) nthCovered(k uint64) T {
+	for {
+		left := n.Left.getCovered()
+		if k < left {
+			n = n.Left
+			continue
+		}
+		k -= left
+		size := ordinal(n.J) - ordinal(n.I) + 1
+		if k < size {
+			x, _ := fromOrdinal[T](ordinal(n.I) + k)
+			return x
+		}
+		k -= size
+		n = n.Right
+	}
+}
+
+// RandomFree returns a value in [lo, hi] not contained in the tree chosen
+// uniformly at random, and false if there is none, in O(log n). Allocating it
+// gives out values in an unpredictable order, unlike an Allocator.
+func (t *Tree[T]) RandomFree(lo, hi T) (T, bool) {
+	if lo > hi {
+		return lo, false
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+	t.counters.inc(lookups)
+
+	if t.root != nil && t.root.covered == 0 { // Every value of a 64-bit T
+		return lo, false
+	}
+	var before uint64 // Values contained before lo
+	if least, _ := Limits[T](); lo > least {
+		before = t.root.coveredUpTo(lo - 1)
+	}
+	// Arithmetic is modulo 2^64, in which 0 free values in [lo, hi] may stand
+	// for every value of a 64-bit T, if lo is free
+	free := ordinal(hi) - ordinal(lo) + 1 - (t.root.coveredUpTo(hi) - before)
+	var k uint64
+	switch {
+	case free != 0:
+		k = rand.Uint64N(free)
+	case t.root.contains(lo):
+		return lo, false
+	default:
+		k = rand.Uint64()
+	}
+	return t.root.nthFree(ordinal(lo) - before + k), true
+}
+
+// nthFree returns the value not contained in the subtree rooted at this node
+// with rank r, which is its ordinal minus the number of values contained
+// before it.
+func (n *node[T]) nthFree(r uint64) T {
+	var before uint64 // Values contained before the subtree rooted at n
+	for n != nil {
+		left := n.Left.getCovered()
+		if ordinal(n.I)-(before+left) > r {
+			n = n.Left
+			continue
+		}
+		before += left + ordinal(n.J) - ordinal(n.I) + 1
+		n = n.Right
+	}
+	x, _ := fromOrdinal[T](r + before)
+	return x
+}
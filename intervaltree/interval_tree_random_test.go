@@ -0,0 +1,88 @@
+package intervaltree
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRandomCovered(t *testing.T) {
+	it := NewTree[int8]()
+	if _, ok := it.RandomCovered(); ok {
+		t.Fatal("Value found in an empty tree")
+	}
+
+	it.Insert(-128, -119)
+	it.Insert(0, 9)
+	it.Insert(100, 104)
+	it.Insert(127, 127)
+	counts := map[int8]int{}
+	for i := 0; i < 26000; i++ {
+		x, ok := it.RandomCovered()
+		if !ok || !it.Contains(x) {
+			t.Fatalf("Sampled value not contained: %d %v", x, ok)
+		}
+		counts[x]++
+	}
+	if len(counts) != 26 {
+		t.Fatalf("Sampled %d distinct values, expected 26", len(counts))
+	}
+	for x, n := range counts {
+		if n < 700 || n > 1300 { // 1000 expected
+			t.Fatalf("Value %d sampled %d times", x, n)
+		}
+	}
+
+	full := New()
+	full.Insert(0, math.MaxUint64)
+	if _, ok := full.RandomCovered(); !ok {
+		t.Fatal("No value found in a full tree")
+	}
+}
+
+func TestRandomFree(t *testing.T) {
+	it := NewTree[int8]()
+	it.Insert(-128, -119)
+	it.Insert(-100, -1)
+	it.Insert(5, 99)
+	it.Insert(101, 127)
+
+	counts := map[int8]int{}
+	for i := 0; i < 24000; i++ {
+		x, ok := it.RandomFree(-120, 110)
+		if !ok || it.Contains(x) || x < -120 || x > 110 {
+			t.Fatalf("Sampled value contained or outside the window: %d %v", x, ok)
+		}
+		counts[x]++
+	}
+	if len(counts) != 24 { // [-118, -101], [0, 4] and 100
+		t.Fatalf("Sampled %d distinct values", len(counts))
+	}
+	for x, n := range counts {
+		if n < 700 || n > 1300 { // 1000 expected
+			t.Fatalf("Value %d sampled %d times", x, n)
+		}
+	}
+
+	if x, ok := it.RandomFree(-100, -1); ok {
+		t.Fatalf("Sampled a value of a full window: %d", x)
+	}
+	if x, ok := it.RandomFree(100, 100); !ok || x != 100 {
+		t.Fatalf("Unexpected sample: %d %v", x, ok)
+	}
+	if _, ok := it.RandomFree(5, 4); ok {
+		t.Fatal("Sampled a value of an invalid window")
+	}
+
+	full := New()
+	if _, ok := full.RandomFree(0, math.MaxUint64); !ok {
+		t.Fatal("No value found in an empty tree")
+	}
+	full.Insert(0, math.MaxUint64)
+	if _, ok := full.RandomFree(0, math.MaxUint64); ok {
+		t.Fatal("Value found in a full tree")
+	}
+	full.Remove(math.MaxUint64, math.MaxUint64)
+	if x, ok := full.RandomFree(0, math.MaxUint64); !ok || x != math.MaxUint64 {
+		t.Fatalf("Unexpected sample: %d %v", x, ok)
+	}
+}
@@ -0,0 +1,43 @@
+package intervaltree
+
+// Len returns the number of intervals in the tree, in O(1).
+func (t *Tree[T]) Len() int {
+	t.RLock()
+	defer t.RUnlock()
+	return t.root.getIntervals()
+}
+
+// KthInterval returns the k-th interval of the tree in ascending order,
+// counting from 0, and whether there is one, in O( log n ). Along with Len, it
+// allows random access and pagination over the intervals.
+func (t *Tree[T]) KthInterval(k int) (Interval[T], bool) {
+	t.RLock()
+	defer t.RUnlock()
+	t.counters.inc(lookups)
+
+	if n := t.root.kth(k); n != nil {
+		return Interval[T]{n.I, n.J}, true
+	}
+	return Interval[T]{}, false
+}
+
+// kth returns the node holding the k-th interval of the subtree rooted at this
+// node in ascending order, or nil if there is none.
+func (n *node[T]) kth(k int) *node[T] {
+	if k < 0 {
+		return nil
+	}
+	for n != nil {
+		left := n.Left.getIntervals()
+		switch {
+		case k < left:
+			n = n.Left
+		case k == left:
+			return n
+		default:
+			k -= left + 1
+			n = n.Right
+		}
+	}
+	return nil
+}
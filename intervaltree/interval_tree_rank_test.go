@@ -0,0 +1,49 @@
+package intervaltree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestKthInterval(t *testing.T) {
+	it := NewTree[int32]()
+	if _, ok := it.KthInterval(0); ok || it.Len() != 0 {
+		t.Fatal("Interval found in an empty tree")
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 3000; i++ {
+		x := int32(r.Intn(10000))
+		y := x + int32(r.Intn(20))
+		if r.Intn(3) == 0 && it.Contains(x) {
+			it.Remove(x, x) // Shrink, split or remove an interval
+		} else {
+			it.Insert(x, y)
+		}
+
+		if i%200 == 0 {
+			if err := it.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			var all []Interval[int32]
+			it.Walk(func(x, y int32) bool {
+				all = append(all, Interval[int32]{x, y})
+				return true
+			})
+			if it.Len() != len(all) {
+				t.Fatalf("Unexpected length %d, expected %d", it.Len(), len(all))
+			}
+			for k, expected := range all {
+				if i, ok := it.KthInterval(k); !ok || i != expected {
+					t.Fatalf("KthInterval(%d) = %v %v, expected %v", k, i, ok, expected)
+				}
+			}
+			if _, ok := it.KthInterval(len(all)); ok {
+				t.Fatal("Interval found past the last one")
+			}
+			if _, ok := it.KthInterval(-1); ok {
+				t.Fatal("Interval found before the first one")
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package intervaltree
+
+import "sort"
+
+// Reassembly tracks the byte ranges received of a stream of known size
+// delivered out of order, such as the segments of a transport or the chunks of
+// an upload. Receiving a byte twice is not an error. It tells which bytes are
+// missing and how long the prefix received without holes is, and calls back
+// when a given prefix is. It is safe for concurrent use unless created with
+// WithoutLocking.
+type Reassembly struct {
+	t       *Tree[uint64]
+	size    uint64
+	waiting []prefixCallback // Sorted by prefix, guarded by the lock of t
+}
+
+// prefixCallback is a function waiting for a prefix of n bytes to be received.
+type prefixCallback struct {
+	n  uint64
+	fn func()
+}
+
+// NewReassembly returns a pointer to a Reassembly of a stream of size bytes,
+// none of them received, backed by a tree created with opts. Streams of unknown
+// size can use math.MaxUint64. The tree always takes the union of the byte
+// ranges received, whatever its OverlapMode.
+func NewReassembly(size uint64, opts ...Option) *Reassembly {
+	opts = append(opts[:len(opts):len(opts)], WithOverlapMode(OverlapUnion))
+	return &Reassembly{t: NewTree[uint64](opts...), size: size}
+}
+
+// Receive records the n bytes starting at offset off as received, which must
+// lie within the stream, and calls the callbacks of the prefixes it completes,
+// in ascending order once the lock is released.
+func (r *Reassembly) Receive(off, n uint64) error {
+	if n == 0 {
+		return nil
+	}
+	if off >= r.size || n > r.size-off {
+		return InvalidIntervalError[uint64]{off, off + n - 1}
+	}
+
+	r.t.Lock()
+	if err := r.t.insert(off, off+n-1); err != nil {
+		r.t.Unlock()
+		return err
+	}
+	end := r.prefixEnd()
+	i := sort.Search(len(r.waiting), func(i int) bool { return r.waiting[i].n > end })
+	done := r.waiting[:i]
+	r.waiting = r.waiting[i:]
+	r.t.Unlock()
+
+	for _, c := range done {
+		c.fn()
+	}
+	return nil
+}
+
+// prefixEnd returns the number of bytes received without holes from the start
+// of the stream. The caller must hold the lock.
+func (r *Reassembly) prefixEnd() uint64 {
+	if c := r.t.root.containingNode(0); c != nil {
+		return c.J + 1
+	}
+	return 0
+}
+
+// ContiguousPrefixEnd returns the number of bytes received without holes from
+// the start of the stream, which is the offset of the first missing byte.
+func (r *Reassembly) ContiguousPrefixEnd() uint64 {
+	r.t.RLock()
+	defer r.t.RUnlock()
+
+	return r.prefixEnd()
+}
+
+// NextMissing returns the offset and length of the first range of bytes not
+// received, up to the next byte received or the end of the stream, and false
+// if every byte was received.
+func (r *Reassembly) NextMissing() (uint64, uint64, bool) {
+	r.t.RLock()
+	defer r.t.RUnlock()
+
+	off := r.prefixEnd()
+	if off == r.size {
+		return off, 0, false
+	}
+	end := r.size
+	r.t.root.walkRange(off, r.size-1, func(i, j uint64) bool {
+		end = i
+		return false
+	})
+	return off, end - off, true
+}
+
+// Received checks if the byte at offset off was received.
+func (r *Reassembly) Received(off uint64) bool {
+	return r.t.Contains(off)
+}
+
+// Complete checks if every byte of the stream was received.
+func (r *Reassembly) Complete() bool {
+	return r.ContiguousPrefixEnd() == r.size
+}
+
+// OnContiguous makes the Reassembly call fn once the first n bytes of the
+// stream are received without holes, right away if they already are.
+func (r *Reassembly) OnContiguous(n uint64, fn func()) {
+	r.t.Lock()
+	if n <= r.prefixEnd() {
+		r.t.Unlock()
+		fn()
+		return
+	}
+	i := sort.Search(len(r.waiting), func(i int) bool { return r.waiting[i].n > n })
+	r.waiting = append(r.waiting, prefixCallback{})
+	copy(r.waiting[i+1:], r.waiting[i:])
+	r.waiting[i] = prefixCallback{n, fn}
+	r.t.Unlock()
+}
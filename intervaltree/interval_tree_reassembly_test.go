@@ -0,0 +1,57 @@
+package intervaltree
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReassembly(t *testing.T) {
+	r := NewReassembly(100)
+	var fired []uint64
+	for _, n := range []uint64{50, 10, 100, 30} {
+		r.OnContiguous(n, func() { fired = append(fired, n) })
+	}
+
+	if off, n, ok := r.NextMissing(); !ok || off != 0 || n != 100 {
+		t.Fatalf("Unexpected missing range: %d %d %v", off, n, ok)
+	}
+	r.Receive(20, 20)
+	r.Receive(60, 10)
+	if off, n, ok := r.NextMissing(); !ok || off != 0 || n != 20 {
+		t.Fatalf("Unexpected missing range: %d %d %v", off, n, ok)
+	}
+	if len(fired) != 0 {
+		t.Fatalf("Callbacks fired early: %v", fired)
+	}
+
+	r.Receive(0, 25) // Partially received again
+	if end := r.ContiguousPrefixEnd(); end != 40 {
+		t.Fatalf("Unexpected prefix end: %d", end)
+	}
+	if len(fired) != 2 || fired[0] != 10 || fired[1] != 30 {
+		t.Fatalf("Unexpected callbacks: %v", fired)
+	}
+	if off, n, ok := r.NextMissing(); !ok || off != 40 || n != 20 {
+		t.Fatalf("Unexpected missing range: %d %d %v", off, n, ok)
+	}
+
+	r.OnContiguous(40, func() { fired = append(fired, 40) })
+	if len(fired) != 3 {
+		t.Fatal("Callback for a contiguous prefix did not fire right away")
+	}
+
+	if err := r.Receive(90, 11); !errors.Is(err, ErrInvalidInterval) {
+		t.Fatalf("Expected an invalid interval error, got %v", err)
+	}
+	r.Receive(40, 20)
+	r.Receive(70, 30)
+	if !r.Complete() || !r.Received(99) {
+		t.Fatal("Stream not complete")
+	}
+	if _, _, ok := r.NextMissing(); ok {
+		t.Fatal("Missing range in a complete stream")
+	}
+	if len(fired) != 5 || fired[3] != 50 || fired[4] != 100 {
+		t.Fatalf("Unexpected callbacks: %v", fired)
+	}
+}
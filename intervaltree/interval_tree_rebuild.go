@@ -0,0 +1,28 @@
+package intervaltree
+
+// Rebuild rearranges the intervals in the tree into a tree of minimal height
+// in O(n), which can improve lookup times after heavy mixed workloads. The
+// contents and the revision of the tree are unchanged.
+func (t *Tree[T]) Rebuild() {
+	var span Span
+	if t.tracer != nil {
+		span = t.tracer.Start("intervaltree.Rebuild")
+		defer span.End(nil)
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	total := int64(t.root.getIntervals())
+	if span != nil {
+		span.SetAttribute("intervals", total)
+	}
+	intervals := make([]Interval[T], 0, total)
+	reportProgress(t.progress, "intervaltree.Rebuild", 0, total)
+	t.root.walk(func(x, y T) bool {
+		intervals = append(intervals, Interval[T]{x, y})
+		reportProgress(t.progress, "intervaltree.Rebuild", int64(len(intervals)), total)
+		return true
+	})
+	t.root = build(intervals)
+}
@@ -0,0 +1,34 @@
+package intervaltree
+
+import (
+	"math/bits"
+	"testing"
+)
+
+func TestRebuild(t *testing.T) {
+	it := New()
+	for x := uint64(0); x < 1000; x++ {
+		it.Insert(3*x, 3*x)
+		if x%3 == 0 {
+			it.Remove(3*x, 3*x)
+		}
+	}
+
+	before, rev := it.ToString(), it.Revision()
+	it.Rebuild()
+	if it.ToString() != before || it.Revision() != rev {
+		t.Fatal("Rebuild changed the tree")
+	}
+	if err := it.root.isAVL(); err != nil {
+		t.Fatalf("Rebuilt tree is not AVL: %v", err)
+	}
+	if h := int(it.root.getHeight()); h != bits.Len(666) {
+		t.Fatalf("Rebuilt tree has height %d, expected %d", h, bits.Len(666))
+	}
+
+	empty := New()
+	empty.Rebuild()
+	if empty.root != nil {
+		t.Fatal("Rebuilt empty tree is not empty")
+	}
+}
@@ -0,0 +1,310 @@
+package intervaltree
+
+import "sync"
+
+// RedBlackTree represents the same set of intervals as Tree, but is balanced
+// as a left-leaning red-black tree. It may be somewhat deeper than an AVL
+// tree, trading a little read depth for cheaper rebalancing on insertion.
+type RedBlackTree[T Integer] struct {
+	root *rbNode[T]
+	sync.RWMutex
+}
+
+// rbNode holds an interval [I, J], the color of the link from its parent and
+// pointers to nodes holding intervals lesser and greater than its own. Red
+// links always lean left and no path has two red links in a row.
+type rbNode[T Integer] struct {
+	I, J        T          // Interval bounds
+	Left, Right *rbNode[T] // Left and right children
+	red         bool       // Color of the link from the parent
+}
+
+// NewRedBlack returns a pointer to an empty RedBlackTree.
+func NewRedBlack[T Integer]() *RedBlackTree[T] {
+	return &RedBlackTree[T]{}
+}
+
+// isRed checks if the link to n is red. Links to empty subtrees are black.
+func (n *rbNode[T]) isRed() bool {
+	return n != nil && n.red
+}
+
+// rotateLeft lifts the left child of this node, returning it.
+func (n *rbNode[T]) rotateLeft() *rbNode[T] {
+	pivot := n.Left
+	n.Left = pivot.Right
+	pivot.Right = n
+	pivot.red, n.red = n.red, true
+	return pivot
+}
+
+// rotateRight lifts the right child of this node, returning it.
+func (n *rbNode[T]) rotateRight() *rbNode[T] {
+	pivot := n.Right
+	n.Right = pivot.Left
+	pivot.Left = n
+	pivot.red, n.red = n.red, true
+	return pivot
+}
+
+// flipColors flips the colors of this node and its children.
+func (n *rbNode[T]) flipColors() {
+	n.red = !n.red
+	n.Left.red = !n.Left.red
+	n.Right.red = !n.Right.red
+}
+
+// fixUp restores the invariants of this node on the way back up, returning the
+// new root of its subtree.
+func (n *rbNode[T]) fixUp() *rbNode[T] {
+	if n.Right.isRed() && !n.Left.isRed() {
+		n = n.rotateRight()
+	}
+	if n.Left.isRed() && n.Left.Left.isRed() {
+		n = n.rotateLeft()
+	}
+	if n.Left.isRed() && n.Right.isRed() {
+		n.flipColors()
+	}
+	return n
+}
+
+// moveRedLeft makes the left child of this node or one of its children red,
+// so that a node can be deleted from the left subtree.
+func (n *rbNode[T]) moveRedLeft() *rbNode[T] {
+	n.flipColors()
+	if n.Right.Left.isRed() {
+		n.Right = n.Right.rotateLeft()
+		n = n.rotateRight()
+		n.flipColors()
+	}
+	return n
+}
+
+// moveRedRight makes the right child of this node or one of its children red,
+// so that a node can be deleted from the right subtree.
+func (n *rbNode[T]) moveRedRight() *rbNode[T] {
+	n.flipColors()
+	if n.Left.Left.isRed() {
+		n = n.rotateLeft()
+		n.flipColors()
+	}
+	return n
+}
+
+// insert adds a node holding [x, y] to the subtree rooted at n, which holds no
+// interval starting at x, returning the new root of the subtree.
+func (n *rbNode[T]) insert(x, y T) *rbNode[T] {
+	if n == nil {
+		return &rbNode[T]{I: x, J: y, red: true}
+	}
+
+	if x < n.I {
+		n.Left = n.Left.insert(x, y)
+	} else {
+		n.Right = n.Right.insert(x, y)
+	}
+	return n.fixUp()
+}
+
+// remove deletes the node holding the interval starting at x from the subtree
+// rooted at n, returning the new root of the subtree. Such a node must exist.
+func (n *rbNode[T]) remove(x T) *rbNode[T] {
+	if x < n.I {
+		if !n.Left.isRed() && !n.Left.Left.isRed() {
+			n = n.moveRedLeft()
+		}
+		n.Left = n.Left.remove(x)
+		return n.fixUp()
+	}
+
+	if n.Left.isRed() {
+		n = n.rotateLeft()
+	}
+	if x == n.I && n.Right == nil {
+		return nil
+	}
+	if !n.Right.isRed() && !n.Right.Left.isRed() {
+		n = n.moveRedRight()
+	}
+	if x == n.I { // Replace this interval with the next one
+		var next *rbNode[T]
+		n.Right, next = n.Right.removeLeast()
+		n.I, n.J = next.I, next.J
+	} else {
+		n.Right = n.Right.remove(x)
+	}
+	return n.fixUp()
+}
+
+// removeLeast deletes the node holding the least interval from the subtree
+// rooted at n, returning the new root of the subtree and the deleted node.
+func (n *rbNode[T]) removeLeast() (*rbNode[T], *rbNode[T]) {
+	if n.Left == nil {
+		return nil, n
+	}
+
+	if !n.Left.isRed() && !n.Left.Left.isRed() {
+		n = n.moveRedLeft()
+	}
+	var least *rbNode[T]
+	n.Left, least = n.Left.removeLeast()
+	return n.fixUp(), least
+}
+
+// floor returns the node holding the greatest interval starting at or before
+// x in the subtree rooted at n, or nil if there is none.
+func (n *rbNode[T]) floor(x T) *rbNode[T] {
+	var ret *rbNode[T]
+	for n != nil {
+		if n.I <= x {
+			ret, n = n, n.Right
+		} else {
+			n = n.Left
+		}
+	}
+	return ret
+}
+
+// higher returns the node holding the least interval starting after x in the
+// subtree rooted at n, or nil if there is none.
+func (n *rbNode[T]) higher(x T) *rbNode[T] {
+	var ret *rbNode[T]
+	for n != nil {
+		if n.I > x {
+			ret, n = n, n.Left
+		} else {
+			n = n.Right
+		}
+	}
+	return ret
+}
+
+// walk calls fn recursively for the intervals of this node and its children in
+// ascending order. It stops as soon as fn returns false, and reports whether
+// the walk was completed.
+func (n *rbNode[T]) walk(fn func(x, y T) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	return n.Left.walk(fn) && fn(n.I, n.J) && n.Right.walk(fn)
+}
+
+// insert adds [x, y] to the tree, which holds no interval starting at x.
+func (t *RedBlackTree[T]) insert(x, y T) {
+	t.root = t.root.insert(x, y)
+	t.root.red = false
+}
+
+// remove deletes the interval starting at x from the tree. Such an interval
+// must exist.
+func (t *RedBlackTree[T]) remove(x T) {
+	if !t.root.Left.isRed() && !t.root.Right.isRed() {
+		t.root.red = true
+	}
+	t.root = t.root.remove(x)
+	if t.root != nil {
+		t.root.red = false
+	}
+}
+
+// Insert adds an interval to the tree. The interval cannot overlap with the
+// tree. If prunning is possible it will be done.
+func (t *RedBlackTree[T]) Insert(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	l, r := t.root.floor(x), t.root.higher(x)
+	if l != nil && l.J >= x {
+		return OverlapError[T]{x, Interval[T]{x, y}, Interval[T]{l.I, l.J}}
+	}
+	if r != nil && r.I <= y {
+		return OverlapError[T]{r.I, Interval[T]{x, y}, Interval[T]{r.I, r.J}}
+	}
+
+	// Neighbours are checked stepping towards the lesser value, so no check
+	// can overflow at the bounds of T
+	joinL := l != nil && l.J == x-1
+	joinR := r != nil && r.I-1 == y
+	switch {
+	case joinL && joinR: // Fill the gap between l and r
+		i, j := l.I, r.J
+		t.remove(r.I)
+		t.root.floor(i).J = j // Removing may have moved l
+	case joinL:
+		l.J = y
+	case joinR:
+		r.I = x
+	default:
+		t.insert(x, y)
+	}
+	return nil
+}
+
+// Remove deletes the interval [x, y] from the tree. Every value in [x, y] must
+// be contained in the tree. Stored intervals are shrunk or split as needed.
+func (t *RedBlackTree[T]) Remove(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	// Stored intervals are never adjacent, so [x, y] must lie in a single one
+	c := t.root.floor(x)
+	if c == nil || c.J < x {
+		return NotContainedError[T]{x}
+	}
+	if y > c.J {
+		return NotContainedError[T]{c.J + 1}
+	}
+
+	switch i, j := c.I, c.J; {
+	case x == i && y == j:
+		t.remove(i)
+	case x == i:
+		c.I = y + 1
+	case y == j:
+		c.J = x - 1
+	default: // Split, the upper part becomes a new node
+		c.J = x - 1
+		t.insert(y+1, j)
+	}
+	return nil
+}
+
+// Contains checks if x is contained in the tree.
+func (t *RedBlackTree[T]) Contains(x T) bool {
+	t.RLock()
+	defer t.RUnlock()
+
+	c := t.root.floor(x)
+	return c != nil && x <= c.J
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to x. It returns false if every such value is contained.
+func (t *RedBlackTree[T]) Next(x T) (T, bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	c := t.root.floor(x)
+	if c == nil || x > c.J {
+		return x, true
+	}
+	return after(c.J)
+}
+
+// Walk calls fn for the intervals in the tree in ascending order. It stops as
+// soon as fn returns false. The tree cannot be changed from fn.
+func (t *RedBlackTree[T]) Walk(fn func(x, y T) bool) {
+	t.RLock()
+	defer t.RUnlock()
+	t.root.walk(fn)
+}
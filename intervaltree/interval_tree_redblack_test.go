@@ -0,0 +1,104 @@
+package intervaltree
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// isRedBlack checks the left-leaning red-black invariants of the subtree
+// rooted at n, and returns the number of black links on its paths to leaves.
+func (n *rbNode[T]) isRedBlack() (int, error) {
+	if n == nil {
+		return 0, nil
+	}
+
+	if n.Right.isRed() || n.isRed() && n.Left.isRed() {
+		return 0, fmt.Errorf("Node [%d, %d] has misplaced red links", n.I, n.J)
+	}
+	if n.Left != nil && n.Left.J >= n.I || n.Right != nil && n.Right.I <= n.J {
+		return 0, fmt.Errorf("Node [%d, %d] is out of order with its children", n.I, n.J)
+	}
+
+	l, err := n.Left.isRedBlack()
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.Right.isRedBlack()
+	if err != nil {
+		return 0, err
+	}
+	if l != r {
+		return 0, fmt.Errorf("Node [%d, %d] has %d and %d black links below", n.I, n.J, l, r)
+	}
+	if !n.red {
+		l++
+	}
+	return l, nil
+}
+
+// String returns the intervals in the tree in the format of ToString.
+func (t *RedBlackTree[T]) String() string {
+	var s strings.Builder
+	t.Walk(func(x, y T) bool {
+		fmt.Fprintf(&s, "[%d -- %d]", x, y)
+		return true
+	})
+	return s.String()
+}
+
+func TestRedBlackTree(t *testing.T) {
+	rt := NewRedBlack[uint8]()
+	for _, i := range []Interval[uint8]{{10, 20}, {30, 40}, {0, 5}, {250, 255}, {21, 29}} {
+		if err := rt.Insert(i.I, i.J); err != nil {
+			t.Fatalf("Failed to insert %v: %v", i, err)
+		}
+	}
+	if err := rt.Insert(40, 45); err != (OverlapError[uint8]{40, Interval[uint8]{40, 45}, Interval[uint8]{10, 40}}) {
+		t.Fatalf("Unexpected error inserting overlapping interval: %v", err)
+	}
+	if s := rt.String(); s != "[0 -- 5][10 -- 40][250 -- 255]" {
+		t.Fatalf("Unexpected intervals: %s", s)
+	}
+	if !full(rt, 255) || next(rt, 12) != 41 || next(rt, 7) != 7 {
+		t.Fatal("Unexpected Next")
+	}
+
+	if err := rt.Remove(15, 16); err != nil {
+		t.Fatalf("Failed to split interval: %v", err)
+	}
+	if s := rt.String(); s != "[0 -- 5][10 -- 14][17 -- 40][250 -- 255]" {
+		t.Fatalf("Unexpected intervals after removal: %s", s)
+	}
+}
+
+func TestRedBlackTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	rt, it := NewRedBlack[int16](), NewTree[int16]()
+	for i := 0; i < 3000; i++ {
+		x := int16(r.Intn(2000) - 1000)
+		y := x + int16(r.Intn(10))
+		if r.Intn(3) == 0 {
+			if errA, errB := rt.Remove(x, y), it.Remove(x, y); (errA == nil) != (errB == nil) {
+				t.Fatalf("Remove [%d, %d] failed differently: %v, %v", x, y, errA, errB)
+			}
+		} else if errA, errB := rt.Insert(x, y), it.Insert(x, y); (errA == nil) != (errB == nil) {
+			t.Fatalf("Insert [%d, %d] failed differently: %v, %v", x, y, errA, errB)
+		}
+
+		if _, err := rt.root.isRedBlack(); err != nil {
+			t.Fatalf("Tree is not a red-black tree after %d operations: %v", i, err)
+		}
+		if rt.String() != it.ToString() {
+			t.Fatalf("Trees differ after %d operations", i)
+		}
+	}
+
+	for x := math.MinInt16; x <= math.MaxInt16; x += 7 {
+		if rt.Contains(int16(x)) != it.Contains(int16(x)) || !sameNext(rt, it, int16(x)) {
+			t.Fatalf("Trees differ at %d", x)
+		}
+	}
+}
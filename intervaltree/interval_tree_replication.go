@@ -0,0 +1,218 @@
+package intervaltree
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// Frames of a replication stream: a tag byte followed by a snapshot or a delta
+// in their binary encodings.
+const (
+	snapshotFrame = 's'
+	deltaFrame    = 'd'
+)
+
+// notify wakes up the streams waiting for changes. The caller must hold the
+// write lock, which keeps out changed, so watchMu is not needed.
+func (t *Tree[T]) notify() {
+	if t.watch != nil {
+		close(t.watch)
+		t.watch = nil
+	}
+}
+
+// changed returns the revision of the tree and a channel closed once it
+// changes. Readers share the lock, so watchMu serializes them.
+func (t *Tree[T]) changed() (uint64, <-chan struct{}) {
+	t.RLock()
+	defer t.RUnlock()
+	t.watchMu.Lock()
+	defer t.watchMu.Unlock()
+	if t.watch == nil {
+		t.watch = make(chan struct{})
+	}
+	return t.rev, t.watch
+}
+
+// Wait blocks until the revision of the tree is other than rev, or ctx is
+// done, and returns the revision of the tree.
+func (t *Tree[T]) Wait(ctx context.Context, rev uint64) (uint64, error) {
+	for {
+		current, changed := t.changed()
+		if current != rev {
+			return current, nil
+		}
+		select {
+		case <-ctx.Done():
+			return current, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Stream replicates the tree to a follower reading from w with Follow: it
+// writes a snapshot of the tree followed by the changes made to it, as they
+// are made, until ctx is done or a write fails. The changes are sent as Watch
+// does from the revision of the snapshot. Stream fails with a RevisionError if
+// the changes it has yet to send are discarded by a later Checkpoint or
+// Restore, after which the follower must start over.
+func (t *Tree[T]) Stream(ctx context.Context, w io.Writer) error {
+	t.Lock()
+	s := Snapshot[T]{Revision: t.rev}
+	t.root.walk(func(x, y T) bool {
+		s.Intervals = append(s.Intervals, Interval[T]{x, y})
+		return true
+	})
+	sent, _ := t.startStream(t.rev) // The current revision is always journaled
+	t.Unlock()
+	defer t.endStream(sent)
+
+	if err := writeFrame(w, snapshotFrame, s); err != nil {
+		return err
+	}
+	return t.sendChanges(ctx, sent, func(d Delta[T]) error {
+		return writeFrame(w, deltaFrame, d)
+	})
+}
+
+// Watch calls fn with the changes made to the tree after revision from, as
+// they are made, until ctx is done or fn fails, and returns that error.
+// Changes are journaled while a watch or a stream is live, unless a checkpoint
+// was already taken, and those sent by every live one are discarded, so
+// watchers keeping up bound the journal. Watch fails with a RevisionError if
+// the changes after from are no longer journaled, as happens when no watch,
+// stream nor checkpoint was live since, or if they are discarded by a later
+// Checkpoint or Restore.
+func (t *Tree[T]) Watch(ctx context.Context, from uint64, fn func(Delta[T]) error) error {
+	t.Lock()
+	sent, err := t.startStream(from)
+	t.Unlock()
+	if err != nil {
+		return err
+	}
+	defer t.endStream(sent)
+	return t.sendChanges(ctx, sent, fn)
+}
+
+// startStream registers a live stream that has sent the changes up to
+// revision from in the journal, starting it if needed, and returns the
+// revision the stream has sent, which it updates through sendChanges. It fails
+// with a RevisionError if the changes after from are not journaled. The caller
+// must hold the write lock.
+func (t *Tree[T]) startStream(from uint64) (*uint64, error) {
+	if t.journal == nil {
+		t.journal = &journal[T]{from: t.rev}
+	}
+	if from < t.journal.from || from > t.rev {
+		if !t.journal.pinned && len(t.journal.streams) == 0 {
+			t.journal = nil
+		}
+		return nil, RevisionError(from)
+	}
+
+	if t.journal.streams == nil {
+		t.journal.streams = make(map[*uint64]struct{})
+	}
+	sent := &from
+	t.journal.streams[sent] = struct{}{}
+	return sent, nil
+}
+
+// sendChanges calls fn with the changes made after revision *sent as they are
+// made, trimming the journal after each call, until ctx is done or fn fails.
+func (t *Tree[T]) sendChanges(ctx context.Context, sent *uint64, fn func(Delta[T]) error) error {
+	for rev := *sent; ; {
+		current, changed := t.changed()
+		if current == rev {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-changed:
+			}
+		}
+
+		d, err := t.Delta(rev)
+		if err != nil {
+			return err
+		}
+		if err := fn(d); err != nil {
+			return err
+		}
+		rev = d.To
+		t.Lock()
+		*sent = rev
+		if t.journal != nil {
+			t.journal.trim()
+		}
+		t.Unlock()
+	}
+}
+
+// endStream unregisters the live stream that has sent up to *sent from the
+// journal, which is dropped once no stream nor checkpoint needs it.
+func (t *Tree[T]) endStream(sent *uint64) {
+	t.Lock()
+	defer t.Unlock()
+	if t.journal == nil {
+		return
+	}
+	delete(t.journal.streams, sent)
+	if !t.journal.pinned && len(t.journal.streams) == 0 {
+		t.journal = nil
+	} else {
+		t.journal.trim()
+	}
+}
+
+// writeFrame writes v as a frame of type tag to w with a single write.
+func writeFrame(w io.Writer, tag byte, v io.WriterTo) error {
+	var buf bytes.Buffer
+	buf.WriteByte(tag)
+	v.WriteTo(&buf)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Follow applies the stream written by Stream from r to the tree, replacing its
+// contents with the snapshot that starts the stream. It returns nil once r is
+// exhausted at the end of a frame, or fails with a RevisionError if a change
+// is missing from the stream.
+func (t *Tree[T]) Follow(r io.Reader) error {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	for first := true; ; first = false {
+		tag, err := br.ReadByte()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		switch {
+		case tag == snapshotFrame && first:
+			s, err := ReadSnapshot[T](br)
+			if err != nil {
+				return err
+			}
+			if err := t.Restore(s); err != nil {
+				return err
+			}
+		case tag == deltaFrame && !first:
+			d, err := ReadDelta[T](br)
+			if err != nil {
+				return err
+			}
+			if err := t.Apply(d); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("Malformed replication stream: unexpected frame %q", tag)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package intervaltree
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestStreamFollow(t *testing.T) {
+	primary := NewTree[int16]()
+	primary.Insert(-100, -50)
+	primary.Insert(10, 20)
+
+	r, w := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- primary.Stream(ctx, w)
+		w.Close()
+	}()
+
+	follower := NewTree[int16]()
+	followed := make(chan error)
+	go func() { followed <- follower.Follow(r) }()
+
+	primary.Insert(30, 40)
+	primary.Remove(-60, -50)
+	primary.Insert(21, 29)
+	for deadline := time.Now().Add(5 * time.Second); follower.Revision() != primary.Revision(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("Follower stuck at revision %d, primary at %d", follower.Revision(), primary.Revision())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if s, e := walkString[int16](follower), walkString[int16](primary); s != e {
+		t.Fatalf("Unexpected follower contents: %s, expected %s", s, e)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Unexpected stream error: %v", err)
+	}
+	if err := <-followed; err != nil {
+		t.Fatalf("Unexpected follow error: %v", err)
+	}
+	if err := follower.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStreamTrimsJournal(t *testing.T) {
+	primary := NewTree[int32]()
+	r, w := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- primary.Stream(ctx, w)
+		w.Close()
+	}()
+	follower := NewTree[int32]()
+	followed := make(chan error)
+	go func() { followed <- follower.Follow(r) }()
+
+	for k := int32(0); k < 2000; k++ {
+		primary.Insert(2*k, 2*k)
+		if k%100 != 99 {
+			continue
+		}
+		// Once the follower caught up, the stream discards what it sent
+		for deadline := time.Now().Add(5 * time.Second); ; {
+			primary.RLock()
+			kept, started := 0, primary.journal != nil
+			if started {
+				kept = len(primary.journal.changes)
+			}
+			primary.RUnlock()
+			if started && kept == 0 && follower.Revision() == primary.Revision() {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Journal holds %d changes after %d insertions", kept, k+1)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if c := cap(primary.journal.changes); c > 1024 {
+			t.Fatalf("Journal grew to %d changes", c)
+		}
+	}
+
+	cancel()
+	<-done
+	<-followed
+	if primary.journal != nil {
+		t.Fatal("Journal kept after the last stream ended")
+	}
+
+	// A checkpoint keeps every change for Delta
+	primary.Checkpoint()
+	primary.Insert(-10, -10)
+	if d, err := primary.Delta(primary.Revision() - 1); err != nil || len(d.Changes) != 1 {
+		t.Fatalf("Checkpointed journal lost changes: %v", err)
+	}
+}
+
+func TestFollowGap(t *testing.T) {
+	var buf bytes.Buffer
+	writeFrame(&buf, snapshotFrame, Snapshot[int]{Revision: 3, Intervals: []Interval[int]{{1, 5}}})
+	writeFrame(&buf, deltaFrame, Delta[int]{From: 3, To: 4, Changes: []Change[int]{{false, Interval[int]{7, 9}}}})
+	writeFrame(&buf, deltaFrame, Delta[int]{From: 5, To: 6, Changes: []Change[int]{{false, Interval[int]{20, 29}}}})
+
+	it := NewTree[int]()
+	var re RevisionError
+	if err := it.Follow(&buf); !errors.As(err, &re) || re != 5 {
+		t.Fatalf("Expected a revision error for the gap, got %v", err)
+	}
+	if it.Revision() != 4 || !it.Contains(8) || it.Contains(20) {
+		t.Fatal("Unexpected contents after the gap")
+	}
+
+	buf.Reset()
+	writeFrame(&buf, deltaFrame, Delta[int]{})
+	if err := it.Follow(&buf); err == nil {
+		t.Fatal("Expected an error for a stream without a snapshot")
+	}
+}
+
+// blockingWriter signals every write on wrote and waits on proceed before
+// accepting it.
+type blockingWriter struct {
+	wrote, proceed chan struct{}
+}
+
+func (w blockingWriter) Write(p []byte) (int, error) {
+	w.wrote <- struct{}{}
+	<-w.proceed
+	return len(p), nil
+}
+
+func TestStreamCheckpoint(t *testing.T) {
+	it := NewTree[int]()
+	it.Insert(1, 1)
+
+	w := blockingWriter{make(chan struct{}), make(chan struct{})}
+	done := make(chan error)
+	go func() { done <- it.Stream(context.Background(), w) }()
+
+	<-w.wrote // Snapshot at revision 1
+	it.Insert(3, 3)
+	it.Checkpoint()
+	close(w.proceed)
+
+	var re RevisionError
+	if err := <-done; !errors.As(err, &re) || re != 1 {
+		t.Fatalf("Expected a revision error, got %v", err)
+	}
+}
+
+func TestWait(t *testing.T) {
+	it := NewTree[uint8]()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if rev, err := it.Wait(ctx, 0); rev != 0 || err != context.DeadlineExceeded {
+		t.Fatalf("Unexpected result waiting on an unchanged tree: %d, %v", rev, err)
+	}
+
+	go it.Insert(1, 2)
+	if rev, err := it.Wait(context.Background(), 0); rev != 1 || err != nil {
+		t.Fatalf("Unexpected result waiting for an insertion: %d, %v", rev, err)
+	}
+	if rev, err := it.Wait(context.Background(), 5); rev != 1 || err != nil {
+		t.Fatalf("Unexpected result waiting on a different revision: %d, %v", rev, err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	it := NewTree[int]()
+	stop := errors.New("stop")
+	deltas := make(chan Delta[int])
+	done := make(chan error)
+	go func() {
+		done <- it.Watch(context.Background(), 0, func(d Delta[int]) error {
+			deltas <- d
+			return stop
+		})
+	}()
+
+	for {
+		it.RLock()
+		started := it.journal != nil
+		it.RUnlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	it.Insert(1, 2)
+	if d := <-deltas; d.From != 0 || d.To != 1 || len(d.Changes) != 1 || d.Changes[0].Interval != (Interval[int]{1, 2}) {
+		t.Fatalf("Unexpected delta: %+v", d)
+	}
+	if err := <-done; err != stop {
+		t.Fatalf("Unexpected error ending the watch: %v", err)
+	}
+	if it.journal != nil {
+		t.Fatalf("Journal kept after the watch ended")
+	}
+
+	var re RevisionError
+	if err := it.Watch(context.Background(), 0, nil); !errors.As(err, &re) || re != 0 {
+		t.Fatalf("Expected a revision error, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package intervaltree
+
+import "fmt"
+
+// Ring represents a set of values in the bounded domain [0, N) in which
+// intervals may wrap around, going past N-1 back to 0, as in circular buffers
+// and rings of slots. A wrapping interval [x, y], with x > y, holds the values
+// in [x, N) and [0, y]. It is kept as those two intervals in a Tree, but is
+// reported and removed as one, and Next wraps around as well. Insertions are
+// always strict, whatever the OverlapMode of the tree. It is safe for
+// concurrent use unless created with WithoutLocking.
+type Ring[T Integer] struct {
+	t *Tree[T]
+	n T
+}
+
+// NewRing returns a pointer to an empty Ring over [0, n), backed by a tree
+// created with opts.
+func NewRing[T Integer](n T, opts ...Option) (*Ring[T], error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("Ring size must be positive: %v", n)
+	}
+	return &Ring[T]{t: NewTree[T](opts...), n: n}, nil
+}
+
+// halves returns the intervals of the tree holding [x, y], which may wrap,
+// and whether it is valid.
+func (r *Ring[T]) halves(x, y T) ([]Interval[T], bool) {
+	if x < 0 || y < 0 || x >= r.n || y >= r.n {
+		return nil, false
+	}
+	if x <= y {
+		return []Interval[T]{{x, y}}, true
+	}
+	return []Interval[T]{{x, r.n - 1}, {0, y}}, true
+}
+
+// Insert adds [x, y], which wraps around if x > y, to the ring. It cannot
+// overlap with the ring. Either all of it or nothing is inserted.
+func (r *Ring[T]) Insert(x, y T) error {
+	halves, ok := r.halves(x, y)
+	if !ok {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	r.t.Lock()
+	defer r.t.Unlock()
+	var requested uint64
+	for _, h := range halves {
+		if !r.t.canInsert(h.I, h.J) {
+			return r.t.insertStrict(h.I, h.J) // Fails as Tree.Insert would
+		}
+		requested += ordinal(h.J) - ordinal(h.I) + 1
+	}
+	if remaining := r.t.capacity - r.t.root.getCovered(); r.t.capped && requested > remaining {
+		return r.t.failed(insertErrors, x, y, CapacityError{r.t.capacity, remaining, requested})
+	}
+	for _, h := range halves {
+		r.t.insertStrict(h.I, h.J)
+	}
+	return nil
+}
+
+// Remove deletes [x, y], which wraps around if x > y, from the ring. Every
+// value in it must be contained in the ring. Either all of it or nothing is
+// removed.
+func (r *Ring[T]) Remove(x, y T) error {
+	halves, ok := r.halves(x, y)
+	if !ok {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	r.t.Lock()
+	defer r.t.Unlock()
+	for _, h := range halves {
+		if !r.t.canRemove(h.I, h.J) {
+			return r.t.remove(h.I, h.J) // Fails as Tree.Remove would
+		}
+	}
+	for _, h := range halves {
+		r.t.remove(h.I, h.J)
+	}
+	return nil
+}
+
+// Contains checks if x is contained in the ring.
+func (r *Ring[T]) Contains(x T) bool {
+	return x >= 0 && x < r.n && r.t.Contains(x)
+}
+
+// Next returns the first value not contained in the ring found going forward
+// from x, which is in [0, N), wrapping around past N-1. It returns false if
+// every value is contained or x is outside the ring.
+func (r *Ring[T]) Next(x T) (T, bool) {
+	if x < 0 || x >= r.n {
+		return x, false
+	}
+
+	r.t.RLock()
+	defer r.t.RUnlock()
+	c := r.t.root.containingNode(x)
+	if c == nil {
+		return x, true
+	} else if c.J < r.n-1 {
+		return c.J + 1, true
+	}
+
+	// Contained up to N-1, continue from 0. Intervals are not adjacent, so a
+	// free value is found before x unless c holds every value.
+	switch w := r.t.root.containingNode(0); w {
+	case nil:
+		return 0, true
+	case c:
+		return x, false
+	default:
+		return w.J + 1, true
+	}
+}
+
+// Walk calls fn for the intervals in the ring in ascending order of their
+// first value, with the one wrapping around, if any, as (x, y) with x > y. It
+// stops as soon as fn returns false.
+func (r *Ring[T]) Walk(fn func(x, y T) bool) {
+	r.t.RLock()
+	defer r.t.RUnlock()
+	if r.t.root == nil {
+		return
+	}
+
+	first, last := r.t.root.least(), r.t.root.greatest()
+	wraps := first != last && first.I == 0 && last.J == r.n-1
+	more := true
+	r.t.root.walk(func(x, y T) bool {
+		if wraps && (x == first.I || x == last.I) {
+			return true // Reported as one at the end
+		}
+		more = fn(x, y)
+		return more
+	})
+	if wraps && more {
+		fn(last.I, first.J)
+	}
+}
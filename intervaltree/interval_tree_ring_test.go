@@ -0,0 +1,99 @@
+package intervaltree
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// ringString returns the intervals of r as reported by Walk.
+func ringString[T Integer](r *Ring[T]) string {
+	s := ""
+	r.Walk(func(x, y T) bool {
+		s += fmt.Sprintf("[%d -- %d]", x, y)
+		return true
+	})
+	return s
+}
+
+func TestRing(t *testing.T) {
+	r, err := NewRing[uint8](10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRing[int](0); err == nil {
+		t.Fatal("Created an empty ring")
+	}
+
+	if err := r.Insert(8, 1); err != nil {
+		t.Fatal(err)
+	}
+	if s := ringString(r); s != "[8 -- 1]" {
+		t.Fatalf("Unexpected ring: %s", s)
+	}
+	if !r.Contains(9) || !r.Contains(0) || r.Contains(2) || r.Contains(10) {
+		t.Fatal("Unexpected membership")
+	}
+	if err := r.Insert(4, 5); err != nil {
+		t.Fatal(err)
+	}
+	if s := ringString(r); s != "[4 -- 5][8 -- 1]" {
+		t.Fatalf("Unexpected ring: %s", s)
+	}
+
+	if err := r.Insert(7, 0); !errors.Is(err, ErrOverlap) {
+		t.Fatalf("Expected an overlap error, got %v", err)
+	}
+	if err := r.Insert(2, 10); !errors.Is(err, ErrInvalidInterval) {
+		t.Fatalf("Expected an invalid interval error, got %v", err)
+	}
+	if s := ringString(r); s != "[4 -- 5][8 -- 1]" {
+		t.Fatalf("Ring changed after failed insertions: %s", s)
+	}
+
+	for _, c := range []struct {
+		x, next uint8
+	}{{8, 2}, {0, 2}, {2, 2}, {4, 6}, {6, 6}} {
+		if n, ok := r.Next(c.x); !ok || n != c.next {
+			t.Fatalf("Next(%d) = %d %v, expected %d", c.x, n, ok, c.next)
+		}
+	}
+
+	if err := r.Remove(9, 0); err != nil {
+		t.Fatal(err)
+	}
+	if s := ringString(r); s != "[1 -- 1][4 -- 5][8 -- 8]" {
+		t.Fatalf("Unexpected ring: %s", s)
+	}
+	if err := r.Remove(8, 1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+
+	r.Insert(6, 7)
+	r.Insert(9, 0)
+	r.Insert(2, 3)
+	if s := ringString(r); s != "[0 -- 9]" {
+		t.Fatalf("Unexpected full ring: %s", s)
+	}
+	if _, ok := r.Next(5); ok {
+		t.Fatal("Next found a free value in a full ring")
+	}
+	r.Remove(3, 3)
+	if n, ok := r.Next(4); !ok || n != 3 {
+		t.Fatalf("Next did not wrap around to the only free value: %d %v", n, ok)
+	}
+}
+
+func TestRingCapacity(t *testing.T) {
+	r, _ := NewRing[int](100, WithCapacity(10))
+	var ce CapacityError
+	if err := r.Insert(95, 5); !errors.As(err, &ce) {
+		t.Fatalf("Expected a capacity error, got %v", err)
+	}
+	if r.Contains(95) || r.Contains(0) {
+		t.Fatal("Part of a rejected interval was inserted")
+	}
+	if err := r.Insert(95, 3); err != nil {
+		t.Fatal(err)
+	}
+}
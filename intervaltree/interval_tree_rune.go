@@ -0,0 +1,77 @@
+package intervaltree
+
+import "unicode"
+
+// RuneTree represents a set of runes, such as the character classes of a lexer,
+// held as intervals of code points.
+type RuneTree struct {
+	Tree[rune]
+}
+
+// NewRune returns a pointer to an empty RuneTree.
+func NewRune() *RuneTree {
+	return &RuneTree{}
+}
+
+// InsertRange adds the runes in [lo, hi] to the tree. The range cannot
+// overlap with the tree and must hold valid code points.
+func (t *RuneTree) InsertRange(lo, hi rune) error {
+	if lo < 0 || hi > unicode.MaxRune {
+		return InvalidIntervalError[rune]{lo, hi}
+	}
+	return t.Insert(lo, hi)
+}
+
+// InsertRune adds r to the tree. r cannot be contained in the tree.
+func (t *RuneTree) InsertRune(r rune) error {
+	return t.InsertRange(r, r)
+}
+
+// InsertTable adds the runes in table to the tree. None of them can be
+// contained in the tree.
+func (t *RuneTree) InsertTable(table *unicode.RangeTable) error {
+	for _, r := range table.R16 {
+		if err := t.insertStride(rune(r.Lo), rune(r.Hi), rune(r.Stride)); err != nil {
+			return err
+		}
+	}
+	for _, r := range table.R32 {
+		if err := t.insertStride(rune(r.Lo), rune(r.Hi), rune(r.Stride)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertStride adds the runes in [lo, hi] that are a multiple of stride away
+// from lo to the tree.
+func (t *RuneTree) insertStride(lo, hi, stride rune) error {
+	if stride == 1 {
+		return t.InsertRange(lo, hi)
+	}
+
+	for r := lo; r <= hi; r += stride {
+		if err := t.InsertRune(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ContainsRune checks if r is contained in the tree.
+func (t *RuneTree) ContainsRune(r rune) bool {
+	return t.Contains(r)
+}
+
+// ContainsString checks if every rune in s is contained in the tree.
+func (t *RuneTree) ContainsString(s string) bool {
+	t.RLock()
+	defer t.RUnlock()
+
+	for _, r := range s {
+		if !t.root.contains(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package intervaltree
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestRuneTree(t *testing.T) {
+	ident := NewRune()
+	ident.InsertRange('a', 'z')
+	ident.InsertRange('A', 'Z')
+	ident.InsertRune('_')
+	ident.InsertRange('0', '9')
+
+	if !ident.ContainsRune('q') || !ident.ContainsRune('_') || ident.ContainsRune('-') {
+		t.Fatal("Unexpected membership in identifier class")
+	}
+	if !ident.ContainsString("snake_Case_42") || ident.ContainsString("kebab-case") {
+		t.Fatal("Unexpected string membership in identifier class")
+	}
+	if err := ident.InsertRange('x', 'z'); err == nil {
+		t.Fatal("Inserted overlapping range")
+	}
+	if err := ident.InsertRange(-1, 'a'); err == nil {
+		t.Fatal("Inserted invalid code point")
+	}
+	if err := ident.InsertRune(unicode.MaxRune + 1); err == nil {
+		t.Fatal("Inserted invalid code point")
+	}
+
+	greek := NewRune()
+	if err := greek.InsertTable(unicode.Greek); err != nil {
+		t.Fatalf("Failed to insert Greek table: %v", err)
+	}
+	for r := rune(0); r <= 0x2000; r++ {
+		if greek.ContainsRune(r) != unicode.Is(unicode.Greek, r) {
+			t.Fatalf("Greek class disagrees with unicode.Greek on %U", r)
+		}
+	}
+}
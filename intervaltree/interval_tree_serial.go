@@ -0,0 +1,131 @@
+package intervaltree
+
+import "errors"
+
+// SerialLess checks if the serial number a precedes b as defined by RFC 1982
+// for 32-bit serial numbers, so that comparisons keep working across
+// wraparound: b follows a if it is less than 2^31 values ahead of it, modulo
+// 2^32. Serial numbers exactly 2^31 apart are left undefined by the RFC, and
+// here the greater one precedes the other.
+func SerialLess(a, b uint32) bool {
+	return int32(b-a) > 0
+}
+
+// SerialTree represents a set of 32-bit serial numbers, such as DNS zone
+// serials or TCP-like sequence numbers, compared as defined by RFC 1982. It
+// keeps working across wraparound by unwrapping serial numbers into uint64
+// values held in a Tree, choosing for each the one nearest to the greatest
+// serial number inserted so far. Serial numbers more than 2^31 values behind
+// it are thus taken as ahead of it, so old intervals are expected to be
+// removed before the sequence space wraps around onto them. It is safe for
+// concurrent use unless created with WithoutLocking.
+type SerialTree struct {
+	t   *Tree[uint64]
+	ref uint64 // Greatest unwrapped serial number, guarded by the lock of t
+}
+
+// NewSerial returns a pointer to an empty SerialTree in which serial numbers
+// are compared to start until a later one is inserted, backed by a tree
+// created with opts.
+func NewSerial(start uint32, opts ...Option) *SerialTree {
+	// Starting in the second lap keeps serial numbers behind start unwrappable
+	return &SerialTree{t: NewTree[uint64](opts...), ref: 1<<32 + uint64(start)}
+}
+
+// unwrap returns the unwrapped value of the serial number s nearest to the
+// greatest one inserted. The caller must hold the lock.
+func (t *SerialTree) unwrap(s uint32) uint64 {
+	return uint64(int64(t.ref) + int64(int32(s-uint32(t.ref))))
+}
+
+// interval returns the unwrapped values of the serial numbers in [x, y], and
+// whether y does not precede x.
+func (t *SerialTree) interval(x, y uint32) (uint64, uint64, bool) {
+	i := t.unwrap(x)
+	return i, i + uint64(y-x), x == y || SerialLess(x, y)
+}
+
+// Insert adds the serial numbers in [x, y] to the tree, x not following y. The
+// interval cannot overlap with the tree.
+func (t *SerialTree) Insert(x, y uint32) error {
+	t.t.Lock()
+	defer t.t.Unlock()
+
+	i, j, ok := t.interval(x, y)
+	if !ok {
+		return InvalidIntervalError[uint32]{x, y}
+	}
+	if err := t.t.insert(i, j); err != nil {
+		return serialError(err)
+	}
+	t.ref = max(t.ref, j)
+	return nil
+}
+
+// Remove deletes the serial numbers in [x, y] from the tree, x not following
+// y. Every serial number in it must be contained in the tree.
+func (t *SerialTree) Remove(x, y uint32) error {
+	t.t.Lock()
+	defer t.t.Unlock()
+
+	i, j, ok := t.interval(x, y)
+	if !ok {
+		return InvalidIntervalError[uint32]{x, y}
+	}
+	return serialError(t.t.remove(i, j))
+}
+
+// Contains checks if the serial number s is contained in the tree.
+func (t *SerialTree) Contains(s uint32) bool {
+	t.t.RLock()
+	defer t.t.RUnlock()
+
+	return t.t.root.contains(t.unwrap(s))
+}
+
+// Next returns the first serial number not contained in the tree at or after
+// s. It always exists, since the tree never holds the whole sequence space.
+func (t *SerialTree) Next(s uint32) uint32 {
+	t.t.RLock()
+	defer t.t.RUnlock()
+
+	x := t.unwrap(s)
+	if c := t.t.root.containingNode(x); c != nil {
+		return uint32(c.J + 1)
+	}
+	return s
+}
+
+// Walk calls fn for the intervals in the tree in ascending serial order. It
+// stops as soon as fn returns false. Intervals crossing the wraparound point
+// are reported as (x, y) with x > y.
+func (t *SerialTree) Walk(fn func(x, y uint32) bool) {
+	t.t.RLock()
+	defer t.t.RUnlock()
+
+	t.t.root.walk(func(i, j uint64) bool {
+		return fn(uint32(i), uint32(j))
+	})
+}
+
+// serialError converts the errors of the underlying tree, which hold
+// unwrapped values, into errors holding serial numbers.
+func serialError(err error) error {
+	var oe OverlapError[uint64]
+	var ne NotContainedError[uint64]
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &oe):
+		return OverlapError[uint32]{uint32(oe.Value), serialInterval(oe.Attempted), serialInterval(oe.Existing)}
+	case errors.As(err, &ne):
+		return NotContainedError[uint32]{uint32(ne.Value)}
+	default:
+		return err
+	}
+}
+
+// serialInterval returns the serial numbers of the unwrapped interval i.
+func serialInterval(i Interval[uint64]) Interval[uint32] {
+	return Interval[uint32]{uint32(i.I), uint32(i.J)}
+}
@@ -0,0 +1,102 @@
+package intervaltree
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestSerialLess(t *testing.T) {
+	for _, c := range []struct {
+		a, b     uint32
+		expected bool
+	}{
+		{0, 1, true},
+		{1, 0, false},
+		{5, 5, false},
+		{math.MaxUint32, 0, true},
+		{0, math.MaxUint32, false},
+		{math.MaxUint32 - 10, 10, true},
+		{0, 1<<31 - 1, true},
+		{1<<31 + 1, 0, true},
+	} {
+		if less := SerialLess(c.a, c.b); less != c.expected {
+			t.Fatalf("SerialLess(%d, %d) = %v, expected %v", c.a, c.b, less, c.expected)
+		}
+	}
+}
+
+func TestSerialTree(t *testing.T) {
+	it := NewSerial(math.MaxUint32 - 100)
+	if err := it.Insert(math.MaxUint32-100, math.MaxUint32-50); err != nil {
+		t.Fatal(err)
+	}
+	if err := it.Insert(math.MaxUint32-20, 20); err != nil {
+		t.Fatal(err)
+	}
+	if err := it.Insert(30, 40); err != nil {
+		t.Fatal(err)
+	}
+	if err := it.Insert(10, 5); !errors.Is(err, ErrInvalidInterval) {
+		t.Fatalf("Expected an invalid interval error, got %v", err)
+	}
+
+	var oe OverlapError[uint32]
+	if err := it.Insert(35, 45); !errors.As(err, &oe) || oe.Existing != (Interval[uint32]{30, 40}) {
+		t.Fatalf("Unexpected overlap error: %v", err)
+	}
+	if err := it.Insert(10, 25); !errors.As(err, &oe) || oe.Existing != (Interval[uint32]{math.MaxUint32 - 20, 20}) {
+		t.Fatalf("Unexpected overlap error: %v", err)
+	}
+
+	s := ""
+	it.Walk(func(x, y uint32) bool {
+		s += fmt.Sprintf("[%d -- %d]", x, y)
+		return true
+	})
+	if expected := "[4294967195 -- 4294967245][4294967275 -- 20][30 -- 40]"; s != expected {
+		t.Fatalf("Unexpected serial tree: %s", s)
+	}
+
+	for _, c := range []struct {
+		s        uint32
+		contains bool
+		next     uint32
+	}{
+		{math.MaxUint32, true, 21},
+		{0, true, 21},
+		{25, false, 25},
+		{35, true, 41},
+		{math.MaxUint32 - 30, false, math.MaxUint32 - 30},
+		{math.MaxUint32 - 60, true, math.MaxUint32 - 49},
+	} {
+		if it.Contains(c.s) != c.contains {
+			t.Fatalf("Contains(%d) = %v", c.s, !c.contains)
+		}
+		if n := it.Next(c.s); n != c.next {
+			t.Fatalf("Next(%d) = %d, expected %d", c.s, n, c.next)
+		}
+	}
+
+	// Moving forward across the sequence space for two laps, removing old serial numbers
+	it.Remove(math.MaxUint32-100, math.MaxUint32-50)
+	if err := it.Remove(math.MaxUint32-20, 40); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+	it.Remove(math.MaxUint32-20, 20)
+	it.Remove(30, 40)
+	x := uint32(41)
+	for i := 0; i < 8; i++ {
+		if err := it.Insert(x, x+1<<30-1); err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 {
+			it.Remove(x-1<<30, x-1)
+		}
+		if !it.Contains(x) || it.Contains(x-1) || it.Next(x) != x+1<<30 {
+			t.Fatalf("Unexpected membership around %d", x)
+		}
+		x += 1 << 30
+	}
+}
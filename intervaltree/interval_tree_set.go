@@ -0,0 +1,67 @@
+package intervaltree
+
+// ReadSet is the set of read operations shared by every representation of a
+// set of intervals in this package.
+type ReadSet[T Integer] interface {
+	// Contains checks if x is contained in the set.
+	Contains(x T) bool
+	// Next returns the minimum value not contained in the set that is greater
+	// or equal to x. It returns false if every such value is contained.
+	Next(x T) (T, bool)
+	// Walk calls fn for the intervals in the set in ascending order. It stops
+	// as soon as fn returns false.
+	Walk(fn func(x, y T) bool)
+}
+
+// Set is a set of values of type T built from intervals, in which adjacent
+// intervals are joined. Tree is its default implementation, and the other
+// backends in this package can be swapped for it without changing callers.
+type Set[T Integer] interface {
+	ReadSet[T]
+	// Insert adds [x, y] to the set. It cannot overlap with the set.
+	Insert(x, y T) error
+	// Remove deletes [x, y] from the set. It must be contained in the set.
+	Remove(x, y T) error
+}
+
+// Backend selects the implementation of a Set returned by NewSet.
+type Backend uint8
+
+// Available backends.
+const (
+	AVLBackend            Backend = iota // Tree
+	ArenaBackend                         // ArenaTree
+	BTreeBackend                         // BTree, with a fanout of 32
+	COWBackend                           // COWTree
+	RedBlackBackend                      // RedBlackTree
+	SmallBackend                         // SmallTree
+	TreapBackend                         // TreapTree
+	WeightBalancedBackend                // WeightBalancedTree
+)
+
+// NewSet returns an empty Set implemented by the backend b. Unknown backends
+// fall back to AVLBackend.
+func NewSet[T Integer](b Backend) Set[T] {
+	switch b {
+	case ArenaBackend:
+		return NewArena[T](0)
+	case BTreeBackend:
+		return NewBTree[T](32)
+	case COWBackend:
+		return NewCOW[T]()
+	case RedBlackBackend:
+		return NewRedBlack[T]()
+	case SmallBackend:
+		return NewSmall[T]()
+	case TreapBackend:
+		return NewTreap[T]()
+	case WeightBalancedBackend:
+		return NewWeightBalanced[T]()
+	}
+	return NewTree[T]()
+}
+
+var (
+	_ Set[uint64]     = (*Tree[uint64])(nil)
+	_ ReadSet[uint64] = (*FrozenTree[uint64])(nil)
+)
@@ -0,0 +1,43 @@
+package intervaltree
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// walkString returns the intervals in s in the format of ToString.
+func walkString[T Integer](s ReadSet[T]) string {
+	var b strings.Builder
+	s.Walk(func(x, y T) bool {
+		fmt.Fprintf(&b, "[%d -- %d]", x, y)
+		return true
+	})
+	return b.String()
+}
+
+func TestBackends(t *testing.T) {
+	for b := AVLBackend; b <= WeightBalancedBackend; b++ {
+		r := rand.New(rand.NewSource(1))
+		s, ref := NewSet[uint16](b), NewTree[uint16]()
+		for i := 0; i < 1000; i++ {
+			x := uint16(r.Intn(1000))
+			y := x + uint16(r.Intn(10))
+			if r.Intn(3) == 0 {
+				if errA, errB := s.Remove(x, y), ref.Remove(x, y); (errA == nil) != (errB == nil) {
+					t.Fatalf("Backend %d: Remove [%d, %d] failed differently: %v, %v", b, x, y, errA, errB)
+				}
+			} else if errA, errB := s.Insert(x, y), ref.Insert(x, y); (errA == nil) != (errB == nil) {
+				t.Fatalf("Backend %d: Insert [%d, %d] failed differently: %v, %v", b, x, y, errA, errB)
+			}
+		}
+
+		if walkString[uint16](s) != ref.ToString() {
+			t.Fatalf("Backend %d differs from Tree", b)
+		}
+		if b == AVLBackend && walkString[uint16](ref.Freeze()) != ref.ToString() {
+			t.Fatal("Frozen tree differs from Tree")
+		}
+	}
+}
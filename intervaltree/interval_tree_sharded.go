@@ -0,0 +1,178 @@
+package intervaltree
+
+// ShardedTree represents the same set of intervals as Tree, but partitions a
+// range of values of T into ranges of equal size, each held by its own Tree
+// and guarded by its own lock, so writers to different ranges do not block
+// each other. Intervals spanning several ranges are split among their trees.
+type ShardedTree[T Integer] struct {
+	shards []*Tree[T]
+	lo     uint64 // Ordinal of the least value of the partitioned range
+	width  uint64 // Number of values in each shard
+}
+
+// ShardedIntervalTree is a ShardedTree of uint64 values.
+type ShardedIntervalTree = ShardedTree[uint64]
+
+// NewSharded returns a pointer to an empty ShardedTree partitioning [lo, hi],
+// the values the tree is expected to hold, into the given number of shards,
+// each configured by opts. Values below lo belong to the first shard and
+// values above hi to the last one, so [lo, hi] only affects how writes are
+// spread. There are never more shards than values in [lo, hi].
+func NewSharded[T Integer](lo, hi T, shards int, opts ...Option) *ShardedTree[T] {
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	span := ordinal(hi) - ordinal(lo)
+	if shards < 1 {
+		shards = 1
+	}
+	if uint64(shards-1) > span {
+		shards = int(span + 1)
+	}
+
+	s := &ShardedTree[T]{lo: ordinal(lo), width: span/uint64(shards) + 1}
+	for k := 0; k < shards; k++ {
+		s.shards = append(s.shards, NewTree[T](opts...))
+	}
+	return s
+}
+
+// shard returns the index of the shard holding x.
+func (s *ShardedTree[T]) shard(x T) int {
+	if ordinal(x) < s.lo {
+		return 0
+	}
+	return int(min((ordinal(x)-s.lo)/s.width, uint64(len(s.shards)-1)))
+}
+
+// bounds returns the least and the greatest values held by the shard k.
+func (s *ShardedTree[T]) bounds(k int) (T, T) {
+	least, greatest := Limits[T]()
+	first := s.lo + uint64(k)*s.width
+	last := first + s.width - 1
+	if k == 0 {
+		first = ordinal(least)
+	}
+	if k == len(s.shards)-1 {
+		last = ordinal(greatest)
+	}
+
+	x, _ := fromOrdinal[T](first)
+	y, _ := fromOrdinal[T](last)
+	return x, y
+}
+
+// split calls fn for every shard overlapped by [x, y] with the part of [x, y]
+// it holds, in ascending order. It stops as soon as fn returns an error, and
+// returns that error.
+func (s *ShardedTree[T]) split(x, y T, fn func(k int, x, y T) error) error {
+	for k := s.shard(x); k <= s.shard(y); k++ {
+		i, j := s.bounds(k)
+		if err := fn(k, clamp(x, i, j), clamp(y, i, j)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clamp returns the value in [lo, hi] closest to x.
+func clamp[T Integer](x, lo, hi T) T {
+	if x < lo {
+		return lo
+	}
+	if x > hi {
+		return hi
+	}
+	return x
+}
+
+// lockRange locks the shards overlapped by [x, y] in ascending order and
+// returns a function unlocking them.
+func (s *ShardedTree[T]) lockRange(x, y T) func() {
+	first, last := s.shard(x), s.shard(y)
+	for k := first; k <= last; k++ {
+		s.shards[k].Lock()
+	}
+	return func() {
+		for k := first; k <= last; k++ {
+			s.shards[k].Unlock()
+		}
+	}
+}
+
+// Insert adds an interval to the tree, following the OverlapMode and the
+// capacity each shard was given. If it spans several shards either all of them
+// or none are changed.
+func (s *ShardedTree[T]) Insert(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	defer s.lockRange(x, y)()
+	err := s.split(x, y, func(k int, i, j T) error {
+		var requested uint64
+		err := s.shards[k].checkInsert(i, j, &requested)
+		if oe, ok := err.(OverlapError[T]); ok {
+			oe.Attempted = Interval[T]{x, y}
+			return oe
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.split(x, y, func(k int, i, j T) error {
+		return s.shards[k].insert(i, j)
+	})
+}
+
+// Remove deletes the interval [x, y] from the tree. Every value in [x, y] must
+// be contained in the tree. If it spans several shards either all of them or
+// none are changed.
+func (s *ShardedTree[T]) Remove(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	defer s.lockRange(x, y)()
+	err := s.split(x, y, func(k int, i, j T) error {
+		c := s.shards[k].root.containingNode(i)
+		if c == nil {
+			return NotContainedError[T]{i}
+		}
+		if j > c.J {
+			return NotContainedError[T]{c.J + 1}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.split(x, y, func(k int, i, j T) error {
+		return s.shards[k].remove(i, j)
+	})
+}
+
+// Contains checks if x is contained in the tree.
+func (s *ShardedTree[T]) Contains(x T) bool {
+	return s.shards[s.shard(x)].Contains(x)
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to x. It returns false if every such value is contained. Shards are
+// queried one after another, so values contained in intervals inserted
+// concurrently may be skipped.
+func (s *ShardedTree[T]) Next(x T) (T, bool) {
+	for k := s.shard(x); ; k++ {
+		n, ok := s.shards[k].Next(x)
+		if !ok || k == len(s.shards)-1 {
+			return n, ok
+		}
+		if next, _ := s.bounds(k + 1); n != next {
+			return n, true
+		}
+		x = n // Contained up to the end of the shard, continue in the next one
+	}
+}
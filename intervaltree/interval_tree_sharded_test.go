@@ -0,0 +1,145 @@
+package intervaltree
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestShardedTree(t *testing.T) {
+	st := NewSharded[int8](math.MinInt8, math.MaxInt8, 4)
+	if len(st.shards) != 4 {
+		t.Fatalf("Unexpected number of shards: %d", len(st.shards))
+	}
+	if i, j := st.bounds(0); i != math.MinInt8 || j != -65 {
+		t.Fatalf("Unexpected bounds of the first shard: [%d, %d]", i, j)
+	}
+	if i, j := st.bounds(3); i != 64 || j != math.MaxInt8 {
+		t.Fatalf("Unexpected bounds of the last shard: [%d, %d]", i, j)
+	}
+
+	if err := st.Insert(-100, 100); err != nil {
+		t.Fatalf("Failed to insert interval spanning every shard: %v", err)
+	}
+	if err := st.Insert(101, 110); err != nil {
+		t.Fatalf("Failed to insert neighbouring interval: %v", err)
+	}
+	if n := next(st, -90); n != 111 {
+		t.Fatalf("Next was not stitched across shards: %d", n)
+	}
+	if err := st.Insert(-120, -100); err != (OverlapError[int8]{-100, Interval[int8]{-120, -100}, Interval[int8]{-100, -65}}) {
+		t.Fatalf("Unexpected error inserting overlapping interval: %v", err)
+	}
+	if st.Contains(-120) {
+		t.Fatal("Failed insert changed a shard")
+	}
+
+	if err := st.Remove(-10, 120); err != (NotContainedError[int8]{111}) {
+		t.Fatalf("Unexpected error removing uncontained interval: %v", err)
+	}
+	if !st.Contains(0) {
+		t.Fatal("Failed remove changed a shard")
+	}
+	if err := st.Remove(-10, 10); err != nil {
+		t.Fatalf("Failed to remove interval spanning two shards: %v", err)
+	}
+	if st.Contains(0) || !st.Contains(-11) || !st.Contains(11) || next(st, -20) != -10 {
+		t.Fatal("Unexpected contents after removal")
+	}
+
+	if len(NewSharded[uint8](0, math.MaxUint8, 1000).shards) != 256 {
+		t.Fatal("More shards than values")
+	}
+}
+
+func TestShardedTreeDense(t *testing.T) {
+	st := NewSharded[uint64](0, 999, 4)
+	for x := uint64(0); x < 1000; x += 2 {
+		st.Insert(x, x)
+	}
+	for k, s := range st.shards {
+		if n := s.Len(); n != 125 {
+			t.Fatalf("Shard %d holds %d intervals", k, n)
+		}
+	}
+
+	if err := st.Insert(5000, 6000); err != nil || st.shard(6000) != 3 || !st.Contains(5500) {
+		t.Fatalf("Failed to insert interval above the domain: %v", err)
+	}
+	if i, j := st.bounds(3); i != 750 || j != math.MaxUint64 {
+		t.Fatalf("Unexpected bounds of the last shard: [%d, %d]", i, j)
+	}
+	if i, j := NewSharded[int](-10, 10, 2).bounds(0); i != math.MinInt || j != 0 {
+		t.Fatalf("Unexpected bounds of the first shard: [%d, %d]", i, j)
+	}
+}
+
+func TestShardedTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	st, it := NewSharded[uint16](50000, 1000, 7), NewTree[uint16]()
+	for i := 0; i < 3000; i++ {
+		x := uint16(r.Intn(math.MaxUint16 + 1))
+		y := x + uint16(r.Intn(math.MaxUint16-int(x)+1)%20000)
+		if r.Intn(3) == 0 {
+			if errA, errB := st.Remove(x, y), it.Remove(x, y); (errA == nil) != (errB == nil) {
+				t.Fatalf("Remove [%d, %d] failed differently: %v, %v", x, y, errA, errB)
+			}
+		} else if errA, errB := st.Insert(x, y), it.Insert(x, y); (errA == nil) != (errB == nil) {
+			t.Fatalf("Insert [%d, %d] failed differently: %v, %v", x, y, errA, errB)
+		}
+	}
+
+	for x := 0; x < math.MaxUint16; x += 13 {
+		if st.Contains(uint16(x)) != it.Contains(uint16(x)) || !sameNext(st, it, uint16(x)) {
+			t.Fatalf("Trees differ at %d", x)
+		}
+	}
+}
+
+func TestShardedTreeConcurrent(t *testing.T) {
+	st := NewSharded[uint64](0, 16000, 8)
+	var wg sync.WaitGroup
+	for g := uint64(0); g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for x := uint64(0); x < 1000; x++ {
+				st.Insert(g*2000+2*x, g*2000+2*x)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for g := uint64(0); g < 8; g++ {
+		if next(st, g*2000+998) != g*2000+999 {
+			t.Fatalf("Missing intervals in shard %d", g)
+		}
+	}
+}
+
+func TestShardedTreeOptions(t *testing.T) {
+	st := NewSharded[uint64](0, 100, 2, WithCapacity(40))
+	var ce CapacityError
+	if err := st.Insert(40, 99); !errors.As(err, &ce) {
+		t.Fatalf("Expected a capacity error, got %v", err)
+	}
+	if st.Contains(40) || st.Contains(99) {
+		t.Fatal("Failed insert changed a shard")
+	}
+	if err := st.Insert(20, 70); err != nil {
+		t.Fatalf("Failed to insert interval within the capacity of both shards: %v", err)
+	}
+
+	st = NewSharded[uint64](0, 100, 2, WithOverlapMode(OverlapUnion))
+	if err := st.Insert(0, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Insert(0, 60); err != nil {
+		t.Fatalf("Union insert failed: %v", err)
+	}
+	if n := next(st, 0); n != 61 {
+		t.Fatalf("Unexpected union: next free value %d", n)
+	}
+}
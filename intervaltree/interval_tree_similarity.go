@@ -0,0 +1,62 @@
+package intervaltree
+
+// Similarity measures how much two sets of values have in common, such as a
+// tree and a replica of it which may have diverged.
+type Similarity struct {
+	Intersection uint64  // Values contained in both sets, 0 if every value of a 64-bit T is
+	Union        uint64  // Values contained in either set, 0 if every value of a 64-bit T is
+	Jaccard      float64 // Intersection over union, or 1 if both sets are empty
+}
+
+// Similarity returns how much the tree and o have in common, computed in a
+// single in-order merge of their nodes, without copying their intervals. Both
+// trees are read under their read locks at once, so the result is consistent
+// across them.
+func (t *Tree[T]) Similarity(o *Tree[T]) Similarity {
+	rlockPair(t, o)
+	defer runlockPair(t, o)
+
+	var s Similarity
+	var inter float64 // For the ratio, as sizes may reach 2^64
+	b := o.root
+	if b != nil {
+		b = b.least()
+	}
+	t.root.walk(func(x, y T) bool {
+		for ; b != nil && b.I <= y; b = o.root.higher(b.I) {
+			if i := (Interval[T]{max(x, b.I), min(y, b.J)}); i.I <= i.J {
+				s.Intersection += ordinal(i.J) - ordinal(i.I) + 1
+				inter += intervalSize(i)
+			}
+			if b.J > y { // b may still overlap the next interval of the tree
+				break
+			}
+		}
+		return true
+	})
+
+	s.Union = t.root.getCovered() + o.root.getCovered() - s.Intersection // Arithmetic is modulo 2^64
+	s.Jaccard = 1
+	if union := t.root.size() + o.root.size() - inter; union > 0 {
+		s.Jaccard = inter / union
+	}
+	return s
+}
+
+// size returns the number of values in the subtree rooted at this node as a
+// float64, which holds 2^64 for every value of a 64-bit T.
+func (n *node[T]) size() float64 {
+	if n == nil {
+		return 0
+	}
+	if n.covered == 0 { // Every value, as the subtree is not empty
+		return 1 << 64
+	}
+	return float64(n.covered)
+}
+
+// intervalSize returns the number of values in i as a float64, which holds
+// 2^64 for every value of a 64-bit T.
+func intervalSize[T Integer](i Interval[T]) float64 {
+	return float64(ordinal(i.J)-ordinal(i.I)) + 1
+}
@@ -0,0 +1,79 @@
+package intervaltree
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	a, b := NewTree[int16](), NewTree[int16]()
+	if s := a.Similarity(b); s != (Similarity{0, 0, 1}) {
+		t.Fatalf("Unexpected similarity of empty trees: %+v", s)
+	}
+
+	a.Insert(0, 9)
+	a.Insert(20, 29)
+	a.Insert(100, 199)
+	b.Insert(5, 24)
+	b.Insert(150, 149+50)
+	b.Insert(300, 329)
+	if s := a.Similarity(b); s != (Similarity{5 + 5 + 50, 120 + 100 - 60, 60.0 / 160}) {
+		t.Fatalf("Unexpected similarity: %+v", s)
+	}
+	if s := b.Similarity(a); s != (Similarity{60, 160, 60.0 / 160}) {
+		t.Fatalf("Similarity is not symmetric: %+v", s)
+	}
+	if s := a.Similarity(a); s != (Similarity{120, 120, 1}) {
+		t.Fatalf("Unexpected similarity of a tree with itself: %+v", s)
+	}
+	if s := a.Similarity(NewTree[int16]()); s != (Similarity{0, 120, 0}) {
+		t.Fatalf("Unexpected similarity with an empty tree: %+v", s)
+	}
+
+	full, half := New(), New()
+	full.Insert(0, math.MaxUint64)
+	half.Insert(0, math.MaxUint64/2)
+	if s := full.Similarity(half); s != (Similarity{1 << 63, 0, 0.5}) {
+		t.Fatalf("Unexpected similarity of 64-bit trees: %+v", s)
+	}
+}
+
+func TestSimilarityMerge(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	a, b := NewTree[uint8](), NewTree[uint8]()
+	for range 40 {
+		x := uint8(rng.Intn(250))
+		a.Insert(x, x+uint8(rng.Intn(5)))
+		x = uint8(rng.Intn(250))
+		b.Insert(x, x+uint8(rng.Intn(5)))
+	}
+
+	var expected Similarity
+	for x := range 256 {
+		inA, inB := a.Contains(uint8(x)), b.Contains(uint8(x))
+		if inA && inB {
+			expected.Intersection++
+		}
+		if inA || inB {
+			expected.Union++
+		}
+	}
+	expected.Jaccard = float64(expected.Intersection) / float64(expected.Union)
+	if s := a.Similarity(b); s != expected {
+		t.Fatalf("Unexpected similarity: %+v, expected %+v", s, expected)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { a.Similarity(b) }); allocs != 0 {
+		t.Fatalf("Similarity allocated %v times", allocs)
+	}
+
+	// Trees sharing a lock are only locked once.
+	var mu sync.Mutex
+	c, d := NewTree[uint8](WithLocker(&mu)), NewTree[uint8](WithLocker(&mu))
+	c.Insert(1, 4)
+	d.Insert(3, 8)
+	if s := c.Similarity(d); s != (Similarity{2, 8, 0.25}) {
+		t.Fatalf("Unexpected similarity of trees sharing a lock: %+v", s)
+	}
+}
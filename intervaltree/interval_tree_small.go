@@ -0,0 +1,166 @@
+package intervaltree
+
+import (
+	"slices"
+	"sort"
+	"sync"
+)
+
+// smallThreshold is the greatest number of intervals a SmallTree holds in a
+// slice before being promoted to a Tree.
+const smallThreshold = 8
+
+// SmallTree represents the same set of intervals as Tree, but starts as a
+// small sorted slice of intervals, searched with binary search, and becomes a
+// Tree only once it holds more than a few intervals. Most sets hold so few
+// intervals that following pointers through a tree is not worth it.
+type SmallTree[T Integer] struct {
+	small []Interval[T] // Intervals in ascending order, until promoted
+	tree  *Tree[T]      // Tree holding the intervals once promoted
+	sync.RWMutex
+}
+
+// NewSmall returns a pointer to an empty SmallTree.
+func NewSmall[T Integer]() *SmallTree[T] {
+	return &SmallTree[T]{}
+}
+
+// search returns the number of intervals in the slice starting at or before x.
+func (s *SmallTree[T]) search(x T) int {
+	return sort.Search(len(s.small), func(k int) bool { return s.small[k].I > x })
+}
+
+// promote moves the intervals into a Tree if there are too many of them.
+func (s *SmallTree[T]) promote() {
+	if len(s.small) <= smallThreshold {
+		return
+	}
+
+	s.tree = NewTree[T](WithoutLocking()) // Guarded by the lock of s
+	s.tree.root = build(s.small)
+	s.small = nil
+}
+
+// Insert adds an interval to the tree. The interval cannot overlap with the
+// tree. If prunning is possible it will be done.
+func (s *SmallTree[T]) Insert(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if s.tree != nil {
+		return s.tree.Insert(x, y)
+	}
+
+	k := s.search(x) // The intervals k-1 and k surround [x, y]
+	if k > 0 && s.small[k-1].J >= x {
+		return OverlapError[T]{x, Interval[T]{x, y}, s.small[k-1]}
+	}
+	if k < len(s.small) && s.small[k].I <= y {
+		return OverlapError[T]{s.small[k].I, Interval[T]{x, y}, s.small[k]}
+	}
+
+	// Neighbours are checked stepping towards the lesser value, so no check
+	// can overflow at the bounds of T
+	joinL := k > 0 && s.small[k-1].J == x-1
+	joinR := k < len(s.small) && s.small[k].I-1 == y
+	switch {
+	case joinL && joinR: // Fill the gap between k-1 and k
+		s.small[k-1].J = s.small[k].J
+		s.small = slices.Delete(s.small, k, k+1)
+	case joinL:
+		s.small[k-1].J = y
+	case joinR:
+		s.small[k].I = x
+	default:
+		s.small = slices.Insert(s.small, k, Interval[T]{x, y})
+		s.promote()
+	}
+	return nil
+}
+
+// Remove deletes the interval [x, y] from the tree. Every value in [x, y] must
+// be contained in the tree. Stored intervals are shrunk or split as needed.
+func (s *SmallTree[T]) Remove(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if s.tree != nil {
+		return s.tree.Remove(x, y)
+	}
+
+	// Stored intervals are never adjacent, so [x, y] must lie in a single one
+	k := s.search(x) - 1
+	if k < 0 || s.small[k].J < x {
+		return NotContainedError[T]{x}
+	}
+	c := &s.small[k]
+	if y > c.J {
+		return NotContainedError[T]{c.J + 1}
+	}
+
+	switch {
+	case x == c.I && y == c.J:
+		s.small = slices.Delete(s.small, k, k+1)
+	case x == c.I:
+		c.I = y + 1
+	case y == c.J:
+		c.J = x - 1
+	default: // Split, the upper part becomes a new interval
+		j := c.J
+		c.J = x - 1
+		s.small = slices.Insert(s.small, k+1, Interval[T]{y + 1, j})
+		s.promote()
+	}
+	return nil
+}
+
+// Contains checks if x is contained in the tree.
+func (s *SmallTree[T]) Contains(x T) bool {
+	s.RLock()
+	defer s.RUnlock()
+	if s.tree != nil {
+		return s.tree.Contains(x)
+	}
+
+	k := s.search(x)
+	return k > 0 && x <= s.small[k-1].J
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to x. It returns false if every such value is contained.
+func (s *SmallTree[T]) Next(x T) (T, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.tree != nil {
+		return s.tree.Next(x)
+	}
+
+	k := s.search(x)
+	if k == 0 || x > s.small[k-1].J {
+		return x, true
+	}
+	return after(s.small[k-1].J)
+}
+
+// Walk calls fn for the intervals in the tree in ascending order. It stops as
+// soon as fn returns false. The tree cannot be changed from fn.
+func (s *SmallTree[T]) Walk(fn func(x, y T) bool) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.tree != nil {
+		s.tree.Walk(fn)
+		return
+	}
+
+	for _, i := range s.small {
+		if !fn(i.I, i.J) {
+			return
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package intervaltree
+
+import "testing"
+
+func TestSmallTree(t *testing.T) {
+	st := NewSmall[uint8]()
+	for _, i := range []Interval[uint8]{{10, 20}, {30, 40}, {0, 5}, {250, 255}, {21, 29}} {
+		if err := st.Insert(i.I, i.J); err != nil {
+			t.Fatalf("Failed to insert %v: %v", i, err)
+		}
+	}
+	if err := st.Insert(40, 45); err != (OverlapError[uint8]{40, Interval[uint8]{40, 45}, Interval[uint8]{10, 40}}) {
+		t.Fatalf("Unexpected error inserting overlapping interval: %v", err)
+	}
+	if s := walkString[uint8](st); s != "[0 -- 5][10 -- 40][250 -- 255]" {
+		t.Fatalf("Unexpected intervals: %s", s)
+	}
+	if !full(st, 255) || next(st, 12) != 41 || next(st, 7) != 7 || !st.Contains(0) || st.Contains(6) {
+		t.Fatal("Unexpected lookups")
+	}
+	if err := st.Remove(15, 16); err != nil {
+		t.Fatalf("Failed to split interval: %v", err)
+	}
+	if s := walkString[uint8](st); s != "[0 -- 5][10 -- 14][17 -- 40][250 -- 255]" {
+		t.Fatalf("Unexpected intervals after removal: %s", s)
+	}
+	if st.tree != nil {
+		t.Fatal("Small tree was promoted")
+	}
+
+	for x := uint8(100); x < 120; x += 2 {
+		st.Insert(x, x)
+	}
+	if st.tree == nil || st.small != nil {
+		t.Fatal("Tree was not promoted")
+	}
+	if s := walkString[uint8](st); s != "[0 -- 5][10 -- 14][17 -- 40][100 -- 100][102 -- 102][104 -- 104][106 -- 106][108 -- 108][110 -- 110][112 -- 112][114 -- 114][116 -- 116][118 -- 118][250 -- 255]" {
+		t.Fatalf("Unexpected intervals after promotion: %s", s)
+	}
+	if err := st.tree.root.isAVL(); err != nil {
+		t.Fatalf("Promoted tree is not AVL: %v", err)
+	}
+}
@@ -0,0 +1,417 @@
+package intervaltree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"unsafe"
+)
+
+// spillPageSize is the greatest number of intervals held by a page of a
+// SpillTree. A page is written to a slot of the file taking mappedPageSize
+// bytes, so pages are split once they grow past it.
+const spillPageSize = mappedPerPage
+
+// spillPage holds a run of consecutive intervals of a SpillTree. Its bounds
+// and size are always in memory, its intervals only while it is resident.
+type spillPage[T Integer] struct {
+	first, last T             // Least and greatest values contained in the page
+	n           int           // Number of intervals
+	data        []Interval[T] // Intervals in ascending order, nil unless resident
+	slot        int64         // Slot of the file holding the page, -1 if none
+	dirty       bool          // Whether data differs from the slot
+	used        uint64        // Tick of the last access, for eviction
+}
+
+// SpillTree represents the same set of intervals as Tree, but keeps them in
+// pages of a few hundred intervals of which only the most recently used stay
+// in memory. Cold pages are spilled to a temporary file and read back when
+// they are touched, so a tree far larger than the available memory can be
+// updated and queried, at the cost of disk accesses on cache misses. The
+// bounds of each page stay in memory, so lookups of values outside of every
+// page never touch the disk.
+//
+// An I/O error makes Insert and Remove fail with it. Queries cannot fail, so
+// they report a value missing from a page that cannot be read as not
+// contained, and the first such error is returned by Err.
+type SpillTree[T Integer] struct {
+	f        *os.File
+	pages    []*spillPage[T] // Non-empty pages in ascending order
+	resident int             // Number of pages held in memory
+	limit    int             // Greatest number of pages held in memory
+	tick     uint64          // Incremented on every access to a page
+	free     []int64         // Slots of the file no longer used
+	slots    int64           // Number of slots of the file
+	err      error           // First error of a query
+	sync.Mutex
+}
+
+// NewSpill returns a pointer to an empty SpillTree keeping about memory bytes
+// of intervals in memory, and never less than a few pages, and spilling the
+// rest to a temporary file in dir, or in os.TempDir if dir is empty. The tree
+// must be closed with Close to remove the file.
+func NewSpill[T Integer](dir string, memory int) (*SpillTree[T], error) {
+	f, err := os.CreateTemp(dir, "intervaltree-spill-*")
+	if err != nil {
+		return nil, err
+	}
+
+	pageBytes := spillPageSize * int(unsafe.Sizeof(Interval[T]{}))
+	return &SpillTree[T]{f: f, limit: max(memory/pageBytes, 3)}, nil
+}
+
+// Close closes and removes the file holding the spilled pages. The tree must
+// not be used afterwards.
+func (s *SpillTree[T]) Close() error {
+	s.Lock()
+	defer s.Unlock()
+
+	err := s.f.Close()
+	if rmErr := os.Remove(s.f.Name()); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// Err returns the first I/O error found by a query, or nil if there was none.
+func (s *SpillTree[T]) Err() error {
+	s.Lock()
+	defer s.Unlock()
+	return s.err
+}
+
+// end finishes an operation, which may have loaded several pages, evicting
+// the least recently used ones until no more than the limit are held in
+// memory. It stops at the first page that cannot be written, and returns the
+// error.
+func (s *SpillTree[T]) end() error {
+	for s.resident > s.limit {
+		var lru *spillPage[T]
+		for _, p := range s.pages {
+			if p.data != nil && (lru == nil || p.used < lru.used) {
+				lru = p
+			}
+		}
+		if err := s.evict(lru); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// endQuery finishes a query as end does, recording the error if a page cannot
+// be written.
+func (s *SpillTree[T]) endQuery() {
+	if err := s.end(); err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// evict writes p to its slot of the file if it changed and drops it from
+// memory. p is kept in memory if it cannot be written, to be retried later.
+func (s *SpillTree[T]) evict(p *spillPage[T]) error {
+	if p.dirty {
+		if p.slot < 0 {
+			if len(s.free) > 0 {
+				p.slot, s.free = s.free[len(s.free)-1], s.free[:len(s.free)-1]
+			} else {
+				p.slot, s.slots = s.slots, s.slots+1
+			}
+		}
+
+		buf := make([]byte, 0, len(p.data)*mappedEntrySize)
+		for _, i := range p.data {
+			buf = binary.LittleEndian.AppendUint64(buf, ordinal(i.I))
+			buf = binary.LittleEndian.AppendUint64(buf, ordinal(i.J))
+		}
+		if _, err := s.f.WriteAt(buf, p.slot*mappedPageSize); err != nil {
+			return err
+		}
+	}
+	p.data, p.dirty = nil, false
+	s.resident--
+	return nil
+}
+
+// load reads p back into memory if it was spilled and marks it as used.
+func (s *SpillTree[T]) load(p *spillPage[T]) ([]Interval[T], error) {
+	s.tick++
+	p.used = s.tick
+	if p.data != nil {
+		return p.data, nil
+	}
+
+	buf := make([]byte, p.n*mappedEntrySize)
+	if _, err := s.f.ReadAt(buf, p.slot*mappedPageSize); err != nil {
+		return nil, fmt.Errorf("Cannot read spilled page: %w", err)
+	}
+	data := make([]Interval[T], p.n, spillPageSize+1)
+	for k := range data {
+		i, _ := fromOrdinal[T](binary.LittleEndian.Uint64(buf[k*mappedEntrySize:]))
+		j, _ := fromOrdinal[T](binary.LittleEndian.Uint64(buf[k*mappedEntrySize+8:]))
+		data[k] = Interval[T]{i, j}
+	}
+	p.data = data
+	s.resident++
+	return data, nil
+}
+
+// query loads p for a query, recording the error if it cannot be read.
+func (s *SpillTree[T]) query(p *spillPage[T]) []Interval[T] {
+	data, err := s.load(p)
+	if err != nil && s.err == nil {
+		s.err = err
+	}
+	return data
+}
+
+// locate returns the index of the last page starting at or before x, or 0 if
+// there is none.
+func (s *SpillTree[T]) locate(x T) int {
+	return max(sort.Search(len(s.pages), func(k int) bool { return s.pages[k].first > x })-1, 0)
+}
+
+// changed updates the bounds of the page k after its intervals changed,
+// dropping it if it became empty and splitting it if it grew too large.
+func (s *SpillTree[T]) changed(k int) {
+	p := s.pages[k]
+	p.dirty = true
+	if p.n = len(p.data); p.n == 0 {
+		if p.slot >= 0 {
+			s.free = append(s.free, p.slot)
+		}
+		s.pages = slices.Delete(s.pages, k, k+1)
+		s.resident--
+		return
+	}
+	p.first, p.last = p.data[0].I, p.data[p.n-1].J
+
+	if p.n > spillPageSize {
+		half := p.n / 2
+		upper := &spillPage[T]{data: slices.Clone(p.data[half:]), slot: -1, used: p.used}
+		p.data = p.data[:half:half]
+		s.pages = slices.Insert(s.pages, k+1, upper)
+		s.resident++
+		s.changed(k)
+		s.changed(k + 1)
+	}
+}
+
+// Insert adds an interval to the tree. The interval cannot overlap with the
+// tree. If prunning is possible it will be done.
+func (s *SpillTree[T]) Insert(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	err := s.insert(x, y)
+	if endErr := s.end(); err == nil {
+		err = endErr
+	}
+	return err
+}
+
+// insert adds [x, y] to the tree, loading the pages it touches.
+func (s *SpillTree[T]) insert(x, y T) error {
+	if len(s.pages) == 0 {
+		s.pages = []*spillPage[T]{{data: []Interval[T]{{x, y}}, slot: -1}}
+		s.resident++
+		s.changed(0)
+		return nil
+	}
+
+	k := s.locate(x)
+	data, err := s.load(s.pages[k])
+	if err != nil {
+		return err
+	}
+
+	// The intervals idx-1 and idx surround [x, y]. The latter is the first of
+	// the next page if every interval of page k starts before x, which is
+	// only loaded if it is adjacent to or overlaps [x, y].
+	idx := sort.Search(len(data), func(i int) bool { return data[i].I > x })
+	if idx > 0 && data[idx-1].J >= x {
+		return OverlapError[T]{x, Interval[T]{x, y}, data[idx-1]}
+	}
+	var succ *Interval[T]
+	next := k // Page holding succ
+	if idx < len(data) {
+		succ = &data[idx]
+	} else if k+1 < len(s.pages) && s.pages[k+1].first-1 <= y {
+		nextData, err := s.load(s.pages[k+1])
+		if err != nil {
+			return err
+		}
+		succ, next = &nextData[0], k+1
+	}
+	if succ != nil && succ.I <= y {
+		return OverlapError[T]{succ.I, Interval[T]{x, y}, *succ}
+	}
+
+	// Neighbours are checked stepping towards the lesser value, so no check
+	// can overflow at the bounds of T
+	joinL := idx > 0 && data[idx-1].J == x-1
+	joinR := succ != nil && succ.I-1 == y
+	p := s.pages[k]
+	switch {
+	case joinL && joinR && next == k: // Fill the gap between idx-1 and idx
+		p.data[idx-1].J = p.data[idx].J
+		p.data = slices.Delete(p.data, idx, idx+1)
+		s.changed(k)
+	case joinL && joinR: // Fill the gap between pages k and k+1
+		p.data[idx-1].J = succ.J
+		np := s.pages[next]
+		np.data = slices.Delete(np.data, 0, 1)
+		s.changed(next) // Before k, whose split would shift next
+		s.changed(k)
+	case joinL:
+		p.data[idx-1].J = y
+		s.changed(k)
+	case joinR:
+		succ.I = x
+		s.changed(next)
+	default:
+		p.data = slices.Insert(p.data, idx, Interval[T]{x, y})
+		s.changed(k)
+	}
+	return nil
+}
+
+// Remove deletes the interval [x, y] from the tree. Every value in [x, y] must
+// be contained in the tree. Stored intervals are shrunk or split as needed.
+func (s *SpillTree[T]) Remove(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	err := s.remove(x, y)
+	if endErr := s.end(); err == nil {
+		err = endErr
+	}
+	return err
+}
+
+// remove deletes [x, y] from the tree, loading the page holding it.
+func (s *SpillTree[T]) remove(x, y T) error {
+	k := s.locate(x)
+	if len(s.pages) == 0 || x < s.pages[k].first || x > s.pages[k].last {
+		return NotContainedError[T]{x}
+	}
+	data, err := s.load(s.pages[k])
+	if err != nil {
+		return err
+	}
+
+	// Stored intervals are never adjacent, so [x, y] must lie in a single one
+	idx := sort.Search(len(data), func(i int) bool { return data[i].I > x }) - 1
+	if idx < 0 || data[idx].J < x {
+		return NotContainedError[T]{x}
+	}
+	p := s.pages[k]
+	c := &p.data[idx]
+	if y > c.J {
+		return NotContainedError[T]{c.J + 1}
+	}
+
+	switch {
+	case x == c.I && y == c.J:
+		p.data = slices.Delete(p.data, idx, idx+1)
+	case x == c.I:
+		c.I = y + 1
+	case y == c.J:
+		c.J = x - 1
+	default: // Split, the upper part becomes a new interval
+		j := c.J
+		c.J = x - 1
+		p.data = slices.Insert(p.data, idx+1, Interval[T]{y + 1, j})
+	}
+	s.changed(k)
+	return nil
+}
+
+// containing returns the interval holding x and whether there is one. The
+// caller must hold the lock.
+func (s *SpillTree[T]) containing(x T) (Interval[T], bool) {
+	k := s.locate(x)
+	if len(s.pages) == 0 || x < s.pages[k].first || x > s.pages[k].last {
+		return Interval[T]{}, false
+	}
+
+	data := s.query(s.pages[k])
+	idx := sort.Search(len(data), func(i int) bool { return data[i].I > x }) - 1
+	if idx < 0 || data[idx].J < x {
+		return Interval[T]{}, false
+	}
+	return data[idx], true
+}
+
+// Contains checks if x is contained in the tree.
+func (s *SpillTree[T]) Contains(x T) bool {
+	s.Lock()
+	defer s.Unlock()
+	defer s.endQuery()
+
+	_, ok := s.containing(x)
+	return ok
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to x. It returns false if every such value is contained.
+func (s *SpillTree[T]) Next(x T) (T, bool) {
+	s.Lock()
+	defer s.Unlock()
+	defer s.endQuery()
+
+	c, ok := s.containing(x)
+	if !ok {
+		return x, true
+	}
+	return after(c.J)
+}
+
+// Walk calls fn for the intervals in the tree in ascending order. It stops as
+// soon as fn returns false. The tree cannot be changed from fn. Pages are
+// loaded one at a time, so walking the tree keeps within its memory budget.
+func (s *SpillTree[T]) Walk(fn func(x, y T) bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	for k := 0; k < len(s.pages); k++ {
+		data := s.query(s.pages[k])
+		s.endQuery()
+		for _, i := range data {
+			if !fn(i.I, i.J) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of intervals in the tree.
+func (s *SpillTree[T]) Len() int {
+	s.Lock()
+	defer s.Unlock()
+
+	var n int
+	for _, p := range s.pages {
+		n += p.n
+	}
+	return n
+}
+
+// Resident returns the number of pages of the tree held in memory and the
+// total number of pages.
+func (s *SpillTree[T]) Resident() (resident, pages int) {
+	s.Lock()
+	defer s.Unlock()
+	return s.resident, len(s.pages)
+}
+
+var _ Set[uint64] = (*SpillTree[uint64])(nil)
@@ -0,0 +1,95 @@
+package intervaltree
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSpillTree(t *testing.T) {
+	s, err := NewSpill[uint16](t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	r := rand.New(rand.NewSource(1))
+	it := NewTree[uint16]()
+	for i := 0; i < 20000; i++ {
+		x := uint16(r.Intn(65536))
+		y := x + uint16(r.Intn(min(65536-int(x), 8)))
+		if r.Intn(3) == 0 && it.Contains(x) { // Shrink, split or remove an interval
+			if n, ok := it.Next(x); ok {
+				y = min(y, n-1)
+			}
+			if err := s.Remove(x, y); err != nil {
+				t.Fatalf("Failed to remove [%d, %d]: %v", x, y, err)
+			}
+			it.Remove(x, y)
+		} else if err, expected := s.Insert(x, y), it.Insert(x, y); (err == nil) != (expected == nil) {
+			t.Fatalf("Insert(%d, %d) failed with %v, expected %v", x, y, err, expected)
+		}
+
+		if i%1000 == 0 {
+			for k := 0; k < 200; k++ {
+				v := uint16(r.Intn(65536))
+				if s.Contains(v) != it.Contains(v) {
+					t.Fatalf("Contains(%d) differs", v)
+				}
+				n, ok := s.Next(v)
+				if en, eok := it.Next(v); n != en || ok != eok {
+					t.Fatalf("Next(%d) = %d, %v, expected %d, %v", v, n, ok, en, eok)
+				}
+			}
+		}
+	}
+
+	var got []Interval[uint16]
+	s.Walk(func(x, y uint16) bool {
+		got = append(got, Interval[uint16]{x, y})
+		return true
+	})
+	if expected := it.Freeze().intervals; !slices.Equal(got, expected) {
+		t.Fatalf("Walk listed %d intervals, expected %d", len(got), len(expected))
+	}
+	if s.Len() != it.Len() {
+		t.Fatalf("Unexpected Len %d, expected %d", s.Len(), it.Len())
+	}
+	if resident, pages := s.Resident(); resident > 3 || pages <= 3 {
+		t.Fatalf("Unexpected %d resident pages out of %d", resident, pages)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSpillTreeErrors(t *testing.T) {
+	s, err := NewSpill[uint32](t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for x := uint32(0); x < 4*spillPageSize; x++ {
+		if err := s.Insert(2*x, 2*x); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Insert(4, 10); err == nil {
+		t.Fatal("Inserted overlapping interval")
+	}
+	if err := s.Remove(1, 1); err == nil {
+		t.Fatal("Removed interval not contained")
+	}
+
+	s.f.Close()                        // Spilled pages can no longer be read
+	if s.Contains(2 * spillPageSize) { // In a page evicted long ago
+		t.Fatal("Unreadable page reported as containing a value")
+	}
+	if s.Err() == nil {
+		t.Fatal("Unreadable page not reported by Err")
+	}
+	if err := s.Insert(2*spillPageSize+1, 2*spillPageSize+1); err == nil {
+		t.Fatal("Inserted into an unreadable page")
+	}
+}
@@ -0,0 +1,28 @@
+package intervaltree
+
+// Stats holds a consistent snapshot of figures describing a tree.
+type Stats[T Integer] struct {
+	Height    int    // Nodes on the longest path from the root to a leaf
+	Intervals int    // Number of intervals
+	Covered   uint64 // Number of values contained, 0 if every value of a 64-bit T is
+	Gaps      int    // Number of gaps between Min and Max
+	Min, Max  T      // Least and greatest values contained, if any
+}
+
+// Stats returns figures describing the tree, gathered under a single lock
+// acquisition in O( log n ), so it can be scraped often.
+func (t *Tree[T]) Stats() Stats[T] {
+	t.RLock()
+	defer t.RUnlock()
+
+	s := Stats[T]{
+		Height:    int(t.root.getHeight()),
+		Intervals: t.root.getIntervals(),
+		Covered:   t.root.getCovered(),
+	}
+	if t.root != nil {
+		s.Min, s.Max = t.root.least().I, t.root.greatest().J
+		s.Gaps = s.Intervals - 1 // Stored intervals are never adjacent
+	}
+	return s
+}
@@ -0,0 +1,27 @@
+package intervaltree
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	it := NewInt64()
+	if s := it.Stats(); s != (Stats[int64]{}) {
+		t.Fatalf("Unexpected stats of empty tree: %+v", s)
+	}
+
+	it.Insert(-10, -1)
+	it.Insert(5, 5)
+	it.Insert(100, 199)
+	expected := Stats[int64]{Height: 2, Intervals: 3, Covered: 111, Gaps: 2, Min: -10, Max: 199}
+	if s := it.Stats(); s != expected {
+		t.Fatalf("Unexpected stats: %+v", s)
+	}
+
+	full := New()
+	full.Insert(0, math.MaxUint64)
+	if s := full.Stats(); s.Covered != 0 || s.Intervals != 1 || s.Max != math.MaxUint64 {
+		t.Fatalf("Unexpected stats of full tree: %+v", s)
+	}
+}
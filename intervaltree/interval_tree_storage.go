@@ -0,0 +1,166 @@
+package intervaltree
+
+// Storage persists a tree bound to it with Bind, so WAL, database or object
+// store backed persistence can be implemented outside this package. The tree
+// appends every change before applying it and periodically replaces the
+// changes with a snapshot, as a WALTree does with its directory.
+type Storage[T Integer] interface {
+	// AppendOp durably records c, which takes the tree to revision rev. If it
+	// fails, the change is not applied.
+	AppendOp(rev uint64, c Change[T]) error
+	// Snapshot durably records s, after which the changes up to its revision
+	// may be discarded.
+	Snapshot(s Snapshot[T]) error
+	// Restore returns the last snapshot recorded and the changes appended
+	// since revision From of the delta, which must not be newer than the
+	// snapshot. Both are empty if nothing was recorded.
+	Restore() (Snapshot[T], Delta[T], error)
+}
+
+// StoredTree represents a Tree persisted to a Storage. Every mutation is
+// appended to the storage before being applied, and the changes are
+// periodically replaced with a snapshot. It is safe for concurrent use.
+type StoredTree[T Integer] struct {
+	tree    *Tree[T]
+	storage Storage[T]
+	changes int // Changes appended since the last snapshot
+	compact int // Changes after which a snapshot is recorded
+}
+
+// Bind returns a StoredTree persisted to s, recovering the tree from the
+// snapshot and the changes it holds. Changes older than the snapshot are
+// skipped. The tree is created with opts, and WithCompaction sets how often a
+// snapshot is recorded.
+func Bind[T Integer](s Storage[T], opts ...Option) (*StoredTree[T], error) {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	st := &StoredTree[T]{tree: NewTree[T](opts...), storage: s, compact: c.compaction}
+	if st.compact <= 0 {
+		st.compact = defaultCompaction
+	}
+
+	snap, d, err := s.Restore()
+	if err != nil {
+		return nil, err
+	}
+	if err := st.tree.Restore(snap); err != nil {
+		return nil, err
+	}
+	if d.From > snap.Revision {
+		return nil, RevisionError(snap.Revision)
+	}
+	changes := d.Changes[min(snap.Revision-d.From, uint64(len(d.Changes))):]
+	d = Delta[T]{snap.Revision, snap.Revision + uint64(len(changes)), changes}
+	if err := st.tree.Apply(d); err != nil {
+		return nil, err
+	}
+	st.changes = len(changes)
+	return st, nil
+}
+
+// Tree returns the bound tree, for reading it. It must not be mutated other
+// than through the StoredTree.
+func (s *StoredTree[T]) Tree() *Tree[T] {
+	return s.tree
+}
+
+// Insert adds the interval [x, y] to the tree once it has been appended to the
+// storage, as Tree.Insert does.
+func (s *StoredTree[T]) Insert(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	s.tree.Lock()
+	defer s.tree.Unlock()
+	plan, err := s.tree.plan(x, y)
+	if err != nil {
+		return s.tree.failed(insertErrors, x, y, err)
+	}
+	for _, i := range plan {
+		if !s.tree.canInsert(i.I, i.J) {
+			return s.tree.insertStrict(i.I, i.J) // Fails with the same error Tree.Insert would
+		}
+		if err := s.storage.AppendOp(s.tree.rev+1, Change[T]{false, i}); err != nil {
+			return err
+		}
+		s.tree.insertStrict(i.I, i.J)
+		s.changes++
+	}
+	s.compactIfNeeded()
+	return nil
+}
+
+// Remove deletes the interval [x, y] from the tree once it has been appended to
+// the storage, as Tree.Remove does.
+func (s *StoredTree[T]) Remove(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	s.tree.Lock()
+	defer s.tree.Unlock()
+	if !s.tree.canRemove(x, y) {
+		return s.tree.remove(x, y) // Fails with the same error Tree.Remove would
+	}
+	if err := s.storage.AppendOp(s.tree.rev+1, Change[T]{true, Interval[T]{x, y}}); err != nil {
+		return err
+	}
+	s.tree.remove(x, y)
+	s.changes++
+	s.compactIfNeeded()
+	return nil
+}
+
+// Contains checks if x is contained in the tree.
+func (s *StoredTree[T]) Contains(x T) bool {
+	return s.tree.Contains(x)
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to x. It returns false if every such value is contained.
+func (s *StoredTree[T]) Next(x T) (T, bool) {
+	return s.tree.Next(x)
+}
+
+// Walk calls fn for every interval in the tree in ascending order, stopping
+// early if fn returns false.
+func (s *StoredTree[T]) Walk(fn func(x, y T) bool) {
+	s.tree.Walk(fn)
+}
+
+// Compact records a snapshot of the tree in the storage.
+func (s *StoredTree[T]) Compact() error {
+	s.tree.Lock()
+	defer s.tree.Unlock()
+	return s.compactLocked()
+}
+
+// compactIfNeeded records a snapshot if enough changes were appended since the
+// last one. The changes are already durable, so a failed snapshot is just
+// retried after the next change. The caller must hold the write lock.
+func (s *StoredTree[T]) compactIfNeeded() {
+	if s.changes >= s.compact {
+		s.compactLocked()
+	}
+}
+
+// compactLocked records a snapshot of the tree in the storage. The caller must
+// hold the write lock.
+func (s *StoredTree[T]) compactLocked() error {
+	snap := Snapshot[T]{Revision: s.tree.rev}
+	s.tree.root.walk(func(x, y T) bool {
+		snap.Intervals = append(snap.Intervals, Interval[T]{x, y})
+		return true
+	})
+	if err := s.storage.Snapshot(snap); err != nil {
+		return err
+	}
+	s.changes = 0
+	return nil
+}
+
+var _ Set[uint64] = (*StoredTree[uint64])(nil)
@@ -0,0 +1,89 @@
+package intervaltree
+
+import (
+	"errors"
+	"testing"
+)
+
+// memStorage is a Storage keeping its records in memory. Like a log that was
+// not truncated after a snapshot, it keeps every change since revision 0.
+type memStorage struct {
+	snap    Snapshot[int32]
+	changes []Change[int32]
+	fail    bool // Whether appending fails
+}
+
+func (m *memStorage) AppendOp(rev uint64, c Change[int32]) error {
+	if m.fail {
+		return errors.New("storage unavailable")
+	}
+	if rev != uint64(len(m.changes))+1 {
+		return RevisionError(rev)
+	}
+	m.changes = append(m.changes, c)
+	return nil
+}
+
+func (m *memStorage) Snapshot(s Snapshot[int32]) error {
+	m.snap = s
+	return nil
+}
+
+func (m *memStorage) Restore() (Snapshot[int32], Delta[int32], error) {
+	return m.snap, Delta[int32]{0, uint64(len(m.changes)), m.changes}, nil
+}
+
+func TestStoredTree(t *testing.T) {
+	m := &memStorage{}
+	s, err := Bind[int32](m, WithCompaction(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Insert(1, 10)
+	s.Insert(20, 30)
+	if err := s.Insert(5, 6); err == nil {
+		t.Fatal("Overlapping insertion succeeded")
+	}
+	s.Remove(4, 6) // Records a snapshot
+	s.Insert(-5, 0)
+	s.Insert(40, 50)
+	expected := walkString[int32](s)
+	if m.snap.Revision != 3 || len(m.changes) != 5 {
+		t.Fatalf("Unexpected snapshot at revision %d with %d changes", m.snap.Revision, len(m.changes))
+	}
+
+	m.fail = true
+	if err := s.Insert(60, 70); err == nil || s.Contains(60) {
+		t.Fatal("Insertion applied without being stored")
+	}
+	m.fail = false
+
+	s, err = Bind[int32](m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := walkString[int32](s); got != expected {
+		t.Fatalf("Unexpected restored tree: %s, expected %s", got, expected)
+	}
+	if s.Tree().Revision() != 5 || s.changes != 2 {
+		t.Fatalf("Unexpected revision %d with %d changes", s.Tree().Revision(), s.changes)
+	}
+
+	if _, err := Bind[int32](&memStorage{snap: Snapshot[int32]{Revision: 3}}); err != nil {
+		t.Fatalf("Failed to bind storage holding only a snapshot: %v", err)
+	}
+}
+
+func TestStoredTreeGap(t *testing.T) {
+	m := &gapStorage{}
+	if _, err := Bind[int32](m); err == nil {
+		t.Fatal("Bound storage missing changes after its snapshot")
+	}
+}
+
+// gapStorage is a Storage whose changes start after its snapshot.
+type gapStorage struct{ memStorage }
+
+func (g *gapStorage) Restore() (Snapshot[int32], Delta[int32], error) {
+	return Snapshot[int32]{Revision: 2}, Delta[int32]{4, 5, []Change[int32]{{false, Interval[int32]{1, 2}}}}, nil
+}
@@ -1,11 +1,40 @@
 package intervaltree
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
+	"slices"
 	"testing"
 )
 
-func (n *node) isAVL() error {
+// nexter is implemented by every set with a Next method.
+type nexter[T Integer] interface {
+	Next(x T) (T, bool)
+}
+
+// next returns the result of s.Next(x), ignoring whether it saturated.
+func next[T Integer, S nexter[T]](s S, x T) T {
+	n, _ := s.Next(x)
+	return n
+}
+
+// full checks if every value of s greater or equal to x is contained.
+func full[T Integer, S nexter[T]](s S, x T) bool {
+	_, ok := s.Next(x)
+	return !ok
+}
+
+// sameNext checks if a and b agree on Next(x).
+func sameNext[T Integer, A, B nexter[T]](a A, b B, x T) bool {
+	an, aok := a.Next(x)
+	bn, bok := b.Next(x)
+	return an == bn && aok == bok
+}
+
+func (n *node[T]) isAVL() error {
 	// Empty tree is always AVL
 	if n == nil {
 		return nil
@@ -114,3 +143,471 @@ func Test3(t *testing.T) {
 	}
 
 }
+
+func TestRemove(t *testing.T) {
+	it := New()
+	it.Insert(10, 20)
+	it.Insert(30, 40)
+
+	if err := it.Remove(5, 1); err == nil {
+		t.Fatal("Removed invalid interval [5, 1]")
+	}
+	if err := it.Remove(21, 22); err != (NotContainedError[uint64]{21}) {
+		t.Fatalf("Unexpected error removing [21, 22]: %v", err)
+	}
+	if err := it.Remove(15, 25); err != (NotContainedError[uint64]{21}) {
+		t.Fatalf("Unexpected error removing [15, 25]: %v", err)
+	}
+
+	for _, c := range []struct {
+		x, y     uint64
+		expected string
+	}{
+		{10, 11, "[12 -- 20][30 -- 40]"},
+		{19, 20, "[12 -- 18][30 -- 40]"},
+		{14, 15, "[12 -- 13][16 -- 18][30 -- 40]"},
+		{30, 40, "[12 -- 13][16 -- 18]"},
+		{12, 13, "[16 -- 18]"},
+		{16, 18, ""},
+	} {
+		if err := it.Remove(c.x, c.y); err != nil {
+			t.Fatalf("Failed to remove [%d, %d]: %v", c.x, c.y, err)
+		}
+		if s := it.ToString(); s != c.expected {
+			t.Fatalf("Unexpected tree after removing [%d, %d]. Got %s, expected %s", c.x, c.y, s, c.expected)
+		}
+		if err := it.root.isAVL(); err != nil {
+			t.Fatalf("Tree is not AVL after removing [%d, %d]: %v", c.x, c.y, err)
+		}
+	}
+}
+
+func TestRandomOperations(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		it := New()
+		ref := make(map[uint64]bool)
+		for i := 0; i < 300; i++ {
+			x := uint64(r.Intn(500))
+			y := x + uint64(r.Intn(4))
+
+			if r.Intn(3) == 0 {
+				covered := true
+				for v := x; v <= y; v++ {
+					covered = covered && ref[v]
+				}
+				if err := it.Remove(x, y); (err == nil) != covered {
+					t.Fatalf("Seed %d: unexpected result removing [%d, %d]: %v", seed, x, y, err)
+				}
+				for v := x; covered && v <= y; v++ {
+					delete(ref, v)
+				}
+			} else if err := it.Insert(x, y); err == nil {
+				for v := x; v <= y; v++ {
+					ref[v] = true
+				}
+			}
+
+			if err := it.root.isAVL(); err != nil {
+				t.Fatalf("Seed %d: tree is not AVL after %d operations: %v", seed, i, err)
+			}
+			for v := uint64(0); v < 505; v++ {
+				if it.Contains(v) != ref[v] {
+					t.Fatalf("Seed %d: Contains(%d) is wrong after %d operations", seed, v, i)
+				}
+			}
+		}
+	}
+}
+
+func TestGenericTree(t *testing.T) {
+	it := NewTree[uint8]()
+	it.Insert(250, 255)
+	it.Insert(0, 3)
+	it.Insert(4, 10)
+	if s := it.ToString(); s != "[0 -- 10][250 -- 255]" {
+		t.Fatalf("Unexpected uint8 tree: %s", s)
+	}
+	if !it.Contains(255) || it.Contains(249) || next(it, 5) != 11 {
+		t.Fatal("Unexpected membership in uint8 tree")
+	}
+	if err := it.Insert(200, 250); err != (OverlapError[uint8]{250, Interval[uint8]{200, 250}, Interval[uint8]{250, 255}}) {
+		t.Fatalf("Unexpected error inserting overlapping interval: %v", err)
+	}
+
+	var b bytes.Buffer
+	if _, err := it.Checkpoint().WriteTo(&b); err != nil {
+		t.Fatalf("Failed to write uint8 snapshot: %v", err)
+	}
+	s, err := ReadSnapshot[uint8](&b)
+	if err != nil {
+		t.Fatalf("Failed to read uint8 snapshot: %v", err)
+	}
+	if len(s.Intervals) != 2 || s.Intervals[1] != (Interval[uint8]{250, 255}) {
+		t.Fatalf("Unexpected uint8 snapshot: %+v", s)
+	}
+
+	b.Reset()
+	if _, err := (Snapshot[uint64]{Intervals: []Interval[uint64]{{1, 300}}}).WriteTo(&b); err != nil {
+		t.Fatalf("Failed to write uint64 snapshot: %v", err)
+	}
+	if _, err := ReadSnapshot[uint8](&b); err == nil {
+		t.Fatal("Read uint8 snapshot holding values out of range")
+	}
+}
+
+func TestInt64Tree(t *testing.T) {
+	it := NewInt64()
+	it.Insert(-5, -1)
+	it.Insert(1, 4)
+	it.Insert(0, 0)
+	if s := it.ToString(); s != "[-5 -- 4]" {
+		t.Fatalf("Intervals around 0 were not joined: %s", s)
+	}
+	if it.Contains(-6) || !it.Contains(-5) || next(it, -3) != 5 {
+		t.Fatal("Unexpected membership around 0")
+	}
+
+	it.Insert(math.MinInt64, math.MinInt64+1)
+	it.Insert(math.MaxInt64-1, math.MaxInt64)
+	it.Insert(math.MinInt64+3, math.MinInt64+4)
+	it.Insert(math.MaxInt64-4, math.MaxInt64-3)
+	if err := it.root.isAVL(); err != nil {
+		t.Fatalf("Tree is not AVL: %v", err)
+	}
+	if !it.Contains(math.MinInt64) || !it.Contains(math.MaxInt64) || it.Contains(math.MinInt64+2) {
+		t.Fatal("Unexpected membership at the bounds of int64")
+	}
+
+	it.Insert(math.MinInt64+2, math.MinInt64+2)
+	it.Insert(math.MaxInt64-2, math.MaxInt64-2)
+	expected := "[-9223372036854775808 -- -9223372036854775804][-5 -- 4][9223372036854775803 -- 9223372036854775807]"
+	if s := it.ToString(); s != expected {
+		t.Fatalf("Intervals at the bounds of int64 were not joined. Got %s, expected %s", s, expected)
+	}
+	if err := it.Insert(math.MinInt64, -10); err != (OverlapError[int64]{math.MinInt64, Interval[int64]{math.MinInt64, -10}, Interval[int64]{math.MinInt64, math.MinInt64 + 4}}) {
+		t.Fatalf("Unexpected error inserting overlapping interval: %v", err)
+	}
+
+	if err := it.Remove(-1, 0); err != nil {
+		t.Fatalf("Failed to remove [-1, 0]: %v", err)
+	}
+	if s := it.ToString(); s != "[-9223372036854775808 -- -9223372036854775804][-5 -- -2][1 -- 4][9223372036854775803 -- 9223372036854775807]" {
+		t.Fatalf("Unexpected tree after removing [-1, 0]: %s", s)
+	}
+
+	var b bytes.Buffer
+	if err := it.EncodeJSON(&b); err != nil {
+		t.Fatalf("Failed to encode int64 tree: %v", err)
+	}
+	decoded := NewInt64()
+	if err := decoded.DecodeJSON(&b); err != nil {
+		t.Fatalf("Failed to decode int64 tree: %v", err)
+	}
+	if decoded.ToString() != it.ToString() {
+		t.Fatalf("Decoded int64 tree differs: %s", decoded.ToString())
+	}
+
+	if _, err := it.Checkpoint().WriteTo(&b); err != nil {
+		t.Fatalf("Failed to write int64 snapshot: %v", err)
+	}
+	s, err := ReadSnapshot[int64](&b)
+	if err != nil {
+		t.Fatalf("Failed to read int64 snapshot: %v", err)
+	}
+	restored := NewInt64()
+	if err := restored.Restore(s); err != nil || restored.ToString() != it.ToString() {
+		t.Fatalf("Restored int64 tree differs: %s, %v", restored.ToString(), err)
+	}
+}
+
+func TestOverlapWhenJoiningChild(t *testing.T) {
+	it := New()
+	it.Insert(10, 10)
+	it.Insert(1, 2)
+	it.Insert(20, 20)
+	it.Insert(5, 6)
+	if err := it.Insert(3, 9); err != (OverlapError[uint64]{5, Interval[uint64]{3, 9}, Interval[uint64]{5, 6}}) {
+		t.Fatalf("Unexpected error inserting [3, 9] over [5, 6]: %v", err)
+	}
+	if s := it.ToString(); s != "[1 -- 2][5 -- 6][10 -- 10][20 -- 20]" {
+		t.Fatalf("Tree changed after failed insert: %s", s)
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	it := NewTree[int8]()
+	it.Insert(-10, 10)
+
+	for _, c := range []struct {
+		err      error
+		sentinel error
+	}{
+		{it.Insert(5, 1), ErrInvalidInterval},
+		{it.Remove(5, 1), ErrInvalidInterval},
+		{it.Insert(10, 12), ErrOverlap},
+		{it.Remove(10, 12), ErrNotFound},
+		{it.Remove(20, 20), ErrNotFound},
+	} {
+		if !errors.Is(c.err, c.sentinel) {
+			t.Fatalf("%v does not wrap %v", c.err, c.sentinel)
+		}
+	}
+}
+
+func TestOverlapErrorIs(t *testing.T) {
+	it := New()
+	it.Insert(10, 20)
+	err := fmt.Errorf("Wrapped: %w", it.Insert(15, 25))
+
+	if !errors.Is(err, OverlapError[uint64]{}) {
+		t.Fatal("Overlap is not an OverlapError")
+	}
+	if !errors.Is(err, OverlapError[uint64]{15, Interval[uint64]{15, 25}, Interval[uint64]{10, 20}}) {
+		t.Fatal("Overlap is not equal to itself")
+	}
+	if errors.Is(err, OverlapError[int64]{}) {
+		t.Fatal("Overlap matches an OverlapError of another type")
+	}
+	if !errors.Is(OverlapError[[]byte]{Value: []byte("a")}, OverlapError[[]byte]{}) {
+		t.Fatal("Overlap of uncomparable values is not an OverlapError")
+	}
+
+	if !errors.Is(err, ErrOverlap) || errors.Is(err, ErrNotFound) {
+		t.Fatal("Overlap does not wrap ErrOverlap")
+	}
+
+	var oe OverlapError[uint64]
+	if !errors.As(err, &oe) {
+		t.Fatal("Overlap cannot be unwrapped")
+	}
+	if a, e := oe.Intervals(); a != (Interval[uint64]{15, 25}) || e != (Interval[uint64]{10, 20}) {
+		t.Fatalf("Unexpected intervals: %v %v", a, e)
+	}
+}
+
+func TestGaps(t *testing.T) {
+	it := New()
+	if g := it.Gaps(5, 10); len(g) != 1 || g[0] != (Interval[uint64]{5, 10}) {
+		t.Fatalf("Unexpected gaps in empty tree: %v", g)
+	}
+
+	it.Insert(0, 4)
+	it.Insert(10, 20)
+	it.Insert(30, 40)
+	for _, c := range []struct {
+		x, y     uint64
+		expected []Interval[uint64]
+	}{
+		{0, 50, []Interval[uint64]{{5, 9}, {21, 29}, {41, 50}}},
+		{12, 35, []Interval[uint64]{{21, 29}}},
+		{22, 25, []Interval[uint64]{{22, 25}}},
+		{10, 20, nil},
+		{3, 10, []Interval[uint64]{{5, 9}}},
+		{41, math.MaxUint64, []Interval[uint64]{{41, math.MaxUint64}}},
+	} {
+		g := it.Gaps(c.x, c.y)
+		if fmt.Sprint(g) != fmt.Sprint(c.expected) {
+			t.Fatalf("Unexpected gaps in [%d, %d]. Got %v, expected %v", c.x, c.y, g, c.expected)
+		}
+	}
+
+	it.Insert(41, math.MaxUint64)
+	if g := it.Gaps(35, math.MaxUint64); len(g) != 0 {
+		t.Fatalf("Unexpected gaps up to the greatest value: %v", g)
+	}
+}
+
+// benchmarkTree returns a tree holding n intervals of length 5 separated by
+// gaps of length 5.
+func benchmarkTree(n int, opts ...Option) *IntervalTree {
+	it := New(opts...)
+	for i := uint64(0); i < uint64(n); i++ {
+		it.Insert(10*i, 10*i+4)
+	}
+	return it
+}
+
+func BenchmarkContains(b *testing.B) {
+	it := benchmarkTree(1 << 16)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		it.Contains(uint64(i*7919) % (10 << 16))
+	}
+}
+
+func BenchmarkNext(b *testing.B) {
+	it := benchmarkTree(1 << 16)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		it.Next(uint64(i*7919) % (10 << 16))
+	}
+}
+
+func BenchmarkInsertSequential(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		it := New()
+		for k := uint64(0); k < 1024; k++ {
+			it.Insert(10*k, 10*k+4)
+		}
+	}
+}
+
+func BenchmarkInsertRandom(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	xs := make([]uint64, 1024)
+	for k := range xs {
+		xs[k] = 10 * uint64(r.Int63n(1<<40))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		it := New()
+		for _, x := range xs {
+			it.Insert(x, x+4)
+		}
+	}
+}
+
+func TestNodeRecycling(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	it, ref := New(WithNodeRecycling()), New()
+	for i := 0; i < 2000; i++ {
+		x := uint64(r.Intn(2000))
+		y := x + uint64(r.Intn(10))
+		if r.Intn(2) == 0 {
+			if errA, errB := it.Remove(x, y), ref.Remove(x, y); errA != errB {
+				t.Fatalf("Remove [%d, %d] failed differently: %v, %v", x, y, errA, errB)
+			}
+		} else if errA, errB := it.Insert(x, y), ref.Insert(x, y); errA != errB {
+			t.Fatalf("Insert [%d, %d] failed differently: %v, %v", x, y, errA, errB)
+		}
+
+		if err := it.root.isAVL(); err != nil {
+			t.Fatalf("Tree is not AVL after %d operations: %v", i, err)
+		}
+		if it.ToString() != ref.ToString() {
+			t.Fatalf("Trees differ after %d operations", i)
+		}
+	}
+
+	it.Insert(6000, 6000)
+	n := it.root.containingNode(6000)
+	it.Remove(6000, 6000)
+	if it.pool.free != n {
+		t.Fatal("Deleted node was not kept")
+	}
+	it.Insert(5000, 5000)
+	if it.root.containingNode(5000) != n {
+		t.Fatal("Deleted node was not reused")
+	}
+}
+
+func BenchmarkChurn(b *testing.B) {
+	for _, c := range []struct {
+		name string
+		opts []Option
+	}{{"Allocating", nil}, {"Recycling", []Option{WithNodeRecycling()}}} {
+		b.Run(c.name, func(b *testing.B) {
+			it := benchmarkTree(1024, c.opts...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				x := 10 * uint64(i%1024)
+				it.Remove(x, x+4)
+				it.Insert(x, x+4)
+			}
+		})
+	}
+}
+
+func TestUnlocked(t *testing.T) {
+	it := NewUnlocked()
+	it.Insert(1, 5)
+	it.Lock() // Would deadlock if locking were done
+	if !it.Contains(3) || next(it, 3) != 6 {
+		t.Fatal("Unexpected contents in unlocked tree")
+	}
+	it.Unlock()
+}
+
+func BenchmarkContainsUnlocked(b *testing.B) {
+	it := benchmarkTree(1<<16, WithoutLocking())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		it.Contains(uint64(i*7919) % (10 << 16))
+	}
+}
+
+func TestNextSaturation(t *testing.T) {
+	for b := AVLBackend; b <= WeightBalancedBackend; b++ {
+		s := NewSet[uint64](b)
+		s.Insert(math.MaxUint64-10, math.MaxUint64)
+		if n, ok := s.Next(math.MaxUint64 - 20); !ok || n != math.MaxUint64-20 {
+			t.Fatalf("Backend %d: unexpected Next below the interval: %d %v", b, n, ok)
+		}
+		if _, ok := s.Next(math.MaxUint64 - 5); ok {
+			t.Fatalf("Backend %d: Next did not saturate", b)
+		}
+	}
+
+	it := NewInt64()
+	it.Insert(0, math.MaxInt64)
+	if _, ok := it.Next(-1); !ok {
+		t.Fatal("Next saturated below the interval")
+	}
+	if n, ok := it.Next(5); ok {
+		t.Fatalf("Next did not saturate: %d", n)
+	}
+	if _, ok := it.Freeze().Next(5); ok {
+		t.Fatal("Frozen Next did not saturate")
+	}
+}
+
+func TestPartialOverlapError(t *testing.T) {
+	it := NewTree[int](WithRemainders())
+	it.Insert(10, 20)
+	it.Insert(30, 40)
+
+	err := it.Insert(0, 35)
+	var pe PartialOverlapError[int]
+	if !errors.As(err, &pe) {
+		t.Fatalf("Expected a partial overlap error, got %v", err)
+	}
+	if expected := []Interval[int]{{0, 9}, {21, 29}}; !slices.Equal(pe.Free, expected) {
+		t.Fatalf("Unexpected free parts: %v, expected %v", pe.Free, expected)
+	}
+	var oe OverlapError[int]
+	if !errors.As(err, &oe) || oe.Attempted != (Interval[int]{0, 35}) || !errors.Is(err, ErrOverlap) {
+		t.Fatalf("Partial overlap is not an overlap: %v", err)
+	}
+	for _, f := range pe.Free {
+		if err := it.Insert(f.I, f.J); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := it.ToString(); s != "[0 -- 40]" {
+		t.Fatalf("Unexpected tree after inserting the free parts: %s", s)
+	}
+
+	plain := NewTree[int]()
+	plain.Insert(1, 1)
+	if err := plain.Insert(0, 2); errors.As(err, &pe) {
+		t.Fatal("Partial overlap reported without WithRemainders")
+	}
+}
+
+func TestLimits(t *testing.T) {
+	if lo, hi := Limits[int8](); lo != math.MinInt8 || hi != math.MaxInt8 {
+		t.Fatalf("Unexpected limits of int8: %d, %d", lo, hi)
+	}
+	if lo, hi := Limits[int64](); lo != math.MinInt64 || hi != math.MaxInt64 {
+		t.Fatalf("Unexpected limits of int64: %d, %d", lo, hi)
+	}
+	if lo, hi := Limits[uint16](); lo != 0 || hi != math.MaxUint16 {
+		t.Fatalf("Unexpected limits of uint16: %d, %d", lo, hi)
+	}
+	if lo, hi := Limits[uintptr](); lo != 0 || hi != ^uintptr(0) {
+		t.Fatalf("Unexpected limits of uintptr: %d, %d", lo, hi)
+	}
+}
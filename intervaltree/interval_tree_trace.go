@@ -0,0 +1,53 @@
+package intervaltree
+
+import "math"
+
+// Tracer starts a span for each traced operation of a tree. Adapters over
+// tracing libraries, such as OpenTelemetry, implement it in a few lines.
+type Tracer interface {
+	Start(op string) Span
+}
+
+// Span records a single traced operation.
+type Span interface {
+	SetAttribute(key string, value int64)
+	End(err error) // err is the error returned by the operation, if any
+}
+
+// WithTracer makes the tree start a span through tr for every Insert,
+// TryInsert, Remove, InsertAll, RemoveAll, Apply, Restore, Rebuild and
+// DecodeJSON call, so slow mutations show up in distributed traces. The spans
+// of Insert, TryInsert, Remove, InsertAll and RemoveAll record the rotations
+// they performed, which are counted as WithBalancingStats does.
+func WithTracer(tr Tracer) Option {
+	return func(c *config) {
+		c.tracer = tr
+	}
+}
+
+// traceInterval starts a span for the operation op on [x, y], recording its
+// size when valid. Sizes that do not fit an int64 are recorded as MaxInt64.
+func (t *Tree[T]) traceInterval(op string, x, y T) Span {
+	span := t.tracer.Start(op)
+	if x <= y {
+		size := ordinal(y) - ordinal(x) + 1
+		if size == 0 || size > math.MaxInt64 {
+			size = math.MaxInt64
+		}
+		span.SetAttribute("interval.size", int64(size))
+	}
+	return span
+}
+
+// traceRotations records on span the rotations performed since the tree had
+// performed rotations. The caller must hold the write lock.
+func (t *Tree[T]) traceRotations(span Span, rotations uint64) {
+	span.SetAttribute("rotations", int64(t.balancing.Rotations-rotations))
+}
+
+// traceBulk starts a span for the operation op on n intervals or changes.
+func (t *Tree[T]) traceBulk(op, key string, n int) Span {
+	span := t.tracer.Start(op)
+	span.SetAttribute(key, int64(n))
+	return span
+}
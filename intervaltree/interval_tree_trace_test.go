@@ -0,0 +1,79 @@
+package intervaltree
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// recordingTracer records the spans it starts.
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	op    string
+	attrs map[string]int64
+	ended bool
+	err   error
+}
+
+func (tr *recordingTracer) Start(op string) Span {
+	s := &recordedSpan{op: op, attrs: make(map[string]int64)}
+	tr.spans = append(tr.spans, s)
+	return s
+}
+
+func (s *recordedSpan) SetAttribute(key string, value int64) {
+	s.attrs[key] = value
+}
+
+func (s *recordedSpan) End(err error) {
+	s.ended, s.err = true, err
+}
+
+func TestTracer(t *testing.T) {
+	tr := &recordingTracer{}
+	it := New(WithTracer(tr))
+	it.Insert(1, 10)
+	it.Insert(5, 6)
+	it.Remove(0, math.MaxUint64)
+	it.Rebuild()
+	it.DecodeJSON(strings.NewReader("[[20,30],[40,40]]"))
+	it.InsertAll([]Interval[uint64]{{50, 50}, {60, 60}, {1, 1}})
+	it.RemoveAll([]Interval[uint64]{{50, 50}, {60, 60}})
+
+	expected := []struct {
+		op    string
+		key   string
+		value int64
+		err   bool
+	}{
+		{"intervaltree.Insert", "interval.size", 10, false},
+		{"intervaltree.Insert", "interval.size", 2, true},
+		{"intervaltree.Remove", "interval.size", math.MaxInt64, true},
+		{"intervaltree.Rebuild", "intervals", 1, false},
+		{"intervaltree.DecodeJSON", "intervals", 2, false},
+		{"intervaltree.InsertAll", "intervals", 3, true},
+		{"intervaltree.RemoveAll", "intervals", 2, false},
+	}
+	if len(tr.spans) != len(expected) {
+		t.Fatalf("Unexpected number of spans: %d", len(tr.spans))
+	}
+	for i, e := range expected {
+		s := tr.spans[i]
+		if s.op != e.op || s.attrs[e.key] != e.value || !s.ended || (s.err != nil) != e.err {
+			t.Fatalf("Unexpected span %d: %+v", i, s)
+		}
+	}
+
+	if r := tr.spans[5].attrs["rotations"]; r != 1 { // Appending [50, 50] and [60, 60] rotates once
+		t.Fatalf("Unexpected rotations of InsertAll: %d", r)
+	}
+
+	var overlap OverlapError[uint64]
+	if !errors.As(tr.spans[1].err, &overlap) {
+		t.Fatalf("Unexpected span error: %v", tr.spans[1].err)
+	}
+}
@@ -0,0 +1,223 @@
+package intervaltree
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// TreapTree represents the same set of intervals as Tree, but is balanced by
+// random priorities instead of AVL rotations: nodes are ordered by interval as
+// in a binary search tree and by priority as in a heap, which keeps the
+// expected depth logarithmic. Its updates are built from splitting and merging
+// subtrees.
+type TreapTree[T Integer] struct {
+	root *treapNode[T]
+	sync.RWMutex
+}
+
+// treapNode holds an interval [I, J], a random priority greater than those of
+// its children and pointers to nodes holding intervals lesser and greater than
+// its own.
+type treapNode[T Integer] struct {
+	I, J        T             // Interval bounds
+	Left, Right *treapNode[T] // Left and right children
+	priority    uint64
+}
+
+// NewTreap returns a pointer to an empty TreapTree.
+func NewTreap[T Integer]() *TreapTree[T] {
+	return &TreapTree[T]{}
+}
+
+// splitTreap splits the subtree rooted at n into the subtrees holding the
+// intervals starting before x and those starting at or after x.
+func splitTreap[T Integer](n *treapNode[T], x T) (*treapNode[T], *treapNode[T]) {
+	if n == nil {
+		return nil, nil
+	}
+
+	if n.I < x {
+		var r *treapNode[T]
+		n.Right, r = splitTreap(n.Right, x)
+		return n, r
+	}
+	var l *treapNode[T]
+	l, n.Left = splitTreap(n.Left, x)
+	return l, n
+}
+
+// mergeTreaps joins the subtrees rooted at a and b, every interval in a being
+// lesser than those in b, and returns the root of the result.
+func mergeTreaps[T Integer](a, b *treapNode[T]) *treapNode[T] {
+	if a == nil {
+		return b
+	} else if b == nil {
+		return a
+	}
+
+	if a.priority > b.priority {
+		a.Right = mergeTreaps(a.Right, b)
+		return a
+	}
+	b.Left = mergeTreaps(a, b.Left)
+	return b
+}
+
+// remove deletes the node holding the interval starting at x from the subtree
+// rooted at n, and returns the new root of the subtree. Such a node must exist.
+func (n *treapNode[T]) remove(x T) *treapNode[T] {
+	if x < n.I {
+		n.Left = n.Left.remove(x)
+		return n
+	} else if x > n.I {
+		n.Right = n.Right.remove(x)
+		return n
+	}
+	return mergeTreaps(n.Left, n.Right)
+}
+
+// floor returns the node holding the greatest interval starting at or before
+// x in the subtree rooted at n, or nil if there is none.
+func (n *treapNode[T]) floor(x T) *treapNode[T] {
+	var ret *treapNode[T]
+	for n != nil {
+		if n.I <= x {
+			ret, n = n, n.Right
+		} else {
+			n = n.Left
+		}
+	}
+	return ret
+}
+
+// higher returns the node holding the least interval starting after x in the
+// subtree rooted at n, or nil if there is none.
+func (n *treapNode[T]) higher(x T) *treapNode[T] {
+	var ret *treapNode[T]
+	for n != nil {
+		if n.I > x {
+			ret, n = n, n.Left
+		} else {
+			n = n.Right
+		}
+	}
+	return ret
+}
+
+// walk calls fn recursively for the intervals of this node and its children in
+// ascending order. It stops as soon as fn returns false, and reports whether
+// the walk was completed.
+func (n *treapNode[T]) walk(fn func(x, y T) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	return n.Left.walk(fn) && fn(n.I, n.J) && n.Right.walk(fn)
+}
+
+// insert adds [x, y] to the tree, which holds no interval starting at x.
+func (t *TreapTree[T]) insert(x, y T) {
+	l, r := splitTreap(t.root, x)
+	n := &treapNode[T]{I: x, J: y, priority: rand.Uint64()}
+	t.root = mergeTreaps(mergeTreaps(l, n), r)
+}
+
+// Insert adds an interval to the tree. The interval cannot overlap with the
+// tree. If prunning is possible it will be done.
+func (t *TreapTree[T]) Insert(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	l, r := t.root.floor(x), t.root.higher(x)
+	if l != nil && l.J >= x {
+		return OverlapError[T]{x, Interval[T]{x, y}, Interval[T]{l.I, l.J}}
+	}
+	if r != nil && r.I <= y {
+		return OverlapError[T]{r.I, Interval[T]{x, y}, Interval[T]{r.I, r.J}}
+	}
+
+	// Neighbours are checked stepping towards the lesser value, so no check
+	// can overflow at the bounds of T
+	joinL := l != nil && l.J == x-1
+	joinR := r != nil && r.I-1 == y
+	switch {
+	case joinL && joinR: // Fill the gap between l and r
+		t.root = t.root.remove(r.I)
+		l.J = r.J
+	case joinL:
+		l.J = y
+	case joinR:
+		r.I = x
+	default:
+		t.insert(x, y)
+	}
+	return nil
+}
+
+// Remove deletes the interval [x, y] from the tree. Every value in [x, y] must
+// be contained in the tree. Stored intervals are shrunk or split as needed.
+func (t *TreapTree[T]) Remove(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	// Stored intervals are never adjacent, so [x, y] must lie in a single one
+	c := t.root.floor(x)
+	if c == nil || c.J < x {
+		return NotContainedError[T]{x}
+	}
+	if y > c.J {
+		return NotContainedError[T]{c.J + 1}
+	}
+
+	switch {
+	case x == c.I && y == c.J:
+		t.root = t.root.remove(c.I)
+	case x == c.I:
+		c.I = y + 1
+	case y == c.J:
+		c.J = x - 1
+	default: // Split, the upper part becomes a new node
+		j := c.J
+		c.J = x - 1
+		t.insert(y+1, j)
+	}
+	return nil
+}
+
+// Contains checks if x is contained in the tree.
+func (t *TreapTree[T]) Contains(x T) bool {
+	t.RLock()
+	defer t.RUnlock()
+
+	c := t.root.floor(x)
+	return c != nil && x <= c.J
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to x. It returns false if every such value is contained.
+func (t *TreapTree[T]) Next(x T) (T, bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	c := t.root.floor(x)
+	if c == nil || x > c.J {
+		return x, true
+	}
+	return after(c.J)
+}
+
+// Walk calls fn for the intervals in the tree in ascending order. It stops as
+// soon as fn returns false. The tree cannot be changed from fn.
+func (t *TreapTree[T]) Walk(fn func(x, y T) bool) {
+	t.RLock()
+	defer t.RUnlock()
+	t.root.walk(fn)
+}
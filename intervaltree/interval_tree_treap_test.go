@@ -0,0 +1,93 @@
+package intervaltree
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// isTreap checks the heap and search tree invariants of the subtree rooted at
+// n.
+func (n *treapNode[T]) isTreap() error {
+	if n == nil {
+		return nil
+	}
+
+	if n.Left != nil && (n.Left.priority > n.priority || n.Left.J >= n.I) {
+		return fmt.Errorf("Node [%d, %d] is inconsistent with its left child", n.I, n.J)
+	}
+	if n.Right != nil && (n.Right.priority > n.priority || n.Right.I <= n.J) {
+		return fmt.Errorf("Node [%d, %d] is inconsistent with its right child", n.I, n.J)
+	}
+
+	if err := n.Left.isTreap(); err != nil {
+		return err
+	}
+	return n.Right.isTreap()
+}
+
+// String returns the intervals in the tree in the format of ToString.
+func (t *TreapTree[T]) String() string {
+	var s strings.Builder
+	t.Walk(func(x, y T) bool {
+		fmt.Fprintf(&s, "[%d -- %d]", x, y)
+		return true
+	})
+	return s.String()
+}
+
+func TestTreapTree(t *testing.T) {
+	tt := NewTreap[uint8]()
+	for _, i := range []Interval[uint8]{{10, 20}, {30, 40}, {0, 5}, {250, 255}, {21, 29}} {
+		if err := tt.Insert(i.I, i.J); err != nil {
+			t.Fatalf("Failed to insert %v: %v", i, err)
+		}
+	}
+	if err := tt.Insert(40, 45); err != (OverlapError[uint8]{40, Interval[uint8]{40, 45}, Interval[uint8]{10, 40}}) {
+		t.Fatalf("Unexpected error inserting overlapping interval: %v", err)
+	}
+	if s := tt.String(); s != "[0 -- 5][10 -- 40][250 -- 255]" {
+		t.Fatalf("Unexpected intervals: %s", s)
+	}
+	if !full(tt, 255) || next(tt, 12) != 41 || next(tt, 7) != 7 {
+		t.Fatal("Unexpected Next")
+	}
+
+	if err := tt.Remove(15, 16); err != nil {
+		t.Fatalf("Failed to split interval: %v", err)
+	}
+	if s := tt.String(); s != "[0 -- 5][10 -- 14][17 -- 40][250 -- 255]" {
+		t.Fatalf("Unexpected intervals after removal: %s", s)
+	}
+}
+
+func TestTreapTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tt, it := NewTreap[int16](), NewTree[int16]()
+	for i := 0; i < 3000; i++ {
+		x := int16(r.Intn(2000) - 1000)
+		y := x + int16(r.Intn(10))
+		if r.Intn(3) == 0 {
+			if errA, errB := tt.Remove(x, y), it.Remove(x, y); (errA == nil) != (errB == nil) {
+				t.Fatalf("Remove [%d, %d] failed differently: %v, %v", x, y, errA, errB)
+			}
+		} else if errA, errB := tt.Insert(x, y), it.Insert(x, y); (errA == nil) != (errB == nil) {
+			t.Fatalf("Insert [%d, %d] failed differently: %v, %v", x, y, errA, errB)
+		}
+
+		if err := tt.root.isTreap(); err != nil {
+			t.Fatalf("Tree is not a treap after %d operations: %v", i, err)
+		}
+		if tt.String() != it.ToString() {
+			t.Fatalf("Trees differ after %d operations", i)
+		}
+	}
+
+	for x := math.MinInt16; x <= math.MaxInt16; x += 7 {
+		if tt.Contains(int16(x)) != it.Contains(int16(x)) || !sameNext(tt, it, int16(x)) {
+			t.Fatalf("Trees differ at %d", x)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package intervaltree
+
+import (
+	"container/heap"
+	"context"
+	"io"
+)
+
+// UnionAll returns a pointer to a Tree configured by opts holding the union of
+// trees. Their intervals are merged in a single pass through a heap, in
+// O(n log k) for n intervals in k trees, rather than folding the trees into one
+// pairwise. Each tree is read under its own lock. It fails with a CapacityError
+// if the union holds more values than a capacity set by WithCapacity.
+func UnionAll[T Integer](trees []*Tree[T], opts ...Option) (*Tree[T], error) {
+	return UnionAllContext(context.Background(), trees, opts...)
+}
+
+// UnionAllContext is like UnionAll, but checks periodically while merging
+// whether ctx is done, in which case it fails with the error of ctx.
+func UnionAllContext[T Integer](ctx context.Context, trees []*Tree[T], opts ...Option) (*Tree[T], error) {
+	runs := make([]func() (Interval[T], error), len(trees))
+	for k, t := range trees {
+		intervals := t.Freeze().intervals
+		runs[k] = func() (Interval[T], error) {
+			if len(intervals) == 0 {
+				return Interval[T]{}, io.EOF
+			}
+			i := intervals[0]
+			intervals = intervals[1:]
+			return i, nil
+		}
+	}
+
+	merged, err := unionRuns(ctx, runs) // Reading from slices only fails if ctx is done
+	if err != nil {
+		return nil, err
+	}
+	return buildChecked(merged, opts)
+}
+
+// unionRuns merges the sorted runs read by the functions in runs, which return
+// io.EOF once exhausted, into ascending, non adjacent intervals in a single pass
+// through a heap. It fails with the error of ctx if ctx is done.
+func unionRuns[T Integer](ctx context.Context, runs []func() (Interval[T], error)) ([]Interval[T], error) {
+	var h cursorHeap[T]
+	for _, next := range runs {
+		c := &runCursor[T]{next: next}
+		if ok, err := c.advance(); err != nil {
+			return nil, err
+		} else if ok {
+			h = append(h, c)
+		}
+	}
+	heap.Init(&h)
+
+	var merged []Interval[T]
+	for k := 0; len(h) > 0; k++ {
+		if err := cancelled(ctx, k); err != nil {
+			return nil, err
+		}
+
+		i := h[0].head
+		if last := len(merged) - 1; last >= 0 && (i.I <= merged[last].J || i.I-1 == merged[last].J) {
+			merged[last].J = max(merged[last].J, i.J)
+		} else {
+			merged = append(merged, i)
+		}
+
+		if ok, err := h[0].advance(); err != nil {
+			return nil, err
+		} else if ok {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	return merged, nil
+}
+
+// runCursor reads a sorted run, holding its least interval not merged yet.
+type runCursor[T Integer] struct {
+	next func() (Interval[T], error)
+	head Interval[T]
+}
+
+// advance reads the next interval of the run into head, and returns whether
+// there was one.
+func (c *runCursor[T]) advance() (bool, error) {
+	i, err := c.next()
+	if err == io.EOF {
+		return false, nil
+	}
+	c.head = i
+	return err == nil, err
+}
+
+// cursorHeap is a min-heap of run cursors by their head.
+type cursorHeap[T Integer] []*runCursor[T]
+
+func (h cursorHeap[T]) Len() int           { return len(h) }
+func (h cursorHeap[T]) Less(a, b int) bool { return h[a].head.I < h[b].head.I }
+func (h cursorHeap[T]) Swap(a, b int)      { h[a], h[b] = h[b], h[a] }
+
+func (h *cursorHeap[T]) Push(x any) {
+	*h = append(*h, x.(*runCursor[T]))
+}
+
+func (h *cursorHeap[T]) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
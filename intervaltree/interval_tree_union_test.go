@@ -0,0 +1,60 @@
+package intervaltree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestUnionAll(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	trees := make([]*Tree[int32], 64)
+	expected := NewTree[int32](WithOverlapMode(OverlapUnion))
+	for k := range trees {
+		trees[k] = NewTree[int32]()
+		for i := 0; i < 100; i++ {
+			x := int32(r.Intn(20000)) - 10000
+			y := x + int32(r.Intn(30))
+			if trees[k].Insert(x, y) == nil {
+				expected.Insert(x, y)
+			}
+		}
+	}
+	trees = append(trees, NewTree[int32]()) // Empty trees are skipped
+
+	u, err := UnionAll(trees)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if u.ToString() != expected.ToString() {
+		t.Fatal("Unexpected union")
+	}
+
+	if u, err := UnionAll[int32](nil); err != nil || u.Len() != 0 {
+		t.Fatalf("Unexpected union of no trees: %v", err)
+	}
+	var ce CapacityError
+	if _, err := UnionAll(trees, WithCapacity(100)); !errors.As(err, &ce) {
+		t.Fatalf("Expected a capacity error, got %v", err)
+	}
+}
+
+func TestUnionAllContext(t *testing.T) {
+	a, b := New(), New()
+	a.Insert(0, 10)
+	b.Insert(5, 20)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if u, err := UnionAllContext(ctx, []*Tree[uint64]{a, b}); err != nil || fmt.Sprint(u.Freeze().intervals) != "[{0 20}]" {
+		t.Fatalf("Unexpected union: %v", err)
+	}
+	cancel()
+	if _, err := UnionAllContext(ctx, []*Tree[uint64]{a, b}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Unexpected error with a cancelled context: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package intervaltree
+
+import "fmt"
+
+// validate checks recursively the invariants of this node and its children:
+// valid bounds, consistent heights, covered values and intervals, and AVL
+// balance. prev points to the last interval visited in ascending order, if any,
+// which must be lesser than and not adjacent to the intervals of this subtree.
+func (n *node[T]) validate(prev **node[T]) error {
+	if n == nil {
+		return nil
+	}
+
+	if err := n.Left.validate(prev); err != nil {
+		return err
+	}
+
+	if n.I > n.J {
+		return fmt.Errorf("Node [%v, %v] holds an invalid interval", n.I, n.J)
+	}
+	if h := max(n.Left.getHeight(), n.Right.getHeight()) + 1; n.height != h {
+		return fmt.Errorf("Node [%v, %v] has height %d, expected %d", n.I, n.J, n.height, h)
+	}
+	if bal := n.balanceFactor(); bal > 1 || bal < -1 {
+		return fmt.Errorf("Node [%v, %v] is unbalanced, balance factor %d", n.I, n.J, bal)
+	}
+	if c := n.Left.getCovered() + n.Right.getCovered() + ordinal(n.J) - ordinal(n.I) + 1; n.covered != c {
+		return fmt.Errorf("Node [%v, %v] covers %d values, expected %d", n.I, n.J, n.covered, c)
+	}
+	if c := n.Left.getIntervals() + n.Right.getIntervals() + 1; n.intervals != c {
+		return fmt.Errorf("Node [%v, %v] holds %d intervals, expected %d", n.I, n.J, n.intervals, c)
+	}
+	if p := *prev; p != nil {
+		if n.I <= p.J {
+			return fmt.Errorf("Node [%v, %v] is out of order or overlaps [%v, %v]", n.I, n.J, p.I, p.J)
+		}
+		if n.I-1 == p.J {
+			return fmt.Errorf("Node [%v, %v] is adjacent to [%v, %v]", n.I, n.J, p.I, p.J)
+		}
+	}
+	*prev = n
+
+	return n.Right.validate(prev)
+}
+
+// Validate checks the invariants of the tree: every node holds a valid
+// interval, heights, covered values and intervals are consistent, the tree is
+// AVL balanced, and intervals are strictly ordered, do not overlap and are not
+// adjacent. It returns an error describing the first violation found.
+func (t *Tree[T]) Validate() error {
+	t.RLock()
+	defer t.RUnlock()
+
+	var prev *node[T]
+	return t.root.validate(&prev)
+}
@@ -0,0 +1,49 @@
+package intervaltree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	it := New()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		x := uint64(r.Intn(10000))
+		it.Insert(x, x+uint64(r.Intn(10)))
+	}
+	if err := it.Validate(); err != nil {
+		t.Fatalf("Valid tree failed validation: %v", err)
+	}
+
+	for _, c := range []struct {
+		name    string
+		corrupt func(n *node[uint64])
+	}{
+		{"height", func(n *node[uint64]) { n.height++ }},
+		{"invalid interval", func(n *node[uint64]) { n.I = n.J + 1 }},
+		{"overlap", func(n *node[uint64]) { n.I = n.Left.least().I }},
+		{"adjacency", func(n *node[uint64]) {
+			l := n.Left
+			for l.Right != nil {
+				l = l.Right
+			}
+			n.I = l.J + 1
+		}},
+	} {
+		it := New()
+		for x := uint64(0); x < 100; x += 3 {
+			it.Insert(x, x)
+		}
+		c.corrupt(it.root)
+		if err := it.Validate(); err == nil {
+			t.Fatalf("Corrupted %s passed validation", c.name)
+		}
+	}
+
+	unbalanced := New()
+	unbalanced.root = &node[uint64]{I: 0, J: 0, height: 3, Right: &node[uint64]{I: 2, J: 2, height: 2, Right: newNode[uint64](4, 4)}}
+	if err := unbalanced.Validate(); err == nil {
+		t.Fatal("Unbalanced tree passed validation")
+	}
+}
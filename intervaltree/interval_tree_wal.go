@@ -0,0 +1,320 @@
+package intervaltree
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Names of the files kept by a WALTree in its directory.
+const (
+	walSnapshotFile = "snapshot"
+	walLogFile      = "wal"
+)
+
+// defaultCompaction is the number of logged changes after which a WALTree
+// compacts its log unless WithCompaction says otherwise.
+const defaultCompaction = 1 << 16
+
+// WALTree represents a Tree persisted to a directory. Every mutation is
+// appended to a write-ahead log and synced before being applied, and the log
+// is periodically compacted into a snapshot, so the tree survives crashes and
+// restarts without an external database. It is safe for concurrent use.
+type WALTree[T Integer] struct {
+	tree    *Tree[T]
+	dir     string
+	log     *os.File
+	size    int64 // Bytes in the log
+	changes int   // Changes in the log
+	compact int   // Changes after which the log is compacted
+}
+
+// WithCompaction makes a WALTree compact its log into a snapshot whenever it
+// holds the given number of changes.
+func WithCompaction(changes int) Option {
+	return func(c *config) {
+		c.compaction = changes
+	}
+}
+
+// OpenWAL returns a WALTree persisted to dir, which is created if it does not
+// exist, recovering the tree from the snapshot and the log found in it. A log
+// record torn by a crash is discarded. The tree is created with opts.
+func OpenWAL[T Integer](dir string, opts ...Option) (*WALTree[T], error) {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	w := &WALTree[T]{tree: NewTree[T](opts...), dir: dir, compact: c.compaction}
+	if w.compact <= 0 {
+		w.compact = defaultCompaction
+	}
+	if err := w.recoverSnapshot(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, walLogFile), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	w.log = f
+	if err := w.recoverLog(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// recoverSnapshot restores the tree from the snapshot file, if there is one.
+func (w *WALTree[T]) recoverSnapshot() error {
+	f, err := os.Open(filepath.Join(w.dir, walSnapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s, err := ReadSnapshot[T](bufio.NewReader(f))
+	if err != nil {
+		return fmt.Errorf("Malformed WAL snapshot: %w", err)
+	}
+	return w.tree.Restore(s)
+}
+
+// recoverLog replays the log records following the snapshot and truncates the
+// log after the last complete record.
+func (w *WALTree[T]) recoverLog() error {
+	r := &countingByteReader{r: bufio.NewReader(w.log)}
+	for {
+		var rev, op, x, length uint64
+		err := readUvarints(r, &rev, &op, &x, &length)
+		if err == io.ErrUnexpectedEOF {
+			break // End of the log, or a record torn by a crash
+		} else if err != nil {
+			return err
+		}
+
+		i, ok := readInterval[T](x, length)
+		if op > 1 || !ok {
+			return fmt.Errorf("Malformed WAL record at offset %d", w.size)
+		}
+		if rev > w.tree.rev { // Older records were compacted into the snapshot
+			if rev != w.tree.rev+1 {
+				return RevisionError(w.tree.rev + 1)
+			}
+			if op == 1 {
+				err = w.tree.Remove(i.I, i.J)
+			} else {
+				err = w.tree.Insert(i.I, i.J)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		w.size, w.changes = r.n, w.changes+1
+	}
+	return w.log.Truncate(w.size)
+}
+
+// countingByteReader counts the bytes read through it.
+type countingByteReader struct {
+	r io.ByteReader
+	n int64
+}
+
+func (cr *countingByteReader) ReadByte() (byte, error) {
+	b, err := cr.r.ReadByte()
+	if err == nil {
+		cr.n++
+	}
+	return b, err
+}
+
+// Insert adds the interval [x, y] to the tree once it has been logged, as
+// Tree.Insert does.
+func (w *WALTree[T]) Insert(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	w.tree.Lock()
+	defer w.tree.Unlock()
+	plan, err := w.tree.plan(x, y)
+	if err != nil {
+		return w.tree.failed(insertErrors, x, y, err)
+	}
+	for _, i := range plan {
+		if !w.tree.canInsert(i.I, i.J) {
+			return w.tree.insertStrict(i.I, i.J) // Fails with the same error Tree.Insert would
+		}
+		if err := w.append(Change[T]{false, i}); err != nil {
+			return err
+		}
+		w.tree.insertStrict(i.I, i.J)
+	}
+	w.compactIfNeeded()
+	return nil
+}
+
+// Remove deletes the interval [x, y] from the tree once it has been logged, as
+// Tree.Remove does.
+func (w *WALTree[T]) Remove(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	w.tree.Lock()
+	defer w.tree.Unlock()
+	if !w.tree.canRemove(x, y) {
+		return w.tree.remove(x, y) // Fails with the same error Tree.Remove would
+	}
+	if err := w.append(Change[T]{true, Interval[T]{x, y}}); err != nil {
+		return err
+	}
+	w.tree.remove(x, y)
+	w.compactIfNeeded()
+	return nil
+}
+
+// Contains checks if x is contained in the tree.
+func (w *WALTree[T]) Contains(x T) bool {
+	return w.tree.Contains(x)
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to x. It returns false if every such value is contained.
+func (w *WALTree[T]) Next(x T) (T, bool) {
+	return w.tree.Next(x)
+}
+
+// Walk calls fn for every interval in the tree in ascending order, stopping
+// early if fn returns false.
+func (w *WALTree[T]) Walk(fn func(x, y T) bool) {
+	w.tree.Walk(fn)
+}
+
+// Compact writes a snapshot of the tree and empties the log.
+func (w *WALTree[T]) Compact() error {
+	w.tree.Lock()
+	defer w.tree.Unlock()
+	return w.compactLocked()
+}
+
+// Close closes the log. The tree must not be used afterwards.
+func (w *WALTree[T]) Close() error {
+	w.tree.Lock()
+	defer w.tree.Unlock()
+	return w.log.Close()
+}
+
+// append writes the record of c, which takes the tree to its next revision, to
+// the log and syncs it. A partially written record is truncated away. The
+// caller must hold the write lock.
+func (w *WALTree[T]) append(c Change[T]) error {
+	op := uint64(0)
+	if c.Removed {
+		op = 1
+	}
+	vw := &varintWriter{w: bufio.NewWriter(w.log)}
+	vw.put(w.tree.rev + 1)
+	vw.put(op)
+	vw.put(ordinal(c.I))
+	vw.put(ordinal(c.J) - ordinal(c.I))
+	n, err := vw.flush()
+	if err == nil {
+		err = w.log.Sync()
+	}
+	if err != nil {
+		w.log.Truncate(w.size)
+		return err
+	}
+
+	w.size += n
+	w.changes++
+	return nil
+}
+
+// compactIfNeeded compacts the log if it holds enough changes. The changes are
+// already durable, so a failed compaction is just retried after the next one.
+// The caller must hold the write lock.
+func (w *WALTree[T]) compactIfNeeded() {
+	if w.changes >= w.compact {
+		w.compactLocked()
+	}
+}
+
+// compactLocked writes a snapshot of the tree and empties the log. The
+// snapshot replaces the previous one atomically, and records it covers are
+// skipped on recovery, so a crash at any point loses nothing. The caller must
+// hold the write lock.
+func (w *WALTree[T]) compactLocked() error {
+	s := Snapshot[T]{Revision: w.tree.rev}
+	w.tree.root.walk(func(x, y T) bool {
+		s.Intervals = append(s.Intervals, Interval[T]{x, y})
+		return true
+	})
+
+	path := filepath.Join(w.dir, walSnapshotFile)
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := s.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	if d, err := os.Open(w.dir); err == nil { // Persist the rename
+		d.Sync()
+		d.Close()
+	}
+
+	if err := w.log.Truncate(0); err != nil {
+		return err
+	}
+	w.size, w.changes = 0, 0
+	return nil
+}
+
+// canInsert checks if [x, y] overlaps no interval in the tree and fits in its
+// capacity. The caller must hold the lock.
+func (t *Tree[T]) canInsert(x, y T) bool {
+	if t.capped && t.checkCapacity(x, y) != nil {
+		return false
+	}
+	return t.disjoint(x, y)
+}
+
+// disjoint checks if [x, y] overlaps no interval in the tree. The caller must
+// hold the lock.
+func (t *Tree[T]) disjoint(x, y T) bool {
+	if l := t.root.floor(x); l != nil && l.J >= x {
+		return false
+	}
+	r := t.root.higher(x)
+	return r == nil || r.I > y
+}
+
+// canRemove checks if [x, y] is contained in the tree. The caller must hold the
+// lock.
+func (t *Tree[T]) canRemove(x, y T) bool {
+	c := t.root.containingNode(x)
+	return c != nil && y <= c.J
+}
@@ -0,0 +1,111 @@
+package intervaltree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWALRecovery(t *testing.T) {
+	dir := t.TempDir()
+	w, err := OpenWAL[int32](dir, WithCompaction(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Insert(1, 10)
+	w.Insert(20, 30)
+	if err := w.Insert(5, 6); err == nil {
+		t.Fatal("Overlapping insertion succeeded")
+	}
+	w.Remove(4, 6)
+	w.Insert(-5, 0) // Compacts the log
+	w.Insert(40, 50)
+	w.Remove(25, 25)
+	expected := walkString[int32](w)
+	w.Close()
+
+	w, err = OpenWAL[int32](dir, WithCompaction(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if s := walkString[int32](w); s != expected {
+		t.Fatalf("Unexpected recovered tree: %s, expected %s", s, expected)
+	}
+	if w.tree.Revision() != 6 || w.changes != 2 {
+		t.Fatalf("Unexpected revision %d with %d logged changes", w.tree.Revision(), w.changes)
+	}
+}
+
+func TestWALTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	w, err := OpenWAL[uint64](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Insert(1, 10)
+	w.Insert(1000, 2000)
+	w.Close()
+
+	// Simulate a crash in the middle of writing the second record
+	path := filepath.Join(dir, walLogFile)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size()-1); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err = OpenWAL[uint64](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := walkString[uint64](w); s != "[1 -- 10]" {
+		t.Fatalf("Unexpected recovered tree: %s", s)
+	}
+	w.Insert(1000, 2000)
+	w.Close()
+
+	w, err = OpenWAL[uint64](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if s := walkString[uint64](w); s != "[1 -- 10][1000 -- 2000]" {
+		t.Fatalf("Unexpected recovered tree after truncation: %s", s)
+	}
+}
+
+func TestWALCrashDuringCompaction(t *testing.T) {
+	dir := t.TempDir()
+	w, err := OpenWAL[uint64](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Insert(1, 10)
+	w.Insert(20, 30)
+
+	// Keep the log as it was before compacting, as if the crash happened
+	// after the snapshot was renamed but before the log was truncated
+	log, err := os.ReadFile(filepath.Join(dir, walLogFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if err := os.WriteFile(filepath.Join(dir, walLogFile), log, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err = OpenWAL[uint64](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if s := walkString[uint64](w); s != "[1 -- 10][20 -- 30]" {
+		t.Fatalf("Unexpected recovered tree: %s", s)
+	}
+}
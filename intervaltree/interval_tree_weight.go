@@ -0,0 +1,352 @@
+package intervaltree
+
+import "sync"
+
+// Balance parameters of WeightBalancedTree, as proven valid by Hirai and
+// Yamamoto: no subtree is more than delta times bigger than its sibling, and
+// a double rotation is used when the inner grandchild is at least gamma times
+// smaller than the outer one.
+const (
+	wbDelta = 3
+	wbGamma = 2
+)
+
+// WeightBalancedTree represents the same set of intervals as Tree, but is
+// balanced by the sizes of subtrees instead of their heights. As every node
+// knows the size of its subtree, it can also count intervals and find them by
+// position in logarithmic time, through Rank, Select and Count.
+type WeightBalancedTree[T Integer] struct {
+	root *wbNode[T]
+	sync.RWMutex
+}
+
+// wbNode holds an interval [I, J], the number of intervals in its subtree and
+// pointers to nodes holding intervals lesser and greater than its own.
+type wbNode[T Integer] struct {
+	I, J        T          // Interval bounds
+	Left, Right *wbNode[T] // Left and right children
+	size        int        // Nodes in the subtree rooted at this node
+}
+
+// NewWeightBalanced returns a pointer to an empty WeightBalancedTree.
+func NewWeightBalanced[T Integer]() *WeightBalancedTree[T] {
+	return &WeightBalancedTree[T]{}
+}
+
+// getSize returns the number of nodes in the subtree rooted at n.
+func (n *wbNode[T]) getSize() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+// updateSize recalculates the size of this node from its children.
+func (n *wbNode[T]) updateSize() {
+	n.size = n.Left.getSize() + n.Right.getSize() + 1
+}
+
+// rotateLeft lifts the left child of this node, returning it.
+func (n *wbNode[T]) rotateLeft() *wbNode[T] {
+	pivot := n.Left
+	n.Left = pivot.Right
+	pivot.Right = n
+	n.updateSize()
+	pivot.updateSize()
+	return pivot
+}
+
+// rotateRight lifts the right child of this node, returning it.
+func (n *wbNode[T]) rotateRight() *wbNode[T] {
+	pivot := n.Right
+	n.Right = pivot.Left
+	pivot.Left = n
+	n.updateSize()
+	pivot.updateSize()
+	return pivot
+}
+
+// rebalance restores the weight balance of this node after one of its
+// subtrees gained or lost a node, returning the new root of its subtree.
+func (n *wbNode[T]) rebalance() *wbNode[T] {
+	l, r := n.Left.getSize(), n.Right.getSize()
+	switch {
+	case l+r <= 1:
+	case r > wbDelta*l:
+		if n.Right.Left.getSize() >= wbGamma*n.Right.Right.getSize() {
+			n.Right = n.Right.rotateLeft()
+		}
+		return n.rotateRight()
+	case l > wbDelta*r:
+		if n.Left.Right.getSize() >= wbGamma*n.Left.Left.getSize() {
+			n.Left = n.Left.rotateRight()
+		}
+		return n.rotateLeft()
+	}
+
+	n.updateSize()
+	return n
+}
+
+// insert adds a node holding [x, y] to the subtree rooted at n, which holds no
+// interval starting at x, returning the new root of the subtree.
+func (n *wbNode[T]) insert(x, y T) *wbNode[T] {
+	if n == nil {
+		return &wbNode[T]{I: x, J: y, size: 1}
+	}
+
+	if x < n.I {
+		n.Left = n.Left.insert(x, y)
+	} else {
+		n.Right = n.Right.insert(x, y)
+	}
+	return n.rebalance()
+}
+
+// remove deletes the node holding the interval starting at x from the subtree
+// rooted at n, returning the new root of the subtree. Such a node must exist.
+func (n *wbNode[T]) remove(x T) *wbNode[T] {
+	if x < n.I {
+		n.Left = n.Left.remove(x)
+	} else if x > n.I {
+		n.Right = n.Right.remove(x)
+	} else if n.Left == nil {
+		return n.Right
+	} else if n.Right == nil {
+		return n.Left
+	} else { // Replace this interval with the next one
+		var next *wbNode[T]
+		n.Right, next = n.Right.removeLeast()
+		n.I, n.J = next.I, next.J
+	}
+	return n.rebalance()
+}
+
+// removeLeast deletes the node holding the least interval from the subtree
+// rooted at n, returning the new root of the subtree and the deleted node.
+func (n *wbNode[T]) removeLeast() (*wbNode[T], *wbNode[T]) {
+	if n.Left == nil {
+		return n.Right, n
+	}
+
+	var least *wbNode[T]
+	n.Left, least = n.Left.removeLeast()
+	return n.rebalance(), least
+}
+
+// floor returns the node holding the greatest interval starting at or before
+// x in the subtree rooted at n, or nil if there is none.
+func (n *wbNode[T]) floor(x T) *wbNode[T] {
+	var ret *wbNode[T]
+	for n != nil {
+		if n.I <= x {
+			ret, n = n, n.Right
+		} else {
+			n = n.Left
+		}
+	}
+	return ret
+}
+
+// higher returns the node holding the least interval starting after x in the
+// subtree rooted at n, or nil if there is none.
+func (n *wbNode[T]) higher(x T) *wbNode[T] {
+	var ret *wbNode[T]
+	for n != nil {
+		if n.I > x {
+			ret, n = n, n.Left
+		} else {
+			n = n.Right
+		}
+	}
+	return ret
+}
+
+// walk calls fn recursively for the intervals of this node and its children in
+// ascending order. It stops as soon as fn returns false, and reports whether
+// the walk was completed.
+func (n *wbNode[T]) walk(fn func(x, y T) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	return n.Left.walk(fn) && fn(n.I, n.J) && n.Right.walk(fn)
+}
+
+// countBefore returns the number of intervals in the subtree rooted at n that
+// end before x.
+func (n *wbNode[T]) countBefore(x T) int {
+	count := 0
+	for n != nil {
+		if n.J < x {
+			count += n.Left.getSize() + 1
+			n = n.Right
+		} else {
+			n = n.Left
+		}
+	}
+	return count
+}
+
+// countUpTo returns the number of intervals in the subtree rooted at n that
+// start at or before x.
+func (n *wbNode[T]) countUpTo(x T) int {
+	count := 0
+	for n != nil {
+		if n.I <= x {
+			count += n.Left.getSize() + 1
+			n = n.Right
+		} else {
+			n = n.Left
+		}
+	}
+	return count
+}
+
+// Insert adds an interval to the tree. The interval cannot overlap with the
+// tree. If prunning is possible it will be done.
+func (t *WeightBalancedTree[T]) Insert(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	l, r := t.root.floor(x), t.root.higher(x)
+	if l != nil && l.J >= x {
+		return OverlapError[T]{x, Interval[T]{x, y}, Interval[T]{l.I, l.J}}
+	}
+	if r != nil && r.I <= y {
+		return OverlapError[T]{r.I, Interval[T]{x, y}, Interval[T]{r.I, r.J}}
+	}
+
+	// Neighbours are checked stepping towards the lesser value, so no check
+	// can overflow at the bounds of T
+	joinL := l != nil && l.J == x-1
+	joinR := r != nil && r.I-1 == y
+	switch {
+	case joinL && joinR: // Fill the gap between l and r
+		j := r.J
+		t.root = t.root.remove(r.I)
+		l.J = j
+	case joinL:
+		l.J = y
+	case joinR:
+		r.I = x
+	default:
+		t.root = t.root.insert(x, y)
+	}
+	return nil
+}
+
+// Remove deletes the interval [x, y] from the tree. Every value in [x, y] must
+// be contained in the tree. Stored intervals are shrunk or split as needed.
+func (t *WeightBalancedTree[T]) Remove(x, y T) error {
+	if x > y {
+		return InvalidIntervalError[T]{x, y}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	// Stored intervals are never adjacent, so [x, y] must lie in a single one
+	c := t.root.floor(x)
+	if c == nil || c.J < x {
+		return NotContainedError[T]{x}
+	}
+	if y > c.J {
+		return NotContainedError[T]{c.J + 1}
+	}
+
+	switch i, j := c.I, c.J; {
+	case x == i && y == j:
+		t.root = t.root.remove(i)
+	case x == i:
+		c.I = y + 1
+	case y == j:
+		c.J = x - 1
+	default: // Split, the upper part becomes a new node
+		c.J = x - 1
+		t.root = t.root.insert(y+1, j)
+	}
+	return nil
+}
+
+// Contains checks if x is contained in the tree.
+func (t *WeightBalancedTree[T]) Contains(x T) bool {
+	t.RLock()
+	defer t.RUnlock()
+
+	c := t.root.floor(x)
+	return c != nil && x <= c.J
+}
+
+// Next returns the minimum value not contained in the tree that is greater or
+// equal to x. It returns false if every such value is contained.
+func (t *WeightBalancedTree[T]) Next(x T) (T, bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	c := t.root.floor(x)
+	if c == nil || x > c.J {
+		return x, true
+	}
+	return after(c.J)
+}
+
+// Walk calls fn for the intervals in the tree in ascending order. It stops as
+// soon as fn returns false. The tree cannot be changed from fn.
+func (t *WeightBalancedTree[T]) Walk(fn func(x, y T) bool) {
+	t.RLock()
+	defer t.RUnlock()
+	t.root.walk(fn)
+}
+
+// Len returns the number of intervals in the tree.
+func (t *WeightBalancedTree[T]) Len() int {
+	t.RLock()
+	defer t.RUnlock()
+	return t.root.getSize()
+}
+
+// Rank returns the number of intervals in the tree that end before x, which is
+// the position of the interval containing x, or of the first interval after x,
+// in ascending order.
+func (t *WeightBalancedTree[T]) Rank(x T) int {
+	t.RLock()
+	defer t.RUnlock()
+	return t.root.countBefore(x)
+}
+
+// Select returns the interval at position k in ascending order, starting at 0,
+// and false if there are not so many intervals.
+func (t *WeightBalancedTree[T]) Select(k int) (Interval[T], bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	for n := t.root; n != nil; {
+		l := n.Left.getSize()
+		switch {
+		case k < l:
+			n = n.Left
+		case k > l:
+			k -= l + 1
+			n = n.Right
+		default:
+			return Interval[T]{n.I, n.J}, true
+		}
+	}
+	return Interval[T]{}, false
+}
+
+// Count returns the number of intervals in the tree that overlap [x, y].
+func (t *WeightBalancedTree[T]) Count(x, y T) int {
+	if x > y {
+		return 0
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+	return t.root.countUpTo(y) - t.root.countBefore(x)
+}
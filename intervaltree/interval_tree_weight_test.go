@@ -0,0 +1,100 @@
+package intervaltree
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// isWeightBalanced checks the size and balance invariants of the subtree
+// rooted at n.
+func (n *wbNode[T]) isWeightBalanced() error {
+	if n == nil {
+		return nil
+	}
+
+	l, r := n.Left.getSize(), n.Right.getSize()
+	if n.size != l+r+1 {
+		return fmt.Errorf("Node [%d, %d] has size %d, expected %d", n.I, n.J, n.size, l+r+1)
+	}
+	if l+r > 1 && (l > wbDelta*r || r > wbDelta*l) {
+		return fmt.Errorf("Node [%d, %d] is unbalanced: %d, %d", n.I, n.J, l, r)
+	}
+
+	if err := n.Left.isWeightBalanced(); err != nil {
+		return err
+	}
+	return n.Right.isWeightBalanced()
+}
+
+// String returns the intervals in the tree in the format of ToString.
+func (t *WeightBalancedTree[T]) String() string {
+	var s strings.Builder
+	t.Walk(func(x, y T) bool {
+		fmt.Fprintf(&s, "[%d -- %d]", x, y)
+		return true
+	})
+	return s.String()
+}
+
+func TestWeightBalancedTree(t *testing.T) {
+	wt := NewWeightBalanced[uint8]()
+	for _, i := range []Interval[uint8]{{10, 20}, {30, 40}, {0, 5}, {250, 255}, {21, 29}, {100, 100}} {
+		if err := wt.Insert(i.I, i.J); err != nil {
+			t.Fatalf("Failed to insert %v: %v", i, err)
+		}
+	}
+	if s := wt.String(); s != "[0 -- 5][10 -- 40][100 -- 100][250 -- 255]" {
+		t.Fatalf("Unexpected intervals: %s", s)
+	}
+
+	if wt.Len() != 4 || wt.Rank(0) != 0 || wt.Rank(6) != 1 || wt.Rank(40) != 1 || wt.Rank(41) != 2 {
+		t.Fatal("Unexpected Len or Rank")
+	}
+	if i, ok := wt.Select(2); !ok || i != (Interval[uint8]{100, 100}) {
+		t.Fatalf("Unexpected Select(2): %v", i)
+	}
+	if _, ok := wt.Select(4); ok {
+		t.Fatal("Selected interval past the end")
+	}
+	if wt.Count(5, 10) != 2 || wt.Count(41, 99) != 0 || wt.Count(0, 255) != 4 || wt.Count(255, 255) != 1 {
+		t.Fatal("Unexpected Count")
+	}
+}
+
+func TestWeightBalancedTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	wt, it := NewWeightBalanced[int16](), NewTree[int16]()
+	for i := 0; i < 3000; i++ {
+		x := int16(r.Intn(2000) - 1000)
+		y := x + int16(r.Intn(10))
+		if r.Intn(3) == 0 {
+			if errA, errB := wt.Remove(x, y), it.Remove(x, y); (errA == nil) != (errB == nil) {
+				t.Fatalf("Remove [%d, %d] failed differently: %v, %v", x, y, errA, errB)
+			}
+		} else if errA, errB := wt.Insert(x, y), it.Insert(x, y); (errA == nil) != (errB == nil) {
+			t.Fatalf("Insert [%d, %d] failed differently: %v, %v", x, y, errA, errB)
+		}
+
+		if err := wt.root.isWeightBalanced(); err != nil {
+			t.Fatalf("Tree is not weight balanced after %d operations: %v", i, err)
+		}
+		if wt.String() != it.ToString() {
+			t.Fatalf("Trees differ after %d operations", i)
+		}
+	}
+
+	intervals := it.Checkpoint().Intervals
+	for k, i := range intervals {
+		if s, ok := wt.Select(k); !ok || s != i || wt.Rank(i.I) != k {
+			t.Fatalf("Unexpected Select or Rank of %v at %d", i, k)
+		}
+	}
+	for x := math.MinInt16; x <= math.MaxInt16; x += 7 {
+		if wt.Contains(int16(x)) != it.Contains(int16(x)) || !sameNext(wt, it, int16(x)) {
+			t.Fatalf("Trees differ at %d", x)
+		}
+	}
+}
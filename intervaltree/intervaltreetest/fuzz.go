@@ -0,0 +1,80 @@
+package intervaltreetest
+
+import (
+	"math/rand"
+	"testing"
+	"unsafe"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+// DecodeOps decodes data into a sequence of operations, so that any input of a
+// fuzzer is a valid one. Each operation takes a byte selecting its kind, the
+// bytes of X in little-endian order and a byte holding Y-X, which keeps
+// intervals short enough for a Reference. Trailing bytes are ignored.
+func DecodeOps[T intervaltree.Integer](data []byte) []Op[T] {
+	size := int(unsafe.Sizeof(T(0)))
+	var ops []Op[T]
+	for ; len(data) >= size+2; data = data[size+2:] {
+		var u uint64
+		for k := size - 1; k >= 0; k-- {
+			u = u<<8 | uint64(data[1+k])
+		}
+		x := T(u)
+		d := data[size+1]
+		for x+T(d) < x { // Past the greatest value of T
+			d--
+		}
+		ops = append(ops, Op[T]{Kind: Kind(data[0] % 4), X: x, Y: x + T(d)})
+	}
+	return ops
+}
+
+// EncodeOps encodes ops in the format read by DecodeOps, such as to build a
+// seed corpus. Intervals are shortened to hold up to 256 values.
+func EncodeOps[T intervaltree.Integer](ops []Op[T]) []byte {
+	size := int(unsafe.Sizeof(T(0)))
+	var data []byte
+	for _, op := range ops {
+		data = append(data, byte(op.Kind))
+		for k := 0; k < size; k++ {
+			data = append(data, byte(op.X>>(8*k)))
+		}
+		d := uint64(op.Y) - uint64(op.X) // Modulo 2^64, as T may be signed
+		if op.Y < op.X || d > 255 {
+			d = 255
+		}
+		data = append(data, byte(d))
+	}
+	return data
+}
+
+// Seeds returns n inputs for a fuzzer of sets of T, each holding ops random
+// operations on values in [lo, hi] as generated by RandomOps.
+func Seeds[T intervaltree.Integer](rnd *rand.Rand, n, ops int, lo, hi T) [][]byte {
+	seeds := make([][]byte, n)
+	for k := range seeds {
+		seeds[k] = EncodeOps(RandomOps(rnd, ops, lo, hi))
+	}
+	return seeds
+}
+
+// Fuzz decodes data with DecodeOps and checks the operations against a set
+// returned by newSet, which must be empty, as Check does, failing t on the
+// first difference or broken invariant. It is meant to be called from the
+// function passed to testing.F.Fuzz:
+//
+//	func FuzzTree(f *testing.F) {
+//		for _, seed := range intervaltreetest.Seeds[uint8](rand.New(rand.NewSource(1)), 16, 64, 0, 255) {
+//			f.Add(seed)
+//		}
+//		f.Fuzz(func(t *testing.T, data []byte) {
+//			intervaltreetest.Fuzz(t, func() intervaltree.Set[uint8] { return intervaltree.NewTree[uint8]() }, data)
+//		})
+//	}
+func Fuzz[T intervaltree.Integer](t testing.TB, newSet func() intervaltree.Set[T], data []byte) {
+	t.Helper()
+	if err := Check(newSet(), DecodeOps[T](data)); err != nil {
+		t.Fatal(err)
+	}
+}
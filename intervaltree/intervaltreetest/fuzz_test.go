@@ -0,0 +1,56 @@
+package intervaltreetest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+func FuzzTree(f *testing.F) {
+	for _, seed := range Seeds[uint8](rand.New(rand.NewSource(1)), 16, 64, 0, 255) {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		Fuzz(t, func() intervaltree.Set[uint8] { return intervaltree.NewTree[uint8]() }, data)
+	})
+}
+
+func FuzzBackends(f *testing.F) {
+	for _, seed := range Seeds[int16](rand.New(rand.NewSource(1)), 16, 64, -500, 500) {
+		f.Add(byte(0), seed)
+	}
+	f.Fuzz(func(t *testing.T, backend byte, data []byte) {
+		b := intervaltree.Backend(backend % (byte(intervaltree.WeightBalancedBackend) + 1))
+		Fuzz(t, func() intervaltree.Set[int16] { return intervaltree.NewSet[int16](b) }, data)
+	})
+}
+
+func TestDecodeOps(t *testing.T) {
+	ops := []Op[int16]{
+		{Kind: Insert, X: -300, Y: -200},
+		{Kind: Remove, X: 32760, Y: 32767},
+		{Kind: Contains, X: 5, Y: 5},
+		{Kind: Next, X: -32768, Y: -32768},
+	}
+	decoded := DecodeOps[int16](append(EncodeOps(ops), 1, 2)) // Trailing bytes are ignored
+	if len(decoded) != len(ops) {
+		t.Fatalf("Decoded %d operations", len(decoded))
+	}
+	for k := range ops {
+		if decoded[k] != ops[k] {
+			t.Fatalf("Operation %d decoded as %v, expected %v", k, decoded[k], ops[k])
+		}
+	}
+
+	// Intervals past the greatest value are shortened
+	if op := DecodeOps[uint8]([]byte{0, 250, 100})[0]; op.X != 250 || op.Y != 255 {
+		t.Fatalf("Unexpected operation: %v", op)
+	}
+	if op := DecodeOps[int8]([]byte{0, 120, 100})[0]; op.X != 120 || op.Y != 127 {
+		t.Fatalf("Unexpected operation: %v", op)
+	}
+	if op := EncodeOps([]Op[uint32]{{Kind: Insert, X: 0, Y: 1000}}); op[5] != 255 {
+		t.Fatalf("Unexpected encoding: %v", op)
+	}
+}
@@ -0,0 +1,45 @@
+package intervaltreetest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+// UpdateEnv is the environment variable that, set to a non-empty value, makes
+// Golden rewrite golden files instead of comparing against them, as in
+//
+//	INTERVALTREETEST_UPDATE=1 go test ./...
+const UpdateEnv = "INTERVALTREETEST_UPDATE"
+
+// Golden compares the golden dump of t in the given form with the contents of
+// the file at path, failing tb with the first differing line if they differ.
+// If UpdateEnv is set, the file and its directory are written instead.
+func Golden[T intervaltree.Integer](tb testing.TB, path string, t *intervaltree.Tree[T], form intervaltree.GoldenForm) {
+	tb.Helper()
+
+	got := t.Golden(form)
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			tb.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		tb.Fatalf("Golden file %s does not exist; set %s=1 to create it", path, UpdateEnv)
+	}
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := intervaltree.CompareGolden(got, string(want)); err != nil {
+		tb.Fatalf("%s: %v", path, err)
+	}
+}
@@ -0,0 +1,20 @@
+package intervaltreetest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+func TestGolden(t *testing.T) {
+	it := intervaltree.NewTree[uint8]()
+	it.Insert(1, 5)
+	it.Insert(10, 10)
+
+	path := filepath.Join(t.TempDir(), "testdata", "tree.golden")
+	t.Setenv(UpdateEnv, "1")
+	Golden(t, path, it, intervaltree.GoldenCanonical)
+	t.Setenv(UpdateEnv, "")
+	Golden(t, path, it, intervaltree.GoldenCanonical)
+}
@@ -0,0 +1,297 @@
+// Package intervaltreetest provides utilities for differential testing of
+// implementations of intervaltree.Set: a trivially correct Reference holding
+// every value in a map, and Check, which runs a sequence of operations against
+// a set and a Reference and reports the first result in which they differ.
+// Random sequences are generated with RandomOps.
+package intervaltreetest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+// Reference is an intervaltree.Set holding every value it contains in a map,
+// so it is obviously correct but only practical for small domains. Its errors
+// carry the same information as those of intervaltree.Tree.
+type Reference[T intervaltree.Integer] struct {
+	values map[T]struct{}
+}
+
+// NewReference returns a pointer to an empty Reference.
+func NewReference[T intervaltree.Integer]() *Reference[T] {
+	return &Reference[T]{values: map[T]struct{}{}}
+}
+
+// Insert adds [x, y] to the set. It cannot overlap with the set.
+func (r *Reference[T]) Insert(x, y T) error {
+	if x > y {
+		return intervaltree.InvalidIntervalError[T]{X: x, Y: y}
+	}
+	for v := x; ; v++ {
+		if r.Contains(v) {
+			return intervaltree.OverlapError[T]{Value: v, Attempted: intervaltree.Interval[T]{I: x, J: y}, Existing: r.around(v)}
+		}
+		if v == y {
+			break
+		}
+	}
+	for v := x; ; v++ {
+		r.values[v] = struct{}{}
+		if v == y {
+			return nil
+		}
+	}
+}
+
+// Remove deletes [x, y] from the set. It must be contained in the set.
+func (r *Reference[T]) Remove(x, y T) error {
+	if x > y {
+		return intervaltree.InvalidIntervalError[T]{X: x, Y: y}
+	}
+	for v := x; ; v++ {
+		if !r.Contains(v) {
+			return intervaltree.NotContainedError[T]{Value: v}
+		}
+		if v == y {
+			break
+		}
+	}
+	for v := x; ; v++ {
+		delete(r.values, v)
+		if v == y {
+			return nil
+		}
+	}
+}
+
+// Contains checks if x is contained in the set.
+func (r *Reference[T]) Contains(x T) bool {
+	_, ok := r.values[x]
+	return ok
+}
+
+// Next returns the minimum value not contained in the set that is greater or
+// equal to x. It returns false if every such value is contained.
+func (r *Reference[T]) Next(x T) (T, bool) {
+	for r.Contains(x) {
+		if x+1 < x { // x is the greatest value of T
+			return x, false
+		}
+		x++
+	}
+	return x, true
+}
+
+// Walk calls fn for the maximal intervals in the set in ascending order. It
+// stops as soon as fn returns false.
+func (r *Reference[T]) Walk(fn func(x, y T) bool) {
+	for _, i := range r.Intervals() {
+		if !fn(i.I, i.J) {
+			return
+		}
+	}
+}
+
+// Intervals returns the maximal intervals in the set in ascending order.
+func (r *Reference[T]) Intervals() []intervaltree.Interval[T] {
+	values := make([]T, 0, len(r.values))
+	for v := range r.values {
+		values = append(values, v)
+	}
+	slices.Sort(values)
+
+	var ret []intervaltree.Interval[T]
+	for _, v := range values {
+		if last := len(ret) - 1; last >= 0 && ret[last].J+1 == v {
+			ret[last].J = v
+		} else {
+			ret = append(ret, intervaltree.Interval[T]{I: v, J: v})
+		}
+	}
+	return ret
+}
+
+// around returns the maximal interval of the set holding x, which must be
+// contained.
+func (r *Reference[T]) around(x T) intervaltree.Interval[T] {
+	i := intervaltree.Interval[T]{I: x, J: x}
+	for i.I-1 < i.I && r.Contains(i.I-1) {
+		i.I--
+	}
+	for i.J+1 > i.J && r.Contains(i.J+1) {
+		i.J++
+	}
+	return i
+}
+
+// Kind selects the operation run by an Op.
+type Kind uint8
+
+// Kinds of operations.
+const (
+	Insert   Kind = iota // Insert(X, Y)
+	Remove               // Remove(X, Y)
+	Contains             // Contains(X)
+	Next                 // Next(X)
+)
+
+// Op is an operation on a set.
+type Op[T intervaltree.Integer] struct {
+	Kind Kind
+	X, Y T // Y is ignored by Contains and Next
+}
+
+func (o Op[T]) String() string {
+	switch o.Kind {
+	case Insert:
+		return fmt.Sprintf("Insert(%v, %v)", o.X, o.Y)
+	case Remove:
+		return fmt.Sprintf("Remove(%v, %v)", o.X, o.Y)
+	case Contains:
+		return fmt.Sprintf("Contains(%v)", o.X)
+	case Next:
+		return fmt.Sprintf("Next(%v)", o.X)
+	}
+	return fmt.Sprintf("Op(%d, %v, %v)", o.Kind, o.X, o.Y)
+}
+
+// MismatchError is returned by Check for the first operation whose result
+// differs between the set and the reference.
+type MismatchError[T intervaltree.Integer] struct {
+	Index     int   // Index of the operation in the sequence
+	Op        Op[T] // Operation run
+	Got, Want string
+}
+
+func (e MismatchError[T]) Error() string {
+	return fmt.Sprintf("Operation %d, %v: got %s, want %s", e.Index, e.Op, e.Got, e.Want)
+}
+
+// Validator is implemented by sets able to check their internal invariants,
+// such as intervaltree.Tree.
+type Validator interface {
+	Validate() error
+}
+
+// Check runs ops against s and a new Reference, in order, and returns a
+// MismatchError for the first operation whose result differs or after which
+// they hold different intervals. Errors are compared by their kind, as told by
+// the sentinels of intervaltree. If s is a Validator, its invariants are
+// checked after every operation as well. s must be empty.
+func Check[T intervaltree.Integer](s intervaltree.Set[T], ops []Op[T]) error {
+	v, _ := s.(Validator)
+	r := NewReference[T]()
+	for k, op := range ops {
+		var got, want string
+		switch op.Kind {
+		case Insert:
+			got, want = errorKind(s.Insert(op.X, op.Y)), errorKind(r.Insert(op.X, op.Y))
+		case Remove:
+			got, want = errorKind(s.Remove(op.X, op.Y)), errorKind(r.Remove(op.X, op.Y))
+		case Contains:
+			got, want = fmt.Sprint(s.Contains(op.X)), fmt.Sprint(r.Contains(op.X))
+		case Next:
+			got, want = next(s, op.X), next(r, op.X)
+		default:
+			return fmt.Errorf("Unknown operation %v", op)
+		}
+		if got != want {
+			return MismatchError[T]{k, op, got, want}
+		}
+
+		if got, want := fmt.Sprint(intervals(s)), fmt.Sprint(r.Intervals()); got != want {
+			return MismatchError[T]{k, op, "intervals " + got, "intervals " + want}
+		}
+		if v != nil {
+			if err := v.Validate(); err != nil {
+				return fmt.Errorf("Operation %d, %v: %w", k, op, err)
+			}
+		}
+	}
+	return nil
+}
+
+// errorKind describes err by the sentinel of intervaltree it wraps.
+func errorKind(err error) string {
+	for _, sentinel := range []error{intervaltree.ErrOverlap, intervaltree.ErrInvalidInterval, intervaltree.ErrNotFound} {
+		if errors.Is(err, sentinel) {
+			return sentinel.Error()
+		}
+	}
+	if err != nil {
+		return "unexpected error: " + err.Error()
+	}
+	return "no error"
+}
+
+// next describes the result of s.Next(x), ignoring the value if there is none.
+func next[T intervaltree.Integer](s intervaltree.ReadSet[T], x T) string {
+	if n, ok := s.Next(x); ok {
+		return fmt.Sprint(n)
+	}
+	return "none"
+}
+
+// intervals returns the intervals in s in ascending order.
+func intervals[T intervaltree.Integer](s intervaltree.ReadSet[T]) []intervaltree.Interval[T] {
+	var ret []intervaltree.Interval[T]
+	s.Walk(func(x, y T) bool {
+		ret = append(ret, intervaltree.Interval[T]{I: x, J: y})
+		return true
+	})
+	return ret
+}
+
+// RandomOps returns n random operations on values in [lo, hi], mixing
+// insertions, removals, often of parts of values inserted before, and lookups.
+// Intervals span up to a tenth of the domain, so that they collide often.
+func RandomOps[T intervaltree.Integer](rnd *rand.Rand, n int, lo, hi T) []Op[T] {
+	width := uint64(hi) - uint64(lo) + 1 // Modulo 2^64, 0 standing for 2^64
+	value := func() T {
+		if width == 0 {
+			return T(rnd.Uint64())
+		}
+		return lo + T(rnd.Uint64()%width)
+	}
+	longest := max(width/10, 1)
+	if width == 0 {
+		longest = math.MaxUint64 / 10
+	}
+	span := func(x T) T {
+		length := uint64(rnd.Int63n(int64(longest)))
+		if y := x + T(length); y >= x && y <= hi {
+			return y
+		}
+		return hi
+	}
+
+	var inserted []Op[T]
+	ops := make([]Op[T], n)
+	for k := range ops {
+		x := value()
+		switch p := rnd.Intn(20); {
+		case p < 8:
+			ops[k] = Op[T]{Kind: Insert, X: x, Y: span(x)}
+			inserted = append(inserted, ops[k])
+		case p < 11 && len(inserted) > 0: // Part of an interval inserted before
+			i := inserted[rnd.Intn(len(inserted))]
+			x = i.X + T(rnd.Uint64()%(uint64(i.Y)-uint64(i.X)+1))
+			ops[k] = Op[T]{Kind: Remove, X: x, Y: i.X + T(rnd.Uint64()%(uint64(i.Y)-uint64(i.X)+1))}
+			if ops[k].Y < x {
+				ops[k].X, ops[k].Y = ops[k].Y, x
+			}
+		case p < 13:
+			ops[k] = Op[T]{Kind: Remove, X: x, Y: span(x)}
+		case p < 17:
+			ops[k] = Op[T]{Kind: Contains, X: x}
+		default:
+			ops[k] = Op[T]{Kind: Next, X: x}
+		}
+	}
+	return ops
+}
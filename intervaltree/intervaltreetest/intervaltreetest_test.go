@@ -0,0 +1,96 @@
+package intervaltreetest
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+func TestBackends(t *testing.T) {
+	for b := intervaltree.AVLBackend; b <= intervaltree.WeightBalancedBackend; b++ {
+		for seed := int64(0); seed < 10; seed++ {
+			r := rand.New(rand.NewSource(seed))
+			if err := Check(intervaltree.NewSet[uint8](b), RandomOps[uint8](r, 500, 0, 255)); err != nil {
+				t.Fatalf("Backend %d, seed %d: %v", b, seed, err)
+			}
+			if err := Check(intervaltree.NewSet[int16](b), RandomOps[int16](r, 500, -300, 300)); err != nil {
+				t.Fatalf("Backend %d, seed %d: %v", b, seed, err)
+			}
+		}
+	}
+}
+
+func TestReference(t *testing.T) {
+	r := NewReference[int8]()
+	r.Insert(-128, -120)
+	r.Insert(0, 10)
+	r.Insert(11, 20)
+	r.Insert(120, 127)
+
+	var oe intervaltree.OverlapError[int8]
+	if err := r.Insert(-5, 5); !errors.As(err, &oe) || oe.Value != 0 || oe.Existing != (intervaltree.Interval[int8]{I: 0, J: 20}) {
+		t.Fatalf("Unexpected overlap error: %v", err)
+	}
+	var ne intervaltree.NotContainedError[int8]
+	if err := r.Remove(15, 25); !errors.As(err, &ne) || ne.Value != 21 {
+		t.Fatalf("Unexpected not contained error: %v", err)
+	}
+	if n, ok := r.Next(5); !ok || n != 21 {
+		t.Fatalf("Unexpected next value: %d %v", n, ok)
+	}
+	if _, ok := r.Next(125); ok {
+		t.Fatal("Next found a value past the greatest one")
+	}
+	if err := r.Remove(-128, -128); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Intervals(); len(got) != 3 || got[0] != (intervaltree.Interval[int8]{I: -127, J: -120}) {
+		t.Fatalf("Unexpected intervals: %v", got)
+	}
+}
+
+// lossySet drops every insertion of a single value.
+type lossySet struct {
+	intervaltree.Set[uint8]
+}
+
+func (s lossySet) Insert(x, y uint8) error {
+	if x == y {
+		return nil
+	}
+	return s.Set.Insert(x, y)
+}
+
+func TestCheckMismatch(t *testing.T) {
+	ops := []Op[uint8]{{Kind: Insert, X: 1, Y: 5}, {Kind: Insert, X: 7, Y: 7}, {Kind: Contains, X: 7}}
+	var me MismatchError[uint8]
+	if err := Check[uint8](lossySet{intervaltree.NewTree[uint8]()}, ops); !errors.As(err, &me) || me.Index != 1 {
+		t.Fatalf("Expected a mismatch at operation 1, got %v", err)
+	}
+	if err := Check[uint8](intervaltree.NewTree[uint8](), ops); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSpillTree(t *testing.T) {
+	s, err := intervaltree.NewSpill[int16](t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// Sparse points fill enough pages to spill some of them
+	var ops []Op[int16]
+	for x := int16(-3000); x < 3000; x += 4 {
+		ops = append(ops, Op[int16]{Kind: Insert, X: x, Y: x})
+	}
+	r := rand.New(rand.NewSource(1))
+	if err := Check(s, append(ops, RandomOps[int16](r, 1000, -3000, 3000)...)); err != nil {
+		t.Fatal(err)
+	}
+	if resident, pages := s.Resident(); pages <= resident {
+		t.Fatalf("No page spilled out of %d", pages)
+	}
+}
@@ -0,0 +1,224 @@
+// Package iptree provides an IntervalTree of IP addresses for IP address
+// management. IPv4 and IPv6 addresses can be held in the same tree, but an
+// interval never spans both families.
+package iptree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+// Tree represents an IntervalTree of IP addresses. As with the underlying
+// intervaltree.DiscreteTree, intervals cannot overlap and it is safe for
+// concurrent use.
+type Tree struct {
+	t *intervaltree.DiscreteTree[netip.Addr]
+}
+
+// ExhaustedError is returned whenever an AllocPrefix() call finds no free
+// prefix of the requested length in the parent prefix.
+type ExhaustedError struct {
+	Parent netip.Prefix
+	Bits   int
+}
+
+func (e ExhaustedError) Error() string {
+	return fmt.Sprintf("No free /%d prefix in %v", e.Bits, e.Parent)
+}
+
+// New returns a pointer to an empty Tree.
+func New() *Tree {
+	return &Tree{intervaltree.NewDiscrete(netip.Addr.Compare, succ, pred)}
+}
+
+// succ returns the address following a, if any.
+func succ(a netip.Addr) (netip.Addr, bool) {
+	n := a.Next()
+	return n, n.IsValid()
+}
+
+// pred returns the address preceding a, if any.
+func pred(a netip.Addr) (netip.Addr, bool) {
+	p := a.Prev()
+	return p, p.IsValid()
+}
+
+// checkRange checks that [from, to] is a valid range of addresses of a single
+// family, and returns it without zones.
+func checkRange(from, to netip.Addr) (netip.Addr, netip.Addr, error) {
+	from, to = from.WithZone(""), to.WithZone("")
+	if !from.IsValid() || !to.IsValid() || from.Is4() != to.Is4() || from.Compare(to) > 0 {
+		return from, to, intervaltree.InvalidIntervalError[netip.Addr]{X: from, Y: to}
+	}
+	return from, to, nil
+}
+
+// InsertRange adds the addresses in [from, to] to the tree. The range cannot
+// overlap with the tree.
+func (t *Tree) InsertRange(from, to netip.Addr) error {
+	from, to, err := checkRange(from, to)
+	if err != nil {
+		return err
+	}
+	return t.t.Insert(from, to)
+}
+
+// InsertPrefix adds the addresses in p to the tree. The prefix cannot overlap
+// with the tree.
+func (t *Tree) InsertPrefix(p netip.Prefix) error {
+	from, to := bounds(p)
+	return t.InsertRange(from, to)
+}
+
+// RemoveRange deletes the addresses in [from, to] from the tree. Every address
+// in the range must be contained in the tree.
+func (t *Tree) RemoveRange(from, to netip.Addr) error {
+	from, to, err := checkRange(from, to)
+	if err != nil {
+		return err
+	}
+	return t.t.Remove(from, to)
+}
+
+// RemovePrefix deletes the addresses in p from the tree. Every address in the
+// prefix must be contained in the tree.
+func (t *Tree) RemovePrefix(p netip.Prefix) error {
+	from, to := bounds(p)
+	return t.RemoveRange(from, to)
+}
+
+// Contains checks if the address a is contained in the tree.
+func (t *Tree) Contains(a netip.Addr) bool {
+	return t.t.Contains(a.WithZone(""))
+}
+
+// NextFree returns the least address of the family of a not contained in the
+// tree that is greater or equal to a. It returns false if there is none.
+func (t *Tree) NextFree(a netip.Addr) (netip.Addr, bool) {
+	if !a.IsValid() {
+		return a, false
+	}
+	return t.t.Next(a.WithZone(""))
+}
+
+// AllocPrefix finds the least prefix of length bits in parent that holds no
+// address contained in the tree, adds it to the tree and returns it.
+func (t *Tree) AllocPrefix(parent netip.Prefix, bits int) (netip.Prefix, error) {
+	parent = parent.Masked()
+	if !parent.IsValid() || bits < parent.Bits() || bits > parent.Addr().BitLen() {
+		return netip.Prefix{}, ExhaustedError{parent, bits}
+	}
+
+	for {
+		p, ok := t.findFree(parent, bits)
+		if !ok {
+			return netip.Prefix{}, ExhaustedError{parent, bits}
+		}
+
+		err := t.InsertPrefix(p)
+		if _, taken := err.(intervaltree.OverlapError[netip.Addr]); !taken {
+			return p, err
+		}
+		// Taken concurrently since it was found, look again
+	}
+}
+
+// findFree returns the least prefix of length bits in parent that holds no
+// address contained in the tree.
+func (t *Tree) findFree(parent netip.Prefix, bits int) (netip.Prefix, bool) {
+	first, last := bounds(parent)
+	host := parent.Addr().BitLen() - bits
+
+	cand := toUint128(first)
+	for _, i := range t.t.Intervals(first, last) {
+		if cand.or(hostMask(host)).less(toUint128(i.I)) { // Fits before i
+			break
+		}
+
+		next, ok := succ(i.J)
+		if !ok || next.Compare(last) > 0 {
+			return netip.Prefix{}, false
+		}
+		if cand, ok = alignUp(toUint128(next), host); !ok {
+			return netip.Prefix{}, false
+		}
+	}
+
+	a := cand.addr(parent.Addr().Is4())
+	if !a.IsValid() || a.Compare(last) > 0 {
+		return netip.Prefix{}, false
+	}
+	return netip.PrefixFrom(a, bits), true
+}
+
+// bounds returns the first and the last addresses in p.
+func bounds(p netip.Prefix) (netip.Addr, netip.Addr) {
+	p = p.Masked()
+	host := p.Addr().BitLen() - p.Bits()
+	return p.Addr(), toUint128(p.Addr()).or(hostMask(host)).addr(p.Addr().Is4())
+}
+
+// uint128 holds the 128 bits of an IPv6 address, or of an IPv4-mapped IPv6
+// address.
+type uint128 struct {
+	hi, lo uint64
+}
+
+// toUint128 returns the bits of a.
+func toUint128(a netip.Addr) uint128 {
+	b := a.As16()
+	return uint128{binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])}
+}
+
+// addr returns the address with bits u, unmapped to IPv4 if is4 is set. It
+// returns an invalid address if u is not an IPv4-mapped address but is4 is.
+func (u uint128) addr(is4 bool) netip.Addr {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], u.hi)
+	binary.BigEndian.PutUint64(b[8:], u.lo)
+	a := netip.AddrFrom16(b)
+	if is4 {
+		if !a.Is4In6() {
+			return netip.Addr{}
+		}
+		return a.Unmap()
+	}
+	return a
+}
+
+// hostMask returns the mask of the lowest host bits.
+func hostMask(host int) uint128 {
+	switch {
+	case host >= 128:
+		return uint128{^uint64(0), ^uint64(0)}
+	case host >= 64:
+		return uint128{1<<(host-64) - 1, ^uint64(0)}
+	}
+	return uint128{0, 1<<host - 1}
+}
+
+func (u uint128) or(v uint128) uint128 {
+	return uint128{u.hi | v.hi, u.lo | v.lo}
+}
+
+func (u uint128) less(v uint128) bool {
+	return u.hi < v.hi || u.hi == v.hi && u.lo < v.lo
+}
+
+// alignUp returns the least multiple of 2^host not lesser than u, and false if
+// it overflows.
+func alignUp(u uint128, host int) (uint128, bool) {
+	m := hostMask(host)
+	if u.hi&m.hi == 0 && u.lo&m.lo == 0 {
+		return u, true
+	}
+
+	u = u.or(m)
+	if u.lo++; u.lo == 0 {
+		u.hi++
+	}
+	return u, u.hi != 0 || u.lo != 0
+}
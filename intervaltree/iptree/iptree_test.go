@@ -0,0 +1,83 @@
+package iptree
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestRangesAndPrefixes(t *testing.T) {
+	it := New()
+	if err := it.InsertPrefix(netip.MustParsePrefix("10.0.0.0/24")); err != nil {
+		t.Fatalf("Failed to insert prefix: %v", err)
+	}
+	if err := it.InsertRange(netip.MustParseAddr("10.0.1.0"), netip.MustParseAddr("10.0.1.9")); err != nil {
+		t.Fatalf("Failed to insert range: %v", err)
+	}
+	if err := it.InsertRange(netip.MustParseAddr("10.0.0.5"), netip.MustParseAddr("10.0.0.7")); err == nil {
+		t.Fatal("Inserted overlapping range")
+	}
+	if err := it.InsertRange(netip.MustParseAddr("10.0.0.5"), netip.MustParseAddr("::1")); err == nil {
+		t.Fatal("Inserted range spanning both families")
+	}
+
+	if !it.Contains(netip.MustParseAddr("10.0.0.255")) || it.Contains(netip.MustParseAddr("10.0.1.10")) {
+		t.Fatal("Unexpected membership")
+	}
+	if a, ok := it.NextFree(netip.MustParseAddr("10.0.0.3")); !ok || a != netip.MustParseAddr("10.0.1.10") {
+		t.Fatalf("Adjacent ranges were not joined, NextFree = %v", a)
+	}
+
+	if err := it.RemovePrefix(netip.MustParsePrefix("10.0.0.128/25")); err != nil {
+		t.Fatalf("Failed to remove prefix: %v", err)
+	}
+	if it.Contains(netip.MustParseAddr("10.0.0.200")) || !it.Contains(netip.MustParseAddr("10.0.1.0")) {
+		t.Fatal("Unexpected membership after removal")
+	}
+
+	it.InsertPrefix(netip.MustParsePrefix("2001:db8::/64"))
+	if a, ok := it.NextFree(netip.MustParseAddr("2001:db8::1")); !ok || a != netip.MustParseAddr("2001:db8:0:1::") {
+		t.Fatalf("Unexpected IPv6 NextFree = %v", a)
+	}
+
+	it.InsertRange(netip.MustParseAddr("255.255.255.0"), netip.MustParseAddr("255.255.255.255"))
+	if _, ok := it.NextFree(netip.MustParseAddr("255.255.255.1")); ok {
+		t.Fatal("NextFree crossed from IPv4 to IPv6")
+	}
+}
+
+func TestAllocPrefix(t *testing.T) {
+	it := New()
+	parent := netip.MustParsePrefix("192.168.0.0/16")
+	it.InsertRange(netip.MustParseAddr("192.168.0.0"), netip.MustParseAddr("192.168.0.10"))
+	it.InsertPrefix(netip.MustParsePrefix("192.168.2.0/24"))
+
+	for _, expected := range []string{"192.168.1.0/24", "192.168.3.0/24", "192.168.4.0/24"} {
+		p, err := it.AllocPrefix(parent, 24)
+		if err != nil || p != netip.MustParsePrefix(expected) {
+			t.Fatalf("Unexpected allocation: %v, %v, expected %s", p, err, expected)
+		}
+	}
+	if p, err := it.AllocPrefix(parent, 28); err != nil || p != netip.MustParsePrefix("192.168.0.16/28") {
+		t.Fatalf("Unexpected /28 allocation: %v, %v", p, err)
+	}
+
+	small := netip.MustParsePrefix("10.0.0.0/30")
+	for i := 0; i < 4; i++ {
+		if _, err := it.AllocPrefix(small, 32); err != nil {
+			t.Fatalf("Failed to allocate /32 number %d: %v", i, err)
+		}
+	}
+	if _, err := it.AllocPrefix(small, 32); err == nil {
+		t.Fatal("Allocated from exhausted prefix")
+	}
+	if _, err := it.AllocPrefix(small, 24); err == nil {
+		t.Fatal("Allocated prefix larger than its parent")
+	}
+
+	if p, err := it.AllocPrefix(netip.MustParsePrefix("::/0"), 0); err != nil || p != netip.MustParsePrefix("::/0") {
+		t.Fatalf("Failed to allocate the whole IPv6 space: %v, %v", p, err)
+	}
+	if _, err := it.AllocPrefix(netip.MustParsePrefix("2001:db8::/32"), 48); err == nil {
+		t.Fatal("Allocated from exhausted IPv6 space")
+	}
+}
@@ -0,0 +1,72 @@
+// Package portalloc provides an allocator of 16-bit ports, such as TCP and UDP
+// ports, built on an intervaltree.Allocator. Ranges can be excluded from
+// allocation, typically from configuration, and are never handed out nor
+// released.
+package portalloc
+
+import (
+	"fmt"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+// ExcludedError is returned whenever a port in an excluded range is released.
+type ExcludedError uint16
+
+func (e ExcludedError) Error() string {
+	return fmt.Sprintf("Port %d is excluded from allocation", uint16(e))
+}
+
+// Allocator hands out ports from a range. It is safe for concurrent use.
+type Allocator struct {
+	a        *intervaltree.Allocator[uint16]
+	excluded *intervaltree.Tree[uint16]
+}
+
+// New returns a pointer to an Allocator of the ports in [lo, hi], none of
+// which is excluded.
+func New(lo, hi uint16) (*Allocator, error) {
+	a, err := intervaltree.NewAllocator[uint16](lo, hi)
+	if err != nil {
+		return nil, err
+	}
+	return &Allocator{a: a, excluded: intervaltree.NewTree[uint16]()}, nil
+}
+
+// Exclude excludes the ports in [from, to] from allocation. They cannot be
+// reserved at the time.
+func (p *Allocator) Exclude(from, to uint16) error {
+	if err := p.a.Reserve(from, to); err != nil {
+		return err
+	}
+	return p.excluded.Insert(from, to)
+}
+
+// Reserve reserves the least free port and returns it.
+func (p *Allocator) Reserve() (uint16, error) {
+	return p.a.Alloc()
+}
+
+// ReserveRange reserves the least n consecutive free ports, as needed for
+// instance by RTP and RTCP pairs, and returns the first of them.
+func (p *Allocator) ReserveRange(n uint16) (uint16, error) {
+	return p.a.AllocN(uint64(n))
+}
+
+// ReservePort reserves port, which must be free.
+func (p *Allocator) ReservePort(port uint16) error {
+	return p.a.Reserve(port, port)
+}
+
+// Release releases the reserved port.
+func (p *Allocator) Release(port uint16) error {
+	if p.excluded.Contains(port) {
+		return ExcludedError(port)
+	}
+	return p.a.Free(port)
+}
+
+// Reserved checks if port is reserved or excluded.
+func (p *Allocator) Reserved(port uint16) bool {
+	return p.a.Allocated(port)
+}
@@ -0,0 +1,52 @@
+package portalloc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAllocator(t *testing.T) {
+	p, err := New(1024, 1100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Exclude(1024, 1029); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Exclude(1050, 1059); err != nil {
+		t.Fatal(err)
+	}
+
+	if port, err := p.Reserve(); err != nil || port != 1030 {
+		t.Fatalf("Unexpected port: %d, %v", port, err)
+	}
+	if err := p.ReservePort(1031); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.ReservePort(1055); err == nil {
+		t.Fatal("Reserved excluded port")
+	}
+	if err := p.ReservePort(1101); err == nil {
+		t.Fatal("Reserved port out of range")
+	}
+	if port, err := p.ReserveRange(18); err != nil || port != 1032 {
+		t.Fatalf("Unexpected range: %d, %v", port, err)
+	}
+	if port, err := p.ReserveRange(2); err != nil || port != 1060 {
+		t.Fatalf("Unexpected range: %d, %v", port, err)
+	}
+
+	var excluded ExcludedError
+	if err := p.Release(1025); !errors.As(err, &excluded) || excluded != 1025 {
+		t.Fatalf("Unexpected error releasing excluded port: %v", err)
+	}
+	if err := p.Release(1030); err != nil || p.Reserved(1030) {
+		t.Fatalf("Failed to release port: %v", err)
+	}
+	if err := p.Release(1030); err == nil {
+		t.Fatal("Released port twice")
+	}
+	if err := p.Exclude(1031, 1031); err == nil {
+		t.Fatal("Excluded reserved port")
+	}
+}
@@ -0,0 +1,87 @@
+// Package promtree exports the figures of an intervaltree.Tree in the
+// Prometheus text exposition format, for services that expose a tree as an
+// allocator. It writes the format directly rather than depending on the
+// Prometheus client library, so any Prometheus-compatible scraper can read it.
+package promtree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+// Collector exports the size, height and covered values of a tree, along with
+// its operation counters and latencies. Counters are only maintained by trees
+// created with intervaltree.WithMetrics.
+type Collector[T intervaltree.Integer] struct {
+	namespace string
+	tree      *intervaltree.Tree[T]
+}
+
+// New returns a pointer to a Collector exporting t, prefixing every metric name
+// with namespace and an underscore.
+func New[T intervaltree.Integer](namespace string, t *intervaltree.Tree[T]) *Collector[T] {
+	return &Collector[T]{namespace: namespace, tree: t}
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format.
+func (c *Collector[T]) WriteTo(w io.Writer) (int64, error) {
+	s, m := c.tree.Stats(), c.tree.Metrics()
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	c.metric(cw, "intervals", "gauge", "Number of intervals in the tree.", float64(s.Intervals))
+	c.metric(cw, "height", "gauge", "Nodes on the longest path from the root to a leaf.", float64(s.Height))
+	c.metric(cw, "covered", "gauge", "Number of values contained in the tree.", float64(s.Covered))
+	c.metric(cw, "inserts_total", "counter", "Successful insertions.", float64(m.Inserts))
+	c.metric(cw, "removes_total", "counter", "Successful removals.", float64(m.Removes))
+	c.metric(cw, "insert_errors_total", "counter", "Failed insertions.", float64(m.InsertErrors))
+	c.metric(cw, "remove_errors_total", "counter", "Failed removals.", float64(m.RemoveErrors))
+	c.metric(cw, "lookups_total", "counter", "Calls to Contains and Next.", float64(m.Lookups))
+	c.summary(cw, "insert_seconds", "Time spent in Insert.", m.InsertTime.Seconds(), m.Inserts+m.InsertErrors)
+	c.summary(cw, "remove_seconds", "Time spent in Remove.", m.RemoveTime.Seconds(), m.Removes+m.RemoveErrors)
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.Flush()
+}
+
+// ServeHTTP writes the metrics as the response, so the Collector can be
+// mounted as a scrape endpoint.
+func (c *Collector[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// metric writes a single-sample metric of type typ.
+func (c *Collector[T]) metric(w io.Writer, name, typ, help string, v float64) {
+	name = c.namespace + "_" + name
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", name, help, name, typ, name, v)
+}
+
+// summary writes a summary metric without quantiles.
+func (c *Collector[T]) summary(w io.Writer, name, help string, sum float64, count uint64) {
+	name = c.namespace + "_" + name
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s summary\n%s_sum %g\n%s_count %d\n", name, help, name, name, sum, name, count)
+}
+
+// countingWriter counts the bytes written through it and keeps the first
+// error, after which writes are dropped.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
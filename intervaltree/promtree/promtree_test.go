@@ -0,0 +1,38 @@
+package promtree
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+func TestCollector(t *testing.T) {
+	it := intervaltree.New(intervaltree.WithMetrics())
+	it.Insert(1, 5)
+	it.Insert(10, 19)
+	it.Insert(3, 4)
+	it.Remove(1, 1)
+
+	rec := httptest.NewRecorder()
+	New("alloc", it).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, line := range []string{
+		"# TYPE alloc_intervals gauge",
+		"alloc_intervals 2\n",
+		"alloc_covered 14\n",
+		"alloc_height 2\n",
+		"alloc_inserts_total 2\n",
+		"alloc_insert_errors_total 1\n",
+		"alloc_removes_total 1\n",
+		"# TYPE alloc_insert_seconds summary",
+		"alloc_insert_seconds_count 3\n",
+		"alloc_remove_seconds_count 1\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Fatalf("Missing %q in output:\n%s", line, body)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Package sqltree mirrors an intervaltree.Tree into a SQLite table with a row
+// ("start", "end") per interval, so operators can inspect and repair the state
+// of an allocator with plain SQL. The tree is rebuilt from the table on
+// startup, and the ranges changed since the last sync are rewritten in a
+// single transaction.
+//
+// The package works on a *sql.DB and does not depend on any SQLite driver,
+// which the application registers. SQLite stores integers as int64, so values
+// of unsigned 64-bit trees must stay below 2^63.
+package sqltree
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"sync"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+// identifier matches the table names accepted by Open, which are interpolated
+// into statements.
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Store is a tree mirrored into a table. Mutations must go through the Store,
+// which records the ranges they change, so Sync only rewrites those. The tree
+// can be read directly.
+type Store[T intervaltree.Integer] struct {
+	db    *sql.DB
+	table string
+	tree  *intervaltree.Tree[T]
+	dirty *intervaltree.Tree[T] // Values whose rows may have changed
+	mu    sync.Mutex
+}
+
+// Open creates the table if it does not exist and returns a pointer to a
+// Store of a tree created with opts holding its rows. It fails if they
+// overlap, are adjacent or hold values out of the range of T.
+func Open[T intervaltree.Integer](ctx context.Context, db *sql.DB, table string, opts ...intervaltree.Option) (*Store[T], error) {
+	if !identifier.MatchString(table) {
+		return nil, fmt.Errorf("Invalid table name %q", table)
+	}
+	create := `CREATE TABLE IF NOT EXISTS ` + table + ` ("start" INTEGER PRIMARY KEY, "end" INTEGER NOT NULL)`
+	if _, err := db.ExecContext(ctx, create); err != nil {
+		return nil, err
+	}
+
+	s := &Store[T]{db: db, table: table, dirty: intervaltree.NewTree[T](intervaltree.WithOverlapMode(intervaltree.OverlapUnion))}
+	intervals, err := s.rows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.tree, err = intervaltree.NewFromSorted(intervals, opts...); err != nil {
+		return nil, fmt.Errorf("Cannot load table %s: %w", table, err)
+	}
+	return s, nil
+}
+
+// rows returns the intervals of the rows of the table in ascending order.
+func (s *Store[T]) rows(ctx context.Context) ([]intervaltree.Interval[T], error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT "start", "end" FROM `+s.table+` ORDER BY "start"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var intervals []intervaltree.Interval[T]
+	for rows.Next() {
+		var x, y int64
+		if err := rows.Scan(&x, &y); err != nil {
+			return nil, err
+		}
+		i := intervaltree.Interval[T]{I: T(x), J: T(y)}
+		if !fits(x, i.I) || !fits(y, i.J) {
+			return nil, fmt.Errorf("Row [%d, %d] of table %s out of range", x, y, s.table)
+		}
+		intervals = append(intervals, i)
+	}
+	return intervals, rows.Err()
+}
+
+// fits reports whether the value v read from a row is represented by x.
+func fits[T intervaltree.Integer](v int64, x T) bool {
+	return int64(x) == v && (v < 0) == (x < 0)
+}
+
+// Tree returns the tree of the store, for reading it. It must not be mutated
+// other than through the store.
+func (s *Store[T]) Tree() *intervaltree.Tree[T] {
+	return s.tree
+}
+
+// representable checks that the values in [x, y] fit in a SQLite integer,
+// which those of unsigned 64-bit trees from 2^63 on do not.
+func representable[T intervaltree.Integer](x, y T) error {
+	for _, v := range [...]T{x, y} {
+		if v > 0 && uint64(v) > math.MaxInt64 {
+			return fmt.Errorf("Value %d does not fit in a SQLite integer", v)
+		}
+	}
+	return nil
+}
+
+// Insert adds [x, y] to the tree, as Tree.Insert does, and marks the range as
+// changed. Values of unsigned 64-bit trees must be below 2^63.
+func (s *Store[T]) Insert(x, y T) error {
+	if err := representable(x, y); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.tree.Insert(x, y); err != nil {
+		return err
+	}
+	s.touch(x, y)
+	return nil
+}
+
+// Remove deletes [x, y] from the tree, as Tree.Remove does, and marks the range
+// as changed. Values of unsigned 64-bit trees must be below 2^63.
+func (s *Store[T]) Remove(x, y T) error {
+	if err := representable(x, y); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.tree.Remove(x, y); err != nil {
+		return err
+	}
+	s.touch(x, y)
+	return nil
+}
+
+// touch marks [x, y] as changed, along with the values around it, as the
+// intervals holding them may have been joined with or split from it.
+func (s *Store[T]) touch(x, y T) {
+	if x-1 < x {
+		x--
+	}
+	if y+1 > y {
+		y++
+	}
+	s.dirty.Insert(x, y)
+}
+
+// Sync rewrites the rows of the ranges changed since the last sync in a single
+// transaction, so the table mirrors the tree. If it fails, the transaction is
+// rolled back and the ranges are rewritten by the next sync.
+func (s *Store[T]) Sync(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	s.dirty.Walk(func(lo, hi T) bool {
+		err = s.syncRange(ctx, tx, lo, hi)
+		return err == nil
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.dirty = intervaltree.NewTree[T](intervaltree.WithOverlapMode(intervaltree.OverlapUnion))
+	return nil
+}
+
+// syncRange replaces the rows holding values in [lo, hi] with the intervals of
+// the tree holding them. It fails rather than writing intervals whose values do
+// not fit in a SQLite integer, which only a tree mutated directly can hold.
+func (s *Store[T]) syncRange(ctx context.Context, tx *sql.Tx, lo, hi T) error {
+	del := `DELETE FROM ` + s.table + ` WHERE "end" >= ? AND "start" <= ?`
+	if _, err := tx.ExecContext(ctx, del, clamp(lo), clamp(hi)); err != nil {
+		return err
+	}
+
+	ins := `INSERT INTO ` + s.table + ` ("start", "end") VALUES (?, ?)`
+	for _, i := range s.tree.Overlapping(lo, hi, -1) {
+		if err := representable(i.I, i.J); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, ins, int64(i.I), int64(i.J)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clamp returns x as an int64, or the greatest int64 if it does not fit, as
+// happens with the bounds of changed ranges next to 2^63.
+func clamp[T intervaltree.Integer](x T) int64 {
+	if x > 0 && uint64(x) > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(x)
+}
+
+// Check verifies that the rows of the table mirror the tree, which holds as
+// long as every change was synced, returning an error describing the first
+// difference found.
+func (s *Store[T]) Check(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows, err := s.rows(ctx)
+	if err != nil {
+		return err
+	}
+	s.tree.Walk(func(x, y T) bool {
+		switch {
+		case len(rows) == 0:
+			err = fmt.Errorf("Interval [%d, %d] missing from table %s", x, y, s.table)
+		case rows[0] != intervaltree.Interval[T]{I: x, J: y}:
+			err = fmt.Errorf("Table %s holds [%d, %d] instead of [%d, %d]", s.table, rows[0].I, rows[0].J, x, y)
+		default:
+			rows = rows[1:]
+		}
+		return err == nil
+	})
+	if err == nil && len(rows) > 0 {
+		err = fmt.Errorf("Table %s holds [%d, %d] not in the tree", s.table, rows[0].I, rows[0].J)
+	}
+	return err
+}
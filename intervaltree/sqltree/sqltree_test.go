@@ -0,0 +1,267 @@
+package sqltree
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"maps"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDriver serves the few statements issued by a Store from an in-memory
+// table mapping the start of each row to its end, standing in for SQLite.
+type fakeDriver struct {
+	mu         sync.Mutex
+	rows       map[int64]int64
+	failInsert bool // Whether inserting rows fails
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{d: d}, nil
+}
+
+// fakeConn is a connection to a fakeDriver. A transaction works on a copy of
+// the table, installed when it commits.
+type fakeConn struct {
+	d  *fakeDriver
+	tx map[int64]int64
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.tx = maps.Clone(c.d.rows)
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.rows, c.tx = c.tx, nil
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.tx = nil
+	return nil
+}
+
+// table returns the rows seen by the connection.
+func (c *fakeConn) table() map[int64]int64 {
+	if c.tx != nil {
+		return c.tx
+	}
+	return c.d.rows
+}
+
+// fakeStmt is a statement of a fakeConn, told apart by its first word.
+type fakeStmt struct {
+	c     *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return strings.Count(s.query, "?") }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.d.mu.Lock()
+	defer s.c.d.mu.Unlock()
+	rows := s.c.table()
+	switch {
+	case strings.HasPrefix(s.query, "CREATE"):
+	case strings.HasPrefix(s.query, "DELETE"):
+		lo, hi := args[0].(int64), args[1].(int64)
+		for x, y := range rows {
+			if y >= lo && x <= hi {
+				delete(rows, x)
+			}
+		}
+	case strings.HasPrefix(s.query, "INSERT"):
+		if s.c.d.failInsert {
+			return nil, errors.New("disk full")
+		}
+		rows[args[0].(int64)] = args[1].(int64)
+	default:
+		return nil, errors.New("unexpected statement " + s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.c.d.mu.Lock()
+	defer s.c.d.mu.Unlock()
+	rows := s.c.table()
+	starts := slices.Sorted(maps.Keys(rows))
+	r := &fakeRows{}
+	for _, x := range starts {
+		r.rows = append(r.rows, [2]int64{x, rows[x]})
+	}
+	return r, nil
+}
+
+// fakeRows are the rows returned by a query, in ascending order.
+type fakeRows struct {
+	rows [][2]int64
+}
+
+func (r *fakeRows) Columns() []string { return []string{"start", "end"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	dest[0], dest[1] = r.rows[0][0], r.rows[0][1]
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var fake = &fakeDriver{rows: map[int64]int64{}}
+
+func init() {
+	sql.Register("sqltreefake", fake)
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	db, _ := sql.Open("sqltreefake", "")
+	defer db.Close()
+	fake.rows = map[int64]int64{}
+
+	if _, err := Open[int32](ctx, db, "bad name;"); err == nil {
+		t.Fatal("Opened table with invalid name")
+	}
+
+	s, err := Open[int32](ctx, db, "allocations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 3000; i++ {
+		x := int32(r.Intn(2000) - 1000)
+		y := x + int32(r.Intn(10))
+		if r.Intn(3) == 0 && s.Tree().Contains(x) {
+			if n, ok := s.Tree().Next(x); ok {
+				y = min(y, n-1)
+			}
+			if err := s.Remove(x, y); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			s.Insert(x, y)
+		}
+
+		if i%100 == 0 {
+			if err := s.Sync(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Check(ctx); err != nil {
+				t.Fatalf("Operation %d: %v", i, err)
+			}
+		}
+	}
+	s.Sync(ctx)
+
+	reopened, err := Open[int32](ctx, db, "allocations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if s.Tree().Len() != reopened.Tree().Len() {
+		t.Fatal("Reopened tree differs from the synced one")
+	}
+
+	// A failed sync leaves the table untouched and is retried by the next one
+	s.Insert(5000, 5000)
+	fake.failInsert = true
+	if err := s.Sync(ctx); err == nil {
+		t.Fatal("Sync succeeded despite failing inserts")
+	}
+	fake.failInsert = false
+	if err := s.Check(ctx); err == nil {
+		t.Fatal("Failed sync changed the table")
+	}
+	if err := s.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStoreRepair(t *testing.T) {
+	ctx := context.Background()
+	db, _ := sql.Open("sqltreefake", "")
+	defer db.Close()
+
+	// Rows edited by hand must still make a valid set
+	fake.rows = map[int64]int64{0: 10, 11: 20}
+	if _, err := Open[uint8](ctx, db, "allocations"); err == nil {
+		t.Fatal("Loaded adjacent rows")
+	}
+	fake.rows = map[int64]int64{0: 300}
+	if _, err := Open[uint8](ctx, db, "allocations"); err == nil {
+		t.Fatal("Loaded row out of the range of the type")
+	}
+	fake.rows = map[int64]int64{-5: 3}
+	if _, err := Open[uint64](ctx, db, "allocations"); err == nil {
+		t.Fatal("Loaded negative row into an unsigned tree")
+	}
+
+	fake.rows = map[int64]int64{-5: 3}
+	s, err := Open[int8](ctx, db, "allocations")
+	if err != nil || !s.Tree().Contains(-5) {
+		t.Fatalf("Failed to load table: %v", err)
+	}
+	fake.rows[10] = 12
+	if err := s.Check(ctx); err == nil {
+		t.Fatal("Check missed a row not in the tree")
+	}
+
+	fake.rows = map[int64]int64{}
+	u, err := Open[uint64](ctx, db, "allocations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Insert(1<<63-1, 1<<63); err == nil {
+		t.Fatal("Inserted value not fitting in a SQLite integer")
+	}
+	if err := u.Insert(1<<63-2, 1<<63-1); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Remove(1<<63, 1<<63+1); err == nil || strings.Contains(err.Error(), "contained") {
+		t.Fatalf("Unexpected error removing value not fitting in a SQLite integer: %v", err)
+	}
+
+	// A tree mutated directly cannot be synced into negative rows
+	u.Tree().Insert(1<<63, 1<<63+5)
+	if err := u.Remove(1<<63-2, 1<<63-2); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Sync(ctx); err == nil {
+		t.Fatal("Synced value not fitting in a SQLite integer")
+	}
+	if len(fake.rows) != 1 || fake.rows[1<<63-2] != 1<<63-1 {
+		t.Fatalf("Failed sync changed the table: %v", fake.rows)
+	}
+}
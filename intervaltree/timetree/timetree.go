@@ -0,0 +1,144 @@
+// Package timetree provides an IntervalTree of time.Time values, for booking
+// and maintenance-window systems. Intervals are half-open, [start, end), so
+// back-to-back intervals are adjacent and get joined. Times are stored with
+// nanosecond precision and must lie between the years 1678 and 2262.
+package timetree
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+// Tree represents an IntervalTree of time.Time values. As with the underlying
+// intervaltree.Tree, intervals cannot overlap and it is safe for concurrent
+// use.
+type Tree struct {
+	t *intervaltree.Int64Tree
+}
+
+// Slot represents the half-open time interval [Start, End).
+type Slot struct {
+	Start, End time.Time
+}
+
+// OutOfRangeError is returned whenever a time that cannot be stored with
+// nanosecond precision is used.
+type OutOfRangeError struct {
+	Time time.Time
+}
+
+func (e OutOfRangeError) Error() string {
+	return fmt.Sprintf("Time out of range: %v", e.Time)
+}
+
+var (
+	minTime = time.Unix(0, math.MinInt64)
+	maxTime = time.Unix(0, math.MaxInt64)
+)
+
+// New returns a pointer to an empty Tree.
+func New() *Tree {
+	return &Tree{intervaltree.NewInt64()}
+}
+
+// nanos returns x as nanoseconds since the Unix epoch.
+func nanos(x time.Time) (int64, error) {
+	if x.Before(minTime) || x.After(maxTime) {
+		return 0, OutOfRangeError{x}
+	}
+	return x.UnixNano(), nil
+}
+
+// span returns the nanoseconds bounding [start, end) as a closed interval.
+func span(start, end time.Time) (int64, int64, error) {
+	if !start.Before(end) {
+		return 0, 0, intervaltree.InvalidIntervalError[time.Time]{X: start, Y: end}
+	}
+
+	x, err := nanos(start)
+	if err != nil {
+		return 0, 0, err
+	}
+	y, err := nanos(end)
+	if err != nil {
+		return 0, 0, err
+	}
+	return x, y - 1, nil
+}
+
+// halfOpen returns the times bounding the nanoseconds in i as a half-open
+// interval.
+func halfOpen(i intervaltree.Interval[int64]) intervaltree.Interval[time.Time] {
+	return intervaltree.Interval[time.Time]{I: time.Unix(0, i.I), J: time.Unix(0, i.J+1)}
+}
+
+// convertError translates errors about nanoseconds into errors about times.
+// Intervals in OverlapErrors are half-open, like those of the tree.
+func convertError(err error) error {
+	switch e := err.(type) {
+	case intervaltree.OverlapError[int64]:
+		return intervaltree.OverlapError[time.Time]{
+			Value:     time.Unix(0, e.Value),
+			Attempted: halfOpen(e.Attempted),
+			Existing:  halfOpen(e.Existing),
+		}
+	case intervaltree.NotContainedError[int64]:
+		return intervaltree.NotContainedError[time.Time]{Value: time.Unix(0, e.Value)}
+	}
+	return err
+}
+
+// Insert adds [start, end) to the tree. The interval cannot overlap with the
+// tree.
+func (t *Tree) Insert(start, end time.Time) error {
+	x, y, err := span(start, end)
+	if err != nil {
+		return err
+	}
+	return convertError(t.t.Insert(x, y))
+}
+
+// Remove deletes [start, end) from the tree. Every instant in [start, end)
+// must be contained in the tree.
+func (t *Tree) Remove(start, end time.Time) error {
+	x, y, err := span(start, end)
+	if err != nil {
+		return err
+	}
+	return convertError(t.t.Remove(x, y))
+}
+
+// Contains checks if the instant x is contained in the tree.
+func (t *Tree) Contains(x time.Time) bool {
+	n, err := nanos(x)
+	return err == nil && t.t.Contains(n)
+}
+
+// Next returns the earliest instant not contained in the tree that is not
+// before x.
+func (t *Tree) Next(x time.Time) (time.Time, error) {
+	n, err := nanos(x)
+	if err != nil {
+		return x, err
+	}
+	next, _ := t.t.Next(n) // Intervals end before maxTime, so one is free
+	return time.Unix(0, next), nil
+}
+
+// FreeSlots returns the maximal slots within [from, to) that hold no instant
+// contained in the tree, in ascending order.
+func (t *Tree) FreeSlots(from, to time.Time) ([]Slot, error) {
+	x, y, err := span(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var slots []Slot
+	for _, g := range t.t.Gaps(x, y) {
+		slots = append(slots, Slot{time.Unix(0, g.I), time.Unix(0, g.J+1)})
+	}
+	return slots, nil
+}
@@ -0,0 +1,74 @@
+package timetree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alkemir/intervaltree/intervaltree"
+)
+
+var base = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func at(hour int) time.Time {
+	return base.Add(time.Duration(hour) * time.Hour)
+}
+
+func TestTree(t *testing.T) {
+	tt := New()
+	if err := tt.Insert(at(9), at(10)); err != nil {
+		t.Fatalf("Failed to insert [9, 10): %v", err)
+	}
+	if err := tt.Insert(at(10), at(12)); err != nil {
+		t.Fatalf("Failed to insert back-to-back [10, 12): %v", err)
+	}
+	if err := tt.Insert(at(14), at(15)); err != nil {
+		t.Fatalf("Failed to insert [14, 15): %v", err)
+	}
+
+	if !tt.Contains(at(9)) || !tt.Contains(at(12).Add(-time.Nanosecond)) || tt.Contains(at(12)) {
+		t.Fatal("Unexpected membership at interval bounds")
+	}
+	if n, err := tt.Next(at(9)); err != nil || !n.Equal(at(12)) {
+		t.Fatalf("Back-to-back intervals were not joined, Next = %v, %v", n, err)
+	}
+
+	err := tt.Insert(at(11), at(13))
+	e, ok := err.(intervaltree.OverlapError[time.Time])
+	if !ok || !e.Value.Equal(at(11)) || !e.Attempted.J.Equal(at(13)) || !e.Existing.I.Equal(at(9)) || !e.Existing.J.Equal(at(12)) {
+		t.Fatalf("Unexpected error inserting overlapping interval: %v", err)
+	}
+	if err := tt.Insert(at(13), at(13)); err == nil {
+		t.Fatal("Inserted empty interval")
+	}
+
+	slots, err := tt.FreeSlots(at(8), at(16))
+	if err != nil {
+		t.Fatalf("FreeSlots failed: %v", err)
+	}
+	expected := []Slot{{at(8), at(9)}, {at(12), at(14)}, {at(15), at(16)}}
+	if len(slots) != len(expected) {
+		t.Fatalf("Unexpected free slots: %v", slots)
+	}
+	for i := range slots {
+		if !slots[i].Start.Equal(expected[i].Start) || !slots[i].End.Equal(expected[i].End) {
+			t.Fatalf("Unexpected free slot %d: %v, expected %v", i, slots[i], expected[i])
+		}
+	}
+
+	if err := tt.Remove(at(10), at(11)); err != nil {
+		t.Fatalf("Failed to remove [10, 11): %v", err)
+	}
+	if tt.Contains(at(10)) || !tt.Contains(at(11)) {
+		t.Fatal("Unexpected membership after removal")
+	}
+}
+
+func TestOutOfRange(t *testing.T) {
+	tt := New()
+	if _, ok := tt.Insert(time.Time{}, base).(OutOfRangeError); !ok {
+		t.Fatal("Inserted time out of range")
+	}
+	if tt.Contains(time.Time{}) {
+		t.Fatal("Tree contains time out of range")
+	}
+}